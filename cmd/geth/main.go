@@ -120,6 +120,7 @@ var (
 		utils.MinerExtraDataFlag,
 		utils.MinerRecommitIntervalFlag,
 		utils.MinerNewPayloadTimeout,
+		utils.MinerBuilderSigningKeyFlag,
 		utils.NATFlag,
 		utils.NoDiscoverFlag,
 		utils.DiscoveryV5Flag,
@@ -190,11 +191,30 @@ var (
 
 	suaveFlags = []cli.Flag{
 		utils.SuaveEthRemoteBackendEndpointFlag,
+		utils.SuaveEthLocalGenesisFlag,
+		utils.SuaveEthLocalStateDumpFlag,
+		utils.SuaveBeaconRemoteEndpointFlag,
+		utils.SuaveRelayRemoteEndpointFlag,
 		utils.SuaveConfidentialTransportRedisEndpointFlag,
+		utils.SuaveConfidentialTransportRedisStreamsEndpointFlag,
+		utils.SuaveConfidentialTransportRedisStreamsGroupFlag,
+		utils.SuaveConfidentialTransportRedisStreamsMaxAgeFlag,
 		utils.SuaveConfidentialStoreRedisEndpointFlag,
 		utils.SuaveConfidentialStorePebbleDbPathFlag,
+		utils.SuaveConfidentialStoreQuotaSenderBidsPerBlockFlag,
+		utils.SuaveConfidentialStoreQuotaSenderBytesPerBidFlag,
+		utils.SuaveConfidentialStoreQuotaSenderTotalBytesFlag,
+		utils.SuaveConfidentialStoreQuotaContractBidsPerBlockFlag,
+		utils.SuaveConfidentialStoreQuotaContractBytesPerBidFlag,
+		utils.SuaveConfidentialStoreQuotaContractTotalBytesFlag,
+		utils.SuaveKettleKeyMaxAgeFlag,
+		utils.SuaveKettleKeyGracePeriodFlag,
 		utils.SuaveEthBundleSigningKeyFlag,
 		utils.SuaveEthBlockSigningKeyFlag,
+		utils.SuaveEncryptionKeyFlag,
+		utils.SuaveAuditLogFlag,
+		utils.SuaveAuditLogEncryptionKeyFlag,
+		utils.SuaveAuditLogPlaintextFlag,
 		utils.SuaveDevModeFlag,
 	}
 )
@@ -238,6 +258,7 @@ func init() {
 		verkleCommand,
 		// Suave commands
 		forgeCommand,
+		suaveCommand,
 	}
 	sort.Sort(cli.CommandsByName(app.Commands))
 
@@ -0,0 +1,331 @@
+package main
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/internal/flags"
+	"github.com/ethereum/go-ethereum/log"
+	suave "github.com/ethereum/go-ethereum/suave/core"
+	"github.com/ethereum/go-ethereum/suave/cstore"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	storeChainIdFlag = &cli.Uint64Flag{
+		Name:  "chainid",
+		Usage: "Chain id the creation transactions of the bids are signed for (default: chain id of the network or of the datadir)",
+	}
+	storeSkipInvalidFlag = &cli.BoolFlag{
+		Name:  "skip-invalid",
+		Usage: "Skip the bids failing signature verification instead of aborting",
+	}
+	storeOverwriteFlag = &cli.BoolFlag{
+		Name:  "overwrite",
+		Usage: "Overwrite the data of the bids already in the destination backend instead of skipping them",
+	}
+	storeToRedisEndpointFlag = &cli.StringFlag{
+		Name:  "to.redis-endpoint",
+		Usage: "Redis endpoint of the confidential storage backend to migrate to",
+	}
+	storeToPebbleDbPathFlag = &cli.StringFlag{
+		Name:  "to.pebble-store-db-path",
+		Usage: "Path to the pebble db of the confidential storage backend to migrate to",
+	}
+
+	storeBackendFlags = []cli.Flag{
+		configFileFlag,
+		utils.SuaveConfidentialStoreRedisEndpointFlag,
+		utils.SuaveConfidentialStorePebbleDbPathFlag,
+	}
+
+	suaveCommand = &cli.Command{
+		Name:  "suave",
+		Usage: "Manage the SUAVE kettle",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "store",
+				Usage: "Manage the confidential store",
+				Subcommands: []*cli.Command{
+					{
+						Name:      "export",
+						Usage:     "Export the confidential store into a file",
+						ArgsUsage: "<filename>",
+						Action:    exportConfidentialStore,
+						Flags:     storeBackendFlags,
+						Description: `
+Writes every bid of the confidential storage backend, with its signature,
+creation transaction and stored data, to the file. The file must not exist.
+If it ends with .gz, the output is gzipped.`,
+					},
+					{
+						Name:      "import",
+						Usage:     "Import a confidential store export",
+						ArgsUsage: "<filename>",
+						Action:    importConfidentialStore,
+						Flags: flags.Merge(storeBackendFlags, []cli.Flag{
+							storeChainIdFlag,
+							storeSkipInvalidFlag,
+							storeOverwriteFlag,
+							utils.SuaveFlag,
+						}, utils.DatabasePathFlags),
+						Description: `
+Adds the bids of an export to the confidential storage backend. The
+signatures of the bids are verified like the ones of the bids received
+from other kettles. Bids already in the backend are skipped, unless
+--overwrite is set. If the file ends with .gz, it is gunzipped.`,
+					},
+					{
+						Name:   "migrate",
+						Usage:  "Copy the confidential store to another backend",
+						Action: migrateConfidentialStore,
+						Flags: flags.Merge(storeBackendFlags, []cli.Flag{
+							storeToRedisEndpointFlag,
+							storeToPebbleDbPathFlag,
+							storeChainIdFlag,
+							storeSkipInvalidFlag,
+							storeOverwriteFlag,
+							utils.SuaveFlag,
+						}, utils.DatabasePathFlags),
+						Description: `
+Copies every bid of the confidential storage backend to the backend set
+with --to.redis-endpoint or --to.pebble-store-db-path, verifying their
+signatures like the import does. Bids already in the destination are
+skipped, unless --overwrite is set. The source backend is left untouched.`,
+					},
+				},
+			},
+			{
+				Name:      "replay",
+				Usage:     "Replay a recorded confidential request",
+				ArgsUsage: "<hash>",
+				Action:    replayConfidentialRequest,
+				Flags: flags.Merge([]cli.Flag{
+					configFileFlag,
+					utils.SuaveAuditLogFlag,
+					utils.SuaveAuditLogEncryptionKeyFlag,
+					utils.SuaveFlag,
+				}, utils.DatabasePathFlags),
+				Description: `
+Finds the request or SuaveTransaction hash in the audit log and executes the
+request again on the state of the block it was executed on. The precompiles
+answer with the recorded responses, so neither the confidential store nor the
+relay or execution backend is reached. The command fails if the replayed
+ConfidentialComputeResult differs from the one of the recorded
+SuaveTransaction. The state of the block must still be available.`,
+			},
+		},
+	}
+)
+
+func exportConfidentialStore(ctx *cli.Context) error {
+	if ctx.Args().Len() < 1 {
+		utils.Fatalf("This command requires an argument.")
+	}
+
+	backend := openConfidentialStore(ctx)
+	defer backend.Stop()
+
+	fn := ctx.Args().First()
+	if _, err := os.Stat(fn); err == nil {
+		utils.Fatalf("Export error: file %s already exists", fn)
+	}
+	out, err := os.OpenFile(fn, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		utils.Fatalf("Export error: %v", err)
+	}
+	defer out.Close()
+
+	var writer io.Writer = out
+	if strings.HasSuffix(fn, ".gz") {
+		gzWriter := gzip.NewWriter(writer)
+		defer gzWriter.Close()
+		writer = gzWriter
+	}
+
+	start := time.Now()
+	exported, err := cstore.ExportStore(backend, writer)
+	if err != nil {
+		utils.Fatalf("Export error: %v", err)
+	}
+	fmt.Printf("Exported %d bids in %v\n", exported, time.Since(start))
+	return nil
+}
+
+func importConfidentialStore(ctx *cli.Context) error {
+	if ctx.Args().Len() < 1 {
+		utils.Fatalf("This command requires an argument.")
+	}
+
+	config := storeImportConfig(ctx)
+	backend := openConfidentialStore(ctx)
+	defer backend.Stop()
+
+	fn := ctx.Args().First()
+	in, err := os.Open(fn)
+	if err != nil {
+		utils.Fatalf("Import error: %v", err)
+	}
+	defer in.Close()
+
+	var reader io.Reader = in
+	if strings.HasSuffix(fn, ".gz") {
+		if reader, err = gzip.NewReader(reader); err != nil {
+			utils.Fatalf("Import error: %v", err)
+		}
+	}
+
+	start := time.Now()
+	result, err := cstore.ImportStore(backend, reader, config)
+	if err != nil {
+		utils.Fatalf("Import error: %v", err)
+	}
+	fmt.Printf("Imported %d bids, %d already present, %d invalid in %v\n", result.Imported, result.Existing, result.Invalid, time.Since(start))
+	return nil
+}
+
+func migrateConfidentialStore(ctx *cli.Context) error {
+	utils.CheckExclusive(ctx, storeToRedisEndpointFlag, storeToPebbleDbPathFlag)
+
+	config := storeImportConfig(ctx)
+	src := openConfidentialStore(ctx)
+	defer src.Stop()
+
+	var (
+		dst cstore.ConfidentialStorageBackend
+		err error
+	)
+	switch {
+	case ctx.IsSet(storeToRedisEndpointFlag.Name):
+		dst, err = cstore.NewRedisStoreBackend(ctx.String(storeToRedisEndpointFlag.Name))
+	case ctx.IsSet(storeToPebbleDbPathFlag.Name):
+		dst, err = cstore.NewPebbleStoreBackend(ctx.String(storeToPebbleDbPathFlag.Name))
+	default:
+		utils.Fatalf("Migration requires --%s or --%s", storeToRedisEndpointFlag.Name, storeToPebbleDbPathFlag.Name)
+	}
+	if err != nil {
+		utils.Fatalf("Could not open the destination backend: %v", err)
+	}
+	defer dst.Stop()
+
+	start := time.Now()
+	result, err := cstore.MigrateStore(src, dst, config)
+	if err != nil {
+		utils.Fatalf("Migration error: %v", err)
+	}
+	fmt.Printf("Migrated %d bids, %d already present, %d invalid in %v\n", result.Imported, result.Existing, result.Invalid, time.Since(start))
+	return nil
+}
+
+// openConfidentialStore opens the persistent confidential storage backend set
+// with the same flags as the node. The in-memory store does not outlive the
+// node, so there is nothing to open without them. The datadir is not locked,
+// a redis backend can be exported while the node runs.
+func openConfidentialStore(ctx *cli.Context) cstore.ExportableStorageBackend {
+	cfg := loadBaseConfig(ctx)
+	utils.SetSuaveConfig(ctx, nil, &cfg.Eth.Suave)
+
+	var (
+		backend cstore.ExportableStorageBackend
+		err     error
+	)
+	switch {
+	case cfg.Eth.Suave.RedisStoreUri != "":
+		backend, err = cstore.NewRedisStoreBackend(cfg.Eth.Suave.RedisStoreUri)
+	case cfg.Eth.Suave.PebbleDbPath != "":
+		backend, err = cstore.NewPebbleStoreBackend(cfg.Eth.Suave.PebbleDbPath)
+	default:
+		utils.Fatalf("No confidential storage backend set, use --%s or --%s", utils.SuaveConfidentialStoreRedisEndpointFlag.Name, utils.SuaveConfidentialStorePebbleDbPathFlag.Name)
+	}
+	if err != nil {
+		utils.Fatalf("Could not open the confidential storage backend: %v", err)
+	}
+	return backend
+}
+
+// storeImportConfig returns the verification settings of imported bids, the
+// creation transactions being checked against the chain id of the kettle.
+func storeImportConfig(ctx *cli.Context) cstore.StoreImportConfig {
+	chainId, err := storeChainId(ctx)
+	if err != nil {
+		utils.Fatalf("Could not determine the chain id: %v", err)
+	}
+	log.Info("Verifying bids", "chainid", chainId)
+
+	return cstore.StoreImportConfig{
+		DASigner:    &cstore.AccountManagerDASigner{},
+		ChainSigner: types.LatestSignerForChainID(chainId),
+		SkipInvalid: ctx.Bool(storeSkipInvalidFlag.Name),
+		Overwrite:   ctx.Bool(storeOverwriteFlag.Name),
+	}
+}
+
+func storeChainId(ctx *cli.Context) (*big.Int, error) {
+	if ctx.IsSet(storeChainIdFlag.Name) {
+		return new(big.Int).SetUint64(ctx.Uint64(storeChainIdFlag.Name)), nil
+	}
+	if genesis := utils.MakeGenesis(ctx); genesis != nil {
+		return genesis.Config.ChainID, nil
+	}
+
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack, true)
+	defer db.Close()
+
+	chainConfig := rawdb.ReadChainConfig(db, rawdb.ReadCanonicalHash(db, 0))
+	if chainConfig == nil || chainConfig.ChainID == nil {
+		return nil, errors.New("no chain in the datadir, use --chainid")
+	}
+	return chainConfig.ChainID, nil
+}
+
+func replayConfidentialRequest(ctx *cli.Context) error {
+	if ctx.Args().Len() < 1 {
+		utils.Fatalf("This command requires an argument.")
+	}
+	hash := common.HexToHash(ctx.Args().First())
+
+	stack, cfg := makeConfigNode(ctx)
+	defer stack.Close()
+
+	if cfg.Eth.Suave.AuditLogPath == "" {
+		utils.Fatalf("No audit log set, use --%s", utils.SuaveAuditLogFlag.Name)
+	}
+	key, err := cfg.Eth.Suave.AuditLogKey()
+	if err != nil {
+		utils.Fatalf("%v", err)
+	}
+	in, err := os.Open(stack.ResolvePath(cfg.Eth.Suave.AuditLogPath))
+	if err != nil {
+		utils.Fatalf("Could not open the audit log: %v", err)
+	}
+	defer in.Close()
+
+	record, err := suave.FindAuditRecord(in, key, hash)
+	if err != nil {
+		utils.Fatalf("%v", err)
+	}
+	fmt.Printf("Request %s executed at block %d, %d precompile calls, %d store reads, %d store writes\n", record.RequestHash, record.BlockNumber, len(record.PrecompileCalls), len(record.StoreReads), len(record.StoreWrites))
+
+	chain, _ := utils.MakeChain(ctx, stack, true)
+
+	result, err := ethapi.ReplayConfidentialRequest(chain, record, nil)
+	if err != nil {
+		utils.Fatalf("Replay error: %v", err)
+	}
+	fmt.Printf("SuaveTransaction %s replayed, ConfidentialComputeResult matches: %x\n", record.TxHash, result)
+	return nil
+}
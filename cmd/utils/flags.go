@@ -522,6 +522,12 @@ var (
 		Value:    ethconfig.Defaults.Miner.NewPayloadTimeout,
 		Category: flags.MinerCategory,
 	}
+	MinerBuilderSigningKeyFlag = &cli.StringFlag{
+		Name:     "miner.builder-signing-key",
+		EnvVars:  []string{"MINER_BUILDER_SIGNING_KEY"},
+		Usage:    "Key paying the proposer of blocks built from bundles (ecdsa) [default: random]",
+		Category: flags.MinerCategory,
+	}
 
 	// Suave settings
 	SuaveEthRemoteBackendEndpointFlag = &cli.StringFlag{
@@ -530,12 +536,55 @@ var (
 		Category: flags.SuaveCategory,
 	}
 
+	SuaveEthLocalGenesisFlag = &cli.StringFlag{
+		Name:     "suave.eth.local-genesis",
+		Usage:    "Genesis file of the in-memory chain blocks are built on without --suave.eth.remote_endpoint (default: funded developer account)",
+		Category: flags.SuaveCategory,
+	}
+
+	SuaveEthLocalStateDumpFlag = &cli.StringFlag{
+		Name:     "suave.eth.local-state-dump",
+		Usage:    "State dump (geth dump) to seed the in-memory chain blocks are built on without --suave.eth.remote_endpoint",
+		Category: flags.SuaveCategory,
+	}
+
+	SuaveBeaconRemoteEndpointFlag = &cli.StringFlag{
+		Name:     "suave.eth.beacon-endpoint",
+		Usage:    "Beacon node API endpoint to follow for the upcoming slot (default: disabled)",
+		Category: flags.SuaveCategory,
+	}
+
+	SuaveRelayRemoteEndpointFlag = &cli.StringFlag{
+		Name:     "suave.eth.relay-endpoint",
+		Usage:    "Relay endpoint to fetch validator registrations from, required with --suave.eth.beacon-endpoint",
+		Category: flags.SuaveCategory,
+	}
+
 	SuaveConfidentialTransportRedisEndpointFlag = &cli.StringFlag{
 		Name:     "suave.confidential.redis-transport-endpoint",
 		Usage:    "Redis endpoint to use as confidential store transport (default: no transport)",
 		Category: flags.SuaveCategory,
 	}
 
+	SuaveConfidentialTransportRedisStreamsEndpointFlag = &cli.StringFlag{
+		Name:     "suave.confidential.redis-streams-transport-endpoint",
+		Usage:    "Redis endpoint to use as durable confidential store transport, messages are kept until the kettle applied them (default: no transport)",
+		Category: flags.SuaveCategory,
+	}
+
+	SuaveConfidentialTransportRedisStreamsGroupFlag = &cli.StringFlag{
+		Name:     "suave.confidential.redis-streams-transport-group",
+		Usage:    "Consumer group of the kettle on the redis streams transport, must be stable across restarts (default: first kettle address)",
+		Category: flags.SuaveCategory,
+	}
+
+	SuaveConfidentialTransportRedisStreamsMaxAgeFlag = &cli.DurationFlag{
+		Name:     "suave.confidential.redis-streams-transport-max-age",
+		Usage:    "Age after which messages are trimmed from the redis streams transport",
+		Value:    suave.DefaultConfig.RedisStoreStreamsMaxAge,
+		Category: flags.SuaveCategory,
+	}
+
 	SuaveConfidentialStoreRedisEndpointFlag = &cli.StringFlag{
 		Name:     "suave.confidential.redis-store-endpoint",
 		Usage:    "Redis endpoint to use as confidential storage backend (default: local store)",
@@ -548,6 +597,56 @@ var (
 		Category: flags.SuaveCategory,
 	}
 
+	SuaveConfidentialStoreQuotaSenderBidsPerBlockFlag = &cli.Uint64Flag{
+		Name:     "suave.confidential.quota.sender-bids-per-block",
+		Usage:    "Maximum number of bids a request signer can create for the same block (default: unlimited)",
+		Category: flags.SuaveCategory,
+	}
+
+	SuaveConfidentialStoreQuotaSenderBytesPerBidFlag = &cli.Uint64Flag{
+		Name:     "suave.confidential.quota.sender-bytes-per-bid",
+		Usage:    "Maximum number of bytes a request signer can store in a single bid (default: unlimited)",
+		Category: flags.SuaveCategory,
+	}
+
+	SuaveConfidentialStoreQuotaSenderTotalBytesFlag = &cli.Uint64Flag{
+		Name:     "suave.confidential.quota.sender-total-bytes",
+		Usage:    "Maximum number of bytes a request signer can store overall, over the lifetime of local and pebble stores (default: unlimited)",
+		Category: flags.SuaveCategory,
+	}
+
+	SuaveConfidentialStoreQuotaContractBidsPerBlockFlag = &cli.Uint64Flag{
+		Name:     "suave.confidential.quota.contract-bids-per-block",
+		Usage:    "Maximum number of bids requests to a contract can create for the same block (default: unlimited)",
+		Category: flags.SuaveCategory,
+	}
+
+	SuaveConfidentialStoreQuotaContractBytesPerBidFlag = &cli.Uint64Flag{
+		Name:     "suave.confidential.quota.contract-bytes-per-bid",
+		Usage:    "Maximum number of bytes requests to a contract can store in a single bid (default: unlimited)",
+		Category: flags.SuaveCategory,
+	}
+
+	SuaveConfidentialStoreQuotaContractTotalBytesFlag = &cli.Uint64Flag{
+		Name:     "suave.confidential.quota.contract-total-bytes",
+		Usage:    "Maximum number of bytes requests to a contract can store overall, over the lifetime of local and pebble stores (default: unlimited)",
+		Category: flags.SuaveCategory,
+	}
+
+	SuaveKettleKeyMaxAgeFlag = &cli.DurationFlag{
+		Name:     "suave.kettle-key.max-age",
+		Usage:    "Age after which a kettle key has to be rotated, 0 disables the check",
+		Value:    suave.DefaultConfig.KettleKeyMaxAge,
+		Category: flags.SuaveCategory,
+	}
+
+	SuaveKettleKeyGracePeriodFlag = &cli.DurationFlag{
+		Name:     "suave.kettle-key.grace-period",
+		Usage:    "Time a rotated kettle key is still accepted for",
+		Value:    suave.DefaultConfig.KettleKeyGracePeriod,
+		Category: flags.SuaveCategory,
+	}
+
 	SuaveEthBundleSigningKeyFlag = &cli.StringFlag{
 		Name:     "suave.eth.bundle-signing-key",
 		EnvVars:  []string{"SUAVE_ETH_BUNDLE_SIGNING_KEY"},
@@ -562,6 +661,32 @@ var (
 		Category: flags.SuaveCategory,
 	}
 
+	SuaveEncryptionKeyFlag = &cli.StringFlag{
+		Name:     "suave.confidential.encryption-key",
+		EnvVars:  []string{"SUAVE_CONFIDENTIAL_ENCRYPTION_KEY"},
+		Usage:    "Key used to decrypt the confidential inputs of compute requests (ecdsa) [default: generated and kept in the datadir]",
+		Category: flags.SuaveCategory,
+	}
+
+	SuaveAuditLogFlag = &cli.StringFlag{
+		Name:     "suave.audit-log",
+		Usage:    "File the confidential executions are recorded in, relative to the datadir (default: disabled)",
+		Category: flags.SuaveCategory,
+	}
+
+	SuaveAuditLogEncryptionKeyFlag = &cli.StringFlag{
+		Name:     "suave.audit-log.encryption-key",
+		EnvVars:  []string{"SUAVE_AUDIT_LOG_ENCRYPTION_KEY"},
+		Usage:    "Key the audit records are encrypted with (hex encoded 32 bytes aes)",
+		Category: flags.SuaveCategory,
+	}
+
+	SuaveAuditLogPlaintextFlag = &cli.BoolFlag{
+		Name:     "suave.audit-log.plaintext",
+		Usage:    "Record the confidential executions, confidential inputs included, without encrypting the audit log",
+		Category: flags.SuaveCategory,
+	}
+
 	SuaveDevModeFlag = &cli.BoolFlag{
 		Name:     "suave.dev",
 		Usage:    "Dev mode for suave",
@@ -1640,6 +1765,13 @@ func setMiner(ctx *cli.Context, cfg *miner.Config) {
 	if ctx.IsSet(MinerNewPayloadTimeout.Name) {
 		cfg.NewPayloadTimeout = ctx.Duration(MinerNewPayloadTimeout.Name)
 	}
+	if ctx.IsSet(MinerBuilderSigningKeyFlag.Name) {
+		key, err := crypto.HexToECDSA(strings.TrimPrefix(ctx.String(MinerBuilderSigningKeyFlag.Name), "0x"))
+		if err != nil {
+			Fatalf("Option %q: %v", MinerBuilderSigningKeyFlag.Name, err)
+		}
+		cfg.BuilderSigningKey = key
+	}
 }
 
 func setRequiredBlocks(ctx *cli.Context, cfg *ethconfig.Config) {
@@ -1713,14 +1845,43 @@ func CheckExclusive(ctx *cli.Context, args ...interface{}) {
 
 func SetSuaveConfig(ctx *cli.Context, stack *node.Node, cfg *suave.Config) {
 	CheckExclusive(ctx, SuaveConfidentialStoreRedisEndpointFlag, SuaveConfidentialStorePebbleDbPathFlag)
+	CheckExclusive(ctx, SuaveConfidentialTransportRedisEndpointFlag, SuaveConfidentialTransportRedisStreamsEndpointFlag)
 	if ctx.IsSet(SuaveEthRemoteBackendEndpointFlag.Name) {
 		cfg.SuaveEthRemoteBackendEndpoint = ctx.String(SuaveEthRemoteBackendEndpointFlag.Name)
 	}
 
+	if ctx.IsSet(SuaveEthLocalGenesisFlag.Name) {
+		cfg.EthLocalGenesisPath = ctx.String(SuaveEthLocalGenesisFlag.Name)
+	}
+
+	if ctx.IsSet(SuaveEthLocalStateDumpFlag.Name) {
+		cfg.EthLocalStateDumpPath = ctx.String(SuaveEthLocalStateDumpFlag.Name)
+	}
+
+	if ctx.IsSet(SuaveBeaconRemoteEndpointFlag.Name) {
+		if !ctx.IsSet(SuaveRelayRemoteEndpointFlag.Name) {
+			Fatalf("Flag %s requires %s", SuaveBeaconRemoteEndpointFlag.Name, SuaveRelayRemoteEndpointFlag.Name)
+		}
+		cfg.BeaconRemoteEndpoint = ctx.String(SuaveBeaconRemoteEndpointFlag.Name)
+		cfg.RelayRemoteEndpoint = ctx.String(SuaveRelayRemoteEndpointFlag.Name)
+	}
+
 	if ctx.IsSet(SuaveConfidentialTransportRedisEndpointFlag.Name) {
 		cfg.RedisStorePubsubUri = ctx.String(SuaveConfidentialTransportRedisEndpointFlag.Name)
 	}
 
+	if ctx.IsSet(SuaveConfidentialTransportRedisStreamsEndpointFlag.Name) {
+		cfg.RedisStoreStreamsUri = ctx.String(SuaveConfidentialTransportRedisStreamsEndpointFlag.Name)
+	}
+
+	if ctx.IsSet(SuaveConfidentialTransportRedisStreamsGroupFlag.Name) {
+		cfg.RedisStoreStreamsGroup = ctx.String(SuaveConfidentialTransportRedisStreamsGroupFlag.Name)
+	}
+
+	if ctx.IsSet(SuaveConfidentialTransportRedisStreamsMaxAgeFlag.Name) {
+		cfg.RedisStoreStreamsMaxAge = ctx.Duration(SuaveConfidentialTransportRedisStreamsMaxAgeFlag.Name)
+	}
+
 	if ctx.IsSet(SuaveConfidentialStoreRedisEndpointFlag.Name) {
 		cfg.RedisStoreUri = ctx.String(SuaveConfidentialStoreRedisEndpointFlag.Name)
 	}
@@ -1729,6 +1890,38 @@ func SetSuaveConfig(ctx *cli.Context, stack *node.Node, cfg *suave.Config) {
 		cfg.PebbleDbPath = ctx.String(SuaveConfidentialStorePebbleDbPathFlag.Name)
 	}
 
+	if ctx.IsSet(SuaveConfidentialStoreQuotaSenderBidsPerBlockFlag.Name) {
+		cfg.StoreQuotas.Sender.BidsPerBlock = ctx.Uint64(SuaveConfidentialStoreQuotaSenderBidsPerBlockFlag.Name)
+	}
+
+	if ctx.IsSet(SuaveConfidentialStoreQuotaSenderBytesPerBidFlag.Name) {
+		cfg.StoreQuotas.Sender.BytesPerBid = ctx.Uint64(SuaveConfidentialStoreQuotaSenderBytesPerBidFlag.Name)
+	}
+
+	if ctx.IsSet(SuaveConfidentialStoreQuotaSenderTotalBytesFlag.Name) {
+		cfg.StoreQuotas.Sender.TotalBytes = ctx.Uint64(SuaveConfidentialStoreQuotaSenderTotalBytesFlag.Name)
+	}
+
+	if ctx.IsSet(SuaveConfidentialStoreQuotaContractBidsPerBlockFlag.Name) {
+		cfg.StoreQuotas.Contract.BidsPerBlock = ctx.Uint64(SuaveConfidentialStoreQuotaContractBidsPerBlockFlag.Name)
+	}
+
+	if ctx.IsSet(SuaveConfidentialStoreQuotaContractBytesPerBidFlag.Name) {
+		cfg.StoreQuotas.Contract.BytesPerBid = ctx.Uint64(SuaveConfidentialStoreQuotaContractBytesPerBidFlag.Name)
+	}
+
+	if ctx.IsSet(SuaveConfidentialStoreQuotaContractTotalBytesFlag.Name) {
+		cfg.StoreQuotas.Contract.TotalBytes = ctx.Uint64(SuaveConfidentialStoreQuotaContractTotalBytesFlag.Name)
+	}
+
+	if ctx.IsSet(SuaveKettleKeyMaxAgeFlag.Name) {
+		cfg.KettleKeyMaxAge = ctx.Duration(SuaveKettleKeyMaxAgeFlag.Name)
+	}
+
+	if ctx.IsSet(SuaveKettleKeyGracePeriodFlag.Name) {
+		cfg.KettleKeyGracePeriod = ctx.Duration(SuaveKettleKeyGracePeriodFlag.Name)
+	}
+
 	if ctx.IsSet(SuaveEthBundleSigningKeyFlag.Name) {
 		cfg.EthBundleSigningKeyHex = ctx.String(SuaveEthBundleSigningKeyFlag.Name)
 	}
@@ -1736,6 +1929,22 @@ func SetSuaveConfig(ctx *cli.Context, stack *node.Node, cfg *suave.Config) {
 	if ctx.IsSet(SuaveEthBlockSigningKeyFlag.Name) {
 		cfg.EthBlockSigningKeyHex = ctx.String(SuaveEthBlockSigningKeyFlag.Name)
 	}
+
+	if ctx.IsSet(SuaveEncryptionKeyFlag.Name) {
+		cfg.EncryptionKeyHex = ctx.String(SuaveEncryptionKeyFlag.Name)
+	}
+
+	if ctx.IsSet(SuaveAuditLogFlag.Name) {
+		cfg.AuditLogPath = ctx.String(SuaveAuditLogFlag.Name)
+	}
+
+	if ctx.IsSet(SuaveAuditLogEncryptionKeyFlag.Name) {
+		cfg.AuditLogEncryptionKeyHex = ctx.String(SuaveAuditLogEncryptionKeyFlag.Name)
+	}
+
+	if ctx.IsSet(SuaveAuditLogPlaintextFlag.Name) {
+		cfg.AuditLogPlaintext = ctx.Bool(SuaveAuditLogPlaintextFlag.Name)
+	}
 }
 
 // SetEthConfig applies eth-related command line flags to the config.
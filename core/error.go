@@ -100,4 +100,8 @@ var (
 
 	// ErrSenderNoEOA is returned if the sender of a transaction is a contract.
 	ErrSenderNoEOA = errors.New("sender not an eoa")
+
+	// ErrUnauthorizedKettle is returned if a SuaveTransaction is signed by a
+	// kettle missing from the kettle registry of the chain.
+	ErrUnauthorizedKettle = errors.New("kettle not authorized")
 )
@@ -0,0 +1,67 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"golang.org/x/exp/slices"
+)
+
+// CheckKettleAuthorization returns ErrUnauthorizedKettle if the transaction is
+// a SuaveTransaction of a kettle missing from the kettle registry of the
+// chain. Other transactions, and any SuaveTransaction on chains without a
+// registry or before the kettle registry block, are accepted.
+func CheckKettleAuthorization(config *params.ChainConfig, blockNumber *big.Int, statedb vm.StateDB, tx *types.Transaction) error {
+	if !config.IsKettleRegistry(blockNumber) {
+		return nil
+	}
+	suaveTx, ok := types.CastTxInner[*types.SuaveTransaction](tx)
+	if !ok {
+		return nil
+	}
+
+	kettle := suaveTx.ConfidentialComputeRequest.KettleAddress
+	if !IsKettleAuthorized(config.KettleRegistry, statedb, kettle) {
+		return fmt.Errorf("%w: %s", ErrUnauthorizedKettle, kettle)
+	}
+	return nil
+}
+
+// IsKettleAuthorized reports whether the kettle is listed in the registry
+// config or set in the mapping of the registry contract.
+func IsKettleAuthorized(registry *params.KettleRegistryConfig, statedb vm.StateDB, kettle common.Address) bool {
+	if slices.Contains(registry.Kettles, kettle) {
+		return true
+	}
+	if registry.Contract == nil {
+		return false
+	}
+	return statedb.GetState(*registry.Contract, KettleRegistrySlot(kettle)) != (common.Hash{})
+}
+
+// KettleRegistrySlot returns the storage slot of the kettle in the
+// mapping(address => bool) at the first slot of the registry contract.
+func KettleRegistrySlot(kettle common.Address) common.Hash {
+	return crypto.Keccak256Hash(common.LeftPadBytes(kettle.Bytes(), 32), common.Hash{}.Bytes())
+}
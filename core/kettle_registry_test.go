@@ -0,0 +1,116 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// signSuaveTransaction returns a SuaveTransaction for a request to the
+// kettle, the kettle signing both the request and the result.
+func signSuaveTransaction(t *testing.T, signer types.Signer, kettleKey *ecdsa.PrivateKey) *types.Transaction {
+	record, err := types.SignTx(types.NewTx(&types.ConfidentialComputeRecord{
+		GasPrice:      big.NewInt(params.InitialBaseFee),
+		Gas:           params.TxGas,
+		To:            &common.Address{0x42},
+		KettleAddress: crypto.PubkeyToAddress(kettleKey.PublicKey),
+	}), signer, kettleKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signedRecord, _ := types.CastTxInner[*types.ConfidentialComputeRecord](record)
+
+	tx, err := types.SignTx(types.NewTx(&types.SuaveTransaction{ConfidentialComputeRequest: *signedRecord}), signer, kettleKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tx
+}
+
+func TestKettleRegistry(t *testing.T) {
+	var (
+		config     = *params.AllEthashProtocolChanges
+		signer     = types.LatestSigner(&config)
+		kettleKey  = newTestKey(t)
+		kettle     = crypto.PubkeyToAddress(kettleKey.PublicKey)
+		registry   = common.Address{0x99}
+		statedb, _ = state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		tx         = signSuaveTransaction(t, signer, kettleKey)
+	)
+	statedb.AddBalance(kettle, big.NewInt(params.Ether))
+
+	// Any kettle is accepted without a registry
+	if err := CheckKettleAuthorization(&config, common.Big1, statedb, tx); err != nil {
+		t.Fatalf("kettle rejected without registry: %v", err)
+	}
+
+	// nor before the registry block
+	config.KettleRegistry = &params.KettleRegistryConfig{Kettles: []common.Address{{0x01}}, Contract: &registry}
+	if err := CheckKettleAuthorization(&config, common.Big1, statedb, tx); err != nil {
+		t.Fatalf("kettle rejected without registry block: %v", err)
+	}
+	config.KettleRegistryBlock = big.NewInt(2)
+	if err := CheckKettleAuthorization(&config, common.Big1, statedb, tx); err != nil {
+		t.Fatalf("kettle rejected before registry block: %v", err)
+	}
+
+	config.KettleRegistryBlock = common.Big1
+	if err := CheckKettleAuthorization(&config, common.Big1, statedb, tx); !errors.Is(err, ErrUnauthorizedKettle) {
+		t.Fatalf("want %v, have %v", ErrUnauthorizedKettle, err)
+	}
+
+	// The registry contract authorizes the kettle
+	statedb.SetState(registry, KettleRegistrySlot(kettle), common.BigToHash(common.Big1))
+	if err := CheckKettleAuthorization(&config, common.Big1, statedb, tx); err != nil {
+		t.Fatalf("kettle in registry contract rejected: %v", err)
+	}
+
+	// So does the chain config
+	statedb.SetState(registry, KettleRegistrySlot(kettle), common.Hash{})
+	config.KettleRegistry.Kettles = append(config.KettleRegistry.Kettles, kettle)
+	if err := CheckKettleAuthorization(&config, common.Big1, statedb, tx); err != nil {
+		t.Fatalf("kettle in chain config rejected: %v", err)
+	}
+
+	// Blocks including the SuaveTransaction of an unregistered kettle are
+	// rejected
+	config.KettleRegistry.Kettles = nil
+	header := &types.Header{Number: common.Big1, GasLimit: params.GenesisGasLimit, BaseFee: big.NewInt(params.InitialBaseFee), Difficulty: common.Big0}
+	_, err := ApplyTransaction(&config, nil, &common.Address{}, new(GasPool).AddGas(header.GasLimit), statedb, header, tx, new(uint64), vm.Config{})
+	if !errors.Is(err, ErrUnauthorizedKettle) {
+		t.Fatalf("want %v, have %v", ErrUnauthorizedKettle, err)
+	}
+}
+
+func newTestKey(t *testing.T) *ecdsa.PrivateKey {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
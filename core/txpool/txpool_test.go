@@ -322,6 +322,36 @@ func TestInvalidTransactions(t *testing.T) {
 	}
 }
 
+func TestUnauthorizedKettle(t *testing.T) {
+	t.Parallel()
+
+	config := *params.AllEthashProtocolChanges
+	config.KettleRegistry = &params.KettleRegistryConfig{Kettles: []common.Address{{0x01}}}
+	config.KettleRegistryBlock = big.NewInt(0)
+	pool, key := setupPoolWithConfig(&config)
+	defer pool.Stop()
+
+	kettle := crypto.PubkeyToAddress(key.PublicKey)
+	record, _ := types.SignTx(types.NewTx(&types.ConfidentialComputeRecord{
+		GasPrice:      big.NewInt(1),
+		Gas:           100000,
+		To:            &common.Address{0x42},
+		KettleAddress: kettle,
+	}), pool.signer, key)
+	signedRecord, _ := types.CastTxInner[*types.ConfidentialComputeRecord](record)
+	tx, _ := types.SignTx(types.NewTx(&types.SuaveTransaction{ConfidentialComputeRequest: *signedRecord}), pool.signer, key)
+
+	testAddBalance(pool, kettle, big.NewInt(params.Ether))
+	if err, want := pool.AddRemote(tx), core.ErrUnauthorizedKettle; !errors.Is(err, want) {
+		t.Errorf("want %v have %v", want, err)
+	}
+
+	config.KettleRegistry.Kettles = append(config.KettleRegistry.Kettles, kettle)
+	if err := pool.AddRemote(tx); err != nil {
+		t.Error("expected", nil, "got", err)
+	}
+}
+
 func TestQueue(t *testing.T) {
 	t.Parallel()
 
@@ -1,9 +1,15 @@
 package types
 
 import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"errors"
+	"fmt"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ecies"
 )
 
 type ConfidentialComputeRecord struct {
@@ -131,6 +137,64 @@ func (tx *ConfidentialComputeRequest) setSignatureValues(chainID, v, r, s *big.I
 	tx.ChainID, tx.V, tx.R, tx.S = chainID, v, r, s
 }
 
+// EncryptedConfidentialComputeRequest is a confidential compute request whose
+// confidential inputs are ECIES-encrypted to the encryption key of the kettle.
+// The signed record commits to the hash of the plaintext inputs, which is
+// verified by the kettle after decryption.
+type EncryptedConfidentialComputeRequest struct {
+	ConfidentialComputeRecord
+	EncryptedConfidentialInputs []byte
+}
+
+// NewEncryptedConfidentialComputeRequest seals the confidential inputs of the
+// request to the encryption key of the kettle.
+func NewEncryptedConfidentialComputeRequest(ccr *ConfidentialComputeRequest, kettleKey *ecdsa.PublicKey) (*EncryptedConfidentialComputeRequest, error) {
+	encryptedInputs, err := ecies.Encrypt(rand.Reader, ecies.ImportECDSAPublic(kettleKey), ccr.ConfidentialInputs, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	record := *(ccr.ConfidentialComputeRecord.copy().(*ConfidentialComputeRecord))
+	record.ConfidentialInputsHash = crypto.Keccak256Hash(ccr.ConfidentialInputs)
+
+	return &EncryptedConfidentialComputeRequest{
+		ConfidentialComputeRecord:   record,
+		EncryptedConfidentialInputs: encryptedInputs,
+	}, nil
+}
+
+// Decrypt opens the confidential inputs with the encryption key of the kettle
+// and returns the plain compute request.
+func (tx *EncryptedConfidentialComputeRequest) Decrypt(kettleKey *ecdsa.PrivateKey) (*ConfidentialComputeRequest, error) {
+	confidentialInputs, err := ecies.ImportECDSA(kettleKey).Decrypt(tx.EncryptedConfidentialInputs, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not decrypt confidential inputs: %w", err)
+	}
+
+	if tx.ConfidentialInputsHash != crypto.Keccak256Hash(confidentialInputs) {
+		return nil, errors.New("confidential inputs hash mismatch")
+	}
+
+	return &ConfidentialComputeRequest{
+		ConfidentialComputeRecord: *(tx.ConfidentialComputeRecord.copy().(*ConfidentialComputeRecord)),
+		ConfidentialInputs:        confidentialInputs,
+	}, nil
+}
+
+// copy creates a deep copy of the transaction data and initializes all fields.
+func (tx *EncryptedConfidentialComputeRequest) copy() TxData {
+	cpy := &EncryptedConfidentialComputeRequest{
+		ConfidentialComputeRecord:   *(tx.ConfidentialComputeRecord.copy().(*ConfidentialComputeRecord)),
+		EncryptedConfidentialInputs: common.CopyBytes(tx.EncryptedConfidentialInputs),
+	}
+
+	return cpy
+}
+
+func (tx *EncryptedConfidentialComputeRequest) txType() byte {
+	return EncryptedConfidentialComputeRequestTxType
+}
+
 type SuaveTransaction struct {
 	ConfidentialComputeRequest ConfidentialComputeRecord `json:"confidentialComputeRequest" gencodec:"required"`
 	ConfidentialComputeResult  []byte                    `json:"confidentialComputeResult" gencodec:"required"`
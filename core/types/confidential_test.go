@@ -109,3 +109,59 @@ func TestSuaveTx(t *testing.T) {
 
 	require.Equal(t, crypto.PubkeyToAddress(testKey.PublicKey), recoveredUnmarshalledSender)
 }
+
+func TestEncryptedCCR(t *testing.T) {
+	testKey, err := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	require.NoError(t, err)
+
+	kettleKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	confidentialInputs := []byte("confidential bundle data")
+
+	encryptedRequest, err := NewEncryptedConfidentialComputeRequest(&ConfidentialComputeRequest{
+		ConfidentialComputeRecord: ConfidentialComputeRecord{
+			KettleAddress: crypto.PubkeyToAddress(kettleKey.PublicKey),
+		},
+		ConfidentialInputs: confidentialInputs,
+	}, &kettleKey.PublicKey)
+	require.NoError(t, err)
+	require.NotContains(t, string(encryptedRequest.EncryptedConfidentialInputs), string(confidentialInputs))
+
+	signer := NewSuaveSigner(new(big.Int))
+	signedTx, err := SignTx(NewTx(encryptedRequest), signer, testKey)
+	require.NoError(t, err)
+
+	marshalledTxBytes, err := signedTx.MarshalBinary()
+	require.NoError(t, err)
+
+	unmarshalledTx := new(Transaction)
+	require.NoError(t, unmarshalledTx.UnmarshalBinary(marshalledTxBytes))
+	require.Equal(t, signedTx.Hash(), unmarshalledTx.Hash())
+
+	recoveredSender, err := signer.Sender(unmarshalledTx)
+	require.NoError(t, err)
+	require.Equal(t, crypto.PubkeyToAddress(testKey.PublicKey), recoveredSender)
+
+	marshalledTxJSON, err := unmarshalledTx.MarshalJSON()
+	require.NoError(t, err)
+	require.NoError(t, unmarshalledTx.UnmarshalJSON(marshalledTxJSON))
+
+	encryptedInner, ok := CastTxInner[*EncryptedConfidentialComputeRequest](unmarshalledTx)
+	require.True(t, ok)
+
+	// only the kettle can open the confidential inputs
+	otherKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	_, err = encryptedInner.Decrypt(otherKey)
+	require.Error(t, err)
+
+	request, err := encryptedInner.Decrypt(kettleKey)
+	require.NoError(t, err)
+	require.Equal(t, confidentialInputs, request.ConfidentialInputs)
+
+	// the record signature is valid for the decrypted request
+	recoveredRequestSender, err := signer.Sender(NewTx(request))
+	require.NoError(t, err)
+	require.Equal(t, crypto.PubkeyToAddress(testKey.PublicKey), recoveredRequestSender)
+}
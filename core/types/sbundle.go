@@ -2,32 +2,82 @@ package types
 
 import (
 	"encoding/json"
+	"fmt"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/google/uuid"
 )
 
 // Simplified Share Bundle Type for PoC
 
 type SBundle struct {
-	BlockNumber     *big.Int      `json:"blockNumber,omitempty"` // if BlockNumber is set it must match DecryptionCondition!
-	Txs             Transactions  `json:"txs"`
-	RevertingHashes []common.Hash `json:"revertingHashes,omitempty"`
-	RefundPercent   *int          `json:"percent,omitempty"`
+	BlockNumber     *big.Int                     `json:"blockNumber,omitempty"` // if BlockNumber is set it must match DecryptionCondition!
+	MaxBlock        *big.Int                     `json:"maxBlock,omitempty"`
+	Txs             Transactions                 `json:"txs"`
+	RevertingHashes []common.Hash                `json:"revertingHashes,omitempty"`
+	RefundPercent   *int                         `json:"percent,omitempty"`
+	RefundConfig    []MevShareBundleRefundConfig `json:"refundConfig,omitempty"`
+	Privacy         *MevShareBundlePrivacy       `json:"privacy,omitempty"`
+
+	// Transactions left out of the block if they fail or revert, instead of failing the bundle
+	DroppingTxHashes []common.Hash `json:"droppingTxHashes,omitempty"`
+	// Unix time bounds of the blocks the bundle can be included in, zero is unbounded
+	MinTimestamp uint64 `json:"minTimestamp,omitempty"`
+	MaxTimestamp uint64 `json:"maxTimestamp,omitempty"`
+	// Bundles sent by the same searcher with the same replacement UUID replace
+	// each other, a bundle without transactions cancels the earlier ones
+	ReplacementUuid *uuid.UUID `json:"replacementUuid,omitempty"`
 }
 
 type RpcSBundle struct {
-	BlockNumber     *hexutil.Big    `json:"blockNumber,omitempty"`
-	Txs             []hexutil.Bytes `json:"txs"`
-	RevertingHashes []common.Hash   `json:"revertingHashes,omitempty"`
-	RefundPercent   *int            `json:"percent,omitempty"`
+	BlockNumber      *hexutil.Big                 `json:"blockNumber,omitempty"`
+	MaxBlock         *hexutil.Big                 `json:"maxBlock,omitempty"`
+	Txs              []hexutil.Bytes              `json:"txs"`
+	RevertingHashes  []common.Hash                `json:"revertingHashes,omitempty"`
+	RefundPercent    *int                         `json:"percent,omitempty"`
+	RefundConfig     []MevShareBundleRefundConfig `json:"refundConfig,omitempty"`
+	Privacy          *MevShareBundlePrivacy       `json:"privacy,omitempty"`
+	DroppingTxHashes []common.Hash                `json:"droppingTxHashes,omitempty"`
+	MinTimestamp     uint64                       `json:"minTimestamp,omitempty"`
+	MaxTimestamp     uint64                       `json:"maxTimestamp,omitempty"`
+	ReplacementUuid  *uuid.UUID                   `json:"replacementUuid,omitempty"`
+}
+
+// IsCancellation reports whether the bundle cancels the earlier bundles with
+// its replacement UUID.
+func (s *SBundle) IsCancellation() bool {
+	return s.ReplacementUuid != nil && len(s.Txs) == 0
+}
+
+// Hash identifies the execution of the bundle: its transactions and which of
+// them are allowed to revert or to be dropped.
+func (s *SBundle) Hash() common.Hash {
+	txHashes := make([]common.Hash, len(s.Txs))
+	for i, tx := range s.Txs {
+		txHashes[i] = tx.Hash()
+	}
+	return rlpHash([]interface{}{txHashes, s.RevertingHashes, s.DroppingTxHashes})
+}
+
+// CheckTimestamp returns an error if a block with the given timestamp is
+// outside of the bundle's timestamp bounds.
+func (s *SBundle) CheckTimestamp(timestamp uint64) error {
+	if s.MinTimestamp != 0 && timestamp < s.MinTimestamp {
+		return fmt.Errorf("block timestamp %d is before the bundle min timestamp %d", timestamp, s.MinTimestamp)
+	}
+	if s.MaxTimestamp != 0 && timestamp > s.MaxTimestamp {
+		return fmt.Errorf("block timestamp %d is after the bundle max timestamp %d", timestamp, s.MaxTimestamp)
+	}
+	return nil
 }
 
 func (s *SBundle) MarshalJSON() ([]byte, error) {
 	txs := []hexutil.Bytes{}
 	for _, tx := range s.Txs {
-		txBytes, err := tx.MarshalBinary()
+		// Blob transactions keep their sidecar
+		txBytes, err := tx.MarshalNetworkBinary()
 		if err != nil {
 			return nil, err
 		}
@@ -40,11 +90,25 @@ func (s *SBundle) MarshalJSON() ([]byte, error) {
 		*blockNumber = hexutil.Big(*s.BlockNumber)
 	}
 
+	var maxBlock *hexutil.Big
+	if s.MaxBlock != nil {
+		maxBlock = new(hexutil.Big)
+		*maxBlock = hexutil.Big(*s.MaxBlock)
+	}
+
 	return json.Marshal(&RpcSBundle{
 		BlockNumber:     blockNumber,
+		MaxBlock:        maxBlock,
 		Txs:             txs,
 		RevertingHashes: s.RevertingHashes,
 		RefundPercent:   s.RefundPercent,
+		RefundConfig:    s.RefundConfig,
+		Privacy:         s.Privacy,
+
+		DroppingTxHashes: s.DroppingTxHashes,
+		MinTimestamp:     s.MinTimestamp,
+		MaxTimestamp:     s.MaxTimestamp,
+		ReplacementUuid:  s.ReplacementUuid,
 	})
 }
 
@@ -61,31 +125,101 @@ func (s *SBundle) UnmarshalJSON(data []byte) error {
 		if err != nil {
 			return err
 		}
+		if sidecar := tx.BlobTxSidecar(); sidecar != nil {
+			if err := sidecar.ValidateBlobCommitmentHashes(tx.BlobHashes()); err != nil {
+				return fmt.Errorf("invalid sidecar of blob transaction %s: %w", tx.Hash(), err)
+			}
+		}
 
 		txs = append(txs, &tx)
 	}
 
 	s.BlockNumber = (*big.Int)(rpcSBundle.BlockNumber)
+	s.MaxBlock = (*big.Int)(rpcSBundle.MaxBlock)
 	s.Txs = txs
 	s.RevertingHashes = rpcSBundle.RevertingHashes
 	s.RefundPercent = rpcSBundle.RefundPercent
+	s.RefundConfig = rpcSBundle.RefundConfig
+	s.Privacy = rpcSBundle.Privacy
+	s.DroppingTxHashes = rpcSBundle.DroppingTxHashes
+	s.MinTimestamp = rpcSBundle.MinTimestamp
+	s.MaxTimestamp = rpcSBundle.MaxTimestamp
+	s.ReplacementUuid = rpcSBundle.ReplacementUuid
+
+	if s.MinTimestamp != 0 && s.MaxTimestamp != 0 && s.MaxTimestamp < s.MinTimestamp {
+		return fmt.Errorf("bundle max timestamp %d is before its min timestamp %d", s.MaxTimestamp, s.MinTimestamp)
+	}
 
 	return nil
 }
 
+// RPCMevShareBundle is the v0.1 bundle format accepted by mev_sendBundle.
 type RPCMevShareBundle struct {
-	Version   string `json:"version"`
-	Inclusion struct {
-		Block string `json:"block"`
-	} `json:"inclusion"`
-	Body []struct {
-		Tx        string `json:"tx"`
-		CanRevert bool   `json:"canRevert"`
-	} `json:"body"`
-	Validity struct {
-		Refund []struct {
-			BodyIdx int `json:"bodyIdx"`
-			Percent int `json:"percent"`
-		} `json:"refund"`
-	} `json:"validity"`
+	Version   string                  `json:"version"`
+	Inclusion MevShareBundleInclusion `json:"inclusion"`
+	Body      []MevShareBundleBody    `json:"body"`
+	Validity  MevShareBundleValidity  `json:"validity"`
+	Privacy   *MevShareBundlePrivacy  `json:"privacy,omitempty"`
+}
+
+type MevShareBundleInclusion struct {
+	Block    string `json:"block"`
+	MaxBlock string `json:"maxBlock,omitempty"`
+}
+
+// MevShareBundleBody is a single body entry. Exactly one of Hash, Tx or
+// Bundle is expected to be set.
+type MevShareBundleBody struct {
+	Hash      string             `json:"hash,omitempty"`
+	Tx        string             `json:"tx,omitempty"`
+	CanRevert bool               `json:"canRevert,omitempty"`
+	Bundle    *RPCMevShareBundle `json:"bundle,omitempty"`
+}
+
+type MevShareBundleValidity struct {
+	Refund       []MevShareBundleRefund       `json:"refund,omitempty"`
+	RefundConfig []MevShareBundleRefundConfig `json:"refundConfig,omitempty"`
+}
+
+type MevShareBundleRefund struct {
+	BodyIdx int `json:"bodyIdx"`
+	Percent int `json:"percent"`
+}
+
+type MevShareBundleRefundConfig struct {
+	Address common.Address `json:"address"`
+	Percent int            `json:"percent"`
+}
+
+type MevShareBundlePrivacy struct {
+	Hints    []string `json:"hints,omitempty"`
+	Builders []string `json:"builders,omitempty"`
+}
+
+// Proposer payment modes of BuildBlockArgsV2.PaymentMode, how the value of a
+// block built from bundles reaches the fee recipient of the proposer.
+const (
+	// The builder collects the fees as coinbase and pays them, minus the cost
+	// of the transfer, to the fee recipient in the last transaction
+	ProposerPaymentTx uint8 = iota
+	// The fee recipient is the coinbase, no payment transaction is needed but
+	// bundles cannot be refunded
+	ProposerPaymentCoinbase
+)
+
+// V2 returns the block building arguments with the default proposer payment
+// mode, the one blocks built through the first version are paid with.
+func (args BuildBlockArgs) V2() BuildBlockArgsV2 {
+	return BuildBlockArgsV2{
+		Slot:           args.Slot,
+		ProposerPubkey: args.ProposerPubkey,
+		Parent:         args.Parent,
+		Timestamp:      args.Timestamp,
+		FeeRecipient:   args.FeeRecipient,
+		GasLimit:       args.GasLimit,
+		Random:         args.Random,
+		Withdrawals:    args.Withdrawals,
+		Extra:          args.Extra,
+		PaymentMode:    ProposerPaymentTx,
+	}
 }
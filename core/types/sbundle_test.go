@@ -0,0 +1,119 @@
+package types
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/google/uuid"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSBundleMarshalling(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	signer := LatestSignerForChainID(big.NewInt(1))
+	blobSigner := NewCancunSigner(big.NewInt(1)) // Bundles target the L1, not SUAVE
+
+	tx := MustSignNewTx(key, signer, &DynamicFeeTx{
+		ChainID:   big.NewInt(1),
+		Nonce:     1,
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(2),
+		Gas:       21000,
+		To:        &common.Address{0x42},
+	})
+
+	sidecar := &BlobTxSidecar{
+		Blobs:       []kzg4844.Blob{{0x1}},
+		Commitments: []kzg4844.Commitment{{0x2}},
+		Proofs:      []kzg4844.Proof{{0x3}},
+	}
+	blobTx := MustSignNewTx(key, blobSigner, &BlobTx{
+		ChainID:    uint256.NewInt(1),
+		Nonce:      2,
+		GasTipCap:  uint256.NewInt(1),
+		GasFeeCap:  uint256.NewInt(2),
+		Gas:        21000,
+		To:         &common.Address{0x43},
+		Value:      uint256.NewInt(0),
+		BlobFeeCap: uint256.NewInt(1),
+		BlobHashes: sidecar.BlobHashes(),
+		Sidecar:    sidecar,
+	})
+
+	replacementUuid := uuid.New()
+	bundle := &SBundle{
+		BlockNumber:      big.NewInt(10),
+		Txs:              Transactions{tx, blobTx},
+		RevertingHashes:  []common.Hash{tx.Hash()},
+		DroppingTxHashes: []common.Hash{blobTx.Hash()},
+		MinTimestamp:     100,
+		MaxTimestamp:     200,
+		ReplacementUuid:  &replacementUuid,
+	}
+
+	data, err := json.Marshal(bundle)
+	require.NoError(t, err)
+
+	var decoded SBundle
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, bundle.BlockNumber, decoded.BlockNumber)
+	require.Equal(t, bundle.RevertingHashes, decoded.RevertingHashes)
+	require.Equal(t, bundle.DroppingTxHashes, decoded.DroppingTxHashes)
+	require.Equal(t, uint64(100), decoded.MinTimestamp)
+	require.Equal(t, uint64(200), decoded.MaxTimestamp)
+	require.Equal(t, replacementUuid, *decoded.ReplacementUuid)
+	require.False(t, decoded.IsCancellation())
+
+	// The blob transaction keeps its sidecar and hash
+	require.Len(t, decoded.Txs, 2)
+	require.Equal(t, tx.Hash(), decoded.Txs[0].Hash())
+	require.Equal(t, blobTx.Hash(), decoded.Txs[1].Hash())
+	require.Equal(t, sidecar, decoded.Txs[1].BlobTxSidecar())
+
+	// Blocks include it without its sidecar
+	blockTx := decoded.Txs[1].WithoutBlobTxSidecar()
+	require.Nil(t, blockTx.BlobTxSidecar())
+	require.Equal(t, blobTx.Hash(), blockTx.Hash())
+	require.NotNil(t, decoded.Txs[1].BlobTxSidecar())
+
+	canonical, err := blockTx.MarshalBinary()
+	require.NoError(t, err)
+	network, err := blockTx.MarshalNetworkBinary()
+	require.NoError(t, err)
+	require.Equal(t, canonical, network)
+
+	// Sidecars must match the blob hashes of their transaction
+	invalid := &SBundle{Txs: Transactions{MustSignNewTx(key, blobSigner, &BlobTx{
+		ChainID:    uint256.NewInt(1),
+		GasTipCap:  uint256.NewInt(1),
+		GasFeeCap:  uint256.NewInt(2),
+		Value:      uint256.NewInt(0),
+		BlobFeeCap: uint256.NewInt(1),
+		BlobHashes: []common.Hash{{0x1}},
+		Sidecar:    sidecar,
+	})}}
+	data, err = json.Marshal(invalid)
+	require.NoError(t, err)
+	require.Error(t, json.Unmarshal(data, &decoded))
+}
+
+func TestSBundleTimestamp(t *testing.T) {
+	bundle := &SBundle{MinTimestamp: 100, MaxTimestamp: 200}
+	require.Error(t, bundle.CheckTimestamp(99))
+	require.NoError(t, bundle.CheckTimestamp(100))
+	require.NoError(t, bundle.CheckTimestamp(200))
+	require.Error(t, bundle.CheckTimestamp(201))
+
+	require.NoError(t, (&SBundle{}).CheckTimestamp(0))
+
+	var decoded SBundle
+	require.Error(t, json.Unmarshal([]byte(`{"txs":[],"minTimestamp":200,"maxTimestamp":100}`), &decoded))
+
+	require.NoError(t, json.Unmarshal([]byte(`{"txs":[],"replacementUuid":"`+uuid.NewString()+`"}`), &decoded))
+	require.True(t, decoded.IsCancellation())
+}
@@ -1,5 +1,5 @@
 // Code generated by suave/gen. DO NOT EDIT.
-// Hash: aca9d97ce0be5bb3637b3f1bab2aeda459891800e3d1071a469b8ea4309b5531
+// Hash: 85037167525daf32489360ed2546ea1b7d45bc74a427af9c27ab900e4e455800
 package types
 
 import "github.com/ethereum/go-ethereum/common"
@@ -8,6 +8,11 @@ type BidId [16]byte
 
 // Structs
 
+type AccessWindow struct {
+	NotBefore uint64
+	NotAfter  uint64
+}
+
 type Bid struct {
 	Id                  BidId
 	Salt                BidId
@@ -17,6 +22,19 @@ type Bid struct {
 	Version             string
 }
 
+type BidKeyRule struct {
+	KeyPrefix string
+	Readers   []common.Address
+	Writers   []common.Address
+}
+
+type BidPolicy struct {
+	Readers          []common.Address
+	Writers          []common.Address
+	KeyRules         []*BidKeyRule
+	DirectCallerOnly bool
+}
+
 type BuildBlockArgs struct {
 	Slot           uint64
 	ProposerPubkey []byte
@@ -29,6 +47,34 @@ type BuildBlockArgs struct {
 	Extra          []byte
 }
 
+type BuildBlockArgsV2 struct {
+	Slot           uint64
+	ProposerPubkey []byte
+	Parent         common.Hash
+	Timestamp      uint64
+	FeeRecipient   common.Address
+	GasLimit       uint64
+	Random         common.Hash
+	Withdrawals    []*Withdrawal
+	Extra          []byte
+	PaymentMode    uint8
+}
+
+type RelayResponse struct {
+	Url        string
+	StatusCode uint64
+	LatencyMs  uint64
+	Body       []byte
+	Error      string
+}
+
+type RelayTarget struct {
+	Url       string
+	TimeoutMs uint64
+	Ssz       bool
+	Gzip      bool
+}
+
 type Withdrawal struct {
 	Index     uint64
 	Validator uint64
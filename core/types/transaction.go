@@ -42,13 +42,14 @@ var (
 
 // Transaction types.
 const (
-	LegacyTxType                     = 0x00
-	AccessListTxType                 = 0x01
-	DynamicFeeTxType                 = 0x02
-	BlobTxType                       = 0x03
-	ConfidentialComputeRecordTxType  = 0x42
-	ConfidentialComputeRequestTxType = 0x43
-	SuaveTxType                      = 0x50
+	LegacyTxType                              = 0x00
+	AccessListTxType                          = 0x01
+	DynamicFeeTxType                          = 0x02
+	BlobTxType                                = 0x03
+	ConfidentialComputeRecordTxType           = 0x42
+	ConfidentialComputeRequestTxType          = 0x43
+	EncryptedConfidentialComputeRequestTxType = 0x44
+	SuaveTxType                               = 0x50
 )
 
 // Transaction is an Ethereum transaction.
@@ -135,6 +136,26 @@ func (tx *Transaction) MarshalBinary() ([]byte, error) {
 	return buf.Bytes(), err
 }
 
+// MarshalNetworkBinary returns the network encoding of the transaction. Blob
+// transactions carrying their sidecar are encoded with their blobs, commitments
+// and proofs as defined by EIP-4844, other transactions as by MarshalBinary.
+func (tx *Transaction) MarshalNetworkBinary() ([]byte, error) {
+	blobTx, ok := tx.inner.(*BlobTx)
+	if !ok || blobTx.Sidecar == nil {
+		return tx.MarshalBinary()
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte(tx.Type())
+	err := rlp.Encode(&buf, &blobTxWithBlobs{
+		BlobTx:      blobTx,
+		Blobs:       blobTx.Sidecar.Blobs,
+		Commitments: blobTx.Sidecar.Commitments,
+		Proofs:      blobTx.Sidecar.Proofs,
+	})
+	return buf.Bytes(), err
+}
+
 // DecodeRLP implements rlp.Decoder
 func (tx *Transaction) DecodeRLP(s *rlp.Stream) error {
 	kind, size, err := s.Kind()
@@ -200,13 +221,15 @@ func (tx *Transaction) decodeTyped(b []byte) (TxData, error) {
 		err := rlp.DecodeBytes(b[1:], &inner)
 		return &inner, err
 	case BlobTxType:
-		var inner BlobTx
-		err := rlp.DecodeBytes(b[1:], &inner)
-		return &inner, err
+		return decodeBlobTx(b[1:])
 	case ConfidentialComputeRequestTxType:
 		var inner ConfidentialComputeRequest
 		err := rlp.DecodeBytes(b[1:], &inner)
 		return &inner, err
+	case EncryptedConfidentialComputeRequestTxType:
+		var inner EncryptedConfidentialComputeRequest
+		err := rlp.DecodeBytes(b[1:], &inner)
+		return &inner, err
 	case SuaveTxType:
 		var inner SuaveTransaction
 		err := rlp.DecodeBytes(b[1:], &inner)
@@ -314,6 +337,35 @@ func (tx *Transaction) BlobGasFeeCap() *big.Int { return tx.inner.blobGasFeeCap(
 // BlobHashes returns the hases of the blob commitments for blob transactions, nil otherwise.
 func (tx *Transaction) BlobHashes() []common.Hash { return tx.inner.blobHashes() }
 
+// BlobTxSidecar returns the sidecar of a blob transaction, nil otherwise.
+func (tx *Transaction) BlobTxSidecar() *BlobTxSidecar {
+	if blobtx, ok := tx.inner.(*BlobTx); ok {
+		return blobtx.Sidecar
+	}
+	return nil
+}
+
+// WithoutBlobTxSidecar returns a copy of tx with the blob sidecar removed,
+// the form blob transactions are included in blocks with.
+func (tx *Transaction) WithoutBlobTxSidecar() *Transaction {
+	blobtx, ok := tx.inner.(*BlobTx)
+	if !ok || blobtx.Sidecar == nil {
+		return tx
+	}
+	cpy := &Transaction{
+		inner: blobtx.withoutSidecar(),
+		time:  tx.time,
+	}
+	// Note: tx.size cache not carried over because the sidecar is included in size!
+	if h := tx.hash.Load(); h != nil {
+		cpy.hash.Store(h)
+	}
+	if f := tx.from.Load(); f != nil {
+		cpy.from.Store(f)
+	}
+	return cpy
+}
+
 // Value returns the ether amount of the transaction.
 func (tx *Transaction) Value() *big.Int { return new(big.Int).Set(tx.inner.value()) }
 
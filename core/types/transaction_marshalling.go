@@ -30,26 +30,27 @@ import (
 type txJSON struct {
 	Type hexutil.Uint64 `json:"type"`
 
-	ChainID                   *hexutil.Big     `json:"chainId,omitempty"`
-	Nonce                     *hexutil.Uint64  `json:"nonce"`
-	To                        *common.Address  `json:"to"`
-	Gas                       *hexutil.Uint64  `json:"gas"`
-	GasPrice                  *hexutil.Big     `json:"gasPrice"`
-	MaxPriorityFeePerGas      *hexutil.Big     `json:"maxPriorityFeePerGas"`
-	MaxFeePerGas              *hexutil.Big     `json:"maxFeePerGas"`
-	MaxFeePerDataGas          *hexutil.Big     `json:"maxFeePerDataGas,omitempty"`
-	Value                     *hexutil.Big     `json:"value"`
-	Input                     *hexutil.Bytes   `json:"input"`
-	AccessList                *AccessList      `json:"accessList,omitempty"`
-	BlobVersionedHashes       []common.Hash    `json:"blobVersionedHashes,omitempty"`
-	KettleAddress             *common.Address  `json:"kettleAddress,omitempty"`
-	ConfidentialInputsHash    *common.Hash     `json:"confidentialInputsHash,omitempty"`
-	ConfidentialInputs        *hexutil.Bytes   `json:"confidentialInputs,omitempty"`
-	RequestRecord             *json.RawMessage `json:"requestRecord,omitempty"`
-	ConfidentialComputeResult *hexutil.Bytes   `json:"confidentialComputeResult,omitempty"`
-	V                         *hexutil.Big     `json:"v"`
-	R                         *hexutil.Big     `json:"r"`
-	S                         *hexutil.Big     `json:"s"`
+	ChainID                     *hexutil.Big     `json:"chainId,omitempty"`
+	Nonce                       *hexutil.Uint64  `json:"nonce"`
+	To                          *common.Address  `json:"to"`
+	Gas                         *hexutil.Uint64  `json:"gas"`
+	GasPrice                    *hexutil.Big     `json:"gasPrice"`
+	MaxPriorityFeePerGas        *hexutil.Big     `json:"maxPriorityFeePerGas"`
+	MaxFeePerGas                *hexutil.Big     `json:"maxFeePerGas"`
+	MaxFeePerDataGas            *hexutil.Big     `json:"maxFeePerDataGas,omitempty"`
+	Value                       *hexutil.Big     `json:"value"`
+	Input                       *hexutil.Bytes   `json:"input"`
+	AccessList                  *AccessList      `json:"accessList,omitempty"`
+	BlobVersionedHashes         []common.Hash    `json:"blobVersionedHashes,omitempty"`
+	KettleAddress               *common.Address  `json:"kettleAddress,omitempty"`
+	ConfidentialInputsHash      *common.Hash     `json:"confidentialInputsHash,omitempty"`
+	ConfidentialInputs          *hexutil.Bytes   `json:"confidentialInputs,omitempty"`
+	EncryptedConfidentialInputs *hexutil.Bytes   `json:"encryptedConfidentialInputs,omitempty"`
+	RequestRecord               *json.RawMessage `json:"requestRecord,omitempty"`
+	ConfidentialComputeResult   *hexutil.Bytes   `json:"confidentialComputeResult,omitempty"`
+	V                           *hexutil.Big     `json:"v"`
+	R                           *hexutil.Big     `json:"r"`
+	S                           *hexutil.Big     `json:"s"`
 
 	// Only used for encoding:
 	Hash common.Hash `json:"hash"`
@@ -147,6 +148,21 @@ func (tx *Transaction) MarshalJSON() ([]byte, error) {
 		enc.R = (*hexutil.Big)(itx.R)
 		enc.S = (*hexutil.Big)(itx.S)
 
+	case *EncryptedConfidentialComputeRequest:
+		enc.KettleAddress = &itx.KettleAddress
+		enc.EncryptedConfidentialInputs = (*hexutil.Bytes)(&itx.EncryptedConfidentialInputs)
+		enc.ConfidentialInputsHash = &itx.ConfidentialInputsHash
+		enc.Nonce = (*hexutil.Uint64)(&itx.Nonce)
+		enc.To = tx.To()
+		enc.Gas = (*hexutil.Uint64)(&itx.Gas)
+		enc.GasPrice = (*hexutil.Big)(itx.GasPrice)
+		enc.Value = (*hexutil.Big)(itx.Value)
+		enc.Input = (*hexutil.Bytes)(&itx.Data)
+		enc.ChainID = (*hexutil.Big)(itx.ChainID)
+		enc.V = (*hexutil.Big)(itx.V)
+		enc.R = (*hexutil.Big)(itx.R)
+		enc.S = (*hexutil.Big)(itx.S)
+
 	case *SuaveTransaction:
 		requestRecord, err := NewTx(&itx.ConfidentialComputeRequest).MarshalJSON()
 		if err != nil {
@@ -517,6 +533,70 @@ func (tx *Transaction) UnmarshalJSON(input []byte) error {
 			}
 		}
 
+	case EncryptedConfidentialComputeRequestTxType:
+		var itx EncryptedConfidentialComputeRequest
+		inner = &itx
+
+		if dec.KettleAddress == nil {
+			return errors.New("missing required field 'kettleAddress' in transaction")
+		}
+		itx.KettleAddress = *dec.KettleAddress
+
+		if dec.ConfidentialInputsHash != nil {
+			itx.ConfidentialInputsHash = *dec.ConfidentialInputsHash
+		}
+
+		if dec.EncryptedConfidentialInputs == nil {
+			return errors.New("missing required field 'encryptedConfidentialInputs' in transaction")
+		}
+		itx.EncryptedConfidentialInputs = *dec.EncryptedConfidentialInputs
+
+		if dec.Nonce == nil {
+			return errors.New("missing required field 'nonce' in transaction")
+		}
+		itx.Nonce = uint64(*dec.Nonce)
+		if dec.To != nil {
+			itx.To = dec.To
+		}
+		if dec.Gas == nil {
+			return errors.New("missing required field 'gas' in transaction")
+		}
+		itx.Gas = uint64(*dec.Gas)
+		if dec.GasPrice == nil {
+			return errors.New("missing required field 'gasPrice' in transaction")
+		}
+		itx.GasPrice = (*big.Int)(dec.GasPrice)
+		if dec.Value == nil {
+			return errors.New("missing required field 'value' in transaction")
+		}
+		itx.Value = (*big.Int)(dec.Value)
+		if dec.Input == nil {
+			return errors.New("missing required field 'input' in transaction")
+		}
+		itx.Data = *dec.Input
+		if dec.ChainID == nil {
+			return errors.New("missing required field 'chainId' in transaction")
+		}
+		itx.ChainID = (*big.Int)(dec.ChainID)
+		if dec.V == nil {
+			return errors.New("missing required field 'r' in transaction")
+		}
+		itx.V = (*big.Int)(dec.V)
+		if dec.R == nil {
+			return errors.New("missing required field 'r' in transaction")
+		}
+		itx.R = (*big.Int)(dec.R)
+		if dec.S == nil {
+			return errors.New("missing required field 's' in transaction")
+		}
+		itx.S = (*big.Int)(dec.S)
+		withSignature := itx.V.Sign() != 0 || itx.R.Sign() != 0 || itx.S.Sign() != 0
+		if withSignature {
+			if err := sanityCheckSignature(itx.V, itx.R, itx.S, false); err != nil {
+				return err
+			}
+		}
+
 	case SuaveTxType:
 		var itx SuaveTransaction
 		inner = &itx
@@ -294,6 +294,9 @@ func (s suaveSigner) Sender(tx *Transaction) (common.Address, error) {
 		if txdata.ConfidentialInputsHash != crypto.Keccak256Hash(txdata.ConfidentialInputs) {
 			return common.Address{}, errors.New("confidential inputs hash mismatch")
 		}
+	case *EncryptedConfidentialComputeRequest:
+		// the inputs hash can only be verified by the kettle after decryption
+		ccr = &txdata.ConfidentialComputeRecord
 	case *ConfidentialComputeRecord:
 		ccr = txdata
 	default:
@@ -341,6 +344,13 @@ func (s suaveSigner) SignatureValues(tx *Transaction, sig []byte) (R, S, V *big.
 		R, S, _ = decodeSignature(sig)
 		V = big.NewInt(int64(sig[64]))
 		return R, S, V, nil
+	case *EncryptedConfidentialComputeRequest:
+		if txdata.ChainID.Sign() != 0 && txdata.ChainID.Cmp(s.chainId) != 0 {
+			return nil, nil, nil, fmt.Errorf("%w: have %d want %d", ErrInvalidChainId, txdata.ChainID, s.chainId)
+		}
+		R, S, _ = decodeSignature(sig)
+		V = big.NewInt(int64(sig[64]))
+		return R, S, V, nil
 	default:
 		return s.londonSigner.SignatureValues(tx, sig)
 	}
@@ -357,6 +367,8 @@ func (s suaveSigner) Hash(tx *Transaction) common.Hash {
 				s.Hash(NewTx(&txdata.ConfidentialComputeRequest)),
 				txdata.ConfidentialComputeResult,
 			})
+	case *EncryptedConfidentialComputeRequest:
+		return s.Hash(NewTx(&txdata.ConfidentialComputeRecord))
 	case *ConfidentialComputeRequest:
 		return prefixedRlpHash(
 			ConfidentialComputeRecordTxType, // Note: this is the same as the Record so that hashes match!
@@ -17,10 +17,15 @@
 package types
 
 import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/holiman/uint256"
 )
 
@@ -38,12 +43,91 @@ type BlobTx struct {
 	BlobFeeCap *uint256.Int // a.k.a. maxFeePerDataGas
 	BlobHashes []common.Hash
 
+	// A blob transaction can optionally contain blobs. This field must be set when BlobTx
+	// is used to create a transaction for signing.
+	Sidecar *BlobTxSidecar `rlp:"-"`
+
 	// Signature values
 	V *uint256.Int `json:"v" gencodec:"required"`
 	R *uint256.Int `json:"r" gencodec:"required"`
 	S *uint256.Int `json:"s" gencodec:"required"`
 }
 
+// BlobTxSidecar contains the blobs of a blob transaction.
+type BlobTxSidecar struct {
+	Blobs       []kzg4844.Blob       // Blobs needed by the blob pool
+	Commitments []kzg4844.Commitment // Commitments needed by the blob pool
+	Proofs      []kzg4844.Proof      // Proofs needed by the blob pool
+}
+
+// BlobHashes computes the blob hashes of the given blobs.
+func (sc *BlobTxSidecar) BlobHashes() []common.Hash {
+	h := make([]common.Hash, len(sc.Commitments))
+	for i := range sc.Commitments {
+		h[i] = sha256.Sum256(sc.Commitments[i][:])
+		h[i][0] = params.BlobTxHashVersion
+	}
+	return h
+}
+
+// ValidateBlobCommitmentHashes checks that the sidecar matches the blob
+// hashes of its transaction.
+func (sc *BlobTxSidecar) ValidateBlobCommitmentHashes(hashes []common.Hash) error {
+	if len(sc.Blobs) != len(hashes) || len(sc.Commitments) != len(hashes) || len(sc.Proofs) != len(hashes) {
+		return fmt.Errorf("invalid number of %d blobs, %d commitments and %d proofs for %d blob hashes", len(sc.Blobs), len(sc.Commitments), len(sc.Proofs), len(hashes))
+	}
+	for i, hash := range sc.BlobHashes() {
+		if hash != hashes[i] {
+			return fmt.Errorf("blob %d: computed hash %#x mismatches transaction one %#x", i, hash, hashes[i])
+		}
+	}
+	return nil
+}
+
+// blobTxWithBlobs is the network encoding of blob transactions carrying
+// their sidecar.
+type blobTxWithBlobs struct {
+	BlobTx      *BlobTx
+	Blobs       []kzg4844.Blob
+	Commitments []kzg4844.Commitment
+	Proofs      []kzg4844.Proof
+}
+
+// decodeBlobTx decodes a blob transaction from either its canonical encoding
+// or its network encoding with the sidecar. The network encoding is a list
+// whose first element is the transaction itself.
+func decodeBlobTx(input []byte) (*BlobTx, error) {
+	outerList, _, err := rlp.SplitList(input)
+	if err != nil {
+		return nil, err
+	}
+	firstElemKind, _, _, err := rlp.Split(outerList)
+	if err != nil {
+		return nil, err
+	}
+
+	if firstElemKind != rlp.List {
+		var inner BlobTx
+		err := rlp.DecodeBytes(input, &inner)
+		return &inner, err
+	}
+
+	var withBlobs blobTxWithBlobs
+	if err := rlp.DecodeBytes(input, &withBlobs); err != nil {
+		return nil, err
+	}
+	if withBlobs.BlobTx == nil {
+		return nil, errors.New("missing blob transaction")
+	}
+	inner := withBlobs.BlobTx
+	inner.Sidecar = &BlobTxSidecar{
+		Blobs:       withBlobs.Blobs,
+		Commitments: withBlobs.Commitments,
+		Proofs:      withBlobs.Proofs,
+	}
+	return inner, nil
+}
+
 // copy creates a deep copy of the transaction data and initializes all fields.
 func (tx *BlobTx) copy() TxData {
 	cpy := &BlobTx{
@@ -90,9 +174,22 @@ func (tx *BlobTx) copy() TxData {
 	if tx.S != nil {
 		cpy.S.Set(tx.S)
 	}
+	if tx.Sidecar != nil {
+		cpy.Sidecar = &BlobTxSidecar{
+			Blobs:       append([]kzg4844.Blob(nil), tx.Sidecar.Blobs...),
+			Commitments: append([]kzg4844.Commitment(nil), tx.Sidecar.Commitments...),
+			Proofs:      append([]kzg4844.Proof(nil), tx.Sidecar.Proofs...),
+		}
+	}
 	return cpy
 }
 
+func (tx *BlobTx) withoutSidecar() *BlobTx {
+	cpy := *tx
+	cpy.Sidecar = nil
+	return &cpy
+}
+
 // accessors for innerTx.
 func (tx *BlobTx) txType() byte              { return BlobTxType }
 func (tx *BlobTx) chainID() *big.Int         { return tx.ChainID.ToBig() }
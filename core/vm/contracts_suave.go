@@ -37,7 +37,7 @@ func (b *suaveRuntime) confidentialStore(bidId types.BidId, key string, data []b
 
 	log.Info("confStore", "bidId", bidId, "key", key)
 
-	caller, err := checkIsPrecompileCallAllowed(b.suaveContext, confidentialStoreAddr, bid)
+	caller, err := checkIsPrecompileCallAllowed(b.suaveContext, confidentialStoreAddr, bid, key)
 	if err != nil {
 		return err
 	}
@@ -60,7 +60,7 @@ func (b *suaveRuntime) confidentialRetrieve(bidId types.BidId, key string) ([]by
 		return nil, suave.ErrBidNotFound
 	}
 
-	caller, err := checkIsPrecompileCallAllowed(b.suaveContext, confidentialRetrieveAddr, bid)
+	caller, err := checkIsPrecompileCallAllowed(b.suaveContext, confidentialRetrieveAddr, bid, key)
 	if err != nil {
 		return nil, err
 	}
@@ -98,6 +98,44 @@ func (b *suaveRuntime) newBid(decryptionCondition uint64, allowedPeekers []commo
 	return bid, nil
 }
 
+func (b *suaveRuntime) newBidWithAccessWindow(decryptionCondition uint64, allowedPeekers []common.Address, allowedStores []common.Address, BidType string, accessWindow types.AccessWindow) (types.Bid, error) {
+	if b.suaveContext.ConfidentialComputeRequestTx == nil {
+		panic("newBidWithAccessWindow: source transaction not present")
+	}
+
+	bid, err := b.suaveContext.Backend.ConfidentialStore.InitializeBidWithAccessWindow(types.Bid{
+		Salt:                suave.RandomBidId(),
+		DecryptionCondition: decryptionCondition,
+		AllowedPeekers:      allowedPeekers,
+		AllowedStores:       allowedStores,
+		Version:             BidType, // TODO : make generic
+	}, accessWindow)
+	if err != nil {
+		return types.Bid{}, err
+	}
+
+	return bid, nil
+}
+
+func (b *suaveRuntime) newBidWithPolicy(decryptionCondition uint64, allowedStores []common.Address, BidType string, policy types.BidPolicy) (types.Bid, error) {
+	if b.suaveContext.ConfidentialComputeRequestTx == nil {
+		panic("newBidWithPolicy: source transaction not present")
+	}
+
+	bid, err := b.suaveContext.Backend.ConfidentialStore.InitializeBidWithPolicy(types.Bid{
+		Salt:                suave.RandomBidId(),
+		DecryptionCondition: decryptionCondition,
+		AllowedPeekers:      policy.Readers,
+		AllowedStores:       allowedStores,
+		Version:             BidType, // TODO : make generic
+	}, policy)
+	if err != nil {
+		return types.Bid{}, err
+	}
+
+	return bid, nil
+}
+
 func (b *suaveRuntime) fetchBids(targetBlock uint64, namespace string) ([]types.Bid, error) {
 	bids1 := b.suaveContext.Backend.ConfidentialStore.FetchBidsByProtocolAndBlock(targetBlock, namespace)
 
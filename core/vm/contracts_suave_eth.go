@@ -2,12 +2,15 @@ package vm
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"math/big"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum/accounts"
@@ -17,9 +20,12 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/log"
+	suave "github.com/ethereum/go-ethereum/suave/core"
 	"github.com/flashbots/go-boost-utils/bls"
 	"github.com/flashbots/go-boost-utils/ssz"
+	"github.com/google/uuid"
 	"github.com/holiman/uint256"
+	"golang.org/x/exp/slices"
 
 	builderCapella "github.com/attestantio/go-builder-client/api/capella"
 	builderV1 "github.com/attestantio/go-builder-client/api/v1"
@@ -72,16 +78,23 @@ func (b *suaveRuntime) simulateBundle(input []byte) (uint64, error) {
 	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(time.Second))
 	defer cancel()
 
-	envelope, err := b.suaveContext.Backend.ConfidentialEthBackend.BuildEthBlock(ctx, nil, bundle.Txs)
+	sims, err := b.suaveContext.Backend.ConfidentialEthBackend.SimulateBundles(ctx, nil, []types.SBundle{bundle})
 	if err != nil {
 		return 0, err
 	}
+	if len(sims) != 1 {
+		return 0, fmt.Errorf("expected 1 bundle simulation, got %d", len(sims))
+	}
 
-	if envelope.ExecutionPayload.GasUsed == 0 {
-		return 0, err
+	sim := sims[0]
+	if sim.Error != "" {
+		return 0, errors.New(sim.Error)
+	}
+	if sim.GasUsed == 0 || sim.Profit == nil {
+		return 0, nil
 	}
 
-	egp := new(big.Int).Div(envelope.BlockValue, big.NewInt(int64(envelope.ExecutionPayload.GasUsed)))
+	egp := new(big.Int).Div(sim.Profit.ToInt(), new(big.Int).SetUint64(uint64(sim.GasUsed)))
 	return egp.Uint64(), nil
 }
 
@@ -113,9 +126,19 @@ func (b *suaveRuntime) ethcall(contractAddr common.Address, input []byte) ([]byt
 }
 
 func (b *suaveRuntime) buildEthBlock(blockArgs types.BuildBlockArgs, bidId types.BidId, namespace string) ([]byte, []byte, error) {
+	return b.buildEthBlockFromBid(buildEthBlockAddr, blockArgs.V2(), bidId)
+}
+
+func (b *suaveRuntime) buildEthBlockV2(blockArgs types.BuildBlockArgsV2, bidId types.BidId, namespace string) ([]byte, []byte, error) {
+	return b.buildEthBlockFromBid(buildEthBlockV2Addr, blockArgs, bidId)
+}
+
+// buildEthBlockFromBid builds a block from the bundles of the (possibly merged)
+// bid, accessing the bids as the precompile at precompileAddr.
+func (b *suaveRuntime) buildEthBlockFromBid(precompileAddr common.Address, blockArgs types.BuildBlockArgsV2, bidId types.BidId) ([]byte, []byte, error) {
 	bidIds := [][16]byte{}
 	// first check for merged bid, else assume regular bid
-	if mergedBidsBytes, err := b.suaveContext.Backend.ConfidentialStore.Retrieve(bidId, buildEthBlockAddr, "default:v0:mergedBids"); err == nil {
+	if mergedBidsBytes, err := b.suaveContext.Backend.ConfidentialStore.Retrieve(bidId, precompileAddr, "default:v0:mergedBids"); err == nil {
 		unpacked, err := bidIdsAbi.Inputs.Unpack(mergedBidsBytes)
 
 		if err != nil {
@@ -126,7 +149,7 @@ func (b *suaveRuntime) buildEthBlock(blockArgs types.BuildBlockArgs, bidId types
 		bidIds = append(bidIds, bidId)
 	}
 
-	var bidsToMerge = make([]types.Bid, len(bidIds))
+	var bidsToMerge = make([]suave.Bid, len(bidIds))
 	for i, bidId := range bidIds {
 		var err error
 
@@ -135,56 +158,28 @@ func (b *suaveRuntime) buildEthBlock(blockArgs types.BuildBlockArgs, bidId types
 			return nil, nil, fmt.Errorf("could not fetch bid id %v: %w", bidId, err)
 		}
 
-		if _, err := checkIsPrecompileCallAllowed(b.suaveContext, buildEthBlockAddr, bid); err != nil {
+		if _, err := checkIsPrecompileCallAllowed(b.suaveContext, precompileAddr, bid, ""); err != nil {
 			return nil, nil, err
 		}
 
-		bidsToMerge[i] = bid.ToInnerBid()
+		bidsToMerge[i] = bid
 	}
 
 	var mergedBundles []types.SBundle
+	var bundleRequests []*types.Transaction
 	for _, bid := range bidsToMerge {
+		bundleRequests = append(bundleRequests, bid.CreationTx)
 		switch bid.Version {
 		case "mevshare:v0:matchBids":
-			// fetch the matched ids and merge the bundle
-			matchedBundleIdsBytes, err := b.suaveContext.Backend.ConfidentialStore.Retrieve(bid.Id, buildEthBlockAddr, "mevshare:v0:mergedBids")
+			// resolve the (possibly nested) matched bids and merge their bundles
+			matchTree, err := b.fetchMevShareBundleTree(precompileAddr, bid.Id, 0)
 			if err != nil {
-				return nil, nil, fmt.Errorf("could not retrieve bid ids data for bid %v, from cdas: %w", bid, err)
-			}
-
-			unpackedBidIds, err := bidIdsAbi.Inputs.Unpack(matchedBundleIdsBytes)
-			if err != nil {
-				return nil, nil, fmt.Errorf("could not unpack bid ids data for bid %v, from cdas: %w", bid, err)
-			}
-
-			matchBidIds := unpackedBidIds[0].([][16]byte)
-
-			userBundleBytes, err := b.suaveContext.Backend.ConfidentialStore.Retrieve(matchBidIds[0], buildEthBlockAddr, "mevshare:v0:ethBundles")
-			if err != nil {
-				return nil, nil, fmt.Errorf("could not retrieve bundle data for bidId %v: %w", matchBidIds[0], err)
-			}
-
-			var userBundle types.SBundle
-			if err := json.Unmarshal(userBundleBytes, &userBundle); err != nil {
-				return nil, nil, fmt.Errorf("could not unmarshal user bundle data for bidId %v: %w", matchBidIds[0], err)
-			}
-
-			matchBundleBytes, err := b.suaveContext.Backend.ConfidentialStore.Retrieve(matchBidIds[1], buildEthBlockAddr, "mevshare:v0:ethBundles")
-			if err != nil {
-				return nil, nil, fmt.Errorf("could not retrieve match bundle data for bidId %v: %w", matchBidIds[1], err)
-			}
-
-			var matchBundle types.SBundle
-			if err := json.Unmarshal(matchBundleBytes, &matchBundle); err != nil {
-				return nil, nil, fmt.Errorf("could not unmarshal match bundle data for bidId %v: %w", matchBidIds[1], err)
+				return nil, nil, err
 			}
 
-			userBundle.Txs = append(userBundle.Txs, matchBundle.Txs...)
-
-			mergedBundles = append(mergedBundles, userBundle)
-
+			mergedBundles = append(mergedBundles, matchTree.flatten())
 		case "mevshare:v0:unmatchedBundles":
-			bundleBytes, err := b.suaveContext.Backend.ConfidentialStore.Retrieve(bid.Id, buildEthBlockAddr, "mevshare:v0:ethBundles")
+			bundleBytes, err := b.suaveContext.Backend.ConfidentialStore.Retrieve(bid.Id, precompileAddr, "mevshare:v0:ethBundles")
 			if err != nil {
 				return nil, nil, fmt.Errorf("could not retrieve bundle data for bidId %v, from cdas: %w", bid.Id, err)
 			}
@@ -195,7 +190,7 @@ func (b *suaveRuntime) buildEthBlock(blockArgs types.BuildBlockArgs, bidId types
 			}
 			mergedBundles = append(mergedBundles, bundle)
 		case "default:v0:ethBundles":
-			bundleBytes, err := b.suaveContext.Backend.ConfidentialStore.Retrieve(bid.Id, buildEthBlockAddr, "default:v0:ethBundles")
+			bundleBytes, err := b.suaveContext.Backend.ConfidentialStore.Retrieve(bid.Id, precompileAddr, "default:v0:ethBundles")
 			if err != nil {
 				return nil, nil, fmt.Errorf("could not retrieve bundle data for bidId %v, from cdas: %w", bid.Id, err)
 			}
@@ -210,6 +205,8 @@ func (b *suaveRuntime) buildEthBlock(blockArgs types.BuildBlockArgs, bidId types
 		}
 	}
 
+	mergedBundles = resolveBundleReplacements(mergedBundles, bundleRequests, b.suaveContext.Backend.ConfidentialStore.BundleReplacement)
+
 	log.Info("requesting a block be built", "mergedBundles", mergedBundles)
 	envelope, err := b.suaveContext.Backend.ConfidentialEthBackend.BuildEthBlockFromBundles(context.TODO(), &blockArgs, mergedBundles)
 	if err != nil {
@@ -314,6 +311,143 @@ func (b *suaveRuntime) submitEthBlockBidToRelay(relayUrl string, builderBidJson
 	return nil, nil
 }
 
+func (b *suaveRuntime) upcomingBuildBlockArgs() (types.BuildBlockArgs, error) {
+	if b.suaveContext.Backend.ConfidentialBeaconBackend == nil {
+		return types.BuildBlockArgs{}, errors.New("no beacon backend configured")
+	}
+
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(time.Second))
+	defer cancel()
+
+	blockArgs, err := b.suaveContext.Backend.ConfidentialBeaconBackend.UpcomingBuildBlockArgs(ctx)
+	if err != nil {
+		return types.BuildBlockArgs{}, fmt.Errorf("could not get upcoming build block args: %w", err)
+	}
+
+	return *blockArgs, nil
+}
+
+func (b *suaveRuntime) upcomingBuildBlockArgsV2() (types.BuildBlockArgsV2, error) {
+	blockArgs, err := b.upcomingBuildBlockArgs()
+	if err != nil {
+		return types.BuildBlockArgsV2{}, err
+	}
+	return blockArgs.V2(), nil
+}
+
+const (
+	defaultRelaySubmissionTimeout = 3 * time.Second
+	maxRelaySubmissionTimeout     = 12 * time.Second
+	maxRelayResponseBodySize      = 16 * 1024
+)
+
+// submitEthBlockBidToRelays submits the JSON encoded builder bid to every relay
+// in parallel and reports the outcome of each submission. Failing relays do not
+// fail the precompile, the caller decides what to make of the responses.
+func (b *suaveRuntime) submitEthBlockBidToRelays(relays []types.RelayTarget, builderBidJson []byte) ([]types.RelayResponse, error) {
+	var bidRequest builderCapella.SubmitBlockRequest
+	if err := bidRequest.UnmarshalJSON(builderBidJson); err != nil {
+		return nil, fmt.Errorf("could not unmarshal builder bid: %w", err)
+	}
+
+	var builderBidSsz []byte
+	for _, relay := range relays {
+		if relay.Ssz {
+			var err error
+			if builderBidSsz, err = bidRequest.MarshalSSZ(); err != nil {
+				return nil, fmt.Errorf("could not encode builder bid as ssz: %w", err)
+			}
+			break
+		}
+	}
+
+	responses := make([]types.RelayResponse, len(relays))
+
+	var wg sync.WaitGroup
+	for i, relay := range relays {
+		body := builderBidJson
+		if relay.Ssz {
+			body = builderBidSsz
+		}
+
+		wg.Add(1)
+		go func(i int, relay types.RelayTarget, body []byte) {
+			defer wg.Done()
+			responses[i] = submitBlockToRelay(relay, body)
+		}(i, relay, body)
+	}
+	wg.Wait()
+
+	return responses, nil
+}
+
+func submitBlockToRelay(relay types.RelayTarget, body []byte) types.RelayResponse {
+	response := types.RelayResponse{Url: relay.Url}
+
+	timeout := time.Duration(relay.TimeoutMs) * time.Millisecond
+	if timeout == 0 {
+		timeout = defaultRelaySubmissionTimeout
+	} else if timeout > maxRelaySubmissionTimeout {
+		timeout = maxRelaySubmissionTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if relay.Gzip {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(body); err != nil {
+			response.Error = fmt.Sprintf("could not compress request: %v", err)
+			return response
+		}
+		if err := zw.Close(); err != nil {
+			response.Error = fmt.Sprintf("could not compress request: %v", err)
+			return response
+		}
+		body = buf.Bytes()
+	}
+
+	endpoint := relay.Url + "/relay/v1/builder/blocks"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		response.Error = fmt.Sprintf("could not prepare request to relay: %v", err)
+		return response
+	}
+
+	if relay.Ssz {
+		req.Header.Add("Content-Type", "application/octet-stream")
+	} else {
+		req.Header.Add("Content-Type", "application/json")
+	}
+	if relay.Gzip {
+		req.Header.Add("Content-Encoding", "gzip")
+	}
+
+	start := time.Now()
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		response.LatencyMs = uint64(time.Since(start).Milliseconds())
+		response.Error = fmt.Sprintf("could not send request to relay: %v", err)
+		return response
+	}
+	defer resp.Body.Close()
+
+	response.StatusCode = uint64(resp.StatusCode)
+	response.Body, err = io.ReadAll(io.LimitReader(resp.Body, maxRelayResponseBodySize))
+	response.LatencyMs = uint64(time.Since(start).Milliseconds())
+	if err != nil {
+		response.Error = fmt.Sprintf("could not read response body: %v", err)
+		return response
+	}
+
+	if resp.StatusCode > 299 {
+		response.Error = fmt.Sprintf("relay request failed with code %d", resp.StatusCode)
+	}
+
+	return response
+}
+
 func executableDataToCapellaExecutionPayload(data *engine.ExecutableData) (*specCapella.ExecutionPayload, error) {
 	transactionData := make([]bellatrix.Transaction, len(data.Transactions))
 	for i, tx := range data.Transactions {
@@ -413,73 +547,297 @@ func (c *suaveRuntime) fillMevShareBundle(bidId types.BidId) ([]byte, error) {
 		return nil, err
 	}
 
-	if _, err := checkIsPrecompileCallAllowed(c.suaveContext, fillMevShareBundleAddr, bid); err != nil {
+	matchTree, err := c.fetchMevShareBundleTree(fillMevShareBundleAddr, bid.Id, 0)
+	if err != nil {
 		return nil, err
 	}
 
-	matchedBundleIdsBytes, err := c.confidentialRetrieve(bidId, "mevshare:v0:mergedBids")
+	shareBundle, err := matchTree.toMevShareBundle(bid.DecryptionCondition)
 	if err != nil {
 		return nil, err
 	}
 
-	unpackedBidIds, err := bidIdsAbi.Inputs.Unpack(matchedBundleIdsBytes)
+	return json.Marshal(shareBundle)
+}
+
+// maxMevShareBundleDepth bounds the nesting of matched bids, which also
+// guards against cycles in "mevshare:v0:mergedBids".
+const maxMevShareBundleDepth = 8
+
+// mevShareBundleNode is a matched bid resolved from the confidential store.
+// Leaves hold the bundle stored under "mevshare:v0:ethBundles", matched bids
+// hold the bids listed under "mevshare:v0:mergedBids" in order, the first one
+// being the bundle that is matched against.
+type mevShareBundleNode struct {
+	bidId    types.BidId
+	bundle   *types.SBundle
+	children []*mevShareBundleNode
+}
+
+func (c *suaveRuntime) fetchMevShareBundleTree(precompile common.Address, bidId types.BidId, depth int) (*mevShareBundleNode, error) {
+	if depth > maxMevShareBundleDepth {
+		return nil, fmt.Errorf("matched bids of %x are nested deeper than %d", bidId, maxMevShareBundleDepth)
+	}
+
+	bid, err := c.suaveContext.Backend.ConfidentialStore.FetchBidById(bidId)
 	if err != nil {
-		return nil, fmt.Errorf("could not unpack bid ids data for bid %v, from cdas: %w", bid, err)
+		return nil, fmt.Errorf("could not fetch bid id %x: %w", bidId, err)
+	}
+
+	if _, err := checkIsPrecompileCallAllowed(c.suaveContext, precompile, bid, ""); err != nil {
+		return nil, err
+	}
+
+	if bid.Version != "mevshare:v0:matchBids" {
+		return c.fetchMevShareBundleLeaf(precompile, bid.Id)
 	}
 
-	matchBidIds := unpackedBidIds[0].([][16]byte)
+	mergedBidsBytes, err := c.suaveContext.Backend.ConfidentialStore.Retrieve(bid.Id, precompile, "mevshare:v0:mergedBids")
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve bid ids data for bid %x: %w", bid.Id, err)
+	}
 
-	userBundleBytes, err := c.confidentialRetrieve(matchBidIds[0], "mevshare:v0:ethBundles")
+	unpackedBidIds, err := bidIdsAbi.Inputs.Unpack(mergedBidsBytes)
 	if err != nil {
-		return nil, fmt.Errorf("could not retrieve bundle data for bidId %v: %w", matchBidIds[0], err)
+		return nil, fmt.Errorf("could not unpack bid ids data for bid %x: %w", bid.Id, err)
 	}
 
-	var userBundle types.SBundle
-	if err := json.Unmarshal(userBundleBytes, &userBundle); err != nil {
-		return nil, fmt.Errorf("could not unmarshal user bundle data for bidId %v: %w", matchBidIds[0], err)
+	mergedBidIds := unpackedBidIds[0].([][16]byte)
+	if len(mergedBidIds) == 0 {
+		return nil, fmt.Errorf("no merged bids in matched bid %x", bid.Id)
+	}
+
+	node := &mevShareBundleNode{bidId: bid.Id}
+	for _, mergedBidId := range mergedBidIds {
+		var child *mevShareBundleNode
+		if mergedBidId == bid.Id {
+			// the matched bid stores its own bundle next to the merged ids
+			child, err = c.fetchMevShareBundleLeaf(precompile, bid.Id)
+		} else {
+			child, err = c.fetchMevShareBundleTree(precompile, mergedBidId, depth+1)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		node.children = append(node.children, child)
 	}
 
-	matchBundleBytes, err := c.confidentialRetrieve(matchBidIds[1], "mevshare:v0:ethBundles")
+	return node, nil
+}
+
+func (c *suaveRuntime) fetchMevShareBundleLeaf(precompile common.Address, bidId types.BidId) (*mevShareBundleNode, error) {
+	bundleBytes, err := c.suaveContext.Backend.ConfidentialStore.Retrieve(bidId, precompile, "mevshare:v0:ethBundles")
 	if err != nil {
-		return nil, fmt.Errorf("could not retrieve match bundle data for bidId %v: %w", matchBidIds[1], err)
+		return nil, fmt.Errorf("could not retrieve bundle data for bidId %x: %w", bidId, err)
+	}
+
+	var bundle types.SBundle
+	if err := json.Unmarshal(bundleBytes, &bundle); err != nil {
+		return nil, fmt.Errorf("could not unmarshal bundle data for bidId %x: %w", bidId, err)
 	}
 
-	var matchBundle types.SBundle
-	if err := json.Unmarshal(matchBundleBytes, &matchBundle); err != nil {
-		return nil, fmt.Errorf("could not unmarshal match bundle data for bidId %v: %w", matchBidIds[1], err)
+	return &mevShareBundleNode{bidId: bidId, bundle: &bundle}, nil
+}
+
+// flatten merges the tree into a single bundle. Transactions are ordered
+// depth-first and the refund settings of the first leaf are kept.
+func (n *mevShareBundleNode) flatten() types.SBundle {
+	if n.bundle != nil {
+		return *n.bundle
+	}
+
+	var merged types.SBundle
+	for i, child := range n.children {
+		childBundle := child.flatten()
+		if i == 0 {
+			merged = childBundle
+			merged.Txs = append(types.Transactions{}, childBundle.Txs...)
+			merged.RevertingHashes = append([]common.Hash{}, childBundle.RevertingHashes...)
+			merged.DroppingTxHashes = append([]common.Hash{}, childBundle.DroppingTxHashes...)
+			continue
+		}
+
+		merged.Txs = append(merged.Txs, childBundle.Txs...)
+		merged.RevertingHashes = append(merged.RevertingHashes, childBundle.RevertingHashes...)
+		merged.DroppingTxHashes = append(merged.DroppingTxHashes, childBundle.DroppingTxHashes...)
+		merged.MaxBlock = minBlock(merged.MaxBlock, childBundle.MaxBlock)
+		merged.MinTimestamp, merged.MaxTimestamp = intersectTimestamps(merged.MinTimestamp, merged.MaxTimestamp, childBundle.MinTimestamp, childBundle.MaxTimestamp)
+		merged.Privacy = mergeMevSharePrivacy(merged.Privacy, childBundle.Privacy)
 	}
 
+	return merged
+}
+
+// toMevShareBundle encodes the tree as a mev_sendBundle v0.1 bundle.
+// Leaves are inlined as transactions while matched bids become nested
+// bundles. Refunds are only granted to the first (matched against) entry.
+func (n *mevShareBundleNode) toMevShareBundle(block uint64) (*types.RPCMevShareBundle, error) {
 	shareBundle := &types.RPCMevShareBundle{
 		Version: "v0.1",
 	}
+	shareBundle.Inclusion.Block = hexutil.EncodeUint64(block)
+
+	children := n.children
+	if n.bundle != nil {
+		children = []*mevShareBundleNode{n}
+	}
+
+	var maxBlock *big.Int
+	for i, child := range children {
+		if child.bundle == nil {
+			nestedBundle, err := child.toMevShareBundle(block)
+			if err != nil {
+				return nil, err
+			}
+
+			if i == 0 {
+				shareBundle.Validity.RefundConfig = nestedBundle.Validity.RefundConfig
+			}
+			shareBundle.Body = append(shareBundle.Body, types.MevShareBundleBody{Bundle: nestedBundle})
+			continue
+		}
+
+		bundle := child.bundle
+		for _, tx := range bundle.Txs {
+			txBytes, err := tx.MarshalBinary()
+			if err != nil {
+				return nil, fmt.Errorf("could not marshal transaction: %w", err)
+			}
+
+			if i == 0 && bundle.RefundPercent != nil {
+				shareBundle.Validity.Refund = append(shareBundle.Validity.Refund, types.MevShareBundleRefund{
+					BodyIdx: len(shareBundle.Body),
+					Percent: *bundle.RefundPercent,
+				})
+			}
+
+			shareBundle.Body = append(shareBundle.Body, types.MevShareBundleBody{
+				Tx:        hexutil.Encode(txBytes),
+				CanRevert: slices.Contains(bundle.RevertingHashes, tx.Hash()),
+			})
+		}
+
+		if i == 0 {
+			shareBundle.Validity.RefundConfig = bundle.RefundConfig
+		}
+		maxBlock = minBlock(maxBlock, bundle.MaxBlock)
+		shareBundle.Privacy = mergeMevSharePrivacy(shareBundle.Privacy, bundle.Privacy)
+	}
 
-	shareBundle.Inclusion.Block = hexutil.EncodeUint64(bid.DecryptionCondition)
+	if maxBlock != nil {
+		if maxBlock.Uint64() < block {
+			return nil, fmt.Errorf("bundle max block %d is before inclusion block %d", maxBlock.Uint64(), block)
+		}
+		shareBundle.Inclusion.MaxBlock = hexutil.EncodeUint64(maxBlock.Uint64())
+	}
+
+	return shareBundle, nil
+}
+
+// intersectTimestamps returns the timestamp bounds satisfying both bundles,
+// zero bounds are open.
+func intersectTimestamps(minA, maxA, minB, maxB uint64) (uint64, uint64) {
+	if minB > minA {
+		minA = minB
+	}
+	if maxA == 0 || (maxB != 0 && maxB < maxA) {
+		maxA = maxB
+	}
+	return minA, maxA
+}
 
-	for _, tx := range append(userBundle.Txs, matchBundle.Txs...) {
-		txBytes, err := tx.MarshalBinary()
+// resolveBundleReplacements applies the replacement UUIDs of the bundles.
+// Bundles sent by the same searcher with the same replacement UUID replace
+// each other, the one created by the request with the highest nonce is kept.
+// If that one is a cancellation none of them are. Bundles replaced or
+// cancelled by the latest bundle recorded in the store are dropped too, even
+// if that one is not built from.
+func resolveBundleReplacements(bundles []types.SBundle, requests []*types.Transaction, recorded func(common.Address, uuid.UUID) (suave.BundleReplacement, bool, error)) []types.SBundle {
+	type replacementKey struct {
+		sender common.Address
+		uuid   uuid.UUID
+	}
+
+	latest := map[replacementKey]int{}
+	keys := make([]*replacementKey, len(bundles))
+	for i, bundle := range bundles {
+		if bundle.ReplacementUuid == nil || requests[i] == nil {
+			continue
+		}
+		sender, err := types.Sender(types.LatestSignerForChainID(requests[i].ChainId()), requests[i])
 		if err != nil {
-			return nil, fmt.Errorf("could not marshal transaction: %w", err)
+			log.Debug("could not recover the sender of a replaceable bundle", "err", err)
+			continue
 		}
 
-		shareBundle.Body = append(shareBundle.Body, struct {
-			Tx        string `json:"tx"`
-			CanRevert bool   `json:"canRevert"`
-		}{Tx: hexutil.Encode(txBytes)})
+		key := &replacementKey{sender, *bundle.ReplacementUuid}
+		keys[i] = key
+		if prev, found := latest[*key]; !found || requests[i].Nonce() >= requests[prev].Nonce() {
+			latest[*key] = i
+		}
 	}
 
-	for i := range userBundle.Txs {
-		refundPercent := 10
-		if userBundle.RefundPercent != nil {
-			refundPercent = *userBundle.RefundPercent
+	resolved := make([]types.SBundle, 0, len(bundles))
+	for i, bundle := range bundles {
+		if keys[i] != nil && latest[*keys[i]] != i {
+			log.Debug("skipping replaced bundle", "replacementUuid", bundle.ReplacementUuid)
+			continue
 		}
-		shareBundle.Validity.Refund = append(shareBundle.Validity.Refund, struct {
-			BodyIdx int `json:"bodyIdx"`
-			Percent int `json:"percent"`
-		}{
-			BodyIdx: i,
-			Percent: refundPercent,
-		})
+		if keys[i] != nil {
+			replacement, found, err := recorded(keys[i].sender, keys[i].uuid)
+			if err != nil {
+				log.Warn("could not fetch the latest replacement of a bundle", "replacementUuid", bundle.ReplacementUuid, "err", err)
+			} else if found && replacement.Replaces(requests[i].Nonce()) {
+				log.Debug("skipping bundle replaced in the store", "replacementUuid", bundle.ReplacementUuid, "bid", replacement.BidId)
+				continue
+			}
+		}
+		if bundle.IsCancellation() {
+			log.Debug("skipping cancelled bundle", "replacementUuid", bundle.ReplacementUuid)
+			continue
+		}
+		resolved = append(resolved, bundle)
+	}
+	return resolved
+}
+
+func minBlock(a, b *big.Int) *big.Int {
+	if a == nil || (b != nil && b.Cmp(a) < 0) {
+		return b
 	}
+	return a
+}
 
-	return json.Marshal(shareBundle)
+// mergeMevSharePrivacy combines the privacy settings of merged bundles.
+// Only hints and builders allowed by every bundle that restricts them are kept.
+func mergeMevSharePrivacy(a, b *types.MevShareBundlePrivacy) *types.MevShareBundlePrivacy {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+
+	return &types.MevShareBundlePrivacy{
+		Hints:    intersectStrings(a.Hints, b.Hints),
+		Builders: intersectStrings(a.Builders, b.Builders),
+	}
+}
+
+func intersectStrings(a, b []string) []string {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+
+	res := []string{}
+	for _, s := range a {
+		if slices.Contains(b, s) {
+			res = append(res, s)
+		}
+	}
+	return res
 }
@@ -1,5 +1,5 @@
 // Code generated by suave/gen. DO NOT EDIT.
-// Hash: aca9d97ce0be5bb3637b3f1bab2aeda459891800e3d1071a469b8ea4309b5531
+// Hash: 85037167525daf32489360ed2546ea1b7d45bc74a427af9c27ab900e4e455800
 package vm
 
 import (
@@ -18,6 +18,7 @@ var (
 
 type SuaveRuntime interface {
 	buildEthBlock(blockArgs types.BuildBlockArgs, bidId types.BidId, namespace string) ([]byte, []byte, error)
+	buildEthBlockV2(blockArgs types.BuildBlockArgsV2, bidId types.BidId, namespace string) ([]byte, []byte, error)
 	confidentialInputs() ([]byte, error)
 	confidentialRetrieve(bidId types.BidId, key string) ([]byte, error)
 	confidentialStore(bidId types.BidId, key string, data1 []byte) error
@@ -26,30 +27,41 @@ type SuaveRuntime interface {
 	fetchBids(cond uint64, namespace string) ([]types.Bid, error)
 	fillMevShareBundle(bidId types.BidId) ([]byte, error)
 	newBid(decryptionCondition uint64, allowedPeekers []common.Address, allowedStores []common.Address, bidType string) (types.Bid, error)
+	newBidWithAccessWindow(decryptionCondition uint64, allowedPeekers []common.Address, allowedStores []common.Address, bidType string, accessWindow types.AccessWindow) (types.Bid, error)
+	newBidWithPolicy(decryptionCondition uint64, allowedStores []common.Address, bidType string, policy types.BidPolicy) (types.Bid, error)
 	signEthTransaction(txn []byte, chainId string, signingKey string) ([]byte, error)
 	simulateBundle(bundleData []byte) (uint64, error)
 	submitBundleJsonRPC(url string, method string, params []byte) ([]byte, error)
 	submitEthBlockBidToRelay(relayUrl string, builderBid []byte) ([]byte, error)
+	submitEthBlockBidToRelays(relays []types.RelayTarget, builderBid []byte) ([]types.RelayResponse, error)
+	upcomingBuildBlockArgs() (types.BuildBlockArgs, error)
+	upcomingBuildBlockArgsV2() (types.BuildBlockArgsV2, error)
 }
 
 var (
-	buildEthBlockAddr            = common.HexToAddress("0x0000000000000000000000000000000042100001")
-	confidentialInputsAddr       = common.HexToAddress("0x0000000000000000000000000000000042010001")
-	confidentialRetrieveAddr     = common.HexToAddress("0x0000000000000000000000000000000042020001")
-	confidentialStoreAddr        = common.HexToAddress("0x0000000000000000000000000000000042020000")
-	ethcallAddr                  = common.HexToAddress("0x0000000000000000000000000000000042100003")
-	extractHintAddr              = common.HexToAddress("0x0000000000000000000000000000000042100037")
-	fetchBidsAddr                = common.HexToAddress("0x0000000000000000000000000000000042030001")
-	fillMevShareBundleAddr       = common.HexToAddress("0x0000000000000000000000000000000043200001")
-	newBidAddr                   = common.HexToAddress("0x0000000000000000000000000000000042030000")
-	signEthTransactionAddr       = common.HexToAddress("0x0000000000000000000000000000000040100001")
-	simulateBundleAddr           = common.HexToAddress("0x0000000000000000000000000000000042100000")
-	submitBundleJsonRPCAddr      = common.HexToAddress("0x0000000000000000000000000000000043000001")
-	submitEthBlockBidToRelayAddr = common.HexToAddress("0x0000000000000000000000000000000042100002")
+	buildEthBlockAddr             = common.HexToAddress("0x0000000000000000000000000000000042100001")
+	buildEthBlockV2Addr           = common.HexToAddress("0x0000000000000000000000000000000042100006")
+	confidentialInputsAddr        = common.HexToAddress("0x0000000000000000000000000000000042010001")
+	confidentialRetrieveAddr      = common.HexToAddress("0x0000000000000000000000000000000042020001")
+	confidentialStoreAddr         = common.HexToAddress("0x0000000000000000000000000000000042020000")
+	ethcallAddr                   = common.HexToAddress("0x0000000000000000000000000000000042100003")
+	extractHintAddr               = common.HexToAddress("0x0000000000000000000000000000000042100037")
+	fetchBidsAddr                 = common.HexToAddress("0x0000000000000000000000000000000042030001")
+	fillMevShareBundleAddr        = common.HexToAddress("0x0000000000000000000000000000000043200001")
+	newBidAddr                    = common.HexToAddress("0x0000000000000000000000000000000042030000")
+	newBidWithAccessWindowAddr    = common.HexToAddress("0x0000000000000000000000000000000042030002")
+	newBidWithPolicyAddr          = common.HexToAddress("0x0000000000000000000000000000000042030003")
+	signEthTransactionAddr        = common.HexToAddress("0x0000000000000000000000000000000040100001")
+	simulateBundleAddr            = common.HexToAddress("0x0000000000000000000000000000000042100000")
+	submitBundleJsonRPCAddr       = common.HexToAddress("0x0000000000000000000000000000000043000001")
+	submitEthBlockBidToRelayAddr  = common.HexToAddress("0x0000000000000000000000000000000042100002")
+	submitEthBlockBidToRelaysAddr = common.HexToAddress("0x0000000000000000000000000000000042100005")
+	upcomingBuildBlockArgsAddr    = common.HexToAddress("0x0000000000000000000000000000000042100004")
+	upcomingBuildBlockArgsV2Addr  = common.HexToAddress("0x0000000000000000000000000000000042100007")
 )
 
 var addrList = []common.Address{
-	buildEthBlockAddr, confidentialInputsAddr, confidentialRetrieveAddr, confidentialStoreAddr, ethcallAddr, extractHintAddr, fetchBidsAddr, fillMevShareBundleAddr, newBidAddr, signEthTransactionAddr, simulateBundleAddr, submitBundleJsonRPCAddr, submitEthBlockBidToRelayAddr,
+	buildEthBlockAddr, buildEthBlockV2Addr, confidentialInputsAddr, confidentialRetrieveAddr, confidentialStoreAddr, ethcallAddr, extractHintAddr, fetchBidsAddr, fillMevShareBundleAddr, newBidAddr, newBidWithAccessWindowAddr, newBidWithPolicyAddr, signEthTransactionAddr, simulateBundleAddr, submitBundleJsonRPCAddr, submitEthBlockBidToRelayAddr, submitEthBlockBidToRelaysAddr, upcomingBuildBlockArgsAddr, upcomingBuildBlockArgsV2Addr,
 }
 
 type SuaveRuntimeAdapter struct {
@@ -61,6 +73,9 @@ func (b *SuaveRuntimeAdapter) run(addr common.Address, input []byte) ([]byte, er
 	case buildEthBlockAddr:
 		return b.buildEthBlock(input)
 
+	case buildEthBlockV2Addr:
+		return b.buildEthBlockV2(input)
+
 	case confidentialInputsAddr:
 		return b.confidentialInputs(input)
 
@@ -85,6 +100,12 @@ func (b *SuaveRuntimeAdapter) run(addr common.Address, input []byte) ([]byte, er
 	case newBidAddr:
 		return b.newBid(input)
 
+	case newBidWithAccessWindowAddr:
+		return b.newBidWithAccessWindow(input)
+
+	case newBidWithPolicyAddr:
+		return b.newBidWithPolicy(input)
+
 	case signEthTransactionAddr:
 		return b.signEthTransaction(input)
 
@@ -97,6 +118,15 @@ func (b *SuaveRuntimeAdapter) run(addr common.Address, input []byte) ([]byte, er
 	case submitEthBlockBidToRelayAddr:
 		return b.submitEthBlockBidToRelay(input)
 
+	case submitEthBlockBidToRelaysAddr:
+		return b.submitEthBlockBidToRelays(input)
+
+	case upcomingBuildBlockArgsAddr:
+		return b.upcomingBuildBlockArgs(input)
+
+	case upcomingBuildBlockArgsV2Addr:
+		return b.upcomingBuildBlockArgsV2(input)
+
 	default:
 		return nil, fmt.Errorf("suave precompile not found for " + addr.String())
 	}
@@ -153,6 +183,57 @@ func (b *SuaveRuntimeAdapter) buildEthBlock(input []byte) (res []byte, err error
 
 }
 
+func (b *SuaveRuntimeAdapter) buildEthBlockV2(input []byte) (res []byte, err error) {
+	var (
+		unpacked []interface{}
+		result   []byte
+	)
+
+	_ = unpacked
+	_ = result
+
+	unpacked, err = artifacts.SuaveAbi.Methods["buildEthBlockV2"].Inputs.Unpack(input)
+	if err != nil {
+		err = errFailedToUnpackInput
+		return
+	}
+
+	var (
+		blockArgs types.BuildBlockArgsV2
+		bidId     types.BidId
+		namespace string
+	)
+
+	if err = mapstructure.Decode(unpacked[0], &blockArgs); err != nil {
+		err = errFailedToDecodeField
+		return
+	}
+
+	if err = mapstructure.Decode(unpacked[1], &bidId); err != nil {
+		err = errFailedToDecodeField
+		return
+	}
+
+	namespace = unpacked[2].(string)
+
+	var (
+		output1 []byte
+		output2 []byte
+	)
+
+	if output1, output2, err = b.impl.buildEthBlockV2(blockArgs, bidId, namespace); err != nil {
+		return
+	}
+
+	result, err = artifacts.SuaveAbi.Methods["buildEthBlockV2"].Outputs.Pack(output1, output2)
+	if err != nil {
+		err = errFailedToPackOutput
+		return
+	}
+	return result, nil
+
+}
+
 func (b *SuaveRuntimeAdapter) confidentialInputs(input []byte) (res []byte, err error) {
 	var (
 		unpacked []interface{}
@@ -457,6 +538,104 @@ func (b *SuaveRuntimeAdapter) newBid(input []byte) (res []byte, err error) {
 
 }
 
+func (b *SuaveRuntimeAdapter) newBidWithAccessWindow(input []byte) (res []byte, err error) {
+	var (
+		unpacked []interface{}
+		result   []byte
+	)
+
+	_ = unpacked
+	_ = result
+
+	unpacked, err = artifacts.SuaveAbi.Methods["newBidWithAccessWindow"].Inputs.Unpack(input)
+	if err != nil {
+		err = errFailedToUnpackInput
+		return
+	}
+
+	var (
+		decryptionCondition uint64
+		allowedPeekers      []common.Address
+		allowedStores       []common.Address
+		bidType             string
+		accessWindow        types.AccessWindow
+	)
+
+	decryptionCondition = unpacked[0].(uint64)
+	allowedPeekers = unpacked[1].([]common.Address)
+	allowedStores = unpacked[2].([]common.Address)
+	bidType = unpacked[3].(string)
+
+	if err = mapstructure.Decode(unpacked[4], &accessWindow); err != nil {
+		err = errFailedToDecodeField
+		return
+	}
+
+	var (
+		bid types.Bid
+	)
+
+	if bid, err = b.impl.newBidWithAccessWindow(decryptionCondition, allowedPeekers, allowedStores, bidType, accessWindow); err != nil {
+		return
+	}
+
+	result, err = artifacts.SuaveAbi.Methods["newBidWithAccessWindow"].Outputs.Pack(bid)
+	if err != nil {
+		err = errFailedToPackOutput
+		return
+	}
+	return result, nil
+
+}
+
+func (b *SuaveRuntimeAdapter) newBidWithPolicy(input []byte) (res []byte, err error) {
+	var (
+		unpacked []interface{}
+		result   []byte
+	)
+
+	_ = unpacked
+	_ = result
+
+	unpacked, err = artifacts.SuaveAbi.Methods["newBidWithPolicy"].Inputs.Unpack(input)
+	if err != nil {
+		err = errFailedToUnpackInput
+		return
+	}
+
+	var (
+		decryptionCondition uint64
+		allowedStores       []common.Address
+		bidType             string
+		policy              types.BidPolicy
+	)
+
+	decryptionCondition = unpacked[0].(uint64)
+	allowedStores = unpacked[1].([]common.Address)
+	bidType = unpacked[2].(string)
+
+	if err = mapstructure.Decode(unpacked[3], &policy); err != nil {
+		err = errFailedToDecodeField
+		return
+	}
+
+	var (
+		bid types.Bid
+	)
+
+	if bid, err = b.impl.newBidWithPolicy(decryptionCondition, allowedStores, bidType, policy); err != nil {
+		return
+	}
+
+	result, err = artifacts.SuaveAbi.Methods["newBidWithPolicy"].Outputs.Pack(bid)
+	if err != nil {
+		err = errFailedToPackOutput
+		return
+	}
+	return result, nil
+
+}
+
 func (b *SuaveRuntimeAdapter) signEthTransaction(input []byte) (res []byte, err error) {
 	var (
 		unpacked []interface{}
@@ -610,3 +789,115 @@ func (b *SuaveRuntimeAdapter) submitEthBlockBidToRelay(input []byte) (res []byte
 	return result, nil
 
 }
+
+func (b *SuaveRuntimeAdapter) submitEthBlockBidToRelays(input []byte) (res []byte, err error) {
+	var (
+		unpacked []interface{}
+		result   []byte
+	)
+
+	_ = unpacked
+	_ = result
+
+	unpacked, err = artifacts.SuaveAbi.Methods["submitEthBlockBidToRelays"].Inputs.Unpack(input)
+	if err != nil {
+		err = errFailedToUnpackInput
+		return
+	}
+
+	var (
+		relays     []types.RelayTarget
+		builderBid []byte
+	)
+
+	if err = mapstructure.Decode(unpacked[0], &relays); err != nil {
+		err = errFailedToDecodeField
+		return
+	}
+
+	builderBid = unpacked[1].([]byte)
+
+	var (
+		responses []types.RelayResponse
+	)
+
+	if responses, err = b.impl.submitEthBlockBidToRelays(relays, builderBid); err != nil {
+		return
+	}
+
+	result, err = artifacts.SuaveAbi.Methods["submitEthBlockBidToRelays"].Outputs.Pack(responses)
+	if err != nil {
+		err = errFailedToPackOutput
+		return
+	}
+	return result, nil
+
+}
+
+func (b *SuaveRuntimeAdapter) upcomingBuildBlockArgs(input []byte) (res []byte, err error) {
+	var (
+		unpacked []interface{}
+		result   []byte
+	)
+
+	_ = unpacked
+	_ = result
+
+	unpacked, err = artifacts.SuaveAbi.Methods["upcomingBuildBlockArgs"].Inputs.Unpack(input)
+	if err != nil {
+		err = errFailedToUnpackInput
+		return
+	}
+
+	var ()
+
+	var (
+		blockArgs types.BuildBlockArgs
+	)
+
+	if blockArgs, err = b.impl.upcomingBuildBlockArgs(); err != nil {
+		return
+	}
+
+	result, err = artifacts.SuaveAbi.Methods["upcomingBuildBlockArgs"].Outputs.Pack(blockArgs)
+	if err != nil {
+		err = errFailedToPackOutput
+		return
+	}
+	return result, nil
+
+}
+
+func (b *SuaveRuntimeAdapter) upcomingBuildBlockArgsV2(input []byte) (res []byte, err error) {
+	var (
+		unpacked []interface{}
+		result   []byte
+	)
+
+	_ = unpacked
+	_ = result
+
+	unpacked, err = artifacts.SuaveAbi.Methods["upcomingBuildBlockArgsV2"].Inputs.Unpack(input)
+	if err != nil {
+		err = errFailedToUnpackInput
+		return
+	}
+
+	var ()
+
+	var (
+		blockArgs types.BuildBlockArgsV2
+	)
+
+	if blockArgs, err = b.impl.upcomingBuildBlockArgsV2(); err != nil {
+		return
+	}
+
+	result, err = artifacts.SuaveAbi.Methods["upcomingBuildBlockArgsV2"].Outputs.Pack(blockArgs)
+	if err != nil {
+		err = errFailedToPackOutput
+		return
+	}
+	return result, nil
+
+}
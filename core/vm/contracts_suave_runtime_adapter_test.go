@@ -19,6 +19,10 @@ func (m *mockRuntime) buildEthBlock(blockArgs types.BuildBlockArgs, bidId types.
 	return []byte{0x1}, []byte{0x1}, nil
 }
 
+func (m *mockRuntime) buildEthBlockV2(blockArgs types.BuildBlockArgsV2, bidId types.BidId, namespace string) ([]byte, []byte, error) {
+	return []byte{0x1}, []byte{0x1}, nil
+}
+
 func (m *mockRuntime) confidentialInputs() ([]byte, error) {
 	return []byte{0x1}, nil
 }
@@ -51,6 +55,14 @@ func (m *mockRuntime) newBid(decryptionCondition uint64, allowedPeekers []common
 	return types.Bid{}, nil
 }
 
+func (m *mockRuntime) newBidWithAccessWindow(decryptionCondition uint64, allowedPeekers []common.Address, allowedStores []common.Address, bidType string, accessWindow types.AccessWindow) (types.Bid, error) {
+	return types.Bid{}, nil
+}
+
+func (m *mockRuntime) newBidWithPolicy(decryptionCondition uint64, allowedStores []common.Address, bidType string, policy types.BidPolicy) (types.Bid, error) {
+	return types.Bid{}, nil
+}
+
 func (m *mockRuntime) signEthTransaction(txn []byte, chainId string, signingKey string) ([]byte, error) {
 	return []byte{0x1}, nil
 }
@@ -67,6 +79,18 @@ func (m *mockRuntime) submitEthBlockBidToRelay(relayUrl string, builderBid []byt
 	return []byte{0x1}, nil
 }
 
+func (m *mockRuntime) submitEthBlockBidToRelays(relays []types.RelayTarget, builderBid []byte) ([]types.RelayResponse, error) {
+	return []types.RelayResponse{{StatusCode: 200}}, nil
+}
+
+func (m *mockRuntime) upcomingBuildBlockArgs() (types.BuildBlockArgs, error) {
+	return types.BuildBlockArgs{Withdrawals: []*types.Withdrawal{{Index: 1}}}, nil
+}
+
+func (m *mockRuntime) upcomingBuildBlockArgsV2() (types.BuildBlockArgsV2, error) {
+	return types.BuildBlockArgsV2{Withdrawals: []*types.Withdrawal{{Index: 1}}}, nil
+}
+
 func TestRuntimeAdapter(t *testing.T) {
 	adapter := &SuaveRuntimeAdapter{
 		impl: &mockRuntime{},
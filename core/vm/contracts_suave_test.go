@@ -1,14 +1,31 @@
 package vm
 
 import (
+	"compress/gzip"
 	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
+	builderCapella "github.com/attestantio/go-builder-client/api/capella"
+	builderV1 "github.com/attestantio/go-builder-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/bellatrix"
+	specCapella "github.com/attestantio/go-eth2-client/spec/capella"
 	"github.com/ethereum/go-ethereum/beacon/engine"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/suave/artifacts"
 	suave "github.com/ethereum/go-ethereum/suave/core"
 	"github.com/ethereum/go-ethereum/suave/cstore"
+	"github.com/google/uuid"
+	"github.com/holiman/uint256"
 	"github.com/stretchr/testify/require"
 )
 
@@ -46,11 +63,15 @@ func (m *mockSuaveBackend) FetchBidsByProtocolAndBlock(blockNumber uint64, names
 	return nil
 }
 
-func (m *mockSuaveBackend) BuildEthBlock(ctx context.Context, args *suave.BuildBlockArgs, txs types.Transactions) (*engine.ExecutionPayloadEnvelope, error) {
+func (m *mockSuaveBackend) BuildEthBlock(ctx context.Context, args *suave.BuildBlockArgsV2, txs types.Transactions) (*engine.ExecutionPayloadEnvelope, error) {
 	return nil, nil
 }
 
-func (m *mockSuaveBackend) BuildEthBlockFromBundles(ctx context.Context, args *suave.BuildBlockArgs, bundles []types.SBundle) (*engine.ExecutionPayloadEnvelope, error) {
+func (m *mockSuaveBackend) BuildEthBlockFromBundles(ctx context.Context, args *suave.BuildBlockArgsV2, bundles []types.SBundle) (*engine.ExecutionPayloadEnvelope, error) {
+	return nil, nil
+}
+
+func (m *mockSuaveBackend) SimulateBundles(ctx context.Context, args *suave.BuildBlockArgsV2, bundles []types.SBundle) ([]*suave.SimulatedBundle, error) {
 	return nil, nil
 }
 
@@ -80,7 +101,7 @@ func newTestBackend(t *testing.T) *suaveRuntime {
 	b := &suaveRuntime{
 		suaveContext: &SuaveContext{
 			Backend: &SuaveExecutionBackend{
-				ConfidentialStore:      confEngine.NewTransactionalStore(reqTx),
+				ConfidentialStore:      confEngine.NewTransactionalStore(reqTx, 0),
 				ConfidentialEthBackend: &mockSuaveBackend{},
 			},
 			ConfidentialComputeRequestTx: reqTx,
@@ -151,3 +172,411 @@ func TestSuave_ConfStoreWorkflow(t *testing.T) {
 	_, err = b.confidentialRetrieve(bid.Id, "key")
 	require.Error(t, err)
 }
+
+func TestSuave_ConfStoreAccessWindow(t *testing.T) {
+	confEngine := cstore.NewConfidentialStoreEngine(cstore.NewLocalConfidentialStore(), &cstore.MockTransport{}, cstore.MockSigner{}, cstore.MockChainSigner{})
+
+	require.NoError(t, confEngine.Start())
+	t.Cleanup(func() { confEngine.Stop() })
+
+	testKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	reqTx, err := types.SignTx(types.NewTx(&types.ConfidentialComputeRequest{
+		ConfidentialComputeRecord: types.ConfidentialComputeRecord{
+			KettleAddress: common.Address{},
+		},
+	}), types.NewSuaveSigner(new(big.Int)), testKey)
+	require.NoError(t, err)
+
+	callerAddr := common.Address{0x1}
+	newRuntimeAt := func(head uint64) *suaveRuntime {
+		return &suaveRuntime{
+			suaveContext: &SuaveContext{
+				Backend: &SuaveExecutionBackend{
+					ConfidentialStore:      confEngine.NewTransactionalStore(reqTx, head),
+					ConfidentialEthBackend: &mockSuaveBackend{},
+				},
+				ConfidentialComputeRequestTx: reqTx,
+				CallerStack:                  []*common.Address{&callerAddr},
+				BlockNumber:                  head,
+			},
+		}
+	}
+
+	b := newRuntimeAt(10)
+	bid, err := b.newBidWithAccessWindow(15, []common.Address{callerAddr}, nil, "a", types.AccessWindow{NotBefore: 15, NotAfter: 20})
+	require.NoError(t, err)
+
+	// data can be committed before the window opens
+	require.NoError(t, b.confidentialStore(bid.Id, "key", []byte{0x1}))
+
+	_, err = b.confidentialRetrieve(bid.Id, "key")
+	require.ErrorIs(t, err, suave.ErrBidNotAccessibleYet)
+	require.ErrorContains(t, err, "accessible from block 15, head is 10")
+
+	require.NoError(t, b.suaveContext.Backend.ConfidentialStore.(*cstore.TransactionalStore).Finalize())
+
+	val, err := newRuntimeAt(15).confidentialRetrieve(bid.Id, "key")
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x1}, val)
+
+	_, err = newRuntimeAt(21).confidentialRetrieve(bid.Id, "key")
+	require.ErrorIs(t, err, suave.ErrBidAccessExpired)
+}
+
+func TestSuave_ConfStorePolicy(t *testing.T) {
+	b := newTestBackend(t)
+
+	var (
+		userContract    = common.Address{0x1}
+		builderContract = common.Address{0x2}
+		otherContract   = common.Address{0x3}
+	)
+
+	b.suaveContext.CallerStack = []*common.Address{&userContract}
+	bid, err := b.newBidWithPolicy(5, nil, "a", types.BidPolicy{
+		Readers: []common.Address{userContract, builderContract},
+		Writers: []common.Address{userContract},
+		KeyRules: []*types.BidKeyRule{{
+			KeyPrefix: "mevshare:v0:ethBundles",
+			Readers:   []common.Address{buildEthBlockAddr},
+			Writers:   []common.Address{userContract},
+		}},
+		DirectCallerOnly: true,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, b.confidentialStore(bid.Id, "mevshare:v0:ethBundles", []byte{0x1}))
+	require.NoError(t, b.confidentialStore(bid.Id, "mevshare:v0:hint", []byte{0x2}))
+
+	// bundles are only readable by buildEthBlock, not by the contracts
+	_, err = b.confidentialRetrieve(bid.Id, "mevshare:v0:ethBundles")
+	require.Error(t, err)
+
+	val, err := b.confidentialRetrieve(bid.Id, "mevshare:v0:hint")
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x2}, val)
+
+	// readers are not writers
+	b.suaveContext.CallerStack = []*common.Address{&builderContract}
+	require.Error(t, b.confidentialStore(bid.Id, "mevshare:v0:hint", []byte{0x3}))
+
+	fetchedBid, err := b.suaveContext.Backend.ConfidentialStore.FetchBidById(bid.Id)
+	require.NoError(t, err)
+
+	caller, err := checkIsPrecompileCallAllowed(b.suaveContext, buildEthBlockAddr, fetchedBid, "")
+	require.NoError(t, err)
+	require.Equal(t, builderContract, caller)
+
+	_, err = checkIsPrecompileCallAllowed(b.suaveContext, simulateBundleAddr, fetchedBid, "")
+	require.ErrorContains(t, err, "precompile simulateBundle")
+
+	// only the direct caller is evaluated
+	b.suaveContext.CallerStack = []*common.Address{&userContract, &otherContract}
+	_, err = b.confidentialRetrieve(bid.Id, "mevshare:v0:hint")
+	require.ErrorContains(t, err, "direct caller")
+
+	_, err = checkIsPrecompileCallAllowed(b.suaveContext, buildEthBlockAddr, fetchedBid, "")
+	require.ErrorContains(t, err, "direct caller")
+
+	fetchedBid.Policy.DirectCallerOnly = false
+	caller, err = checkIsPrecompileCallAllowed(b.suaveContext, confidentialRetrieveAddr, fetchedBid, "mevshare:v0:hint")
+	require.NoError(t, err)
+	require.Equal(t, userContract, caller)
+}
+
+func TestSuave_FillMevShareBundle(t *testing.T) {
+	b := newTestBackend(t)
+
+	callerAddr := common.Address{0x1}
+	b.suaveContext.CallerStack = append(b.suaveContext.CallerStack, &callerAddr)
+	allowedPeekers := []common.Address{callerAddr, fillMevShareBundleAddr}
+
+	newBundleBid := func(version string, bundle *types.SBundle) types.Bid {
+		bid, err := b.newBid(5, allowedPeekers, nil, version)
+		require.NoError(t, err)
+
+		bundleBytes, err := json.Marshal(bundle)
+		require.NoError(t, err)
+		require.NoError(t, b.confidentialStore(bid.Id, "mevshare:v0:ethBundles", bundleBytes))
+		return bid
+	}
+
+	mergeBids := func(bid types.Bid, bidIds ...types.BidId) {
+		ids := make([][16]byte, len(bidIds))
+		for i, id := range bidIds {
+			ids[i] = id
+		}
+		mergedBytes, err := bidIdsAbi.Inputs.Pack(ids)
+		require.NoError(t, err)
+		require.NoError(t, b.confidentialStore(bid.Id, "mevshare:v0:mergedBids", mergedBytes))
+	}
+
+	newTx := func(nonce uint64) *types.Transaction {
+		return types.NewTx(&types.LegacyTx{Nonce: nonce, To: &common.Address{}, Gas: 21000, GasPrice: big.NewInt(1)})
+	}
+
+	userTx, backrunTx1, backrunTx2, backrunTx3 := newTx(0), newTx(1), newTx(2), newTx(3)
+	refundPercent := 50
+	refundConfig := []types.MevShareBundleRefundConfig{{Address: common.Address{0x2}, Percent: 100}}
+
+	userBid := newBundleBid("mevshare:v0:unmatchedBundles", &types.SBundle{
+		Txs:           types.Transactions{userTx},
+		MaxBlock:      big.NewInt(7),
+		RefundPercent: &refundPercent,
+		RefundConfig:  refundConfig,
+		Privacy:       &types.MevShareBundlePrivacy{Hints: []string{"calldata"}, Builders: []string{"a", "b"}},
+	})
+
+	// three-way match: user bundle, the matching bid itself and another backrun
+	otherBid := newBundleBid("mevshare:v0:unmatchedBundles", &types.SBundle{
+		Txs:             types.Transactions{backrunTx2},
+		RevertingHashes: []common.Hash{backrunTx2.Hash()},
+		Privacy:         &types.MevShareBundlePrivacy{Builders: []string{"b"}},
+	})
+	matchBid := newBundleBid("mevshare:v0:matchBids", &types.SBundle{Txs: types.Transactions{backrunTx1}})
+	mergeBids(matchBid, userBid.Id, matchBid.Id, otherBid.Id)
+
+	bundleBytes, err := b.fillMevShareBundle(matchBid.Id)
+	require.NoError(t, err)
+
+	var shareBundle types.RPCMevShareBundle
+	require.NoError(t, json.Unmarshal(bundleBytes, &shareBundle))
+
+	require.Equal(t, "v0.1", shareBundle.Version)
+	require.Equal(t, "0x5", shareBundle.Inclusion.Block)
+	require.Len(t, shareBundle.Body, 3)
+	for i, tx := range []*types.Transaction{userTx, backrunTx1, backrunTx2} {
+		txBytes, err := tx.MarshalBinary()
+		require.NoError(t, err)
+		require.Equal(t, hexutil.Encode(txBytes), shareBundle.Body[i].Tx)
+	}
+	require.False(t, shareBundle.Body[0].CanRevert)
+	require.True(t, shareBundle.Body[2].CanRevert)
+	require.Equal(t, "0x7", shareBundle.Inclusion.MaxBlock)
+	require.Equal(t, []types.MevShareBundleRefund{{BodyIdx: 0, Percent: refundPercent}}, shareBundle.Validity.Refund)
+	require.Equal(t, refundConfig, shareBundle.Validity.RefundConfig)
+	require.Equal(t, &types.MevShareBundlePrivacy{Hints: []string{"calldata"}, Builders: []string{"b"}}, shareBundle.Privacy)
+
+	// nested match: the three-way match is backrun again
+	nestedBid := newBundleBid("mevshare:v0:matchBids", &types.SBundle{Txs: types.Transactions{backrunTx3}})
+	mergeBids(nestedBid, matchBid.Id, nestedBid.Id)
+
+	bundleBytes, err = b.fillMevShareBundle(nestedBid.Id)
+	require.NoError(t, err)
+
+	var nestedShareBundle types.RPCMevShareBundle
+	require.NoError(t, json.Unmarshal(bundleBytes, &nestedShareBundle))
+
+	require.Len(t, nestedShareBundle.Body, 2)
+	require.Equal(t, &shareBundle, nestedShareBundle.Body[0].Bundle)
+	require.Empty(t, nestedShareBundle.Validity.Refund)
+
+	// cycles are rejected
+	cycleBidA := newBundleBid("mevshare:v0:matchBids", &types.SBundle{Txs: types.Transactions{backrunTx1}})
+	cycleBidB := newBundleBid("mevshare:v0:matchBids", &types.SBundle{Txs: types.Transactions{backrunTx2}})
+	mergeBids(cycleBidA, cycleBidB.Id, cycleBidA.Id)
+	mergeBids(cycleBidB, cycleBidA.Id, cycleBidB.Id)
+	_, err = b.fillMevShareBundle(cycleBidA.Id)
+	require.Error(t, err)
+}
+
+func TestSuave_SubmitEthBlockBidToRelays(t *testing.T) {
+	b := newTestBackend(t)
+
+	bidRequest := builderCapella.SubmitBlockRequest{
+		Message: &builderV1.BidTrace{Slot: 1, Value: uint256.NewInt(1)},
+		ExecutionPayload: &specCapella.ExecutionPayload{
+			BlockNumber:  1,
+			ExtraData:    []byte{},
+			Transactions: []bellatrix.Transaction{},
+			Withdrawals:  []*specCapella.Withdrawal{},
+		},
+	}
+	builderBidJson, err := bidRequest.MarshalJSON()
+	require.NoError(t, err)
+
+	newRelay := func(handler http.HandlerFunc) string {
+		srv := httptest.NewServer(handler)
+		t.Cleanup(srv.Close)
+		return srv.URL
+	}
+
+	jsonRelay := newRelay(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/relay/v1/builder/blocks", r.URL.Path)
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		var received builderCapella.SubmitBlockRequest
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.NoError(t, received.UnmarshalJSON(body))
+		require.Equal(t, bidRequest.Message.Slot, received.Message.Slot)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	sszGzipRelay := newRelay(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "application/octet-stream", r.Header.Get("Content-Type"))
+		require.Equal(t, "gzip", r.Header.Get("Content-Encoding"))
+
+		zr, err := gzip.NewReader(r.Body)
+		require.NoError(t, err)
+		body, err := io.ReadAll(zr)
+		require.NoError(t, err)
+
+		var received builderCapella.SubmitBlockRequest
+		require.NoError(t, received.UnmarshalSSZ(body))
+		require.Equal(t, bidRequest.ExecutionPayload.BlockNumber, received.ExecutionPayload.BlockNumber)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	failingRelay := newRelay(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("invalid bid"))
+	})
+
+	slowRelay := newRelay(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	})
+
+	responses, err := b.submitEthBlockBidToRelays([]types.RelayTarget{
+		{Url: jsonRelay},
+		{Url: sszGzipRelay, Ssz: true, Gzip: true},
+		{Url: failingRelay},
+		{Url: slowRelay, TimeoutMs: 50},
+	}, builderBidJson)
+	require.NoError(t, err)
+	require.Len(t, responses, 4)
+
+	require.Equal(t, jsonRelay, responses[0].Url)
+	require.Equal(t, uint64(http.StatusOK), responses[0].StatusCode)
+	require.Empty(t, responses[0].Error)
+
+	require.Equal(t, uint64(http.StatusNoContent), responses[1].StatusCode)
+	require.Empty(t, responses[1].Error)
+
+	require.Equal(t, uint64(http.StatusBadRequest), responses[2].StatusCode)
+	require.Equal(t, []byte("invalid bid"), responses[2].Body)
+	require.NotEmpty(t, responses[2].Error)
+
+	require.Zero(t, responses[3].StatusCode)
+	require.NotEmpty(t, responses[3].Error)
+}
+
+func TestSuave_ResolveBundleReplacements(t *testing.T) {
+	signer := types.LatestSignerForChainID(big.NewInt(1))
+	searcherA, _ := crypto.GenerateKey()
+	searcherB, _ := crypto.GenerateKey()
+
+	request := func(key *ecdsa.PrivateKey, nonce uint64) *types.Transaction {
+		return types.MustSignNewTx(key, signer, &types.LegacyTx{Nonce: nonce, GasPrice: big.NewInt(1)})
+	}
+	tx := types.NewTx(&types.LegacyTx{Nonce: 1})
+
+	replacementUuid := uuid.New()
+	bundles := []types.SBundle{
+		{Txs: types.Transactions{tx}, ReplacementUuid: &replacementUuid, MinTimestamp: 1},
+		{Txs: types.Transactions{tx}, ReplacementUuid: &replacementUuid, MinTimestamp: 2},
+		// Same UUID from another searcher does not replace the bundles of the first one
+		{Txs: types.Transactions{tx}, ReplacementUuid: &replacementUuid, MinTimestamp: 3},
+		{Txs: types.Transactions{tx}, MinTimestamp: 4},
+	}
+	requests := []*types.Transaction{request(searcherA, 2), request(searcherA, 1), request(searcherB, 0), request(searcherB, 1)}
+
+	noRecords := func(common.Address, uuid.UUID) (suave.BundleReplacement, bool, error) {
+		return suave.BundleReplacement{}, false, nil
+	}
+	resolved := resolveBundleReplacements(bundles, requests, noRecords)
+	require.Len(t, resolved, 3)
+	require.Equal(t, uint64(1), resolved[0].MinTimestamp)
+	require.Equal(t, uint64(3), resolved[1].MinTimestamp)
+	require.Equal(t, uint64(4), resolved[2].MinTimestamp)
+
+	// A later cancellation drops the bundle
+	bundles = append(bundles, types.SBundle{ReplacementUuid: &replacementUuid})
+	requests = append(requests, request(searcherA, 3))
+	resolved = resolveBundleReplacements(bundles, requests, noRecords)
+	require.Len(t, resolved, 2)
+	require.Equal(t, uint64(3), resolved[0].MinTimestamp)
+
+	// Bundles replaced or cancelled by a later request recorded in the store
+	// are dropped, even when it is not built from
+	searcherAddrA := crypto.PubkeyToAddress(searcherA.PublicKey)
+	recorded := func(replacement suave.BundleReplacement) func(common.Address, uuid.UUID) (suave.BundleReplacement, bool, error) {
+		return func(sender common.Address, id uuid.UUID) (suave.BundleReplacement, bool, error) {
+			if sender != searcherAddrA || id != replacementUuid {
+				return suave.BundleReplacement{}, false, nil
+			}
+			return replacement, true, nil
+		}
+	}
+	resolved = resolveBundleReplacements(bundles[:4], requests[:4], recorded(suave.BundleReplacement{Nonce: 5}))
+	require.Len(t, resolved, 2)
+	require.Equal(t, uint64(3), resolved[0].MinTimestamp)
+	require.Equal(t, uint64(4), resolved[1].MinTimestamp)
+
+	resolved = resolveBundleReplacements(bundles[:4], requests[:4], recorded(suave.BundleReplacement{Nonce: 2, Cancelled: true}))
+	require.Len(t, resolved, 2)
+
+	// The bundle recorded as the latest one is kept
+	resolved = resolveBundleReplacements(bundles[:4], requests[:4], recorded(suave.BundleReplacement{Nonce: 2}))
+	require.Len(t, resolved, 3)
+	require.Equal(t, uint64(1), resolved[0].MinTimestamp)
+
+	minTs, maxTs := intersectTimestamps(1, 0, 5, 10)
+	require.Equal(t, uint64(5), minTs)
+	require.Equal(t, uint64(10), maxTs)
+	minTs, maxTs = intersectTimestamps(6, 8, 5, 10)
+	require.Equal(t, uint64(6), minTs)
+	require.Equal(t, uint64(8), maxTs)
+}
+
+func TestSuave_AuditRecordAndReplay(t *testing.T) {
+	b := newTestBackend(t)
+
+	callerAddr := common.Address{0x1}
+	bid, err := b.newBid(5, []common.Address{callerAddr}, nil, "a")
+	require.NoError(t, err)
+
+	record := &suave.AuditRecord{}
+	b.suaveContext.AuditRecord = record
+	b.suaveContext.Backend.ConfidentialStore = NewAuditedConfidentialStore(b.suaveContext.Backend.ConfidentialStore, record)
+	b.suaveContext.CallerStack = []*common.Address{&callerAddr}
+
+	storeInput, err := artifacts.SuaveAbi.Methods["confidentialStore"].Inputs.Pack(bid.Id, "key", []byte{0x42})
+	require.NoError(t, err)
+	retrieveInput, err := artifacts.SuaveAbi.Methods["confidentialRetrieve"].Inputs.Pack(bid.Id, "key")
+	require.NoError(t, err)
+
+	_, err = NewSuavePrecompiledContractWrapper(confidentialStoreAddr, b.suaveContext).Run(storeInput)
+	require.NoError(t, err)
+	retrieved, err := NewSuavePrecompiledContractWrapper(confidentialRetrieveAddr, b.suaveContext).Run(retrieveInput)
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x42}, retrieved)
+
+	require.Len(t, record.PrecompileCalls, 2)
+	require.Equal(t, confidentialRetrieveAddr, record.PrecompileCalls[1].Address)
+	require.Equal(t, hexutil.Bytes(retrieved), record.PrecompileCalls[1].Output)
+	require.Equal(t, []*suave.StoreAccess{{BidId: bid.Id, Caller: callerAddr, Key: "key", Value: []byte{0x42}}}, record.StoreWrites)
+	require.Equal(t, []*suave.StoreAccess{{BidId: bid.Id, Caller: callerAddr, Key: "key", Value: []byte{0x42}}}, record.StoreReads)
+
+	// The replay answers from the record without reaching the store
+	replay := suave.NewAuditReplay(record)
+	replayCtx := &SuaveContext{Backend: &SuaveExecutionBackend{}, AuditReplay: replay}
+
+	_, err = NewSuavePrecompiledContractWrapper(confidentialStoreAddr, replayCtx).Run(storeInput)
+	require.NoError(t, err)
+	require.Error(t, replay.Done())
+	replayed, err := NewSuavePrecompiledContractWrapper(confidentialRetrieveAddr, replayCtx).Run(retrieveInput)
+	require.NoError(t, err)
+	require.Equal(t, retrieved, replayed)
+	require.NoError(t, replay.Done())
+
+	// Calls that were not recorded diverge
+	_, err = NewSuavePrecompiledContractWrapper(confidentialRetrieveAddr, replayCtx).Run(retrieveInput)
+	require.ErrorContains(t, err, "replay diverged")
+	require.Error(t, replay.Done())
+}
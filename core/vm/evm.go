@@ -43,7 +43,7 @@ type (
 func (evm *EVM) precompile(addr common.Address) (PrecompiledContract, bool) {
 	// First check confidential precompiles, only then continue to the regular ones
 	if evm.chainRules.IsSuave {
-		if isPrecompileAddr(addr) && evm.Config.IsConfidential {
+		if evm.Config.IsConfidential && isPrecompileAddr(evm.SuaveContext.precompiles(), addr) {
 			suaveContext := NewRuntimeSuaveContext(evm, addr)
 			return NewSuavePrecompiledContractWrapper(addr, suaveContext), true
 		}
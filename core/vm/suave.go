@@ -2,15 +2,18 @@ package vm
 
 import (
 	"crypto/ecdsa"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/suave/artifacts"
 	suave "github.com/ethereum/go-ethereum/suave/core"
 	"github.com/flashbots/go-boost-utils/bls"
+	"github.com/google/uuid"
 	"golang.org/x/exp/slices"
 )
 
@@ -18,10 +21,42 @@ import (
 // required by Suave runtime.
 type ConfidentialStore interface {
 	InitializeBid(bid types.Bid) (types.Bid, error)
+	InitializeBidWithAccessWindow(bid types.Bid, accessWindow suave.AccessWindow) (types.Bid, error)
+	InitializeBidWithPolicy(bid types.Bid, policy suave.BidPolicy) (types.Bid, error)
 	Store(bidId suave.BidId, caller common.Address, key string, value []byte) (suave.Bid, error)
 	Retrieve(bid types.BidId, caller common.Address, key string) ([]byte, error)
 	FetchBidById(suave.BidId) (suave.Bid, error)
 	FetchBidsByProtocolAndBlock(blockNumber uint64, namespace string) []suave.Bid
+	BundleReplacement(sender common.Address, replacementUuid uuid.UUID) (suave.BundleReplacement, bool, error)
+}
+
+// auditedConfidentialStore records the values the MEVM reads from and writes
+// to the confidential store.
+type auditedConfidentialStore struct {
+	ConfidentialStore
+	record *suave.AuditRecord
+}
+
+// NewAuditedConfidentialStore returns a store recording the values stored and
+// retrieved through it in the audit record.
+func NewAuditedConfidentialStore(store ConfidentialStore, record *suave.AuditRecord) ConfidentialStore {
+	return &auditedConfidentialStore{ConfidentialStore: store, record: record}
+}
+
+func (s *auditedConfidentialStore) Store(bidId suave.BidId, caller common.Address, key string, value []byte) (suave.Bid, error) {
+	bid, err := s.ConfidentialStore.Store(bidId, caller, key, value)
+	if err == nil {
+		s.record.AddStoreWrite(bidId, caller, key, value)
+	}
+	return bid, err
+}
+
+func (s *auditedConfidentialStore) Retrieve(bidId types.BidId, caller common.Address, key string) ([]byte, error) {
+	value, err := s.ConfidentialStore.Retrieve(bidId, caller, key)
+	if err == nil {
+		s.record.AddStoreRead(bidId, caller, key, value)
+	}
+	return value, err
 }
 
 type SuaveContext struct {
@@ -30,6 +65,20 @@ type SuaveContext struct {
 	ConfidentialComputeRequestTx *types.Transaction
 	ConfidentialInputs           []byte
 	CallerStack                  []*common.Address
+	// Chain head the request runs against, bid access windows are checked against it
+	BlockNumber uint64
+	// Optional, records the precompile calls of the execution
+	AuditRecord *suave.AuditRecord
+	// Optional, answers the precompile calls with the ones of a recorded execution
+	AuditReplay *suave.AuditReplay
+}
+
+// precompiles returns the precompiles the node registered, nil if none.
+func (c *SuaveContext) precompiles() *SuavePrecompileRegistry {
+	if c == nil || c.Backend == nil {
+		return nil
+	}
+	return c.Backend.Precompiles
 }
 
 type SuaveExecutionBackend struct {
@@ -37,6 +86,10 @@ type SuaveExecutionBackend struct {
 	EthBlockSigningKey     *bls.SecretKey
 	ConfidentialStore      ConfidentialStore
 	ConfidentialEthBackend suave.ConfidentialEthBackend
+	// Optional, only set when the node follows a beacon node
+	ConfidentialBeaconBackend suave.ConfidentialBeaconBackend
+	// Optional, precompiles the node registered in addition to the generated ones
+	Precompiles *SuavePrecompileRegistry
 }
 
 func NewRuntimeSuaveContext(evm *EVM, caller common.Address) *SuaveContext {
@@ -49,6 +102,9 @@ func NewRuntimeSuaveContext(evm *EVM, caller common.Address) *SuaveContext {
 		ConfidentialComputeRequestTx: evm.SuaveContext.ConfidentialComputeRequestTx,
 		ConfidentialInputs:           evm.SuaveContext.ConfidentialInputs,
 		CallerStack:                  append(evm.SuaveContext.CallerStack, &caller),
+		BlockNumber:                  evm.SuaveContext.BlockNumber,
+		AuditRecord:                  evm.SuaveContext.AuditRecord,
+		AuditReplay:                  evm.SuaveContext.AuditReplay,
 	}
 }
 
@@ -74,8 +130,13 @@ func (p *SuavePrecompiledContractWrapper) Run(input []byte) ([]byte, error) {
 		},
 	}
 
+	registered, isRegistered := p.suaveContext.precompiles().lookup(p.addr)
+
 	if metrics.EnabledExpensive {
 		precompileName := artifacts.PrecompileAddressToName(p.addr)
+		if isRegistered {
+			precompileName = registered.method.Name
+		}
 		metrics.GetOrRegisterMeter("suave/runtime/"+precompileName, nil).Mark(1)
 
 		now := time.Now()
@@ -84,28 +145,83 @@ func (p *SuavePrecompiledContractWrapper) Run(input []byte) ([]byte, error) {
 		}()
 	}
 
+	if notice, ok := artifacts.DeprecatedPrecompiles[p.addr]; ok {
+		var caller common.Address
+		if len(p.suaveContext.CallerStack) != 0 && p.suaveContext.CallerStack[len(p.suaveContext.CallerStack)-1] != nil {
+			caller = *p.suaveContext.CallerStack[len(p.suaveContext.CallerStack)-1]
+		}
+		log.Warn("Deprecated precompile called", "name", artifacts.PrecompileAddressToName(p.addr), "addr", p.addr, "caller", caller, "notice", notice)
+	}
+
 	if p.addr == isConfidentialAddress {
 		// 'isConfidential' is a special precompile, redo as a function?
 		return []byte{0x1}, nil
 	}
 
-	ret, err := stub.run(p.addr, input)
+	if p.suaveContext.AuditReplay != nil {
+		return replayPrecompileCall(p.suaveContext.AuditReplay, p.addr, input)
+	}
+
+	var (
+		ret []byte
+		err error
+	)
+	if isRegistered {
+		ret, err = registered.run(p.suaveContext, input)
+	} else {
+		ret, err = stub.run(p.addr, input)
+	}
 	if err != nil && ret == nil {
 		ret = []byte(err.Error())
 	}
 
+	p.suaveContext.AuditRecord.AddPrecompileCall(p.addr, input, ret, err, errors.Is(err, ErrExecutionReverted))
 	return ret, err
 }
 
-func isPrecompileAddr(addr common.Address) bool {
+// replayPrecompileCall answers the call with the output of the recorded one,
+// failing the same way it did.
+func replayPrecompileCall(replay *suave.AuditReplay, addr common.Address, input []byte) ([]byte, error) {
+	call, err := replay.Next(addr, input)
+	if err != nil {
+		return []byte(err.Error()), err
+	}
+
+	switch {
+	case call.Reverted:
+		return call.Output, ErrExecutionReverted
+	case call.Error != "":
+		return call.Output, errors.New(call.Error)
+	default:
+		return call.Output, nil
+	}
+}
+
+func isPrecompileAddr(registry *SuavePrecompileRegistry, addr common.Address) bool {
 	if addr == isConfidentialAddress {
 		return true
 	}
+	if _, ok := registry.lookup(addr); ok {
+		return true
+	}
 	return slices.Contains(addrList, addr)
 }
 
-// Returns the caller
-func checkIsPrecompileCallAllowed(suaveContext *SuaveContext, precompile common.Address, bid suave.Bid) (common.Address, error) {
+// Returns the caller on whose behalf the precompile accesses the bid. The key
+// is only relevant to confidentialStore and confidentialRetrieve, other
+// precompiles are checked against the bid as a whole.
+func checkIsPrecompileCallAllowed(suaveContext *SuaveContext, precompile common.Address, bid suave.Bid, key string) (common.Address, error) {
+	// Data can be stored at any time, the access window only guards reading it
+	if precompile != confidentialStoreAddr {
+		if err := bid.CheckAccessWindow(suaveContext.BlockNumber); err != nil {
+			return common.Address{}, fmt.Errorf("precompile %s (%x) not allowed on %x: %w", artifacts.PrecompileAddressToName(precompile), precompile, bid.Id, err)
+		}
+	}
+
+	if bid.Policy != nil {
+		return checkBidPolicy(suaveContext, precompile, bid, key)
+	}
+
 	anyPeekerAllowed := slices.Contains(bid.AllowedPeekers, suave.AllowedPeekerAny)
 	if anyPeekerAllowed {
 		for i := len(suaveContext.CallerStack) - 1; i >= 0; i-- {
@@ -140,3 +256,44 @@ func checkIsPrecompileCallAllowed(suaveContext *SuaveContext, precompile common.
 
 	return common.Address{}, fmt.Errorf("no caller of %s (%x) is allowed on %x", artifacts.PrecompileAddressToName(precompile), precompile, bid.Id)
 }
+
+// checkBidPolicy evaluates the policy of the bid. confidentialStore and
+// confidentialRetrieve act on behalf of their caller, which must be a writer
+// or reader of the key. Any other precompile must itself be a reader of the
+// bid, in addition to its caller. Depending on the policy either the direct
+// caller or any caller in the stack has to be allowed.
+func checkBidPolicy(suaveContext *SuaveContext, precompile common.Address, bid suave.Bid, key string) (common.Address, error) {
+	precompileName := artifacts.PrecompileAddressToName(precompile)
+
+	var allowed []common.Address
+	switch precompile {
+	case confidentialStoreAddr:
+		allowed = bid.AllowedWriters(key)
+	case confidentialRetrieveAddr:
+		allowed = bid.AllowedReaders(key)
+	default:
+		if !bid.IsReader(precompile) {
+			return common.Address{}, fmt.Errorf("precompile %s (%x) not allowed on %x", precompileName, precompile, bid.Id)
+		}
+		allowed = bid.AllowedReaders("")
+	}
+
+	for i := len(suaveContext.CallerStack) - 1; i >= 0; i-- {
+		caller := suaveContext.CallerStack[i]
+		if caller == nil || *caller == precompile {
+			continue
+		}
+		if suave.IsAllowed(allowed, *caller) {
+			return *caller, nil
+		}
+		if bid.Policy.DirectCallerOnly {
+			return common.Address{}, fmt.Errorf("direct caller %x of %s (%x) is not allowed on %x", *caller, precompileName, precompile, bid.Id)
+		}
+	}
+
+	if slices.Contains(allowed, suave.AllowedPeekerAny) {
+		return precompile, nil
+	}
+
+	return common.Address{}, fmt.Errorf("no caller of %s (%x) is allowed on %x", precompileName, precompile, bid.Id)
+}
@@ -0,0 +1,350 @@
+package vm
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/suave/artifacts"
+)
+
+// SuavePrecompileHandler implements a precompile registered at runtime. It is
+// called with the ABI decoded inputs of the precompile and returns the values
+// to ABI encode as its outputs.
+type SuavePrecompileHandler func(suaveContext *SuaveContext, args []interface{}) ([]interface{}, error)
+
+// registeredPrecompile is a precompile added to a SuavePrecompileRegistry.
+type registeredPrecompile struct {
+	addr    common.Address
+	method  abi.Method
+	handler SuavePrecompileHandler
+}
+
+func (p *registeredPrecompile) run(suaveContext *SuaveContext, input []byte) ([]byte, error) {
+	args, err := p.method.Inputs.Unpack(input)
+	if err != nil {
+		return nil, errFailedToUnpackInput
+	}
+
+	results, err := p.handler(suaveContext, args)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := p.method.Outputs.Pack(results...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errFailedToPackOutput, err)
+	}
+	return output, nil
+}
+
+// SuavePrecompileRegistry holds the precompiles a node makes available to
+// confidential execution in addition to the generated ones. Each node has its
+// own registry, handed to the MEVM through its SuaveExecutionBackend. A nil
+// registry has no precompiles.
+type SuavePrecompileRegistry struct {
+	lock        sync.RWMutex
+	precompiles map[common.Address]*registeredPrecompile
+}
+
+func NewSuavePrecompileRegistry() *SuavePrecompileRegistry {
+	return &SuavePrecompileRegistry{
+		precompiles: make(map[common.Address]*registeredPrecompile),
+	}
+}
+
+// Register makes a Go handler available to confidential execution at the
+// given address. The signature is a Solidity-style declaration of the
+// precompile, e.g.
+//
+//	riskCheck(address account, uint256 amount) returns (bool)
+//
+// Elementary types and arrays of them are supported. Precompiles must be
+// registered while the node is constructed, before any request is executed.
+func (r *SuavePrecompileRegistry) Register(addr common.Address, signature string, handler SuavePrecompileHandler) error {
+	if handler == nil {
+		return errors.New("precompile handler is nil")
+	}
+
+	method, err := parsePrecompileSignature(signature)
+	if err != nil {
+		return fmt.Errorf("invalid precompile signature %q: %w", signature, err)
+	}
+
+	if addr == isConfidentialAddress || artifacts.PrecompileAddressToName(addr) != "" || isEthPrecompileAddr(addr) {
+		return fmt.Errorf("address %s is reserved for a builtin precompile", addr)
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for _, p := range r.precompiles {
+		if p.addr == addr {
+			return fmt.Errorf("address %s is already registered for %s", addr, p.method.Name)
+		}
+		if p.method.Name == method.Name {
+			return fmt.Errorf("precompile %s is already registered at %s", method.Name, p.addr)
+		}
+	}
+	if _, ok := artifacts.SuaveMethods[method.Name]; ok {
+		return fmt.Errorf("precompile %s is a builtin precompile", method.Name)
+	}
+
+	r.precompiles[addr] = &registeredPrecompile{
+		addr:    addr,
+		method:  method,
+		handler: handler,
+	}
+	return nil
+}
+
+// Unregister removes a precompile added with Register.
+func (r *SuavePrecompileRegistry) Unregister(addr common.Address) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	delete(r.precompiles, addr)
+}
+
+// Precompiles returns the names and addresses of the registered precompiles.
+func (r *SuavePrecompileRegistry) Precompiles() map[string]common.Address {
+	res := make(map[string]common.Address)
+	if r == nil {
+		return res
+	}
+
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	for addr, p := range r.precompiles {
+		res[p.method.Name] = addr
+	}
+	return res
+}
+
+func (r *SuavePrecompileRegistry) lookup(addr common.Address) (*registeredPrecompile, bool) {
+	if r == nil {
+		return nil, false
+	}
+
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	p, ok := r.precompiles[addr]
+	return p, ok
+}
+
+func isEthPrecompileAddr(addr common.Address) bool {
+	_, ok := PrecompiledContractsBerlin[addr]
+	return ok
+}
+
+// parsePrecompileSignature parses "name(type [name], ...) [returns (type [name], ...)]".
+func parsePrecompileSignature(signature string) (abi.Method, error) {
+	signature = strings.TrimSpace(signature)
+
+	open := strings.Index(signature, "(")
+	if open <= 0 {
+		return abi.Method{}, errors.New("missing name or arguments")
+	}
+	name := strings.TrimSpace(signature[:open])
+	if !isIdentifier(name) {
+		return abi.Method{}, fmt.Errorf("invalid name %q", name)
+	}
+
+	inputs, rest, err := parseArgumentList(signature[open:])
+	if err != nil {
+		return abi.Method{}, err
+	}
+
+	var outputs abi.Arguments
+	if rest = strings.TrimSpace(rest); rest != "" {
+		if !strings.HasPrefix(rest, "returns") {
+			return abi.Method{}, fmt.Errorf("unexpected %q", rest)
+		}
+		outputs, rest, err = parseArgumentList(strings.TrimSpace(strings.TrimPrefix(rest, "returns")))
+		if err != nil {
+			return abi.Method{}, err
+		}
+		if strings.TrimSpace(rest) != "" {
+			return abi.Method{}, fmt.Errorf("unexpected %q", rest)
+		}
+	}
+
+	return abi.NewMethod(name, name, abi.Function, "view", true, false, inputs, outputs), nil
+}
+
+// parseArgumentList parses a parenthesized list of arguments and returns the
+// remainder of the input.
+func parseArgumentList(input string) (abi.Arguments, string, error) {
+	if !strings.HasPrefix(input, "(") {
+		return nil, "", fmt.Errorf("expected argument list at %q", input)
+	}
+	end := strings.Index(input, ")")
+	if end < 0 {
+		return nil, "", errors.New("unterminated argument list")
+	}
+
+	args := abi.Arguments{}
+	list := strings.TrimSpace(input[1:end])
+	if list == "" {
+		return args, input[end+1:], nil
+	}
+
+	for i, field := range strings.Split(list, ",") {
+		parts := strings.Fields(field)
+		if len(parts) == 0 || len(parts) > 2 {
+			return nil, "", fmt.Errorf("invalid argument %q", strings.TrimSpace(field))
+		}
+
+		typ, err := abi.NewType(parts[0], "", nil)
+		if err != nil {
+			return nil, "", err
+		}
+		if err := checkPrecompileType(typ); err != nil {
+			return nil, "", fmt.Errorf("unsupported type %s: %w", parts[0], err)
+		}
+
+		argName := fmt.Sprintf("arg%d", i)
+		if len(parts) == 2 {
+			if !isIdentifier(parts[1]) {
+				return nil, "", fmt.Errorf("invalid argument name %q", parts[1])
+			}
+			argName = parts[1]
+		}
+		args = append(args, abi.Argument{Name: argName, Type: typ})
+	}
+	return args, input[end+1:], nil
+}
+
+func checkPrecompileType(typ abi.Type) error {
+	switch typ.T {
+	case abi.TupleTy, abi.FunctionTy:
+		return errors.New("tuples and functions are not supported")
+	case abi.IntTy, abi.UintTy:
+		if typ.Size == 0 || typ.Size > 256 || typ.Size%8 != 0 {
+			return errors.New("invalid integer size")
+		}
+	case abi.SliceTy, abi.ArrayTy:
+		return checkPrecompileType(*typ.Elem)
+	}
+	return nil
+}
+
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		if r != '_' && !unicode.IsLetter(r) && (i == 0 || !unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return true
+}
+
+// Library returns the source of a Solidity library wrapping the registered
+// precompiles, in the same shape as the generated Suave library.
+func (r *SuavePrecompileRegistry) Library(libraryName string) string {
+	var precompiles []*registeredPrecompile
+	if r != nil {
+		r.lock.RLock()
+		for _, p := range r.precompiles {
+			precompiles = append(precompiles, p)
+		}
+		r.lock.RUnlock()
+	}
+
+	sort.Slice(precompiles, func(i, j int) bool {
+		return precompiles[i].method.Name < precompiles[j].method.Name
+	})
+
+	var b strings.Builder
+	b.WriteString("// SPDX-License-Identifier: UNLICENSED\n")
+	b.WriteString("pragma solidity ^0.8.8;\n\n")
+	fmt.Fprintf(&b, "library %s {\n", libraryName)
+	b.WriteString("    error PeekerReverted(address, bytes);\n")
+
+	for _, p := range precompiles {
+		fmt.Fprintf(&b, "\n    address public constant %s = %s;\n", toConstantName(p.method.Name), p.addr.Hex())
+	}
+
+	for _, p := range precompiles {
+		b.WriteString("\n")
+		writeSolidityFunction(&b, p)
+	}
+
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func writeSolidityFunction(b *strings.Builder, p *registeredPrecompile) {
+	constant := toConstantName(p.method.Name)
+
+	// the names of the locals of the function body are not available to arguments
+	used := map[string]bool{"success": true, "data": true}
+	for _, arg := range p.method.Inputs {
+		used[arg.Name] = true
+	}
+
+	params := make([]string, len(p.method.Inputs))
+	names := make([]string, len(p.method.Inputs))
+	for i, arg := range p.method.Inputs {
+		name := arg.Name
+		if name == "success" || name == "data" {
+			for n := 1; used[name]; n++ {
+				name = fmt.Sprintf("%s%d", arg.Name, n)
+			}
+			used[name] = true
+		}
+		params[i] = solidityParam(arg.Type) + " " + name
+		names[i] = name
+	}
+
+	returns := make([]string, len(p.method.Outputs))
+	decoded := make([]string, len(p.method.Outputs))
+	for i, arg := range p.method.Outputs {
+		returns[i] = solidityParam(arg.Type)
+		decoded[i] = arg.Type.String()
+	}
+
+	fmt.Fprintf(b, "    function %s(%s) internal view", p.method.Name, strings.Join(params, ", "))
+	if len(returns) != 0 {
+		fmt.Fprintf(b, " returns (%s)", strings.Join(returns, ", "))
+	}
+	b.WriteString(" {\n")
+	fmt.Fprintf(b, "        (bool success, bytes memory data) = %s.staticcall(abi.encode(%s));\n", constant, strings.Join(names, ", "))
+	b.WriteString("        if (!success) {\n")
+	fmt.Fprintf(b, "            revert PeekerReverted(%s, data);\n", constant)
+	b.WriteString("        }\n")
+	if len(decoded) != 0 {
+		fmt.Fprintf(b, "\n        return abi.decode(data, (%s));\n", strings.Join(decoded, ", "))
+	}
+	b.WriteString("    }\n")
+}
+
+func solidityParam(typ abi.Type) string {
+	switch typ.T {
+	case abi.StringTy, abi.BytesTy, abi.SliceTy, abi.ArrayTy:
+		return typ.String() + " memory"
+	default:
+		return typ.String()
+	}
+}
+
+// toConstantName converts a camel case name to upper snake case.
+func toConstantName(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) && i != 0 && !unicode.IsUpper(rune(name[i-1])) {
+			b.WriteRune('_')
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
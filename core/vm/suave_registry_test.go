@@ -0,0 +1,121 @@
+package vm
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuavePrecompileRegistry(t *testing.T) {
+	addr := common.HexToAddress("0x0000000000000000000000000000000099990001")
+	limit := big.NewInt(100)
+
+	registry := NewSuavePrecompileRegistry()
+
+	var calledWith *SuaveContext
+	err := registry.Register(addr, "riskCheck(address account, uint256 amount) returns (bool, string)", func(suaveContext *SuaveContext, args []interface{}) ([]interface{}, error) {
+		calledWith = suaveContext
+		if args[1].(*big.Int).Cmp(limit) > 0 {
+			return []interface{}{false, "over limit"}, nil
+		}
+		return []interface{}{true, ""}, nil
+	})
+	require.NoError(t, err)
+
+	require.True(t, isPrecompileAddr(registry, addr))
+	require.False(t, isPrecompileAddr(nil, addr))
+	require.False(t, isPrecompileAddr(NewSuavePrecompileRegistry(), addr))
+	require.Equal(t, addr, registry.Precompiles()["riskCheck"])
+
+	// conflicting registrations are rejected
+	require.Error(t, registry.Register(addr, "otherCheck()", func(*SuaveContext, []interface{}) ([]interface{}, error) { return nil, nil }))
+	require.Error(t, registry.Register(common.Address{0x1}, "riskCheck()", func(*SuaveContext, []interface{}) ([]interface{}, error) { return nil, nil }))
+	require.Error(t, registry.Register(newBidAddr, "myNewBid()", func(*SuaveContext, []interface{}) ([]interface{}, error) { return nil, nil }))
+	require.Error(t, registry.Register(common.BytesToAddress([]byte{0x1}), "ecrecover2()", func(*SuaveContext, []interface{}) ([]interface{}, error) { return nil, nil }))
+
+	registered, ok := registry.lookup(addr)
+	require.True(t, ok)
+
+	suaveContext := &SuaveContext{Backend: &SuaveExecutionBackend{Precompiles: registry}}
+	wrapper := NewSuavePrecompiledContractWrapper(addr, suaveContext)
+
+	input, err := registered.method.Inputs.Pack(common.Address{0x2}, big.NewInt(101))
+	require.NoError(t, err)
+
+	output, err := wrapper.Run(input)
+	require.NoError(t, err)
+	require.Same(t, suaveContext, calledWith)
+
+	results, err := registered.method.Outputs.Unpack(output)
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{false, "over limit"}, results)
+
+	// malformed input does not reach the handler
+	_, err = wrapper.Run([]byte{0x1})
+	require.ErrorIs(t, err, errFailedToUnpackInput)
+}
+
+func TestSuavePrecompileRegistry_HandlerError(t *testing.T) {
+	addr := common.HexToAddress("0x0000000000000000000000000000000099990002")
+
+	registry := NewSuavePrecompileRegistry()
+	err := registry.Register(addr, "alwaysFails()", func(*SuaveContext, []interface{}) ([]interface{}, error) {
+		return nil, errors.New("not today")
+	})
+	require.NoError(t, err)
+
+	suaveContext := &SuaveContext{Backend: &SuaveExecutionBackend{Precompiles: registry}}
+	output, err := NewSuavePrecompiledContractWrapper(addr, suaveContext).Run(nil)
+	require.EqualError(t, err, "not today")
+	require.Equal(t, []byte("not today"), output)
+}
+
+func TestParsePrecompileSignature(t *testing.T) {
+	cases := []struct {
+		signature string
+		sig       string
+		outputs   int
+		err       bool
+	}{
+		{"check()", "check()", 0, false},
+		{"check(uint64 a, bytes) returns (address[])", "check(uint64,bytes)", 1, false},
+		{" check ( string name ) returns ( bool ok , uint8 ) ", "check(string)", 2, false},
+		{"check", "", 0, true},
+		{"1check()", "", 0, true},
+		{"check(uint7)", "", 0, true},
+		{"check(uint64 a b)", "", 0, true},
+		{"check() returns bool", "", 0, true},
+		{"check() view", "", 0, true},
+	}
+
+	for _, c := range cases {
+		method, err := parsePrecompileSignature(c.signature)
+		if c.err {
+			require.Error(t, err, c.signature)
+			continue
+		}
+		require.NoError(t, err, c.signature)
+		require.Equal(t, c.sig, method.Sig)
+		require.Len(t, method.Outputs, c.outputs)
+	}
+}
+
+func TestSuavePrecompileRegistry_Library(t *testing.T) {
+	addr := common.HexToAddress("0x0000000000000000000000000000000099990003")
+
+	registry := NewSuavePrecompileRegistry()
+	err := registry.Register(addr, "riskCheck(address account, bytes data) returns (bool)", func(*SuaveContext, []interface{}) ([]interface{}, error) {
+		return []interface{}{true}, nil
+	})
+	require.NoError(t, err)
+
+	library := registry.Library("Risk")
+	require.Contains(t, library, "library Risk {")
+	require.Contains(t, library, "address public constant RISK_CHECK = 0x0000000000000000000000000000000099990003;")
+	require.Contains(t, library, "function riskCheck(address account, bytes memory data1) internal view returns (bool) {")
+	require.Contains(t, library, "RISK_CHECK.staticcall(abi.encode(account, data1));")
+	require.Contains(t, library, "return abi.decode(data, (bool));")
+}
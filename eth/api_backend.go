@@ -43,6 +43,7 @@ import (
 	"github.com/ethereum/go-ethereum/miner"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rpc"
+	suave_backends "github.com/ethereum/go-ethereum/suave/backends"
 	suave "github.com/ethereum/go-ethereum/suave/core"
 	"github.com/ethereum/go-ethereum/suave/cstore"
 	"github.com/flashbots/go-boost-utils/bls"
@@ -56,8 +57,13 @@ type EthAPIBackend struct {
 	gpo                      *gasprice.Oracle
 	suaveEthBundleSigningKey *ecdsa.PrivateKey
 	suaveEthBlockSigningKey  *bls.SecretKey
+	suaveEncryptionKey       *ecdsa.PrivateKey
 	suaveEngine              *cstore.ConfidentialStoreEngine
 	suaveEthBackend          suave.ConfidentialEthBackend
+	suaveBeaconBackend       *suave_backends.RemoteBeaconBackend
+	suaveResults             *suave.ConfidentialResults
+	suaveAuditLog            *suave.AuditLog
+	suavePrecompiles         *vm.SuavePrecompileRegistry
 }
 
 // For testing purposes
@@ -65,6 +71,12 @@ func (b *EthAPIBackend) SuaveEngine() *cstore.ConfidentialStoreEngine {
 	return b.suaveEngine
 }
 
+// SuaveBeaconBackend returns the beacon backend the node follows, nil if it
+// does not follow a beacon node.
+func (b *EthAPIBackend) SuaveBeaconBackend() *suave_backends.RemoteBeaconBackend {
+	return b.suaveBeaconBackend
+}
+
 // ChainConfig returns the active chain configuration.
 func (b *EthAPIBackend) ChainConfig() *params.ChainConfig {
 	return b.eth.blockchain.Config()
@@ -289,12 +301,19 @@ func (b *EthAPIBackend) GetMEVM(ctx context.Context, msg *core.Message, state *s
 	}
 
 	suaveCtxCopy := *suaveCtx
-	storeTransaction := b.suaveEngine.NewTransactionalStore(suaveCtx.ConfidentialComputeRequestTx)
+	suaveCtxCopy.BlockNumber = header.Number.Uint64()
+	storeTransaction := b.suaveEngine.NewTransactionalStore(suaveCtx.ConfidentialComputeRequestTx, suaveCtxCopy.BlockNumber)
+	var confidentialStore vm.ConfidentialStore = storeTransaction
+	if suaveCtx.AuditRecord != nil {
+		confidentialStore = vm.NewAuditedConfidentialStore(storeTransaction, suaveCtx.AuditRecord)
+	}
 	suaveCtxCopy.Backend = &vm.SuaveExecutionBackend{
-		EthBundleSigningKey:    suaveCtx.Backend.EthBundleSigningKey,
-		EthBlockSigningKey:     suaveCtx.Backend.EthBlockSigningKey,
-		ConfidentialStore:      storeTransaction,
-		ConfidentialEthBackend: b.suaveEthBackend,
+		EthBundleSigningKey:       suaveCtx.Backend.EthBundleSigningKey,
+		EthBlockSigningKey:        suaveCtx.Backend.EthBlockSigningKey,
+		ConfidentialStore:         confidentialStore,
+		ConfidentialEthBackend:    b.suaveEthBackend,
+		ConfidentialBeaconBackend: b.confidentialBeaconBackend(),
+		Precompiles:               b.suavePrecompiles,
 	}
 	return vm.NewConfidentialEVM(suaveCtxCopy, context, txContext, state, b.eth.blockchain.Config(), *vmConfig), storeTransaction.Finalize, state.Error
 }
@@ -440,29 +459,68 @@ func (b *EthAPIBackend) StartMining() error {
 	return b.eth.StartMining()
 }
 
+func (b *EthAPIBackend) SuaveEncryptionKey() *ecdsa.PrivateKey {
+	return b.suaveEncryptionKey
+}
+
+func (b *EthAPIBackend) KettleKeys() []*suave.KettleKey {
+	return b.suaveEngine.KettleKeys()
+}
+
+func (b *EthAPIBackend) SuaveConfidentialResults() *suave.ConfidentialResults {
+	return b.suaveResults
+}
+
+func (b *EthAPIBackend) SuaveAuditLog() *suave.AuditLog {
+	return b.suaveAuditLog
+}
+
+// SuavePrecompiles returns the registry embedding applications add their own
+// precompiles to, before the node starts executing requests.
+func (b *EthAPIBackend) SuavePrecompiles() *vm.SuavePrecompileRegistry {
+	return b.suavePrecompiles
+}
+
 func (b *EthAPIBackend) SuaveContext(requestTx *types.Transaction, ccr *types.ConfidentialComputeRequest) vm.SuaveContext {
-	storeTransaction := b.suaveEngine.NewTransactionalStore(requestTx)
+	blockNumber := b.eth.blockchain.CurrentBlock().Number.Uint64()
+	storeTransaction := b.suaveEngine.NewTransactionalStore(requestTx, blockNumber)
 	return vm.SuaveContext{
 		ConfidentialComputeRequestTx: requestTx,
 		ConfidentialInputs:           ccr.ConfidentialInputs,
 		CallerStack:                  []*common.Address{},
+		BlockNumber:                  blockNumber,
 		Backend: &vm.SuaveExecutionBackend{
-			EthBundleSigningKey:    b.suaveEthBundleSigningKey,
-			EthBlockSigningKey:     b.suaveEthBlockSigningKey,
-			ConfidentialStore:      storeTransaction,
-			ConfidentialEthBackend: b.suaveEthBackend,
+			EthBundleSigningKey:       b.suaveEthBundleSigningKey,
+			EthBlockSigningKey:        b.suaveEthBlockSigningKey,
+			ConfidentialStore:         storeTransaction,
+			ConfidentialEthBackend:    b.suaveEthBackend,
+			ConfidentialBeaconBackend: b.confidentialBeaconBackend(),
+			Precompiles:               b.suavePrecompiles,
 		},
 	}
 }
 
-func (b *EthAPIBackend) BuildBlockFromTxs(ctx context.Context, buildArgs *suave.BuildBlockArgs, txs types.Transactions) (*types.Block, *big.Int, error) {
+// confidentialBeaconBackend avoids handing a typed nil to the MEVM when the
+// node does not follow a beacon node.
+func (b *EthAPIBackend) confidentialBeaconBackend() suave.ConfidentialBeaconBackend {
+	if b.suaveBeaconBackend == nil {
+		return nil
+	}
+	return b.suaveBeaconBackend
+}
+
+func (b *EthAPIBackend) BuildBlockFromTxs(ctx context.Context, buildArgs *suave.BuildBlockArgsV2, txs types.Transactions) (*types.Block, *big.Int, error) {
 	return b.eth.Miner().BuildBlockFromTxs(ctx, buildArgs, txs)
 }
 
-func (b *EthAPIBackend) BuildBlockFromBundles(ctx context.Context, buildArgs *suave.BuildBlockArgs, bundles []types.SBundle) (*types.Block, *big.Int, error) {
+func (b *EthAPIBackend) BuildBlockFromBundles(ctx context.Context, buildArgs *suave.BuildBlockArgsV2, bundles []types.SBundle) (*types.Block, *big.Int, error) {
 	return b.eth.Miner().BuildBlockFromBundles(ctx, buildArgs, bundles)
 }
 
+func (b *EthAPIBackend) SimulateBundlesForBlock(ctx context.Context, buildArgs *suave.BuildBlockArgsV2, bundles []types.SBundle) ([]*miner.BundleSimulation, error) {
+	return b.eth.Miner().SimulateBundles(ctx, buildArgs, bundles)
+}
+
 func (b *EthAPIBackend) StateAtBlock(ctx context.Context, block *types.Block, reexec uint64, base *state.StateDB, readOnly bool, preferDisk bool) (*state.StateDB, tracers.StateReleaseFunc, error) {
 	return b.eth.StateAtBlock(ctx, block, reexec, base, readOnly, preferDisk)
 }
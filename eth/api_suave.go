@@ -0,0 +1,212 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/suave/artifacts"
+	suave "github.com/ethereum/go-ethereum/suave/core"
+	"github.com/flashbots/go-boost-utils/bls"
+)
+
+// confidentialResultsLimit is the number of confidential results kept for the
+// request signers to fetch.
+const confidentialResultsLimit = 4096
+
+// SuaveAPI provides information about the kettle to SUAVE clients.
+type SuaveAPI struct {
+	e *Ethereum
+}
+
+// NewSuaveAPI creates a new SuaveAPI instance.
+func NewSuaveAPI(e *Ethereum) *SuaveAPI {
+	return &SuaveAPI{e}
+}
+
+// KettleInfo returns the capability document of the kettle, signed by each of
+// its kettle addresses.
+func (api *SuaveAPI) KettleInfo(ctx context.Context) (*suave.SignedKettleInfo, error) {
+	info := api.e.kettleInfo()
+
+	infoBytes, err := json.Marshal(info)
+	if err != nil {
+		return nil, err
+	}
+
+	signed := &suave.SignedKettleInfo{
+		Info:       infoBytes,
+		Signatures: []*suave.KettleInfoSignature{},
+	}
+	for _, addr := range info.KettleAddresses {
+		account := accounts.Account{Address: addr}
+		wallet, err := api.e.AccountManager().Find(account)
+		if err != nil {
+			return nil, err
+		}
+
+		signature, err := wallet.SignData(account, accounts.MimetypeTextPlain, infoBytes)
+		if err != nil {
+			return nil, fmt.Errorf("could not sign kettle info with %s: %w", addr, err)
+		}
+
+		signed.Signatures = append(signed.Signatures, &suave.KettleInfoSignature{
+			KettleAddress: addr,
+			Signature:     signature,
+		})
+	}
+
+	return signed, nil
+}
+
+// PrecompilesLibrary returns a Solidity library wrapping the precompiles the
+// embedding application registered in addition to the generated ones.
+func (api *SuaveAPI) PrecompilesLibrary(ctx context.Context) string {
+	return api.e.APIBackend.SuavePrecompiles().Library("SuaveRegistered")
+}
+
+// ConfidentialResult returns the logs emitted during the confidential execution
+// of a request, by the hash of the request or of its SuaveTransaction. Only
+// the signer of the request can fetch them, with the EIP-191 signature of
+// suave.ConfidentialResultMessage for one of the kettle addresses.
+func (api *SuaveAPI) ConfidentialResult(ctx context.Context, hash common.Hash, auth suave.ConfidentialResultAuth) (*suave.ConfidentialResult, error) {
+	signer, err := auth.Verify(api.e.blockchain.Config().ChainID, api.e.kettleAddresses(), hash, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	// Unknown results and results of other signers are not told apart
+	result := api.e.APIBackend.SuaveConfidentialResults().Get(hash)
+	if result == nil || result.Sender != signer {
+		return nil, fmt.Errorf("no confidential result for %s signed by %s", hash, signer)
+	}
+	return result, nil
+}
+
+// ConfidentialLogsAPI streams the confidential results of requests to their
+// signers.
+type ConfidentialLogsAPI struct {
+	e *Ethereum
+}
+
+// NewConfidentialLogsAPI creates a new ConfidentialLogsAPI instance.
+func NewConfidentialLogsAPI(e *Ethereum) *ConfidentialLogsAPI {
+	return &ConfidentialLogsAPI{e}
+}
+
+// ConfidentialLogs sends the confidential results of the requests signed by
+// the authenticated account as they are executed.
+func (api *ConfidentialLogsAPI) ConfidentialLogs(ctx context.Context, auth suave.ConfidentialLogsAuth) (*rpc.Subscription, error) {
+	if err := auth.Verify(api.e.blockchain.Config().ChainID, api.e.kettleAddresses(), time.Now()); err != nil {
+		return nil, err
+	}
+
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+	go func() {
+		results := make(chan *suave.ConfidentialResult, 128)
+		sub := api.e.APIBackend.SuaveConfidentialResults().Subscribe(results)
+		defer sub.Unsubscribe()
+
+		for {
+			select {
+			case result := <-results:
+				if result.Sender == auth.Address {
+					notifier.Notify(rpcSub.ID, result)
+				}
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
+// RotateKettleKey hands the identity of the kettle over from a local account
+// to another one, both have to be unlocked. The old account is still accepted
+// for graceSeconds, by default the configured grace period.
+func (api *AdminAPI) RotateKettleKey(oldAddr common.Address, newAddr common.Address, graceSeconds *uint64) (*suave.KettleKeyHandover, error) {
+	grace := api.eth.config.Suave.KettleKeyGracePeriod
+	if graceSeconds != nil {
+		grace = time.Duration(*graceSeconds) * time.Second
+	}
+	return api.eth.APIBackend.SuaveEngine().RotateKettleKey(oldAddr, newAddr, grace)
+}
+
+func (s *Ethereum) kettleInfo() *suave.KettleInfo {
+	info := &suave.KettleInfo{
+		KettleAddresses:    s.kettleAddresses(),
+		PrecompileSpecHash: artifacts.SpecHash,
+		Precompiles:        map[string]common.Address{},
+		ChainID:            (*hexutil.Big)(s.blockchain.Config().ChainID),
+		EthBackend:         s.config.Suave.EthBackendType(),
+		BeaconBackend:      s.config.Suave.BeaconRemoteEndpoint != "",
+		RelayBackend:       s.config.Suave.RelayRemoteEndpoint != "",
+		StoreBackend:       s.config.Suave.StoreBackendType(),
+		StoreTransport:     s.config.Suave.StoreTransportType(),
+		BuildVersion:       params.VersionWithMeta,
+	}
+	for name, addr := range artifacts.SuaveMethods {
+		info.Precompiles[name] = addr
+	}
+	for name, addr := range s.APIBackend.SuavePrecompiles().Precompiles() {
+		info.Precompiles[name] = addr
+	}
+	if info.KettleAddresses == nil {
+		info.KettleAddresses = []common.Address{}
+	}
+	if encryptionKey := s.APIBackend.SuaveEncryptionKey(); encryptionKey != nil {
+		info.EncryptionKey = crypto.FromECDSAPub(&encryptionKey.PublicKey)
+	}
+	if bundleSigningKey := s.APIBackend.suaveEthBundleSigningKey; bundleSigningKey != nil {
+		info.BundleSigningKey = crypto.FromECDSAPub(&bundleSigningKey.PublicKey)
+	}
+	if blockSigningKey := s.APIBackend.suaveEthBlockSigningKey; blockSigningKey != nil {
+		if pk, err := bls.PublicKeyFromSecretKey(blockSigningKey); err == nil {
+			info.BlockSigningKey = bls.PublicKeyToBytes(pk)
+		}
+	}
+	return info
+}
+
+// kettleAddresses returns the keys requests can be sent to: the active ones,
+// then the ones retired by a rotation that are still in their grace window.
+func (s *Ethereum) kettleAddresses() []common.Address {
+	addrs := []common.Address{}
+	for _, key := range s.APIBackend.SuaveEngine().KettleKeys() {
+		if key.Usable() {
+			addrs = append(addrs, key.Address)
+		}
+	}
+	return addrs
+}
@@ -252,6 +252,16 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	var confidentialStoreTransport cstore.StoreTransportTopic
 	if config.Suave.RedisStorePubsubUri != "" {
 		confidentialStoreTransport = cstore.NewRedisPubSubTransport(config.Suave.RedisStorePubsubUri)
+	} else if config.Suave.RedisStoreStreamsUri != "" {
+		group := config.Suave.RedisStoreStreamsGroup
+		if group == "" {
+			kettleAddresses := stack.AccountManager().Accounts()
+			if len(kettleAddresses) == 0 {
+				return nil, errors.New("redis streams transport requires a consumer group or a kettle account")
+			}
+			group = kettleAddresses[0].Hex()
+		}
+		confidentialStoreTransport = cstore.NewRedisStreamsTransport(config.Suave.RedisStoreStreamsUri, group, config.Suave.RedisStoreStreamsMaxAge)
 	} else {
 		confidentialStoreTransport = cstore.MockTransport{}
 	}
@@ -260,7 +270,21 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	if config.Suave.SuaveEthRemoteBackendEndpoint != "" {
 		suaveEthBackend = suave_backends.NewRemoteEthBackend(config.Suave.SuaveEthRemoteBackendEndpoint)
 	} else {
-		suaveEthBackend = &suave_backends.EthMock{}
+		genesis, err := suave_backends.LoadLocalEthGenesis(config.Suave.EthLocalGenesisPath, config.Suave.EthLocalStateDumpPath)
+		if err != nil {
+			return nil, err
+		}
+		localEthBackend, err := suave_backends.NewLocalEthBackend(genesis, config.Miner.BuilderSigningKey)
+		if err != nil {
+			return nil, err
+		}
+		stack.RegisterLifecycle(localEthBackend)
+		suaveEthBackend = localEthBackend
+	}
+
+	var suaveBeaconBackend *suave_backends.RemoteBeaconBackend
+	if config.Suave.BeaconRemoteEndpoint != "" {
+		suaveBeaconBackend = suave_backends.NewRemoteBeaconBackend(config.Suave.BeaconRemoteEndpoint, config.Suave.RelayRemoteEndpoint)
 	}
 
 	var suaveEthBundleSigningKey *ecdsa.PrivateKey
@@ -283,11 +307,42 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		return nil, err
 	}
 
+	suaveEncryptionKey, err := config.Suave.EncryptionKey(stack.ResolvePath("suave-encryptionkey"))
+	if err != nil {
+		return nil, err
+	}
+
 	suaveDaSigner := &cstore.AccountManagerDASigner{Manager: eth.AccountManager()}
 
 	confidentialStoreEngine := cstore.NewConfidentialStoreEngine(confidentialStoreBackend, confidentialStoreTransport, suaveDaSigner, types.LatestSigner(chainConfig))
+	confidentialStoreEngine.SetQuotas(config.Suave.StoreQuotas)
 
-	eth.APIBackend = &EthAPIBackend{stack.Config().ExtRPCEnabled(), stack.Config().AllowUnprotectedTxs, eth, nil, suaveEthBundleSigningKey, suaveEthBlockSigningKey, confidentialStoreEngine, suaveEthBackend}
+	kettleKeys, err := cstore.NewKettleKeyRing(stack.ResolvePath("kettlekeys.json"), config.Suave.KettleKeyMaxAge)
+	if err != nil {
+		return nil, err
+	}
+	confidentialStoreEngine.SetKeyRing(kettleKeys)
+
+	var suaveAuditLog *suave.AuditLog
+	if config.Suave.AuditLogPath != "" {
+		auditLogKey, err := config.Suave.AuditLogKey()
+		if err != nil {
+			return nil, err
+		}
+		// The records hold the confidential inputs of the requests
+		if auditLogKey == nil && !config.Suave.AuditLogPlaintext {
+			return nil, errors.New("the audit log records confidential inputs, set an audit log encryption key or explicitly allow a plaintext audit log")
+		}
+		auditLogPath := stack.ResolvePath(config.Suave.AuditLogPath)
+		suaveAuditLog, err = suave.OpenAuditLog(auditLogPath, auditLogKey, suaveDaSigner)
+		if err != nil {
+			return nil, err
+		}
+		stack.RegisterLifecycle(suaveAuditLog)
+		log.Info("Recording confidential executions", "path", auditLogPath, "encrypted", auditLogKey != nil)
+	}
+
+	eth.APIBackend = &EthAPIBackend{stack.Config().ExtRPCEnabled(), stack.Config().AllowUnprotectedTxs, eth, nil, suaveEthBundleSigningKey, suaveEthBlockSigningKey, suaveEncryptionKey, confidentialStoreEngine, suaveEthBackend, suaveBeaconBackend, suave.NewConfidentialResults(confidentialResultsLimit), suaveAuditLog, vm.NewSuavePrecompileRegistry()}
 	if eth.APIBackend.allowUnprotectedTxs {
 		log.Info("Unprotected transactions allowed")
 	}
@@ -316,6 +371,9 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	stack.RegisterProtocols(eth.Protocols())
 	stack.RegisterLifecycle(eth)
 	stack.RegisterLifecycle(confidentialStoreEngine)
+	if suaveBeaconBackend != nil {
+		stack.RegisterLifecycle(suaveBeaconBackend)
+	}
 
 	// Successful startup; push a marker and check previous unclean shutdowns.
 	eth.shutdownTracker.MarkStartup()
@@ -351,6 +409,23 @@ func (s *Ethereum) APIs() []rpc.API {
 		Service:   backends.NewEthBackendServer(s.APIBackend),
 	})
 
+	apis = append(apis, rpc.API{
+		Namespace: "suave",
+		Service:   NewSuaveAPI(s),
+	})
+
+	apis = append(apis, rpc.API{
+		Namespace: "eth",
+		Service:   NewConfidentialLogsAPI(s),
+	})
+
+	if s.APIBackend.suaveBeaconBackend != nil {
+		apis = append(apis, rpc.API{
+			Namespace: "suave",
+			Service:   backends.NewBeaconBackendAPI(s.APIBackend.suaveBeaconBackend),
+		})
+	}
+
 	// Append any APIs exposed explicitly by the consensus engine
 	apis = append(apis, s.engine.APIs(s.BlockChain())...)
 
@@ -1024,8 +1024,11 @@ func DoCall(ctx context.Context, b Backend, args TransactionArgs, blockNrOrHash
 
 	if args.IsConfidential {
 		if args.KettleAddress == nil {
-			acc := b.AccountManager().Accounts()[0]
-			args.KettleAddress = &acc
+			addrs := kettleAddresses(b)
+			if len(addrs) == 0 {
+				return nil, errors.New("kettle has no kettle address")
+			}
+			args.KettleAddress = &addrs[0]
 		}
 
 		tx := args.ToTransaction()
@@ -1354,30 +1357,31 @@ func (s *BlockChainAPI) rpcMarshalBlock(ctx context.Context, b *types.Block, inc
 
 // RPCTransaction represents a transaction that will serialize to the RPC representation of a transaction
 type RPCTransaction struct {
-	BlockHash                 *common.Hash      `json:"blockHash"`
-	BlockNumber               *hexutil.Big      `json:"blockNumber"`
-	From                      common.Address    `json:"from"`
-	Gas                       hexutil.Uint64    `json:"gas"`
-	GasPrice                  *hexutil.Big      `json:"gasPrice"`
-	GasFeeCap                 *hexutil.Big      `json:"maxFeePerGas,omitempty"`
-	GasTipCap                 *hexutil.Big      `json:"maxPriorityFeePerGas,omitempty"`
-	Hash                      common.Hash       `json:"hash"`
-	Input                     hexutil.Bytes     `json:"input"`
-	Nonce                     hexutil.Uint64    `json:"nonce"`
-	To                        *common.Address   `json:"to"`
-	TransactionIndex          *hexutil.Uint64   `json:"transactionIndex"`
-	Value                     *hexutil.Big      `json:"value"`
-	Type                      hexutil.Uint64    `json:"type"`
-	Accesses                  *types.AccessList `json:"accessList,omitempty"`
-	ChainID                   *hexutil.Big      `json:"chainId,omitempty"`
-	KettleAddress             *common.Address   `json:"kettleAddress,omitempty"`
-	ConfidentialInputsHash    *common.Hash      `json:"confidentialInputsHash,omitempty"`
-	ConfidentialInputs        *hexutil.Bytes    `json:"confidentialInputs,omitempty"`
-	RequestRecord             *json.RawMessage  `json:"requestRecord,omitempty"`
-	ConfidentialComputeResult *hexutil.Bytes    `json:"confidentialComputeResult,omitempty"`
-	V                         *hexutil.Big      `json:"v"`
-	R                         *hexutil.Big      `json:"r"`
-	S                         *hexutil.Big      `json:"s"`
+	BlockHash                   *common.Hash      `json:"blockHash"`
+	BlockNumber                 *hexutil.Big      `json:"blockNumber"`
+	From                        common.Address    `json:"from"`
+	Gas                         hexutil.Uint64    `json:"gas"`
+	GasPrice                    *hexutil.Big      `json:"gasPrice"`
+	GasFeeCap                   *hexutil.Big      `json:"maxFeePerGas,omitempty"`
+	GasTipCap                   *hexutil.Big      `json:"maxPriorityFeePerGas,omitempty"`
+	Hash                        common.Hash       `json:"hash"`
+	Input                       hexutil.Bytes     `json:"input"`
+	Nonce                       hexutil.Uint64    `json:"nonce"`
+	To                          *common.Address   `json:"to"`
+	TransactionIndex            *hexutil.Uint64   `json:"transactionIndex"`
+	Value                       *hexutil.Big      `json:"value"`
+	Type                        hexutil.Uint64    `json:"type"`
+	Accesses                    *types.AccessList `json:"accessList,omitempty"`
+	ChainID                     *hexutil.Big      `json:"chainId,omitempty"`
+	KettleAddress               *common.Address   `json:"kettleAddress,omitempty"`
+	ConfidentialInputsHash      *common.Hash      `json:"confidentialInputsHash,omitempty"`
+	ConfidentialInputs          *hexutil.Bytes    `json:"confidentialInputs,omitempty"`
+	EncryptedConfidentialInputs *hexutil.Bytes    `json:"encryptedConfidentialInputs,omitempty"`
+	RequestRecord               *json.RawMessage  `json:"requestRecord,omitempty"`
+	ConfidentialComputeResult   *hexutil.Bytes    `json:"confidentialComputeResult,omitempty"`
+	V                           *hexutil.Big      `json:"v"`
+	R                           *hexutil.Big      `json:"r"`
+	S                           *hexutil.Big      `json:"s"`
 }
 
 // newRPCTransaction returns a transaction that will serialize to the RPC
@@ -1461,6 +1465,17 @@ func newRPCTransaction(tx *types.Transaction, blockHash common.Hash, blockNumber
 		result.ConfidentialInputs = (*hexutil.Bytes)(&inner.ConfidentialInputs)
 		result.ConfidentialInputsHash = &inner.ConfidentialInputsHash
 		result.ChainID = (*hexutil.Big)(tx.ChainId())
+	case types.EncryptedConfidentialComputeRequestTxType:
+		inner, ok := types.CastTxInner[*types.EncryptedConfidentialComputeRequest](tx)
+		if !ok {
+			log.Error("could not marshal rpc transaction: tx did not cast correctly")
+			return nil
+		}
+
+		result.KettleAddress = &inner.KettleAddress
+		result.EncryptedConfidentialInputs = (*hexutil.Bytes)(&inner.EncryptedConfidentialInputs)
+		result.ConfidentialInputsHash = &inner.ConfidentialInputsHash
+		result.ChainID = (*hexutil.Big)(tx.ChainId())
 	case types.SuaveTxType:
 		inner, ok := types.CastTxInner[*types.SuaveTransaction](tx)
 		if !ok {
@@ -1886,6 +1901,7 @@ func (s *TransactionAPI) SendTransaction(ctx context.Context, args TransactionAr
 			log.Error("could not finalize confidential store", "err", err)
 			return tx.Hash(), err
 		}
+		recordConfidentialResult(s.b, state, header, signed, msg.From, ntx)
 		signed = ntx
 	}
 	return SubmitTransaction(ctx, s.b, signed)
@@ -1916,7 +1932,7 @@ func (s *TransactionAPI) SendRawTransaction(ctx context.Context, input hexutil.B
 		return common.Hash{}, err
 	}
 
-	if _, ok := types.CastTxInner[*types.ConfidentialComputeRequest](tx); ok {
+	if tx.Type() == types.ConfidentialComputeRequestTxType || tx.Type() == types.EncryptedConfidentialComputeRequestTxType {
 		state, header, err := s.b.StateAndHeaderByNumber(ctx, rpc.LatestBlockNumber)
 		if state == nil || err != nil {
 			return common.Hash{}, err
@@ -1935,6 +1951,7 @@ func (s *TransactionAPI) SendRawTransaction(ctx context.Context, input hexutil.B
 			log.Error("could not finalize confidential store", "err", err)
 			return tx.Hash(), err
 		}
+		recordConfidentialResult(s.b, state, header, tx, msg.From, ntx)
 		tx = ntx
 	}
 
@@ -1969,15 +1986,19 @@ func (m *mevmStateLogger) CaptureTxStart(gasLimit uint64) {}
 func (m *mevmStateLogger) CaptureTxEnd(restGas uint64) {}
 
 // TODO: should be its own api
-func runMEVM(ctx context.Context, b Backend, state *state.StateDB, header *types.Header, tx *types.Transaction, msg *core.Message, isCall bool) (*types.Transaction, *core.ExecutionResult, func() error, error) {
+func runMEVM(ctx context.Context, b Backend, state *state.StateDB, header *types.Header, tx *types.Transaction, msg *core.Message, isCall bool) (signed *types.Transaction, result *core.ExecutionResult, finalize func() error, err error) {
 	var cancel context.CancelFunc
 	ctx, cancel = context.WithCancel(ctx)
 	defer cancel()
 
 	// TODO: copy the inner, but only once
-	confidentialRequest, ok := types.CastTxInner[*types.ConfidentialComputeRequest](tx)
-	if !ok {
-		return nil, nil, nil, errors.New("invalid transaction passed")
+	confidentialRequest, err := confidentialComputeRequest(b, tx)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if err := checkKettleKey(b, confidentialRequest.KettleAddress); err != nil {
+		return nil, nil, nil, err
 	}
 
 	// Look up the wallet containing the requested execution node
@@ -1991,6 +2012,30 @@ func runMEVM(ctx context.Context, b Backend, state *state.StateDB, header *types
 
 	blockCtx := core.NewEVMBlockContext(header, NewChainContext(ctx, b), nil)
 	suaveCtx := b.SuaveContext(tx, confidentialRequest)
+	if auditLog := b.SuaveAuditLog(); auditLog != nil && !isCall {
+		record := newAuditRecord(tx, confidentialRequest, header)
+		suaveCtx.AuditRecord = record
+		defer func() {
+			if err != nil {
+				record.Error = err.Error()
+				appendAuditRecord(auditLog, record)
+				return
+			}
+			record.TxHash = signed.Hash()
+			record.SuaveTransaction, _ = signed.MarshalBinary()
+
+			// The record is written once the store writes are, or failed
+			storeFinalize := finalize
+			finalize = func() error {
+				err := storeFinalize()
+				if err != nil {
+					record.Error = fmt.Sprintf("could not finalize confidential store: %v", err)
+				}
+				appendAuditRecord(auditLog, record)
+				return err
+			}
+		}()
+	}
 	evm, storeFinalize, vmError := b.GetMEVM(ctx, msg, state, header, &vm.Config{IsConfidential: true, NoBaseFee: isCall, Tracer: storageAccessTracer}, &blockCtx, &suaveCtx)
 
 	// Wait for the context to be done and cancel the evm. Even if the
@@ -2000,11 +2045,12 @@ func runMEVM(ctx context.Context, b Backend, state *state.StateDB, header *types
 		evm.Cancel()
 	}()
 
-	// Execute the message.
+	// Execute the message, keying the logs by the request
+	state.SetTxContext(tx.Hash(), 0)
 	gp := new(core.GasPool).AddGas(header.GasLimit)
 
 	msg.SkipAccountChecks = true // validate elsewhere!
-	result, err := core.ApplyMessage(evm, msg, gp)
+	result, err = core.ApplyMessage(evm, msg, gp)
 	// If the timer caused an abort, return an appropriate error message
 	if evm.Cancelled() {
 		return nil, nil, nil, fmt.Errorf("execution aborted")
@@ -2024,27 +2070,91 @@ func runMEVM(ctx context.Context, b Backend, state *state.StateDB, header *types
 		return nil, nil, nil, fmt.Errorf("confidential request cannot modify state storage")
 	}
 
-	// Check for call in return
-	var computeResult []byte
+	computeResult := confidentialComputeResult(result.ReturnData)
+	suaveResultTxData := &types.SuaveTransaction{ConfidentialComputeRequest: confidentialRequest.ConfidentialComputeRecord, ConfidentialComputeResult: computeResult}
+
+	signed, err = wallet.SignTx(account, types.NewTx(suaveResultTxData), tx.ChainId())
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// will copy the inner tx again!
+	return signed, result, storeFinalize, nil
+}
 
+// confidentialComputeResult returns the ConfidentialComputeResult of the
+// SuaveTransaction for the data returned by the confidential execution.
+func confidentialComputeResult(returnData []byte) []byte {
+	// Check for call in return
 	args := abi.Arguments{abi.Argument{Type: abi.Type{T: abi.BytesTy}}}
-	unpacked, err := args.Unpack(result.ReturnData)
+	unpacked, err := args.Unpack(returnData)
 	if err == nil && len(unpacked[0].([]byte))%32 == 4 {
 		// This is supposed to be the case for all confidential compute!
-		computeResult = unpacked[0].([]byte)
-	} else {
-		computeResult = result.ReturnData // Or should it be nil maybe in this case?
+		return unpacked[0].([]byte)
 	}
+	return returnData // Or should it be nil maybe in this case?
+}
 
-	suaveResultTxData := &types.SuaveTransaction{ConfidentialComputeRequest: confidentialRequest.ConfidentialComputeRecord, ConfidentialComputeResult: computeResult}
+// newAuditRecord returns the record of the confidential execution of the
+// request on the state of the block.
+func newAuditRecord(tx *types.Transaction, request *types.ConfidentialComputeRequest, header *types.Header) *suave.AuditRecord {
+	encoded, _ := tx.MarshalBinary()
+	return &suave.AuditRecord{
+		RequestHash:        tx.Hash(),
+		Kettle:             request.KettleAddress,
+		Time:               uint64(time.Now().Unix()),
+		BlockNumber:        header.Number.Uint64(),
+		BlockHash:          header.Hash(),
+		Request:            encoded,
+		ConfidentialInputs: request.ConfidentialInputs,
+	}
+}
 
-	signed, err := wallet.SignTx(account, types.NewTx(suaveResultTxData), tx.ChainId())
-	if err != nil {
-		return nil, nil, nil, err
+func appendAuditRecord(auditLog *suave.AuditLog, record *suave.AuditRecord) {
+	if err := auditLog.Append(record); err != nil {
+		log.Error("Could not write the audit record", "request", record.RequestHash, "err", err)
 	}
+}
 
-	// will copy the inner tx again!
-	return signed, result, storeFinalize, nil
+// recordConfidentialResult keeps the logs emitted during the confidential
+// execution of the request for its signer. They are not part of the
+// SuaveTransaction and never make it on chain.
+func recordConfidentialResult(b Backend, state *state.StateDB, header *types.Header, request *types.Transaction, sender common.Address, suaveTx *types.Transaction) {
+	results := b.SuaveConfidentialResults()
+	if results == nil {
+		return
+	}
+
+	logs := state.GetLogs(request.Hash(), header.Number.Uint64(), header.Hash())
+	if logs == nil {
+		logs = []*types.Log{}
+	}
+	results.Add(&suave.ConfidentialResult{
+		RequestHash: request.Hash(),
+		TxHash:      suaveTx.Hash(),
+		Sender:      sender,
+		Logs:        logs,
+	})
+}
+
+// confidentialComputeRequest returns the compute request of the transaction
+// with its confidential inputs in plaintext. Encrypted inputs are opened with
+// the encryption key of the kettle.
+func confidentialComputeRequest(b Backend, tx *types.Transaction) (*types.ConfidentialComputeRequest, error) {
+	if request, ok := types.CastTxInner[*types.ConfidentialComputeRequest](tx); ok {
+		return request, nil
+	}
+
+	encryptedRequest, ok := types.CastTxInner[*types.EncryptedConfidentialComputeRequest](tx)
+	if !ok {
+		return nil, errors.New("invalid transaction passed")
+	}
+
+	encryptionKey := b.SuaveEncryptionKey()
+	if encryptionKey == nil {
+		return nil, errors.New("kettle does not support encrypted confidential inputs")
+	}
+	return encryptedRequest.Decrypt(encryptionKey)
 }
 
 // Sign calculates an ECDSA signature for:
@@ -2366,6 +2476,76 @@ func toHexSlice(b [][]byte) []string {
 }
 
 // KettleAddress returns the execution addresseses available in the Kettle.
+// Active keys come first, followed by the keys retired by a rotation that
+// are still accepted until the end of their grace window.
 func (s *TransactionAPI) KettleAddress(ctx context.Context) ([]common.Address, error) {
-	return s.b.AccountManager().Accounts(), nil
+	return kettleAddresses(s.b), nil
+}
+
+// KettleKeys returns the signing keys of the Kettle with their rotation
+// status, including the handovers of retired keys to their successors.
+func (s *TransactionAPI) KettleKeys(ctx context.Context) []*suave.KettleKey {
+	return s.b.KettleKeys()
+}
+
+// kettleAddresses returns the keys requests can be sent to, active keys first.
+func kettleAddresses(b Backend) []common.Address {
+	addrs := []common.Address{}
+	for _, key := range b.KettleKeys() {
+		if key.Usable() {
+			addrs = append(addrs, key.Address)
+		}
+	}
+	return addrs
+}
+
+// checkKettleKey returns an error if addr is a kettle key retired by a
+// rotation whose grace window ended.
+func checkKettleKey(b Backend, addr common.Address) error {
+	for _, key := range b.KettleKeys() {
+		if key.Address == addr && !key.Usable() {
+			return fmt.Errorf("%w: %s was handed over to %s", suave.ErrKettleKeyRetired, addr, key.Handover.NewAddress)
+		}
+	}
+	return nil
+}
+
+// KettleEncryptionKey is the public key that confidential inputs for a kettle
+// address are encrypted to. The signature of the kettle address over the
+// keccak256 hash of the key lets clients authenticate it.
+type KettleEncryptionKey struct {
+	KettleAddress common.Address `json:"kettleAddress"`
+	PublicKey     hexutil.Bytes  `json:"publicKey"`
+	Signature     hexutil.Bytes  `json:"signature"`
+}
+
+// KettleEncryptionKeys returns the encryption keys of the execution addresses
+// available in the Kettle.
+func (s *TransactionAPI) KettleEncryptionKeys(ctx context.Context) ([]*KettleEncryptionKey, error) {
+	encryptionKey := s.b.SuaveEncryptionKey()
+	if encryptionKey == nil {
+		return nil, errors.New("kettle does not support encrypted confidential inputs")
+	}
+	publicKey := crypto.FromECDSAPub(&encryptionKey.PublicKey)
+
+	res := []*KettleEncryptionKey{}
+	for _, addr := range kettleAddresses(s.b) {
+		account := accounts.Account{Address: addr}
+		wallet, err := s.b.AccountManager().Find(account)
+		if err != nil {
+			return nil, err
+		}
+
+		signature, err := wallet.SignData(account, accounts.MimetypeTextPlain, publicKey)
+		if err != nil {
+			return nil, fmt.Errorf("could not sign encryption key with %s: %w", addr, err)
+		}
+
+		res = append(res, &KettleEncryptionKey{
+			KettleAddress: addr,
+			PublicKey:     publicKey,
+			Signature:     signature,
+		})
+	}
+	return res, nil
 }
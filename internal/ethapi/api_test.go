@@ -301,6 +301,18 @@ func (b testBackend) GetEVM(ctx context.Context, msg *core.Message, state *state
 func (b testBackend) SuaveContext(requestTx *types.Transaction, ccr *types.ConfidentialComputeRequest) vm.SuaveContext {
 	return vm.SuaveContext{}
 }
+func (b testBackend) SuaveEncryptionKey() *ecdsa.PrivateKey {
+	return nil
+}
+func (b testBackend) KettleKeys() []*suave.KettleKey {
+	return nil
+}
+func (b testBackend) SuaveConfidentialResults() *suave.ConfidentialResults {
+	return nil
+}
+func (b testBackend) SuaveAuditLog() *suave.AuditLog {
+	return nil
+}
 func (b testBackend) GetMEVM(ctx context.Context, msg *core.Message, state *state.StateDB, header *types.Header, vmConfig *vm.Config, blockCtx *vm.BlockContext, suaveCtx *vm.SuaveContext) (*vm.EVM, func() error, func() error) {
 	return nil, nil, nil
 }
@@ -353,11 +365,11 @@ func (b testBackend) ServiceFilter(ctx context.Context, session *bloombits.Match
 	panic("implement me")
 }
 
-func (b testBackend) BuildBlockFromTxs(ctx context.Context, buildArgs *suave.BuildBlockArgs, txs types.Transactions) (*types.Block, *big.Int, error) {
+func (b testBackend) BuildBlockFromTxs(ctx context.Context, buildArgs *suave.BuildBlockArgsV2, txs types.Transactions) (*types.Block, *big.Int, error) {
 	panic("implement me")
 }
 
-func (b testBackend) BuildBlockFromBundles(ctx context.Context, buildArgs *suave.BuildBlockArgs, bundles []types.SBundle) (*types.Block, *big.Int, error) {
+func (b testBackend) BuildBlockFromBundles(ctx context.Context, buildArgs *suave.BuildBlockArgsV2, bundles []types.SBundle) (*types.Block, *big.Int, error) {
 	panic("implement me")
 }
 
@@ -19,6 +19,7 @@ package ethapi
 
 import (
 	"context"
+	"crypto/ecdsa"
 	"math/big"
 	"time"
 
@@ -35,6 +36,7 @@ import (
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rpc"
+	suave "github.com/ethereum/go-ethereum/suave/core"
 )
 
 // Backend interface provides the common API services (that are provided by
@@ -88,6 +90,16 @@ type Backend interface {
 	ChainConfig() *params.ChainConfig
 	Engine() consensus.Engine
 	SuaveContext(requestTx *types.Transaction, ccr *types.ConfidentialComputeRequest) vm.SuaveContext
+	SuaveEncryptionKey() *ecdsa.PrivateKey
+	// KettleKeys returns the signing keys of the kettle, active keys first.
+	KettleKeys() []*suave.KettleKey
+	// SuaveConfidentialResults returns where the results of confidential
+	// execution only delivered to the request signers are kept, nil if the
+	// backend does not keep them.
+	SuaveConfidentialResults() *suave.ConfidentialResults
+	// SuaveAuditLog returns the log the confidential executions are recorded
+	// in, nil if they are not recorded.
+	SuaveAuditLog() *suave.AuditLog
 
 	// This is copied from filters.Backend
 	// eth/filters needs to be initialized from this backend type, so methods needed by
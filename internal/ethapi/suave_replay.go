@@ -0,0 +1,100 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	suave "github.com/ethereum/go-ethereum/suave/core"
+)
+
+// ReplayConfidentialRequest executes the request of the audit record again on
+// the state of the block it was executed on. The precompile calls are
+// answered with the recorded ones instead of reaching the confidential store,
+// the relay or the execution backend. It returns the replayed
+// ConfidentialComputeResult, an error if it does not match the one of the
+// recorded SuaveTransaction. The precompiles, which may be nil, are the ones
+// the recording node registered in addition to the generated ones.
+func ReplayConfidentialRequest(chain *core.BlockChain, record *suave.AuditRecord, precompiles *vm.SuavePrecompileRegistry) ([]byte, error) {
+	if len(record.SuaveTransaction) == 0 {
+		return nil, fmt.Errorf("request %s produced no SuaveTransaction: %s", record.RequestHash, record.Error)
+	}
+
+	requestTx := new(types.Transaction)
+	if err := requestTx.UnmarshalBinary(record.Request); err != nil {
+		return nil, fmt.Errorf("invalid recorded request: %w", err)
+	}
+	suaveTx := new(types.Transaction)
+	if err := suaveTx.UnmarshalBinary(record.SuaveTransaction); err != nil {
+		return nil, fmt.Errorf("invalid recorded SuaveTransaction: %w", err)
+	}
+	suaveTxData, ok := types.CastTxInner[*types.SuaveTransaction](suaveTx)
+	if !ok {
+		return nil, errors.New("recorded SuaveTransaction is not a SuaveTransaction")
+	}
+
+	header := chain.GetHeaderByHash(record.BlockHash)
+	if header == nil {
+		return nil, fmt.Errorf("block %d (%s) not found", record.BlockNumber, record.BlockHash)
+	}
+	statedb, err := chain.StateAt(header.Root)
+	if err != nil {
+		return nil, fmt.Errorf("state of block %d not available: %w", header.Number, err)
+	}
+
+	msg, err := core.TransactionToMessage(requestTx, types.LatestSigner(chain.Config()), header.BaseFee)
+	if err != nil {
+		return nil, err
+	}
+	msg.SkipAccountChecks = true
+
+	replay := suave.NewAuditReplay(record)
+	suaveCtx := vm.SuaveContext{
+		Backend:                      &vm.SuaveExecutionBackend{Precompiles: precompiles},
+		ConfidentialComputeRequestTx: requestTx,
+		ConfidentialInputs:           record.ConfidentialInputs,
+		CallerStack:                  []*common.Address{},
+		BlockNumber:                  header.Number.Uint64(),
+		AuditReplay:                  replay,
+	}
+	evm := vm.NewConfidentialEVM(suaveCtx, core.NewEVMBlockContext(header, chain, nil), core.NewEVMTxContext(msg), statedb, chain.Config(), vm.Config{IsConfidential: true})
+
+	statedb.SetTxContext(requestTx.Hash(), 0)
+	result, err := core.ApplyMessage(evm, msg, new(core.GasPool).AddGas(header.GasLimit))
+	if err != nil {
+		return nil, err
+	}
+	if err := replay.Done(); err != nil {
+		return nil, err
+	}
+	if result.Failed() {
+		return nil, fmt.Errorf("replay failed: %w: %s", result.Err, hexutil.Encode(result.Revert()))
+	}
+
+	computeResult := confidentialComputeResult(result.ReturnData)
+	if !bytes.Equal(computeResult, suaveTxData.ConfidentialComputeResult) {
+		return computeResult, fmt.Errorf("ConfidentialComputeResult mismatch: recorded %x, replayed %x", suaveTxData.ConfidentialComputeResult, computeResult)
+	}
+	return computeResult, nil
+}
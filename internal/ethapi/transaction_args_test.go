@@ -18,6 +18,7 @@ package ethapi
 
 import (
 	"context"
+	"crypto/ecdsa"
 	"errors"
 	"math/big"
 	"reflect"
@@ -312,6 +313,18 @@ func (b *backendMock) GetEVM(ctx context.Context, msg *core.Message, state *stat
 func (b *backendMock) SuaveContext(requestTx *types.Transaction, ccr *types.ConfidentialComputeRequest) vm.SuaveContext {
 	return vm.SuaveContext{}
 }
+func (b *backendMock) SuaveEncryptionKey() *ecdsa.PrivateKey {
+	return nil
+}
+func (b *backendMock) KettleKeys() []*suave.KettleKey {
+	return nil
+}
+func (b *backendMock) SuaveConfidentialResults() *suave.ConfidentialResults {
+	return nil
+}
+func (b *backendMock) SuaveAuditLog() *suave.AuditLog {
+	return nil
+}
 func (b *backendMock) GetMEVM(ctx context.Context, msg *core.Message, state *state.StateDB, header *types.Header, vmConfig *vm.Config, blockCtx *vm.BlockContext, suaveCtx *vm.SuaveContext) (*vm.EVM, func() error, func() error) {
 	return nil, nil, nil
 }
@@ -351,10 +364,10 @@ func (b *backendMock) SubscribeRemovedLogsEvent(ch chan<- core.RemovedLogsEvent)
 
 func (b *backendMock) Engine() consensus.Engine { return nil }
 
-func (b *backendMock) BuildBlockFromTxs(ctx context.Context, buildArgs *suave.BuildBlockArgs, txs types.Transactions) (*types.Block, *big.Int, error) {
+func (b *backendMock) BuildBlockFromTxs(ctx context.Context, buildArgs *suave.BuildBlockArgsV2, txs types.Transactions) (*types.Block, *big.Int, error) {
 	return nil, nil, errors.New("not implemented")
 }
 
-func (b *backendMock) BuildBlockFromBundles(ctx context.Context, buildArgs *suave.BuildBlockArgs, bundles []types.SBundle) (*types.Block, *big.Int, error) {
+func (b *backendMock) BuildBlockFromBundles(ctx context.Context, buildArgs *suave.BuildBlockArgsV2, bundles []types.SBundle) (*types.Block, *big.Int, error) {
 	return nil, nil, errors.New("not implemented")
 }
@@ -18,6 +18,7 @@ package les
 
 import (
 	"context"
+	"crypto/ecdsa"
 	"errors"
 	"math/big"
 	"time"
@@ -201,6 +202,22 @@ func (b *LesApiBackend) SuaveContext(requestTx *types.Transaction, ccr *types.Co
 	return vm.SuaveContext{}
 }
 
+func (b *LesApiBackend) SuaveEncryptionKey() *ecdsa.PrivateKey {
+	return nil
+}
+
+func (b *LesApiBackend) KettleKeys() []*suave.KettleKey {
+	return nil
+}
+
+func (b *LesApiBackend) SuaveConfidentialResults() *suave.ConfidentialResults {
+	return nil
+}
+
+func (b *LesApiBackend) SuaveAuditLog() *suave.AuditLog {
+	return nil
+}
+
 func (b *LesApiBackend) GetMEVM(ctx context.Context, msg *core.Message, state *state.StateDB, header *types.Header, vmConfig *vm.Config, blockCtx *vm.BlockContext, suaveCtx *vm.SuaveContext) (*vm.EVM, func() error, func() error) {
 	return nil, nil, nil
 }
@@ -346,10 +363,10 @@ func (b *LesApiBackend) StateAtTransaction(ctx context.Context, block *types.Blo
 	return b.eth.stateAtTransaction(ctx, block, txIndex, reexec)
 }
 
-func (b *LesApiBackend) BuildBlockFromTxs(ctx context.Context, buildArgs *suave.BuildBlockArgs, txs types.Transactions) (*types.Block, *big.Int, error) {
+func (b *LesApiBackend) BuildBlockFromTxs(ctx context.Context, buildArgs *suave.BuildBlockArgsV2, txs types.Transactions) (*types.Block, *big.Int, error) {
 	return nil, nil, errors.New("not implemented")
 }
 
-func (b *LesApiBackend) BuildBlockFromBundles(context.Context, *types.BuildBlockArgs, []types.SBundle) (*types.Block, *big.Int, error) {
+func (b *LesApiBackend) BuildBlockFromBundles(context.Context, *types.BuildBlockArgsV2, []types.SBundle) (*types.Block, *big.Int, error) {
 	return nil, nil, errors.New("not implemented")
 }
@@ -0,0 +1,244 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"runtime"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	// bundleSimulationCacheSize is the number of bundle simulation results
+	// kept.
+	bundleSimulationCacheSize = 1024
+	// bundleStateCacheSize is the number of states left by simulated bundles
+	// kept, each holding a copy of the parent state with the bundle applied.
+	bundleStateCacheSize = 16
+)
+
+// BundleSimulation is the outcome of a bundle executed on top of the parent of
+// the block it is meant for.
+type BundleSimulation struct {
+	Hash    common.Hash // Hash of the bundle, see SBundle.Hash
+	GasUsed uint64      // Gas used by the included transactions
+	Profit  *big.Int    // Balance change of the coinbase
+	Err     error       // Why the bundle cannot be included, nil if it can
+}
+
+type bundleSimulationKey struct {
+	parent common.Hash
+	bundle common.Hash
+}
+
+// simulatedBundle is a cached simulation and the block context it ran in.
+// Failures depending on the timestamp bounds of the bundle, which are not
+// part of its hash, are never cached.
+type simulatedBundle struct {
+	*BundleSimulation
+
+	header *types.Header // Block the bundle was simulated in
+}
+
+// simulatedBundleState is what a successful simulation changed, so that a
+// block starting with the bundle does not execute it again.
+type simulatedBundleState struct {
+	header    *types.Header // Block the bundle was simulated in
+	state     *state.StateDB
+	txs       []*types.Transaction
+	receipts  []*types.Receipt
+	gasUsed   uint64
+	gasLeft   uint64
+	committed int
+}
+
+// validFor reports whether the simulation executed the bundle in the same
+// block context as the header, its results then being the ones the block
+// would get.
+func (s *simulatedBundle) validFor(header *types.Header) bool {
+	return sameBlockContext(s.header, header)
+}
+
+func (s *simulatedBundleState) validFor(header *types.Header) bool {
+	return sameBlockContext(s.header, header)
+}
+
+func sameBlockContext(a, b *types.Header) bool {
+	return a.ParentHash == b.ParentHash &&
+		a.Coinbase == b.Coinbase &&
+		a.Time == b.Time &&
+		a.GasLimit == b.GasLimit &&
+		a.MixDigest == b.MixDigest
+}
+
+// prepareBundleWork returns the environment of a block built from bundles,
+// the coinbase depending on the proposer payment mode.
+func (w *worker) prepareBundleWork(args *types.BuildBlockArgsV2) (*environment, *generateParams, error) {
+	coinbase := args.FeeRecipient
+	switch args.PaymentMode {
+	case types.ProposerPaymentTx:
+		coinbase = crypto.PubkeyToAddress(w.builderKey.PublicKey)
+	case types.ProposerPaymentCoinbase:
+	default:
+		return nil, nil, fmt.Errorf("unknown proposer payment mode %d", args.PaymentMode)
+	}
+
+	params := &generateParams{
+		timestamp:   args.Timestamp,
+		forceTime:   true,
+		parentHash:  args.Parent,
+		coinbase:    coinbase,
+		gasLimit:    args.GasLimit,
+		random:      args.Random,
+		extra:       args.Extra,
+		withdrawals: args.Withdrawals,
+		noUncle:     true,
+		noTxs:       false,
+	}
+
+	work, err := w.prepareWork(params)
+	if err != nil {
+		return nil, nil, err
+	}
+	if work.header.BaseFee == nil {
+		work.discard()
+		return nil, nil, errors.New("blocks can only be built from bundles after london")
+	}
+	if work.gasPool == nil {
+		work.gasPool = new(core.GasPool).AddGas(work.header.GasLimit)
+	}
+	return work, params, nil
+}
+
+// simulateBundles executes each bundle on its own on top of the parent of the
+// block described by args, in parallel. Bundles already simulated in the same
+// block context are not executed again.
+func (w *worker) simulateBundles(ctx context.Context, args *types.BuildBlockArgsV2, bundles []types.SBundle) ([]*BundleSimulation, error) {
+	work, _, err := w.prepareBundleWork(args)
+	if err != nil {
+		return nil, err
+	}
+	defer work.discard()
+
+	var (
+		results = make([]*BundleSimulation, len(bundles))
+		sem     = make(chan struct{}, runtime.NumCPU())
+		wg      sync.WaitGroup
+	)
+	for i := range bundles {
+		bundle := &bundles[i]
+		key := bundleSimulationKey{parent: work.header.ParentHash, bundle: bundle.Hash()}
+		if err := bundle.CheckTimestamp(work.header.Time); err != nil {
+			results[i] = &BundleSimulation{Hash: key.bundle, Err: err}
+			continue
+		}
+		if sim, ok := w.bundleSims.Get(key); ok && sim.validFor(work.header) {
+			results[i] = sim.BundleSimulation
+			continue
+		}
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return nil, ctx.Err()
+		}
+		// Every bundle runs on its own copy of the parent state
+		env := work.copy()
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			sim, post := w.simulateBundle(env, bundle, key.bundle)
+			w.bundleSims.Add(key, sim)
+			if post != nil {
+				w.bundleStates.Add(key, post)
+			}
+			results[i] = sim.BundleSimulation
+		}(i)
+	}
+	wg.Wait()
+	return results, nil
+}
+
+// simulateBundle commits the bundle to the environment, which is kept by the
+// returned state if the bundle can be included.
+func (w *worker) simulateBundle(env *environment, bundle *types.SBundle, hash common.Hash) (*simulatedBundle, *simulatedBundleState) {
+	sim := &simulatedBundle{
+		BundleSimulation: &BundleSimulation{Hash: hash},
+		header:           types.CopyHeader(env.header),
+	}
+
+	profitPre := env.state.GetBalance(env.coinbase)
+	committed, err := w.commitBundle(env, bundle)
+	if err != nil {
+		log.Debug("Bundle simulation failed", "hash", hash, "err", err)
+		sim.Err = err
+		return sim, nil
+	}
+
+	sim.GasUsed = env.header.GasUsed
+	sim.Profit = new(big.Int).Sub(env.state.GetBalance(env.coinbase), profitPre)
+	return sim, &simulatedBundleState{
+		header:    sim.header,
+		state:     env.state,
+		txs:       env.txs,
+		receipts:  env.receipts,
+		gasUsed:   env.header.GasUsed,
+		gasLeft:   env.gasPool.Gas(),
+		committed: committed,
+	}
+}
+
+// commitSimulatedBundle commits the bundle to the environment like
+// commitBundle. A bundle starting the block is taken from its simulation
+// instead, when it was simulated in the same block context. The timestamp
+// bounds of the bundle are checked by the caller.
+func (w *worker) commitSimulatedBundle(env *environment, bundle *types.SBundle) (int, error) {
+	if len(env.txs) != 0 {
+		return w.commitBundle(env, bundle)
+	}
+	key := bundleSimulationKey{parent: env.header.ParentHash, bundle: bundle.Hash()}
+	if sim, ok := w.bundleSims.Get(key); ok && sim.validFor(env.header) && sim.Err != nil {
+		return 0, sim.Err
+	}
+	post, ok := w.bundleStates.Get(key)
+	if !ok || !post.validFor(env.header) {
+		return w.commitBundle(env, bundle)
+	}
+
+	env.state.StopPrefetcher()
+	env.state = post.state.Copy()
+	env.txs = append(env.txs, post.txs...)
+	env.receipts = append(env.receipts, copyReceipts(post.receipts)...)
+	env.tcount = len(env.txs)
+	env.header.GasUsed = post.gasUsed
+	env.gasPool = new(core.GasPool).AddGas(post.gasLeft)
+	return post.committed, nil
+}
@@ -19,6 +19,7 @@ package miner
 
 import (
 	"context"
+	"crypto/ecdsa"
 	"fmt"
 	"math/big"
 	"sync"
@@ -54,6 +55,8 @@ type Config struct {
 	Recommit  time.Duration  // The time interval for miner to re-create mining work.
 
 	NewPayloadTimeout time.Duration // The maximum time allowance for creating a new payload
+
+	BuilderSigningKey *ecdsa.PrivateKey `toml:"-"` // Key paying the proposer of blocks built from bundles, random if not set
 }
 
 // DefaultConfig contains default settings for miner.
@@ -254,10 +257,16 @@ func (miner *Miner) BuildPayload(args *BuildPayloadArgs) (*Payload, error) {
 	return miner.worker.buildPayload(args)
 }
 
-func (miner *Miner) BuildBlockFromTxs(ctx context.Context, buildArgs *types.BuildBlockArgs, txs types.Transactions) (*types.Block, *big.Int, error) {
+func (miner *Miner) BuildBlockFromTxs(ctx context.Context, buildArgs *types.BuildBlockArgsV2, txs types.Transactions) (*types.Block, *big.Int, error) {
 	return miner.worker.buildBlockFromTxs(ctx, buildArgs, txs)
 }
 
-func (miner *Miner) BuildBlockFromBundles(ctx context.Context, buildArgs *types.BuildBlockArgs, bundles []types.SBundle) (*types.Block, *big.Int, error) {
+func (miner *Miner) BuildBlockFromBundles(ctx context.Context, buildArgs *types.BuildBlockArgsV2, bundles []types.SBundle) (*types.Block, *big.Int, error) {
 	return miner.worker.buildBlockFromBundles(ctx, buildArgs, bundles)
 }
+
+// SimulateBundles executes the bundles on top of the parent of the block, in
+// parallel. The simulations are reused by blocks built from bundles.
+func (miner *Miner) SimulateBundles(ctx context.Context, buildArgs *types.BuildBlockArgsV2, bundles []types.SBundle) ([]*BundleSimulation, error) {
+	return miner.worker.simulateBundles(ctx, buildArgs, bundles)
+}
@@ -18,6 +18,7 @@ package miner
 
 import (
 	"context"
+	"crypto/ecdsa"
 	"errors"
 	"fmt"
 	"math/big"
@@ -27,16 +28,19 @@ import (
 
 	mapset "github.com/deckarep/golang-set/v2"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/lru"
 	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/consensus/misc"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/trie"
+	"golang.org/x/exp/slices"
 )
 
 const (
@@ -83,6 +87,12 @@ var (
 	errBlockInterruptedByNewHead  = errors.New("new head arrived while building block")
 	errBlockInterruptedByRecommit = errors.New("recommit interrupt while building block")
 	errBlockInterruptedByTimeout  = errors.New("timeout while building block")
+
+	// errBundleBlobTx is returned for bundles with blob transactions. Bundles
+	// carry blob transactions with their sidecars, but building blocks with
+	// them needs the Cancun blob gas fields of the header and a blobs bundle
+	// in the submitted payload, neither of which is supported yet.
+	errBundleBlobTx = errors.New("blob transactions are not supported in bundles")
 )
 
 // environment is the worker's current environment and holds all
@@ -262,6 +272,11 @@ type worker struct {
 	// payload in proof-of-stake stage.
 	recommit time.Duration
 
+	// Blocks built from bundles
+	builderKey   *ecdsa.PrivateKey                                      // Key collecting the fees and paying the proposer
+	bundleSims   *lru.Cache[bundleSimulationKey, *simulatedBundle]      // Bundles simulated on top of their parent
+	bundleStates *lru.Cache[bundleSimulationKey, *simulatedBundleState] // States left by the successful simulations
+
 	// External functions
 	isLocalBlock func(header *types.Header) bool // Function used to determine whether the specified block is mined by local miner.
 
@@ -298,6 +313,13 @@ func newWorker(config *Config, chainConfig *params.ChainConfig, engine consensus
 		exitCh:             make(chan struct{}),
 		resubmitIntervalCh: make(chan time.Duration),
 		resubmitAdjustCh:   make(chan *intervalAdjust, resubmitAdjustChanSize),
+		builderKey:         config.BuilderSigningKey,
+		bundleSims:         lru.NewCache[bundleSimulationKey, *simulatedBundle](bundleSimulationCacheSize),
+		bundleStates:       lru.NewCache[bundleSimulationKey, *simulatedBundleState](bundleStateCacheSize),
+	}
+	if worker.builderKey == nil {
+		// Without a configured builder the fees are collected by a random key
+		worker.builderKey, _ = crypto.GenerateKey()
 	}
 	// Subscribe NewTxsEvent for tx pool
 	worker.txsSub = eth.TxPool().SubscribeNewTxsEvent(worker.txsCh)
@@ -1247,7 +1269,7 @@ func (w *worker) getSealingBlock(parent common.Hash, timestamp uint64, coinbase
 	}
 }
 
-func (w *worker) buildBlockFromTxs(ctx context.Context, args *types.BuildBlockArgs, txs types.Transactions) (*types.Block, *big.Int, error) {
+func (w *worker) buildBlockFromTxs(ctx context.Context, args *types.BuildBlockArgsV2, txs types.Transactions) (*types.Block, *big.Int, error) {
 	params := &generateParams{
 		timestamp:   args.Timestamp,
 		forceTime:   true,
@@ -1282,50 +1304,40 @@ func (w *worker) buildBlockFromTxs(ctx context.Context, args *types.BuildBlockAr
 	return block, blockProfit, nil
 }
 
-func (w *worker) buildBlockFromBundles(ctx context.Context, args *types.BuildBlockArgs, bundles []types.SBundle) (*types.Block, *big.Int, error) {
-	// create ephemeral addr and private key for payment txn
-	ephemeralPrivKey, err := crypto.GenerateKey()
-	if err != nil {
-		return nil, nil, err
-	}
-	ephemeralAddr := crypto.PubkeyToAddress(ephemeralPrivKey.PublicKey)
-
-	params := &generateParams{
-		timestamp:   args.Timestamp,
-		forceTime:   true,
-		parentHash:  args.Parent,
-		coinbase:    ephemeralAddr, // NOTE : overriding BuildBlockArgs.FeeRecipient
-		gasLimit:    args.GasLimit,
-		random:      args.Random,
-		extra:       args.Extra,
-		withdrawals: args.Withdrawals,
-		noUncle:     true,
-		noTxs:       false,
-	}
-
-	work, err := w.prepareWork(params)
+func (w *worker) buildBlockFromBundles(ctx context.Context, args *types.BuildBlockArgsV2, bundles []types.SBundle) (*types.Block, *big.Int, error) {
+	work, params, err := w.prepareBundleWork(args)
 	if err != nil {
 		return nil, nil, err
 	}
 	defer work.discard()
 
-	// Assume static 28000 gas transfers for both mev-share and proposer payments
-	refundTransferCost := new(big.Int).Mul(big.NewInt(28000), work.header.BaseFee)
+	coinbase := work.coinbase
 
-	profitPre := work.state.GetBalance(params.coinbase)
+	valuePre := work.state.GetBalance(args.FeeRecipient)
+	profitPre := work.state.GetBalance(coinbase)
 
 	for _, bundle := range bundles {
 		// NOTE: failing bundles will cause the block to not be built!
 
+		if err := bundle.CheckTimestamp(work.header.Time); err != nil {
+			log.Debug("Skipping bundle", "reason", err)
+			continue
+		}
+
 		// apply bundle
-		profitPreBundle := work.state.GetBalance(params.coinbase)
-		if err := w.rawCommitTransactions(work, bundle.Txs); err != nil {
+		profitPreBundle := work.state.GetBalance(coinbase)
+		committed, err := w.commitSimulatedBundle(work, &bundle)
+		if err != nil {
 			return nil, nil, err
 		}
-		profitPostBundle := work.state.GetBalance(params.coinbase)
+		profitPostBundle := work.state.GetBalance(coinbase)
 
 		// calc & refund user if bundle has multiple txns and wants refund
-		if len(bundle.Txs) > 1 && bundle.RefundPercent != nil {
+		if committed > 1 && bundle.RefundPercent != nil {
+			if args.PaymentMode != types.ProposerPaymentTx {
+				return nil, nil, errors.New("bundle refunds require the proposer payment transaction mode")
+			}
+
 			// Note: PoC logic, this could be gamed by not sending any eth to coinbase
 			refundPrct := *bundle.RefundPercent
 			if refundPrct == 0 {
@@ -1334,64 +1346,174 @@ func (w *worker) buildBlockFromBundles(ctx context.Context, args *types.BuildBlo
 			}
 			bundleProfit := new(big.Int).Sub(profitPostBundle, profitPreBundle)
 			refundAmt := new(big.Int).Div(bundleProfit, big.NewInt(int64(refundPrct)))
-			// subtract payment txn transfer costs
-			refundAmt = new(big.Int).Sub(refundAmt, refundTransferCost)
 
-			currNonce := work.state.GetNonce(ephemeralAddr)
-			// HACK to include payment txn
 			// multi refund block untested
 			bidTx := bundle.Txs[0] // NOTE : assumes first txn is refund recipient
 			refundAddr, err := types.Sender(types.LatestSignerForChainID(bidTx.ChainId()), bidTx)
 			if err != nil {
 				return nil, nil, err
 			}
-			paymentTx, err := types.SignTx(types.NewTx(&types.LegacyTx{
-				Nonce:    currNonce,
-				To:       &refundAddr,
-				Value:    refundAmt,
-				Gas:      28000,
-				GasPrice: work.header.BaseFee,
-			}), work.signer, ephemeralPrivKey)
-
-			if err != nil {
-				return nil, nil, err
+			if err := w.commitBuilderPayment(work, refundAddr, refundAmt); err != nil {
+				return nil, nil, fmt.Errorf("could not refund bundle: %w", err)
 			}
+		}
+	}
 
-			// commit payment txn
-			if err := w.rawCommitTransactions(work, types.Transactions{paymentTx}); err != nil {
-				return nil, nil, err
-			}
+	if args.PaymentMode == types.ProposerPaymentTx {
+		proposerProfit := new(big.Int).Sub(work.state.GetBalance(coinbase), profitPre)
+		if err := w.commitBuilderPayment(work, args.FeeRecipient, proposerProfit); err != nil {
+			return nil, nil, fmt.Errorf("could not pay proposer: %w", err)
 		}
 	}
 
-	profitPost := work.state.GetBalance(params.coinbase)
-	proposerProfit := new(big.Int).Set(profitPost) // = post-pre-transfer_cost
-	proposerProfit = proposerProfit.Sub(profitPost, profitPre)
-	proposerProfit = proposerProfit.Sub(proposerProfit, refundTransferCost)
+	// The value of the block is what the fee recipient actually received
+	blockValue := new(big.Int).Sub(work.state.GetBalance(args.FeeRecipient), valuePre)
 
-	currNonce := work.state.GetNonce(ephemeralAddr)
-	paymentTx, err := types.SignTx(types.NewTx(&types.LegacyTx{
-		Nonce:    currNonce,
-		To:       &args.FeeRecipient,
-		Value:    proposerProfit,
-		Gas:      28000,
-		GasPrice: work.header.BaseFee,
-	}), work.signer, ephemeralPrivKey)
+	log.Info("buildBlockFromBundles", "num_bundles", len(bundles), "num_txns", len(work.txs), "value", blockValue, "payment_mode", args.PaymentMode)
+	block, err := w.engine.FinalizeAndAssemble(w.chain, work.header, work.state, work.txs, work.unclelist(), work.receipts, params.withdrawals)
 	if err != nil {
-		return nil, nil, fmt.Errorf("could not sign proposer payment: %w", err)
+		return nil, nil, err
 	}
+	return block, blockValue, nil
+}
 
-	// commit payment txn
-	if err := w.rawCommitTransactions(work, types.Transactions{paymentTx}); err != nil {
-		return nil, nil, fmt.Errorf("could not sign proposer payment: %w", err)
+// commitBuilderPayment transfers the amount from the builder to the
+// recipient, the cost of the transfer being paid out of it. The payment is a
+// dynamic fee transaction paying exactly the base fee with the gas it needs,
+// so recipients running code on receipt are paid as well.
+func (w *worker) commitBuilderPayment(env *environment, to common.Address, amount *big.Int) error {
+	newPayment := func(gas uint64) (*types.Transaction, error) {
+		value := new(big.Int).Sub(amount, new(big.Int).Mul(new(big.Int).SetUint64(gas), env.header.BaseFee))
+		if value.Sign() < 0 {
+			return nil, fmt.Errorf("amount %v does not cover the payment gas %d", amount, gas)
+		}
+		return types.SignNewTx(w.builderKey, env.signer, &types.DynamicFeeTx{
+			ChainID:   w.chainConfig.ChainID,
+			Nonce:     env.state.GetNonce(crypto.PubkeyToAddress(w.builderKey.PublicKey)),
+			GasTipCap: new(big.Int),
+			GasFeeCap: env.header.BaseFee,
+			Gas:       gas,
+			To:        &to,
+			Value:     value,
+		})
 	}
 
-	log.Info("buildBlockFromBundles", "num_bundles", len(bundles), "num_txns", len(work.txs), "profit", proposerProfit)
-	block, err := w.engine.FinalizeAndAssemble(w.chain, work.header, work.state, work.txs, work.unclelist(), work.receipts, params.withdrawals)
+	gas, err := w.estimatePaymentGas(env, to, amount, newPayment)
 	if err != nil {
-		return nil, nil, err
+		return err
+	}
+	tx, err := newPayment(gas)
+	if err != nil {
+		return err
+	}
+	return w.rawCommitTransactions(env, types.Transactions{tx})
+}
+
+// estimatePaymentGas returns the lowest gas limit the payment succeeds with.
+// Transfers to accounts without code take params.TxGas, others are executed
+// against the block built so far.
+func (w *worker) estimatePaymentGas(env *environment, to common.Address, amount *big.Int, newPayment func(gas uint64) (*types.Transaction, error)) (uint64, error) {
+	if env.gasPool == nil {
+		env.gasPool = new(core.GasPool).AddGas(env.header.GasLimit)
+	}
+	if len(env.state.GetCode(to)) == 0 {
+		return params.TxGas, nil
+	}
+
+	// Returns whether the payment fails with the gas limit
+	failed := func(gas uint64) (bool, error) {
+		tx, err := newPayment(gas)
+		if err != nil {
+			return true, err
+		}
+		msg, err := core.TransactionToMessage(tx, env.signer, env.header.BaseFee)
+		if err != nil {
+			return true, err
+		}
+
+		snap := env.state.Snapshot()
+		defer env.state.RevertToSnapshot(snap)
+
+		evm := vm.NewEVM(core.NewEVMBlockContext(env.header, w.chain, &env.coinbase), core.NewEVMTxContext(msg), env.state, w.chainConfig, *w.chain.GetVMConfig())
+		result, err := core.ApplyMessage(evm, msg, new(core.GasPool).AddGas(env.gasPool.Gas()))
+		if err != nil {
+			return true, err
+		}
+		return result.Failed(), nil
+	}
+
+	// The gas is paid out of the amount, which bounds the gas limit
+	hi := env.gasPool.Gas()
+	if allowance := new(big.Int).Div(amount, env.header.BaseFee); allowance.IsUint64() && allowance.Uint64() < hi {
+		hi = allowance.Uint64()
+	}
+	if isFailed, err := failed(hi); err != nil {
+		return 0, fmt.Errorf("payment to %s fails: %w", to, err)
+	} else if isFailed {
+		return 0, fmt.Errorf("payment to %s reverts with the %d gas available", to, hi)
+	}
+	lo := params.TxGas - 1
+	for lo+1 < hi {
+		mid := (hi + lo) / 2
+		if isFailed, _ := failed(mid); isFailed {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return hi, nil
+}
+
+// commitBundle applies the transactions of the bundle and returns how many of
+// them were included. Transactions listed in DroppingTxHashes are left out if
+// they are invalid or revert without being allowed to, any other failure
+// fails the bundle.
+func (w *worker) commitBundle(env *environment, bundle *types.SBundle) (int, error) {
+	if env.gasPool == nil {
+		env.gasPool = new(core.GasPool).AddGas(env.header.GasLimit)
+	}
+
+	committed := 0
+	for _, tx := range bundle.Txs {
+		if tx.Type() == types.BlobTxType {
+			return 0, fmt.Errorf("%w: %s", errBundleBlobTx, tx.Hash())
+		}
+		if tx.Protected() && !w.chainConfig.IsEIP155(env.header.Number) {
+			return 0, fmt.Errorf("invalid reply protected tx %s", tx.Hash())
+		}
+
+		dropping := slices.Contains(bundle.DroppingTxHashes, tx.Hash())
+		var (
+			snap    = env.state.Snapshot()
+			gp      = env.gasPool.Gas()
+			gasUsed = env.header.GasUsed
+		)
+
+		env.state.SetTxContext(tx.Hash(), env.tcount)
+		_, err := w.commitTransaction(env, tx)
+		if err != nil {
+			if dropping {
+				log.Debug("Dropping invalid bundle transaction", "hash", tx.Hash(), "err", err)
+				continue
+			}
+			return 0, err
+		}
+
+		receipt := env.receipts[len(env.receipts)-1]
+		if dropping && receipt.Status == types.ReceiptStatusFailed && !slices.Contains(bundle.RevertingHashes, tx.Hash()) {
+			log.Debug("Dropping reverted bundle transaction", "hash", tx.Hash())
+			env.state.RevertToSnapshot(snap)
+			env.gasPool.SetGas(gp)
+			env.header.GasUsed = gasUsed
+			env.txs = env.txs[:len(env.txs)-1]
+			env.receipts = env.receipts[:len(env.receipts)-1]
+			continue
+		}
+
+		env.tcount++
+		committed++
 	}
-	return block, proposerProfit, nil
+	return committed, nil
 }
 
 func (w *worker) rawCommitTransactions(env *environment, txs types.Transactions) error {
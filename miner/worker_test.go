@@ -17,6 +17,7 @@
 package miner
 
 import (
+	"context"
 	"crypto/rand"
 	"errors"
 	"math/big"
@@ -29,6 +30,7 @@ import (
 	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/consensus/clique"
 	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/consensus/misc"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
@@ -39,6 +41,7 @@ import (
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
 )
 
 const (
@@ -660,3 +663,318 @@ func testGetSealingWork(t *testing.T, chainConfig *params.ChainConfig, engine co
 		}
 	}
 }
+
+func TestBuildBlockFromBundles(t *testing.T) {
+	engine := ethash.NewFaker()
+	defer engine.Close()
+
+	w, _ := newTestWorker(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), 0)
+	defer w.close()
+
+	parent := w.chain.CurrentBlock()
+	args := &types.BuildBlockArgsV2{
+		Parent:       parent.Hash(),
+		Timestamp:    parent.Time + 12,
+		FeeRecipient: testUserAddress,
+		GasLimit:     parent.GasLimit,
+	}
+
+	signer := types.LatestSigner(ethashChainConfig)
+	newTx := func(nonce uint64) *types.Transaction {
+		return types.MustSignNewTx(testBankKey, signer, &types.LegacyTx{
+			Nonce:    nonce,
+			To:       &testUserAddress,
+			Value:    big.NewInt(1000),
+			Gas:      params.TxGas,
+			GasPrice: big.NewInt(10 * params.InitialBaseFee),
+		})
+	}
+	tx0, tx1, invalidTx := newTx(0), newTx(1), newTx(5)
+
+	bundles := []types.SBundle{
+		// The invalid transaction can be dropped
+		{Txs: types.Transactions{tx0, invalidTx}, DroppingTxHashes: []common.Hash{invalidTx.Hash()}},
+		// Bundles outside of their timestamp bounds are skipped
+		{Txs: types.Transactions{tx1}, MinTimestamp: args.Timestamp + 1},
+		{Txs: types.Transactions{tx1}, MaxTimestamp: args.Timestamp - 1},
+	}
+	block, _, err := w.buildBlockFromBundles(context.Background(), args, bundles)
+	if err != nil {
+		t.Fatalf("failed to build block: %v", err)
+	}
+	// The bundle transaction and the proposer payment
+	if len(block.Transactions()) != 2 {
+		t.Fatalf("block transactions mismatch: have %d, want %d", len(block.Transactions()), 2)
+	}
+	if block.Transactions()[0].Hash() != tx0.Hash() {
+		t.Fatalf("bundle transaction mismatch: have %s, want %s", block.Transactions()[0].Hash(), tx0.Hash())
+	}
+
+	bundles = append(bundles, types.SBundle{Txs: types.Transactions{tx1}, MinTimestamp: args.Timestamp, MaxTimestamp: args.Timestamp})
+	block, _, err = w.buildBlockFromBundles(context.Background(), args, bundles)
+	if err != nil {
+		t.Fatalf("failed to build block: %v", err)
+	}
+	if len(block.Transactions()) != 3 || block.Transactions()[1].Hash() != tx1.Hash() {
+		t.Fatalf("bundle within its timestamp bounds not included")
+	}
+
+	// Invalid transactions that cannot be dropped fail the bundle
+	_, _, err = w.buildBlockFromBundles(context.Background(), args, []types.SBundle{{Txs: types.Transactions{tx0, invalidTx}}})
+	if err == nil {
+		t.Fatal("expected bundle with invalid transaction to fail")
+	}
+
+	// Blob transactions are rejected, even if they could be dropped
+	blobTx := types.MustSignNewTx(testBankKey, types.NewCancunSigner(ethashChainConfig.ChainID), &types.BlobTx{
+		ChainID:    uint256.MustFromBig(ethashChainConfig.ChainID),
+		Nonce:      1,
+		GasTipCap:  uint256.NewInt(params.InitialBaseFee),
+		GasFeeCap:  uint256.NewInt(10 * params.InitialBaseFee),
+		Gas:        params.TxGas,
+		To:         &testUserAddress,
+		Value:      uint256.NewInt(1000),
+		BlobFeeCap: uint256.NewInt(1),
+		BlobHashes: []common.Hash{{0x1}},
+	})
+	_, _, err = w.buildBlockFromBundles(context.Background(), args, []types.SBundle{{Txs: types.Transactions{tx0, blobTx}, DroppingTxHashes: []common.Hash{blobTx.Hash()}}})
+	if !errors.Is(err, errBundleBlobTx) {
+		t.Fatalf("blob transaction error mismatch: have %v, want %v", err, errBundleBlobTx)
+	}
+}
+
+func TestBuildBlockFromBundlesPaymentMode(t *testing.T) {
+	engine := ethash.NewFaker()
+	defer engine.Close()
+
+	builderKey, _ := crypto.GenerateKey()
+	builderAddress := crypto.PubkeyToAddress(builderKey.PublicKey)
+
+	config := *testConfig
+	config.BuilderSigningKey = builderKey
+	backend := newTestWorkerBackend(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), 0)
+	w := newWorker(&config, ethashChainConfig, engine, backend, new(event.TypeMux), nil, false)
+	defer w.close()
+
+	parent := w.chain.CurrentBlock()
+	signer := types.LatestSigner(ethashChainConfig)
+	newTx := func(nonce uint64, to *common.Address, data []byte) *types.Transaction {
+		return types.MustSignNewTx(testBankKey, signer, &types.LegacyTx{
+			Nonce:    nonce,
+			To:       to,
+			Value:    big.NewInt(1000),
+			Gas:      100000,
+			GasPrice: big.NewInt(10 * params.InitialBaseFee),
+			Data:     data,
+		})
+	}
+
+	// The fee recipient is the coinbase, the block holds no payment
+	args := &types.BuildBlockArgsV2{
+		Parent:       parent.Hash(),
+		Timestamp:    parent.Time + 12,
+		FeeRecipient: testUserAddress,
+		GasLimit:     parent.GasLimit,
+		PaymentMode:  types.ProposerPaymentCoinbase,
+	}
+	block, value, err := w.buildBlockFromBundles(context.Background(), args, []types.SBundle{{Txs: types.Transactions{newTx(0, &testUserAddress, nil)}}})
+	if err != nil {
+		t.Fatalf("failed to build block: %v", err)
+	}
+	if block.Coinbase() != testUserAddress || len(block.Transactions()) != 1 {
+		t.Fatalf("unexpected block: coinbase %s, %d transactions", block.Coinbase(), len(block.Transactions()))
+	}
+	// The transferred value and the priority fee
+	tip := new(big.Int).Sub(big.NewInt(10*params.InitialBaseFee), block.BaseFee())
+	want := new(big.Int).Add(big.NewInt(1000), new(big.Int).Mul(tip, new(big.Int).SetUint64(block.GasUsed())))
+	if value.Cmp(want) != 0 {
+		t.Fatalf("block value mismatch: have %v, want %v", value, want)
+	}
+
+	// Bundles cannot be refunded without a payment transaction
+	refund := 10
+	refundBundle := types.SBundle{Txs: types.Transactions{newTx(0, &testUserAddress, nil), newTx(1, &testUserAddress, nil)}, RefundPercent: &refund}
+	if _, _, err := w.buildBlockFromBundles(context.Background(), args, []types.SBundle{refundBundle}); err == nil {
+		t.Fatal("expected refund without payment transaction to fail")
+	}
+
+	// The fee recipient is a contract storing the value it receives, which
+	// takes more than a plain transfer
+	deployment := newTx(0, nil, common.FromHex("0x6434600055006000526005601bf3"))
+	feeRecipient := crypto.CreateAddress(testBankAddress, 0)
+	args.FeeRecipient = feeRecipient
+	args.PaymentMode = types.ProposerPaymentTx
+	block, value, err = w.buildBlockFromBundles(context.Background(), args, []types.SBundle{{Txs: types.Transactions{deployment, newTx(1, &testUserAddress, nil)}}})
+	if err != nil {
+		t.Fatalf("failed to build block: %v", err)
+	}
+	if block.Coinbase() != builderAddress || len(block.Transactions()) != 3 {
+		t.Fatalf("unexpected block: coinbase %s, %d transactions", block.Coinbase(), len(block.Transactions()))
+	}
+	payment := block.Transactions()[2]
+	if payment.Type() != types.DynamicFeeTxType || *payment.To() != feeRecipient || payment.Gas() <= params.TxGas {
+		t.Fatalf("unexpected payment: type %d, to %s, gas %d", payment.Type(), payment.To(), payment.Gas())
+	}
+	if sender, _ := types.Sender(signer, payment); sender != builderAddress {
+		t.Fatalf("payment sender mismatch: have %s, want %s", sender, builderAddress)
+	}
+	// The value sent with the deployment counts as well
+	if want := new(big.Int).Add(payment.Value(), big.NewInt(1000)); value.Cmp(want) != 0 {
+		t.Fatalf("block value mismatch: have %v, want %v", value, want)
+	}
+	if _, err := w.chain.InsertChain(types.Blocks{block}); err != nil {
+		t.Fatalf("failed to import block: %v", err)
+	}
+	statedb, _ := w.chain.State()
+	if stored := statedb.GetState(feeRecipient, common.Hash{}); stored.Big().Cmp(payment.Value()) != 0 {
+		t.Fatalf("fee recipient stored %v, want %v", stored.Big(), payment.Value())
+	}
+	if balance := statedb.GetBalance(feeRecipient); balance.Cmp(value) != 0 {
+		t.Fatalf("fee recipient balance mismatch: have %v, want %v", balance, value)
+	}
+}
+
+func TestSimulateBundles(t *testing.T) {
+	engine := ethash.NewFaker()
+	defer engine.Close()
+
+	w, _ := newTestWorker(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), 0)
+	defer w.close()
+
+	parent := w.chain.CurrentBlock()
+	args := &types.BuildBlockArgsV2{
+		Parent:       parent.Hash(),
+		Timestamp:    parent.Time + 12,
+		FeeRecipient: testUserAddress,
+		GasLimit:     parent.GasLimit,
+	}
+
+	signer := types.LatestSigner(ethashChainConfig)
+	newTx := func(nonce uint64) *types.Transaction {
+		return types.MustSignNewTx(testBankKey, signer, &types.LegacyTx{
+			Nonce:    nonce,
+			To:       &testUserAddress,
+			Value:    big.NewInt(1000),
+			Gas:      params.TxGas,
+			GasPrice: big.NewInt(10 * params.InitialBaseFee),
+		})
+	}
+	bundles := []types.SBundle{
+		{Txs: types.Transactions{newTx(0)}},
+		{Txs: types.Transactions{newTx(5)}},
+		// Every bundle is simulated on its own on top of the parent
+		{Txs: types.Transactions{newTx(0), newTx(1)}},
+	}
+
+	sims, err := w.simulateBundles(context.Background(), args, bundles)
+	if err != nil {
+		t.Fatalf("failed to simulate bundles: %v", err)
+	}
+	if len(sims) != len(bundles) {
+		t.Fatalf("simulations mismatch: have %d, want %d", len(sims), len(bundles))
+	}
+	tip := new(big.Int).Sub(big.NewInt(10*params.InitialBaseFee), misc.CalcBaseFee(ethashChainConfig, parent))
+	for i, want := range []uint64{params.TxGas, 0, 2 * params.TxGas} {
+		sim := sims[i]
+		if sim.Hash != bundles[i].Hash() {
+			t.Errorf("bundle %d: hash mismatch: have %s, want %s", i, sim.Hash, bundles[i].Hash())
+		}
+		if want == 0 {
+			if sim.Err == nil {
+				t.Errorf("bundle %d: expected simulation to fail", i)
+			}
+			continue
+		}
+		if sim.Err != nil || sim.GasUsed != want {
+			t.Errorf("bundle %d: unexpected simulation: gas %d, err %v", i, sim.GasUsed, sim.Err)
+			continue
+		}
+		if profit := new(big.Int).Mul(tip, new(big.Int).SetUint64(want)); sim.Profit.Cmp(profit) != 0 {
+			t.Errorf("bundle %d: profit mismatch: have %v, want %v", i, sim.Profit, profit)
+		}
+	}
+
+	// Simulations are cached for the parent
+	again, err := w.simulateBundles(context.Background(), args, bundles)
+	if err != nil {
+		t.Fatalf("failed to simulate bundles: %v", err)
+	}
+	for i := range sims {
+		if again[i] != sims[i] {
+			t.Errorf("bundle %d: simulation not cached", i)
+		}
+	}
+
+	// A block starting with a simulated bundle is the same as one executing it
+	build := func() *types.Block {
+		block, _, err := w.buildBlockFromBundles(context.Background(), args, []types.SBundle{bundles[2], {Txs: types.Transactions{newTx(2)}}})
+		if err != nil {
+			t.Fatalf("failed to build block: %v", err)
+		}
+		return block
+	}
+	cached, cachedAgain := build(), build()
+	w.bundleSims.Purge()
+	w.bundleStates.Purge()
+	if executed := build(); cached.Hash() != executed.Hash() || cachedAgain.Hash() != executed.Hash() {
+		t.Fatalf("block mismatch: cached %s, cached again %s, executed %s", cached.Hash(), cachedAgain.Hash(), executed.Hash())
+	}
+
+	// The cached failure of the first bundle fails the block
+	if _, err := w.simulateBundles(context.Background(), args, bundles[1:2]); err != nil {
+		t.Fatalf("failed to simulate bundles: %v", err)
+	}
+	if _, _, err := w.buildBlockFromBundles(context.Background(), args, bundles[1:2]); err == nil {
+		t.Fatal("expected block with failing bundle to fail")
+	}
+}
+
+func TestSimulateBundlesTimestampNotCached(t *testing.T) {
+	engine := ethash.NewFaker()
+	defer engine.Close()
+
+	w, _ := newTestWorker(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), 0)
+	defer w.close()
+
+	parent := w.chain.CurrentBlock()
+	args := &types.BuildBlockArgsV2{
+		Parent:       parent.Hash(),
+		Timestamp:    parent.Time + 12,
+		FeeRecipient: testUserAddress,
+		GasLimit:     parent.GasLimit,
+	}
+	tx := types.MustSignNewTx(testBankKey, types.LatestSigner(ethashChainConfig), &types.LegacyTx{
+		Nonce:    0,
+		To:       &testUserAddress,
+		Value:    big.NewInt(1000),
+		Gas:      params.TxGas,
+		GasPrice: big.NewInt(10 * params.InitialBaseFee),
+	})
+
+	// The bounds are not part of the hash, both bundles share simulations
+	expired := types.SBundle{Txs: types.Transactions{tx}, MaxTimestamp: args.Timestamp - 1}
+	unbounded := types.SBundle{Txs: types.Transactions{tx}}
+
+	sims, err := w.simulateBundles(context.Background(), args, []types.SBundle{expired})
+	if err != nil {
+		t.Fatalf("failed to simulate bundles: %v", err)
+	}
+	if sims[0].Err == nil {
+		t.Fatal("expected simulation of expired bundle to fail")
+	}
+
+	sims, err = w.simulateBundles(context.Background(), args, []types.SBundle{unbounded})
+	if err != nil {
+		t.Fatalf("failed to simulate bundles: %v", err)
+	}
+	if sims[0].Err != nil {
+		t.Fatalf("unexpected simulation failure of unbounded bundle: %v", sims[0].Err)
+	}
+	block, _, err := w.buildBlockFromBundles(context.Background(), args, []types.SBundle{expired, unbounded})
+	if err != nil {
+		t.Fatalf("failed to build block: %v", err)
+	}
+	if len(block.Transactions()) == 0 || block.Transactions()[0].Hash() != tx.Hash() {
+		t.Fatal("block does not include the unbounded bundle")
+	}
+}
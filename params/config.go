@@ -332,6 +332,7 @@ type ChainConfig struct {
 	BerlinBlock         *big.Int `json:"berlinBlock,omitempty"`         // Berlin switch block (nil = no fork, 0 = already on berlin)
 	LondonBlock         *big.Int `json:"londonBlock,omitempty"`         // London switch block (nil = no fork, 0 = already on london)
 	SuaveBlock          *big.Int `json:"suaveBlock,omitempty"`          // London switch block (nil = no fork, 0 = already on london)
+	KettleRegistryBlock *big.Int `json:"kettleRegistryBlock,omitempty"` // Kettle registry enforcement block (nil = not enforced, 0 = enforced from genesis)
 	ArrowGlacierBlock   *big.Int `json:"arrowGlacierBlock,omitempty"`   // Eip-4345 (bomb delay) switch block (nil = no fork, 0 = already activated)
 	GrayGlacierBlock    *big.Int `json:"grayGlacierBlock,omitempty"`    // Eip-5133 (bomb delay) switch block (nil = no fork, 0 = already activated)
 	MergeNetsplitBlock  *big.Int `json:"mergeNetsplitBlock,omitempty"`  // Virtual fork after The Merge to use as a network splitter
@@ -354,6 +355,10 @@ type ChainConfig struct {
 	// Various consensus engines
 	Ethash *EthashConfig `json:"ethash,omitempty"`
 	Clique *CliqueConfig `json:"clique,omitempty"`
+
+	// KettleRegistry restricts the kettles SuaveTransactions are accepted
+	// from, starting at KettleRegistryBlock. Any kettle is accepted if nil.
+	KettleRegistry *KettleRegistryConfig `json:"kettleRegistry,omitempty"`
 }
 
 // EthashConfig is the consensus engine configs for proof-of-work based sealing.
@@ -375,6 +380,16 @@ func (c *CliqueConfig) String() string {
 	return "clique"
 }
 
+// KettleRegistryConfig lists the kettles allowed to sign SuaveTransactions,
+// either in the chain config or in the storage of a registry contract, which
+// is typically deployed in the genesis. A kettle in either is authorized.
+type KettleRegistryConfig struct {
+	Kettles []common.Address `json:"kettles,omitempty"`
+	// Contract holding a mapping(address => bool) of the authorized kettles
+	// at its first storage slot
+	Contract *common.Address `json:"contract,omitempty"`
+}
+
 // Description returns a human-readable description of ChainConfig.
 func (c *ChainConfig) Description() string {
 	var banner string
@@ -525,6 +540,12 @@ func (c *ChainConfig) IsSuave(num *big.Int) bool {
 	return isBlockForked(c.SuaveBlock, num)
 }
 
+// IsKettleRegistry returns whether the kettle registry is enforced at num,
+// from the kettle registry block on.
+func (c *ChainConfig) IsKettleRegistry(num *big.Int) bool {
+	return c.KettleRegistry != nil && isBlockForked(c.KettleRegistryBlock, num)
+}
+
 // IsLondon returns whether num is either equal to the London fork block or greater.
 func (c *ChainConfig) IsLondon(num *big.Int) bool {
 	return isBlockForked(c.LondonBlock, num)
@@ -702,6 +723,13 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, headNumber *big.Int,
 	if isForkBlockIncompatible(c.LondonBlock, newcfg.LondonBlock, headNumber) {
 		return newBlockCompatError("London fork block", c.LondonBlock, newcfg.LondonBlock)
 	}
+	if isForkBlockIncompatible(c.KettleRegistryBlock, newcfg.KettleRegistryBlock, headNumber) {
+		return newBlockCompatError("Kettle registry block", c.KettleRegistryBlock, newcfg.KettleRegistryBlock)
+	}
+	// The registry can only change together with a new activation block
+	if (isBlockForked(c.KettleRegistryBlock, headNumber) || isBlockForked(newcfg.KettleRegistryBlock, headNumber)) && !kettleRegistryEqual(c.KettleRegistry, newcfg.KettleRegistry) {
+		return newBlockCompatError("Kettle registry", c.KettleRegistryBlock, newcfg.KettleRegistryBlock)
+	}
 	if isForkBlockIncompatible(c.ArrowGlacierBlock, newcfg.ArrowGlacierBlock, headNumber) {
 		return newBlockCompatError("Arrow Glacier fork block", c.ArrowGlacierBlock, newcfg.ArrowGlacierBlock)
 	}
@@ -785,6 +813,27 @@ func configTimestampEqual(x, y *uint64) bool {
 	return *x == *y
 }
 
+// kettleRegistryEqual returns whether x and y authorize the same kettles.
+func kettleRegistryEqual(x, y *KettleRegistryConfig) bool {
+	if x == nil || y == nil {
+		return x == y
+	}
+	if (x.Contract == nil) != (y.Contract == nil) || (x.Contract != nil && *x.Contract != *y.Contract) {
+		return false
+	}
+	kettles := make(map[common.Address]struct{}, len(x.Kettles))
+	for _, kettle := range x.Kettles {
+		kettles[kettle] = struct{}{}
+	}
+	for _, kettle := range y.Kettles {
+		if _, ok := kettles[kettle]; !ok {
+			return false
+		}
+		delete(kettles, kettle)
+	}
+	return len(kettles) == 0
+}
+
 // ConfigCompatError is raised if the locally-stored blockchain is initialised with a
 // ChainConfig that would alter the past.
 type ConfigCompatError struct {
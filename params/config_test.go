@@ -22,6 +22,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/math"
 )
 
@@ -75,6 +76,57 @@ func TestCheckCompatible(t *testing.T) {
 				RewindToBlock: 9,
 			},
 		},
+		{
+			stored:    &ChainConfig{KettleRegistryBlock: big.NewInt(10)},
+			new:       &ChainConfig{KettleRegistryBlock: big.NewInt(20)},
+			headBlock: 15,
+			wantErr: &ConfigCompatError{
+				What:          "Kettle registry block",
+				StoredBlock:   big.NewInt(10),
+				NewBlock:      big.NewInt(20),
+				RewindToBlock: 9,
+			},
+		},
+		{
+			stored:    &ChainConfig{KettleRegistryBlock: big.NewInt(10)},
+			new:       &ChainConfig{KettleRegistryBlock: big.NewInt(20)},
+			headBlock: 5,
+			wantErr:   nil,
+		},
+		{
+			stored:    &ChainConfig{KettleRegistryBlock: big.NewInt(10), KettleRegistry: &KettleRegistryConfig{Kettles: []common.Address{{0x01}, {0x02}}}},
+			new:       &ChainConfig{KettleRegistryBlock: big.NewInt(10), KettleRegistry: &KettleRegistryConfig{Kettles: []common.Address{{0x02}, {0x01}}}},
+			headBlock: 15,
+			wantErr:   nil,
+		},
+		{
+			stored:    &ChainConfig{KettleRegistryBlock: big.NewInt(10), KettleRegistry: &KettleRegistryConfig{Kettles: []common.Address{{0x01}}}},
+			new:       &ChainConfig{KettleRegistryBlock: big.NewInt(10), KettleRegistry: &KettleRegistryConfig{Kettles: []common.Address{{0x01}, {0x02}}}},
+			headBlock: 15,
+			wantErr: &ConfigCompatError{
+				What:          "Kettle registry",
+				StoredBlock:   big.NewInt(10),
+				NewBlock:      big.NewInt(10),
+				RewindToBlock: 9,
+			},
+		},
+		{
+			stored:    &ChainConfig{KettleRegistryBlock: big.NewInt(10), KettleRegistry: &KettleRegistryConfig{Kettles: []common.Address{{0x01}}}},
+			new:       &ChainConfig{KettleRegistryBlock: big.NewInt(10), KettleRegistry: &KettleRegistryConfig{Contract: &common.Address{0x03}}},
+			headBlock: 15,
+			wantErr: &ConfigCompatError{
+				What:          "Kettle registry",
+				StoredBlock:   big.NewInt(10),
+				NewBlock:      big.NewInt(10),
+				RewindToBlock: 9,
+			},
+		},
+		{
+			stored:    &ChainConfig{KettleRegistryBlock: big.NewInt(10), KettleRegistry: &KettleRegistryConfig{Kettles: []common.Address{{0x01}}}},
+			new:       &ChainConfig{KettleRegistryBlock: big.NewInt(20), KettleRegistry: &KettleRegistryConfig{Kettles: []common.Address{{0x01}, {0x02}}}},
+			headBlock: 5,
+			wantErr:   nil,
+		},
 		{
 			stored:    &ChainConfig{ConstantinopleBlock: big.NewInt(30)},
 			new:       &ChainConfig{ConstantinopleBlock: big.NewInt(30), PetersburgBlock: big.NewInt(30)},
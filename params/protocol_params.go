@@ -163,6 +163,7 @@ const (
 	BlobTxDataGasPerBlob             = 1 << 17 // Gas consumption of a single data blob (== blob byte size)
 	BlobTxMinDataGasprice            = 1       // Minimum gas price for data blobs
 	BlobTxDataGaspriceUpdateFraction = 2225652 // Controls the maximum rate of change for data gas price
+	BlobTxHashVersion                = 0x01    // Version byte of the commitment hash
 )
 
 // Gas discount table for BLS12-381 G1 and G2 multi exponentiation operations
@@ -1,48 +1,65 @@
 // Code generated by suave/gen. DO NOT EDIT.
-// Hash: aca9d97ce0be5bb3637b3f1bab2aeda459891800e3d1071a469b8ea4309b5531
+// Hash: 85037167525daf32489360ed2546ea1b7d45bc74a427af9c27ab900e4e455800
 package artifacts
 
 import (
 	"github.com/ethereum/go-ethereum/common"
 )
 
+// SpecHash identifies the version of the spec the precompiles were generated from
+const SpecHash = "85037167525daf32489360ed2546ea1b7d45bc74a427af9c27ab900e4e455800"
+
 // List of suave precompile addresses
 var (
-	buildEthBlockAddr            = common.HexToAddress("0x0000000000000000000000000000000042100001")
-	confidentialInputsAddr       = common.HexToAddress("0x0000000000000000000000000000000042010001")
-	confidentialRetrieveAddr     = common.HexToAddress("0x0000000000000000000000000000000042020001")
-	confidentialStoreAddr        = common.HexToAddress("0x0000000000000000000000000000000042020000")
-	ethcallAddr                  = common.HexToAddress("0x0000000000000000000000000000000042100003")
-	extractHintAddr              = common.HexToAddress("0x0000000000000000000000000000000042100037")
-	fetchBidsAddr                = common.HexToAddress("0x0000000000000000000000000000000042030001")
-	fillMevShareBundleAddr       = common.HexToAddress("0x0000000000000000000000000000000043200001")
-	newBidAddr                   = common.HexToAddress("0x0000000000000000000000000000000042030000")
-	signEthTransactionAddr       = common.HexToAddress("0x0000000000000000000000000000000040100001")
-	simulateBundleAddr           = common.HexToAddress("0x0000000000000000000000000000000042100000")
-	submitBundleJsonRPCAddr      = common.HexToAddress("0x0000000000000000000000000000000043000001")
-	submitEthBlockBidToRelayAddr = common.HexToAddress("0x0000000000000000000000000000000042100002")
+	buildEthBlockAddr             = common.HexToAddress("0x0000000000000000000000000000000042100001")
+	buildEthBlockV2Addr           = common.HexToAddress("0x0000000000000000000000000000000042100006")
+	confidentialInputsAddr        = common.HexToAddress("0x0000000000000000000000000000000042010001")
+	confidentialRetrieveAddr      = common.HexToAddress("0x0000000000000000000000000000000042020001")
+	confidentialStoreAddr         = common.HexToAddress("0x0000000000000000000000000000000042020000")
+	ethcallAddr                   = common.HexToAddress("0x0000000000000000000000000000000042100003")
+	extractHintAddr               = common.HexToAddress("0x0000000000000000000000000000000042100037")
+	fetchBidsAddr                 = common.HexToAddress("0x0000000000000000000000000000000042030001")
+	fillMevShareBundleAddr        = common.HexToAddress("0x0000000000000000000000000000000043200001")
+	newBidAddr                    = common.HexToAddress("0x0000000000000000000000000000000042030000")
+	newBidWithAccessWindowAddr    = common.HexToAddress("0x0000000000000000000000000000000042030002")
+	newBidWithPolicyAddr          = common.HexToAddress("0x0000000000000000000000000000000042030003")
+	signEthTransactionAddr        = common.HexToAddress("0x0000000000000000000000000000000040100001")
+	simulateBundleAddr            = common.HexToAddress("0x0000000000000000000000000000000042100000")
+	submitBundleJsonRPCAddr       = common.HexToAddress("0x0000000000000000000000000000000043000001")
+	submitEthBlockBidToRelayAddr  = common.HexToAddress("0x0000000000000000000000000000000042100002")
+	submitEthBlockBidToRelaysAddr = common.HexToAddress("0x0000000000000000000000000000000042100005")
+	upcomingBuildBlockArgsAddr    = common.HexToAddress("0x0000000000000000000000000000000042100004")
+	upcomingBuildBlockArgsV2Addr  = common.HexToAddress("0x0000000000000000000000000000000042100007")
 )
 
 var SuaveMethods = map[string]common.Address{
-	"buildEthBlock":            buildEthBlockAddr,
-	"confidentialInputs":       confidentialInputsAddr,
-	"confidentialRetrieve":     confidentialRetrieveAddr,
-	"confidentialStore":        confidentialStoreAddr,
-	"ethcall":                  ethcallAddr,
-	"extractHint":              extractHintAddr,
-	"fetchBids":                fetchBidsAddr,
-	"fillMevShareBundle":       fillMevShareBundleAddr,
-	"newBid":                   newBidAddr,
-	"signEthTransaction":       signEthTransactionAddr,
-	"simulateBundle":           simulateBundleAddr,
-	"submitBundleJsonRPC":      submitBundleJsonRPCAddr,
-	"submitEthBlockBidToRelay": submitEthBlockBidToRelayAddr,
+	"buildEthBlock":             buildEthBlockAddr,
+	"buildEthBlockV2":           buildEthBlockV2Addr,
+	"confidentialInputs":        confidentialInputsAddr,
+	"confidentialRetrieve":      confidentialRetrieveAddr,
+	"confidentialStore":         confidentialStoreAddr,
+	"ethcall":                   ethcallAddr,
+	"extractHint":               extractHintAddr,
+	"fetchBids":                 fetchBidsAddr,
+	"fillMevShareBundle":        fillMevShareBundleAddr,
+	"newBid":                    newBidAddr,
+	"newBidWithAccessWindow":    newBidWithAccessWindowAddr,
+	"newBidWithPolicy":          newBidWithPolicyAddr,
+	"signEthTransaction":        signEthTransactionAddr,
+	"simulateBundle":            simulateBundleAddr,
+	"submitBundleJsonRPC":       submitBundleJsonRPCAddr,
+	"submitEthBlockBidToRelay":  submitEthBlockBidToRelayAddr,
+	"submitEthBlockBidToRelays": submitEthBlockBidToRelaysAddr,
+	"upcomingBuildBlockArgs":    upcomingBuildBlockArgsAddr,
+	"upcomingBuildBlockArgsV2":  upcomingBuildBlockArgsV2Addr,
 }
 
 func PrecompileAddressToName(addr common.Address) string {
 	switch addr {
 	case buildEthBlockAddr:
 		return "buildEthBlock"
+	case buildEthBlockV2Addr:
+		return "buildEthBlockV2"
 	case confidentialInputsAddr:
 		return "confidentialInputs"
 	case confidentialRetrieveAddr:
@@ -59,6 +76,10 @@ func PrecompileAddressToName(addr common.Address) string {
 		return "fillMevShareBundle"
 	case newBidAddr:
 		return "newBid"
+	case newBidWithAccessWindowAddr:
+		return "newBidWithAccessWindow"
+	case newBidWithPolicyAddr:
+		return "newBidWithPolicy"
 	case signEthTransactionAddr:
 		return "signEthTransaction"
 	case simulateBundleAddr:
@@ -67,6 +88,15 @@ func PrecompileAddressToName(addr common.Address) string {
 		return "submitBundleJsonRPC"
 	case submitEthBlockBidToRelayAddr:
 		return "submitEthBlockBidToRelay"
+	case submitEthBlockBidToRelaysAddr:
+		return "submitEthBlockBidToRelays"
+	case upcomingBuildBlockArgsAddr:
+		return "upcomingBuildBlockArgs"
+	case upcomingBuildBlockArgsV2Addr:
+		return "upcomingBuildBlockArgsV2"
 	}
 	return ""
 }
+
+// DeprecatedPrecompiles maps the address of each deprecated precompile to its deprecation notice
+var DeprecatedPrecompiles = map[common.Address]string{}
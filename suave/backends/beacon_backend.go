@@ -0,0 +1,335 @@
+package backends
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/capella"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+	suave "github.com/ethereum/go-ethereum/suave/core"
+	"github.com/r3labs/sse"
+)
+
+var (
+	_ suave.ConfidentialBeaconBackend = &RemoteBeaconBackend{}
+
+	ErrNoUpcomingSlot = errors.New("no payload attributes received for the upcoming slot")
+)
+
+// RemoteBeaconBackend follows the payload_attributes events of a beacon node
+// and the validator registrations of a relay to assemble the block building
+// arguments of the upcoming slot.
+type RemoteBeaconBackend struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	beaconEndpoint string
+	relayEndpoint  string
+	client         *http.Client
+
+	lock       sync.RWMutex
+	upcoming   *types.BuildBlockArgs
+	validators map[uint64]ValidatorData
+
+	feed  event.Feed
+	scope event.SubscriptionScope
+}
+
+func NewRemoteBeaconBackend(beaconEndpoint string, relayEndpoint string) *RemoteBeaconBackend {
+	return &RemoteBeaconBackend{
+		beaconEndpoint: beaconEndpoint,
+		relayEndpoint:  relayEndpoint,
+		client:         &http.Client{Timeout: 3 * time.Second},
+		validators:     make(map[uint64]ValidatorData),
+	}
+}
+
+func (b *RemoteBeaconBackend) Start() error {
+	if b.cancel != nil {
+		b.cancel()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	b.ctx = ctx
+	b.cancel = cancel
+
+	go b.subscribePayloadAttributes(ctx)
+
+	return nil
+}
+
+func (b *RemoteBeaconBackend) Stop() error {
+	if b.cancel == nil {
+		return errors.New("Beacon backend: Stop() called before Start()")
+	}
+
+	b.cancel()
+	b.scope.Close()
+
+	return nil
+}
+
+// UpcomingBuildBlockArgs returns the block building arguments of the most
+// recent slot announced by the beacon node, as long as the slot has not
+// started yet.
+func (b *RemoteBeaconBackend) UpcomingBuildBlockArgs(ctx context.Context) (*types.BuildBlockArgs, error) {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	if b.upcoming == nil || b.upcoming.Timestamp < uint64(time.Now().Unix()) {
+		return nil, ErrNoUpcomingSlot
+	}
+
+	return copyBuildBlockArgs(b.upcoming), nil
+}
+
+// SubscribeBuildBlockArgs notifies the channel every time the block building
+// arguments of a new slot are available.
+func (b *RemoteBeaconBackend) SubscribeBuildBlockArgs(ch chan<- *types.BuildBlockArgs) event.Subscription {
+	return b.scope.Track(b.feed.Subscribe(ch))
+}
+
+func (b *RemoteBeaconBackend) subscribePayloadAttributes(ctx context.Context) {
+	eventsURL := fmt.Sprintf("%s/eth/v1/events?topics=payload_attributes", b.beaconEndpoint)
+	log.Info("Beacon backend: subscribing to payload_attributes events", "url", eventsURL)
+
+	for ctx.Err() == nil {
+		client := sse.NewClient(eventsURL)
+		err := client.SubscribeRawWithContext(ctx, func(msg *sse.Event) {
+			var payloadAttributesEvent PayloadAttributesEvent
+			if err := json.Unmarshal(msg.Data, &payloadAttributesEvent); err != nil {
+				log.Error("Beacon backend: could not unmarshal payload_attributes event", "err", err)
+				return
+			}
+
+			if err := b.handlePayloadAttributes(ctx, &payloadAttributesEvent); err != nil {
+				log.Error("Beacon backend: could not process payload_attributes event", "slot", payloadAttributesEvent.Data.ProposalSlot, "err", err)
+			}
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			log.Error("Beacon backend: failed to subscribe to payload_attributes events", "err", err)
+		}
+
+		log.Warn("Beacon backend: payload_attributes subscription ended, reconnecting")
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+func (b *RemoteBeaconBackend) handlePayloadAttributes(ctx context.Context, paEvent *PayloadAttributesEvent) error {
+	validatorData, err := b.validatorForSlot(ctx, paEvent.Data.ProposalSlot)
+	if err != nil {
+		return err
+	}
+
+	proposerPubkey, err := hexutil.Decode(validatorData.Pubkey)
+	if err != nil {
+		return fmt.Errorf("could not decode proposer pubkey %s: %w", validatorData.Pubkey, err)
+	}
+
+	buildArgs := &types.BuildBlockArgs{
+		Slot:           paEvent.Data.ProposalSlot,
+		ProposerPubkey: proposerPubkey,
+		Parent:         paEvent.Data.ParentBlockHash,
+		Timestamp:      paEvent.Data.PayloadAttributes.Timestamp,
+		FeeRecipient:   validatorData.FeeRecipient,
+		GasLimit:       validatorData.GasLimit,
+		Random:         paEvent.Data.PayloadAttributes.PrevRandao,
+		Withdrawals:    []*types.Withdrawal{},
+	}
+
+	for _, w := range paEvent.Data.PayloadAttributes.Withdrawals {
+		buildArgs.Withdrawals = append(buildArgs.Withdrawals, &types.Withdrawal{
+			Index:     uint64(w.Index),
+			Validator: uint64(w.ValidatorIndex),
+			Address:   common.Address(w.Address),
+			Amount:    uint64(w.Amount),
+		})
+	}
+
+	b.lock.Lock()
+	b.upcoming = buildArgs
+	b.lock.Unlock()
+
+	log.Info("Beacon backend: new upcoming slot", "slot", buildArgs.Slot, "parent", buildArgs.Parent, "feeRecipient", buildArgs.FeeRecipient)
+	b.feed.Send(copyBuildBlockArgs(buildArgs))
+
+	return nil
+}
+
+// validatorForSlot returns the validator registration of the slot's proposer.
+// Registrations are cached until the relay no longer lists the slot.
+func (b *RemoteBeaconBackend) validatorForSlot(ctx context.Context, slot uint64) (ValidatorData, error) {
+	b.lock.RLock()
+	validatorData, found := b.validators[slot]
+	b.lock.RUnlock()
+	if found {
+		return validatorData, nil
+	}
+
+	validators, err := b.fetchValidators(ctx)
+	if err != nil {
+		return ValidatorData{}, err
+	}
+
+	b.lock.Lock()
+	b.validators = validators
+	b.lock.Unlock()
+
+	validatorData, found = validators[slot]
+	if !found {
+		return ValidatorData{}, fmt.Errorf("no validator registered for slot %d", slot)
+	}
+
+	return validatorData, nil
+}
+
+func (b *RemoteBeaconBackend) fetchValidators(ctx context.Context) (map[uint64]ValidatorData, error) {
+	endpoint := b.relayEndpoint + "/relay/v1/builder/validators"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not prepare request to relay: %w", err)
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("could not send request to relay: %w", err)
+	}
+	defer resp.Body.Close()
+
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("could not read relay response body: %w", err)
+	}
+
+	if resp.StatusCode > 299 {
+		return nil, fmt.Errorf("relay request failed with code %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	var dst GetValidatorRelayResponse
+	if err := json.Unmarshal(bodyBytes, &dst); err != nil {
+		return nil, fmt.Errorf("could not unmarshal relay response %s: %w", string(bodyBytes), err)
+	}
+
+	res := make(map[uint64]ValidatorData, len(dst))
+	for _, data := range dst {
+		res[data.Slot] = ValidatorData{
+			Pubkey:       data.Entry.Message.Pubkey,
+			FeeRecipient: common.HexToAddress(data.Entry.Message.FeeRecipient),
+			GasLimit:     data.Entry.Message.GasLimit,
+		}
+	}
+
+	return res, nil
+}
+
+func copyBuildBlockArgs(args *types.BuildBlockArgs) *types.BuildBlockArgs {
+	cpy := *args
+	cpy.ProposerPubkey = common.CopyBytes(args.ProposerPubkey)
+	cpy.Extra = common.CopyBytes(args.Extra)
+	cpy.Withdrawals = make([]*types.Withdrawal, len(args.Withdrawals))
+	for i, w := range args.Withdrawals {
+		wCpy := *w
+		cpy.Withdrawals[i] = &wCpy
+	}
+	return &cpy
+}
+
+// BeaconBackendAPI exposes the upcoming slot to RPC clients.
+type BeaconBackendAPI struct {
+	b *RemoteBeaconBackend
+}
+
+func NewBeaconBackendAPI(b *RemoteBeaconBackend) *BeaconBackendAPI {
+	return &BeaconBackendAPI{b}
+}
+
+func (api *BeaconBackendAPI) UpcomingBuildBlockArgs(ctx context.Context) (*types.BuildBlockArgs, error) {
+	return api.b.UpcomingBuildBlockArgs(ctx)
+}
+
+// NewBuildBlockArgs sends a notification each time the beacon node announces
+// the payload attributes of a new slot.
+func (api *BeaconBackendAPI) NewBuildBlockArgs(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		argsCh := make(chan *types.BuildBlockArgs, 16)
+		argsSub := api.b.SubscribeBuildBlockArgs(argsCh)
+		defer argsSub.Unsubscribe()
+
+		for {
+			select {
+			case args := <-argsCh:
+				notifier.Notify(rpcSub.ID, args)
+			case <-argsSub.Err():
+				return
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
+type PayloadAttributesEvent struct {
+	Version string                     `json:"version"`
+	Data    PayloadAttributesEventData `json:"data"`
+}
+
+type PayloadAttributesEventData struct {
+	ProposalSlot      uint64            `json:"proposal_slot,string"`
+	ParentBlockHash   common.Hash       `json:"parent_block_hash"`
+	PayloadAttributes PayloadAttributes `json:"payload_attributes"`
+}
+
+type PayloadAttributes struct {
+	Timestamp             uint64                `json:"timestamp,string"`
+	PrevRandao            common.Hash           `json:"prev_randao"`
+	SuggestedFeeRecipient common.Address        `json:"suggested_fee_recipient"`
+	Withdrawals           []*capella.Withdrawal `json:"withdrawals"`
+}
+
+type ValidatorData struct {
+	Pubkey       string
+	FeeRecipient common.Address
+	GasLimit     uint64
+}
+
+type GetValidatorRelayResponse []struct {
+	Slot  uint64 `json:"slot,string"`
+	Entry struct {
+		Message struct {
+			FeeRecipient string `json:"fee_recipient"`
+			GasLimit     uint64 `json:"gas_limit,string"`
+			Timestamp    uint64 `json:"timestamp,string"`
+			Pubkey       string `json:"pubkey"`
+		} `json:"message"`
+		Signature string `json:"signature"`
+	} `json:"entry"`
+}
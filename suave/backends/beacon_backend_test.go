@@ -0,0 +1,126 @@
+package backends
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testPayloadAttributesEvent = `{"version":"capella","data":{"proposer_index":"123","proposal_slot":"10","parent_block_number":"9","parent_block_root":"0x0000000000000000000000000000000000000000000000000000000000000000","parent_block_hash":"0x0100000000000000000000000000000000000000000000000000000000000000","payload_attributes":{"timestamp":"4102444800","prev_randao":"0x0200000000000000000000000000000000000000000000000000000000000000","suggested_fee_recipient":"0x0000000000000000000000000000000000000003","withdrawals":[{"index":"5","validator_index":"6","address":"0x0000000000000000000000000000000000000007","amount":"8"}]}}}`
+
+	testRelayValidators = `[{"slot":"10","validator_index":"123","entry":{"message":{"fee_recipient":"0x0000000000000000000000000000000000000004","gas_limit":"30000000","timestamp":"1","pubkey":"0xa1b2"},"signature":"0x"}}]`
+)
+
+// newBeaconStub serves a single payload_attributes event to every subscriber
+// along with the validator registrations of the relay.
+func newBeaconStub(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/eth/v1/events", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "payload_attributes", r.URL.Query().Get("topics"))
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, "event: payload_attributes\ndata: %s\n\n", testPayloadAttributesEvent)
+		w.(http.Flusher).Flush()
+
+		<-r.Context().Done()
+	})
+	mux.HandleFunc("/relay/v1/builder/validators", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(testRelayValidators))
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+var expectedBuildBlockArgs = &types.BuildBlockArgs{
+	Slot:           10,
+	ProposerPubkey: []byte{0xa1, 0xb2},
+	Parent:         common.Hash{0x01},
+	Timestamp:      4102444800,
+	FeeRecipient:   common.HexToAddress("0x04"),
+	GasLimit:       30000000,
+	Random:         common.Hash{0x02},
+	Withdrawals: []*types.Withdrawal{
+		{Index: 5, Validator: 6, Address: common.HexToAddress("0x07"), Amount: 8},
+	},
+}
+
+func TestBeaconBackend_UpcomingBuildBlockArgs(t *testing.T) {
+	srv := newBeaconStub(t)
+
+	backend := NewRemoteBeaconBackend(srv.URL, srv.URL)
+
+	_, err := backend.UpcomingBuildBlockArgs(context.Background())
+	require.ErrorIs(t, err, ErrNoUpcomingSlot)
+
+	argsCh := make(chan *types.BuildBlockArgs, 1)
+	sub := backend.SubscribeBuildBlockArgs(argsCh)
+	defer sub.Unsubscribe()
+
+	require.NoError(t, backend.Start())
+	defer backend.Stop()
+
+	select {
+	case args := <-argsCh:
+		require.Equal(t, expectedBuildBlockArgs, args)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no build block args received")
+	}
+
+	args, err := backend.UpcomingBuildBlockArgs(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, expectedBuildBlockArgs, args)
+}
+
+func TestBeaconBackend_UpcomingBuildBlockArgs_PastSlot(t *testing.T) {
+	backend := NewRemoteBeaconBackend("", "")
+
+	// The slot of the last payload attributes has started
+	backend.upcoming = &types.BuildBlockArgs{Slot: 10, Timestamp: uint64(time.Now().Unix()) - 12}
+	_, err := backend.UpcomingBuildBlockArgs(context.Background())
+	require.ErrorIs(t, err, ErrNoUpcomingSlot)
+}
+
+func TestBeaconBackend_Subscription(t *testing.T) {
+	beaconSrv := newBeaconStub(t)
+
+	backend := NewRemoteBeaconBackend(beaconSrv.URL, beaconSrv.URL)
+
+	srv := rpc.NewServer()
+	require.NoError(t, srv.RegisterName("suave", NewBeaconBackendAPI(backend)))
+	clt := rpc.DialInProc(srv)
+	defer clt.Close()
+
+	argsCh := make(chan *types.BuildBlockArgs, 1)
+	sub, err := clt.Subscribe(context.Background(), "suave", argsCh, "newBuildBlockArgs")
+	require.NoError(t, err)
+	defer sub.Unsubscribe()
+
+	require.NoError(t, backend.Start())
+	defer backend.Stop()
+
+	select {
+	case args := <-argsCh:
+		require.Equal(t, expectedBuildBlockArgs, args)
+	case err := <-sub.Err():
+		t.Fatal(err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no build block args received")
+	}
+
+	var args types.BuildBlockArgs
+	require.NoError(t, clt.Call(&args, "suave_upcomingBuildBlockArgs"))
+	require.Equal(t, expectedBuildBlockArgs, &args)
+}
@@ -6,14 +6,17 @@ import (
 
 	"github.com/ethereum/go-ethereum/beacon/engine"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/miner"
 	suave "github.com/ethereum/go-ethereum/suave/core"
 )
 
 // EthBackend is the set of functions exposed from the SUAVE-enabled node
 type EthBackend interface {
-	BuildEthBlock(ctx context.Context, buildArgs *types.BuildBlockArgs, txs types.Transactions) (*engine.ExecutionPayloadEnvelope, error)
-	BuildEthBlockFromBundles(ctx context.Context, buildArgs *types.BuildBlockArgs, bundles []types.SBundle) (*engine.ExecutionPayloadEnvelope, error)
+	BuildEthBlock(ctx context.Context, buildArgs *types.BuildBlockArgsV2, txs types.Transactions) (*engine.ExecutionPayloadEnvelope, error)
+	BuildEthBlockFromBundles(ctx context.Context, buildArgs *types.BuildBlockArgsV2, bundles []types.SBundle) (*engine.ExecutionPayloadEnvelope, error)
+	SimulateBundles(ctx context.Context, buildArgs *types.BuildBlockArgsV2, bundles []types.SBundle) ([]*suave.SimulatedBundle, error)
 	Call(ctx context.Context, contractAddr common.Address, input []byte) ([]byte, error)
 }
 
@@ -23,8 +26,9 @@ var _ EthBackend = &EthBackendServer{}
 // to resolve the EthBackend server queries
 type EthBackendServerBackend interface {
 	CurrentHeader() *types.Header
-	BuildBlockFromTxs(ctx context.Context, buildArgs *suave.BuildBlockArgs, txs types.Transactions) (*types.Block, *big.Int, error)
-	BuildBlockFromBundles(ctx context.Context, buildArgs *suave.BuildBlockArgs, bundles []types.SBundle) (*types.Block, *big.Int, error)
+	BuildBlockFromTxs(ctx context.Context, buildArgs *suave.BuildBlockArgsV2, txs types.Transactions) (*types.Block, *big.Int, error)
+	BuildBlockFromBundles(ctx context.Context, buildArgs *suave.BuildBlockArgsV2, bundles []types.SBundle) (*types.Block, *big.Int, error)
+	SimulateBundlesForBlock(ctx context.Context, buildArgs *suave.BuildBlockArgsV2, bundles []types.SBundle) ([]*miner.BundleSimulation, error)
 	Call(ctx context.Context, contractAddr common.Address, input []byte) ([]byte, error)
 }
 
@@ -36,17 +40,23 @@ func NewEthBackendServer(b EthBackendServerBackend) *EthBackendServer {
 	return &EthBackendServer{b}
 }
 
-func (e *EthBackendServer) BuildEthBlock(ctx context.Context, buildArgs *types.BuildBlockArgs, txs types.Transactions) (*engine.ExecutionPayloadEnvelope, error) {
+// defaultBuildArgs returns the arguments of a block built on top of the
+// current head, used when the caller does not target a specific slot.
+func (e *EthBackendServer) defaultBuildArgs() *types.BuildBlockArgsV2 {
+	head := e.b.CurrentHeader()
+	return &types.BuildBlockArgsV2{
+		Parent:       head.Hash(),
+		Timestamp:    head.Time + uint64(12),
+		FeeRecipient: common.Address{0x42},
+		GasLimit:     30000000,
+		Random:       head.Root,
+		Withdrawals:  nil,
+	}
+}
+
+func (e *EthBackendServer) BuildEthBlock(ctx context.Context, buildArgs *types.BuildBlockArgsV2, txs types.Transactions) (*engine.ExecutionPayloadEnvelope, error) {
 	if buildArgs == nil {
-		head := e.b.CurrentHeader()
-		buildArgs = &types.BuildBlockArgs{
-			Parent:       head.Hash(),
-			Timestamp:    head.Time + uint64(12),
-			FeeRecipient: common.Address{0x42},
-			GasLimit:     30000000,
-			Random:       head.Root,
-			Withdrawals:  nil,
-		}
+		buildArgs = e.defaultBuildArgs()
 	}
 
 	block, profit, err := e.b.BuildBlockFromTxs(ctx, buildArgs, txs)
@@ -57,17 +67,9 @@ func (e *EthBackendServer) BuildEthBlock(ctx context.Context, buildArgs *types.B
 	return engine.BlockToExecutableData(block, profit), nil
 }
 
-func (e *EthBackendServer) BuildEthBlockFromBundles(ctx context.Context, buildArgs *types.BuildBlockArgs, bundles []types.SBundle) (*engine.ExecutionPayloadEnvelope, error) {
+func (e *EthBackendServer) BuildEthBlockFromBundles(ctx context.Context, buildArgs *types.BuildBlockArgsV2, bundles []types.SBundle) (*engine.ExecutionPayloadEnvelope, error) {
 	if buildArgs == nil {
-		head := e.b.CurrentHeader()
-		buildArgs = &types.BuildBlockArgs{
-			Parent:       head.Hash(),
-			Timestamp:    head.Time + uint64(12),
-			FeeRecipient: common.Address{0x42},
-			GasLimit:     30000000,
-			Random:       head.Root,
-			Withdrawals:  nil,
-		}
+		buildArgs = e.defaultBuildArgs()
 	}
 
 	block, profit, err := e.b.BuildBlockFromBundles(ctx, buildArgs, bundles)
@@ -78,6 +80,34 @@ func (e *EthBackendServer) BuildEthBlockFromBundles(ctx context.Context, buildAr
 	return engine.BlockToExecutableData(block, profit), nil
 }
 
+// SimulateBundles executes each bundle on its own on top of the parent of the
+// block, in parallel. Simulations are cached by parent and bundle, blocks
+// built from bundles reuse them.
+func (e *EthBackendServer) SimulateBundles(ctx context.Context, buildArgs *types.BuildBlockArgsV2, bundles []types.SBundle) ([]*suave.SimulatedBundle, error) {
+	if buildArgs == nil {
+		buildArgs = e.defaultBuildArgs()
+	}
+
+	sims, err := e.b.SimulateBundlesForBlock(ctx, buildArgs, bundles)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*suave.SimulatedBundle, len(sims))
+	for i, sim := range sims {
+		results[i] = &suave.SimulatedBundle{
+			BundleHash: sim.Hash,
+			GasUsed:    hexutil.Uint64(sim.GasUsed),
+		}
+		if sim.Err != nil {
+			results[i].Error = sim.Err.Error()
+		} else {
+			results[i].Profit = (*hexutil.Big)(sim.Profit)
+		}
+	}
+	return results, nil
+}
+
 func (e *EthBackendServer) Call(ctx context.Context, contractAddr common.Address, input []byte) ([]byte, error) {
 	return e.b.Call(ctx, contractAddr, input)
 }
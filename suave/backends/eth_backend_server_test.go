@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/miner"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/ethereum/go-ethereum/trie"
 	"github.com/stretchr/testify/require"
@@ -22,12 +23,18 @@ func TestEthBackend_Compatibility(t *testing.T) {
 
 	clt := &RemoteEthBackend{client: rpc.DialInProc(srv)}
 
-	_, err := clt.BuildEthBlock(context.Background(), &types.BuildBlockArgs{}, nil)
+	_, err := clt.BuildEthBlock(context.Background(), &types.BuildBlockArgsV2{}, nil)
 	require.NoError(t, err)
 
-	_, err = clt.BuildEthBlockFromBundles(context.Background(), &types.BuildBlockArgs{}, nil)
+	_, err = clt.BuildEthBlockFromBundles(context.Background(), &types.BuildBlockArgsV2{}, nil)
 	require.NoError(t, err)
 
+	sims, err := clt.SimulateBundles(context.Background(), &types.BuildBlockArgsV2{}, []types.SBundle{{}})
+	require.NoError(t, err)
+	require.Len(t, sims, 1)
+	require.Equal(t, uint64(1000), uint64(sims[0].GasUsed))
+	require.Equal(t, big.NewInt(11000), sims[0].Profit.ToInt())
+
 	_, err = clt.Call(context.Background(), common.Address{}, nil)
 	require.NoError(t, err)
 }
@@ -39,12 +46,12 @@ func (n *mockBackend) CurrentHeader() *types.Header {
 	return &types.Header{}
 }
 
-func (n *mockBackend) BuildBlockFromTxs(ctx context.Context, buildArgs *suave.BuildBlockArgs, txs types.Transactions) (*types.Block, *big.Int, error) {
+func (n *mockBackend) BuildBlockFromTxs(ctx context.Context, buildArgs *suave.BuildBlockArgsV2, txs types.Transactions) (*types.Block, *big.Int, error) {
 	block := types.NewBlock(&types.Header{GasUsed: 1000, BaseFee: big.NewInt(1)}, txs, nil, nil, trie.NewStackTrie(nil))
 	return block, big.NewInt(11000), nil
 }
 
-func (n *mockBackend) BuildBlockFromBundles(ctx context.Context, buildArgs *suave.BuildBlockArgs, bundles []types.SBundle) (*types.Block, *big.Int, error) {
+func (n *mockBackend) BuildBlockFromBundles(ctx context.Context, buildArgs *suave.BuildBlockArgsV2, bundles []types.SBundle) (*types.Block, *big.Int, error) {
 	var txs types.Transactions
 	for _, bundle := range bundles {
 		txs = append(txs, bundle.Txs...)
@@ -53,6 +60,14 @@ func (n *mockBackend) BuildBlockFromBundles(ctx context.Context, buildArgs *suav
 	return block, big.NewInt(11000), nil
 }
 
+func (n *mockBackend) SimulateBundlesForBlock(ctx context.Context, buildArgs *suave.BuildBlockArgsV2, bundles []types.SBundle) ([]*miner.BundleSimulation, error) {
+	sims := make([]*miner.BundleSimulation, len(bundles))
+	for i := range bundles {
+		sims[i] = &miner.BundleSimulation{Hash: bundles[i].Hash(), GasUsed: 1000, Profit: big.NewInt(11000)}
+	}
+	return sims, nil
+}
+
 func (n *mockBackend) Call(ctx context.Context, contractAddr common.Address, input []byte) ([]byte, error) {
 	return []byte{0x1}, nil
 }
@@ -2,41 +2,18 @@ package backends
 
 import (
 	"context"
-	"math/big"
 
 	"github.com/ethereum/go-ethereum/beacon/engine"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/rpc"
 	suave "github.com/ethereum/go-ethereum/suave/core"
-	"github.com/ethereum/go-ethereum/trie"
 )
 
 var (
-	_ EthBackend = &EthMock{}
 	_ EthBackend = &RemoteEthBackend{}
 )
 
-type EthMock struct{}
-
-func (e *EthMock) BuildEthBlock(ctx context.Context, args *suave.BuildBlockArgs, txs types.Transactions) (*engine.ExecutionPayloadEnvelope, error) {
-	block := types.NewBlock(&types.Header{GasUsed: 1000}, txs, nil, nil, trie.NewStackTrie(nil))
-	return engine.BlockToExecutableData(block, big.NewInt(11000)), nil
-}
-
-func (e *EthMock) BuildEthBlockFromBundles(ctx context.Context, args *suave.BuildBlockArgs, bundles []types.SBundle) (*engine.ExecutionPayloadEnvelope, error) {
-	var txs types.Transactions
-	for _, bundle := range bundles {
-		txs = append(txs, bundle.Txs...)
-	}
-	block := types.NewBlock(&types.Header{GasUsed: 1000}, txs, nil, nil, trie.NewStackTrie(nil))
-	return engine.BlockToExecutableData(block, big.NewInt(11000)), nil
-}
-
-func (e *EthMock) Call(ctx context.Context, contractAddr common.Address, input []byte) ([]byte, error) {
-	return nil, nil
-}
-
 type RemoteEthBackend struct {
 	endpoint string
 	client   *rpc.Client
@@ -70,20 +47,27 @@ func (e *RemoteEthBackend) call(ctx context.Context, result interface{}, method
 	return nil
 }
 
-func (e *RemoteEthBackend) BuildEthBlock(ctx context.Context, args *suave.BuildBlockArgs, txs types.Transactions) (*engine.ExecutionPayloadEnvelope, error) {
+func (e *RemoteEthBackend) BuildEthBlock(ctx context.Context, args *suave.BuildBlockArgsV2, txs types.Transactions) (*engine.ExecutionPayloadEnvelope, error) {
 	var result engine.ExecutionPayloadEnvelope
 	err := e.call(ctx, &result, "suavex_buildEthBlock", args, txs)
 
 	return &result, err
 }
 
-func (e *RemoteEthBackend) BuildEthBlockFromBundles(ctx context.Context, args *suave.BuildBlockArgs, bundles []types.SBundle) (*engine.ExecutionPayloadEnvelope, error) {
+func (e *RemoteEthBackend) BuildEthBlockFromBundles(ctx context.Context, args *suave.BuildBlockArgsV2, bundles []types.SBundle) (*engine.ExecutionPayloadEnvelope, error) {
 	var result engine.ExecutionPayloadEnvelope
 	err := e.call(ctx, &result, "suavex_buildEthBlockFromBundles", args, bundles)
 
 	return &result, err
 }
 
+func (e *RemoteEthBackend) SimulateBundles(ctx context.Context, args *suave.BuildBlockArgsV2, bundles []types.SBundle) ([]*suave.SimulatedBundle, error) {
+	var result []*suave.SimulatedBundle
+	err := e.call(ctx, &result, "suavex_simulateBundles", args, bundles)
+
+	return result, err
+}
+
 func (e *RemoteEthBackend) Call(ctx context.Context, contractAddr common.Address, input []byte) ([]byte, error) {
 	var result []byte
 	err := e.call(ctx, &result, "suavex_call", contractAddr, input)
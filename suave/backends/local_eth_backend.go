@@ -0,0 +1,276 @@
+package backends
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum/beacon/engine"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/consensus/beacon"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/miner"
+	"github.com/ethereum/go-ethereum/params"
+	suave "github.com/ethereum/go-ethereum/suave/core"
+)
+
+var (
+	_ EthBackend              = &LocalEthBackend{}
+	_ EthBackendServerBackend = &LocalEthBackend{}
+	_ miner.Backend           = &LocalEthBackend{}
+)
+
+const (
+	// localCallGasCap and localCallTimeout bound the calls to the local chain
+	// like the calls served by a remote execution node.
+	localCallGasCap  = 100000
+	localCallTimeout = 5 * time.Second
+)
+
+// LocalEthBackend executes the blocks requested by the MEVM on an in-memory
+// chain of its own, with the same block building code as a remote execution
+// node. It lets contracts be developed against realistic results without
+// running a second node.
+type LocalEthBackend struct {
+	genesis    *core.Genesis
+	builderKey *ecdsa.PrivateKey
+	server     *EthBackendServer
+
+	chain  *core.BlockChain
+	txPool *txpool.TxPool
+	miner  *miner.Miner
+	mux    *event.TypeMux
+}
+
+// NewLocalEthBackend returns a backend executing blocks on a chain starting
+// at the genesis, DefaultLocalEthGenesis if nil. The proposer payments of
+// blocks built from bundles are signed with the builder key, an ephemeral one
+// if nil.
+func NewLocalEthBackend(genesis *core.Genesis, builderKey *ecdsa.PrivateKey) (*LocalEthBackend, error) {
+	if genesis == nil {
+		genesis = DefaultLocalEthGenesis()
+	}
+	e := &LocalEthBackend{genesis: genesis, builderKey: builderKey}
+	if err := e.start(); err != nil {
+		return nil, err
+	}
+	e.server = NewEthBackendServer(e)
+	return e, nil
+}
+
+// DefaultLocalEthGenesis returns the genesis of the local chain when none is
+// configured: a post-merge chain funding the developer account of the kettle.
+func DefaultLocalEthGenesis() *core.Genesis {
+	chainConfig := *params.AllEthashProtocolChanges
+	chainConfig.SuaveBlock = nil
+	chainConfig.TerminalTotalDifficulty = common.Big0
+	chainConfig.ShanghaiTime = new(uint64)
+
+	return &core.Genesis{
+		Config:     &chainConfig,
+		GasLimit:   miner.DefaultConfig.GasCeil,
+		BaseFee:    big.NewInt(params.InitialBaseFee),
+		Difficulty: common.Big0,
+		Alloc: core.GenesisAlloc{
+			common.HexToAddress("0xB5fEAfbDD752ad52Afb7e1bD2E40432A485bBB7F"): {Balance: new(big.Int).Mul(big.NewInt(params.Ether), big.NewInt(1000000))},
+		},
+	}
+}
+
+// LoadLocalEthGenesis reads the genesis of the local chain from a genesis
+// file, DefaultLocalEthGenesis if empty, and seeds it with the accounts of a
+// state dump as written by "geth dump", if set. Accounts of the dump replace
+// the ones of the genesis.
+func LoadLocalEthGenesis(genesisPath string, stateDumpPath string) (*core.Genesis, error) {
+	genesis := DefaultLocalEthGenesis()
+	if genesisPath != "" {
+		genesis = new(core.Genesis)
+		if err := readJSONFile(genesisPath, genesis); err != nil {
+			return nil, fmt.Errorf("invalid genesis file: %w", err)
+		}
+		if genesis.Config == nil {
+			return nil, fmt.Errorf("genesis file %s has no chain config", genesisPath)
+		}
+	}
+
+	if stateDumpPath != "" {
+		var dump state.Dump
+		if err := readJSONFile(stateDumpPath, &dump); err != nil {
+			return nil, fmt.Errorf("invalid state dump: %w", err)
+		}
+		if genesis.Alloc == nil {
+			genesis.Alloc = make(core.GenesisAlloc, len(dump.Accounts))
+		}
+		for addr, account := range dump.Accounts {
+			genesisAccount, err := dumpToGenesisAccount(account)
+			if err != nil {
+				return nil, fmt.Errorf("invalid account %s in state dump: %w", addr, err)
+			}
+			genesis.Alloc[addr] = genesisAccount
+		}
+	}
+	return genesis, nil
+}
+
+func dumpToGenesisAccount(account state.DumpAccount) (core.GenesisAccount, error) {
+	balance, ok := math.ParseBig256(account.Balance)
+	if !ok {
+		return core.GenesisAccount{}, fmt.Errorf("invalid balance %q", account.Balance)
+	}
+
+	genesisAccount := core.GenesisAccount{
+		Balance: balance,
+		Nonce:   account.Nonce,
+		Code:    account.Code,
+	}
+	if len(account.Storage) > 0 {
+		genesisAccount.Storage = make(map[common.Hash]common.Hash, len(account.Storage))
+		for key, value := range account.Storage {
+			// Dumps hold the values without leading zeroes nor prefix
+			genesisAccount.Storage[key] = common.HexToHash(value)
+		}
+	}
+	return genesisAccount, nil
+}
+
+func readJSONFile(path string, v interface{}) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return json.NewDecoder(file).Decode(v)
+}
+
+func (e *LocalEthBackend) start() error {
+	chainConfig := e.genesis.Config
+	consensusEngine := beacon.New(ethash.NewFaker())
+
+	chain, err := core.NewBlockChain(rawdb.NewMemoryDatabase(), nil, e.genesis, nil, consensusEngine, vm.Config{}, nil, nil)
+	if err != nil {
+		return fmt.Errorf("could not create the local chain: %w", err)
+	}
+
+	txPoolConfig := txpool.DefaultConfig
+	txPoolConfig.Journal = ""
+
+	e.chain = chain
+	e.txPool = txpool.NewTxPool(txPoolConfig, chainConfig, chain)
+	e.mux = new(event.TypeMux)
+
+	minerConfig := miner.DefaultConfig
+	minerConfig.BuilderSigningKey = e.builderKey
+	e.miner = miner.New(e, &minerConfig, chainConfig, e.mux, consensusEngine, func(*types.Header) bool { return false })
+
+	log.Info("Started local execution backend", "chainid", chainConfig.ChainID, "genesis", chain.Genesis().Hash(), "accounts", len(e.genesis.Alloc))
+	return nil
+}
+
+// Start implements node.Lifecycle, the local chain is created with the
+// backend.
+func (e *LocalEthBackend) Start() error {
+	return nil
+}
+
+// Stop implements node.Lifecycle, releasing the local chain.
+func (e *LocalEthBackend) Stop() error {
+	e.miner.Close()
+	e.txPool.Stop()
+	e.chain.Stop()
+	e.mux.Stop()
+	return nil
+}
+
+// BlockChain implements miner.Backend.
+func (e *LocalEthBackend) BlockChain() *core.BlockChain {
+	return e.chain
+}
+
+// TxPool implements miner.Backend, the pool of the local chain stays empty.
+func (e *LocalEthBackend) TxPool() *txpool.TxPool {
+	return e.txPool
+}
+
+func (e *LocalEthBackend) BuildEthBlock(ctx context.Context, args *types.BuildBlockArgsV2, txs types.Transactions) (*engine.ExecutionPayloadEnvelope, error) {
+	return e.server.BuildEthBlock(ctx, args, txs)
+}
+
+func (e *LocalEthBackend) BuildEthBlockFromBundles(ctx context.Context, args *types.BuildBlockArgsV2, bundles []types.SBundle) (*engine.ExecutionPayloadEnvelope, error) {
+	return e.server.BuildEthBlockFromBundles(ctx, args, bundles)
+}
+
+func (e *LocalEthBackend) SimulateBundles(ctx context.Context, args *types.BuildBlockArgsV2, bundles []types.SBundle) ([]*suave.SimulatedBundle, error) {
+	return e.server.SimulateBundles(ctx, args, bundles)
+}
+
+func (e *LocalEthBackend) CurrentHeader() *types.Header {
+	return e.chain.CurrentBlock()
+}
+
+func (e *LocalEthBackend) BuildBlockFromTxs(ctx context.Context, args *types.BuildBlockArgsV2, txs types.Transactions) (*types.Block, *big.Int, error) {
+	return e.miner.BuildBlockFromTxs(ctx, e.localBuildArgs(args), txs)
+}
+
+func (e *LocalEthBackend) BuildBlockFromBundles(ctx context.Context, args *types.BuildBlockArgsV2, bundles []types.SBundle) (*types.Block, *big.Int, error) {
+	return e.miner.BuildBlockFromBundles(ctx, e.localBuildArgs(args), bundles)
+}
+
+func (e *LocalEthBackend) SimulateBundlesForBlock(ctx context.Context, args *types.BuildBlockArgsV2, bundles []types.SBundle) ([]*miner.BundleSimulation, error) {
+	return e.miner.SimulateBundles(ctx, e.localBuildArgs(args), bundles)
+}
+
+// localBuildArgs adapts the arguments of a block meant for another chain to
+// the local one: blocks whose parent is not known locally are built on top of
+// the local head, after it.
+func (e *LocalEthBackend) localBuildArgs(args *types.BuildBlockArgsV2) *types.BuildBlockArgsV2 {
+	local := *args
+
+	parent := e.chain.GetHeaderByHash(args.Parent)
+	if parent == nil {
+		parent = e.chain.CurrentBlock()
+		log.Debug("Building local block on the head", "parent", args.Parent, "head", parent.Hash())
+		local.Parent = parent.Hash()
+	}
+	if local.Timestamp <= parent.Time {
+		local.Timestamp = parent.Time + 12
+	}
+	return &local
+}
+
+func (e *LocalEthBackend) Call(ctx context.Context, contractAddr common.Address, input []byte) ([]byte, error) {
+
+	head := e.chain.CurrentBlock()
+	statedb, err := e.chain.StateAt(head.Root)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, localCallTimeout)
+	defer cancel()
+
+	evm := vm.NewEVM(core.NewEVMBlockContext(head, e.chain, nil), vm.TxContext{}, statedb, e.chain.Config(), vm.Config{NoBaseFee: true})
+	go func() {
+		<-ctx.Done()
+		evm.Cancel()
+	}()
+
+	result, _, err := evm.Call(vm.AccountRef(common.Address{}), contractAddr, input, localCallGasCap, new(big.Int))
+	if evm.Cancelled() {
+		return nil, fmt.Errorf("execution aborted (timeout = %v)", localCallTimeout)
+	}
+	return result, err
+}
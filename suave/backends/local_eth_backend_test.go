@@ -0,0 +1,97 @@
+package backends
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalEthBackend(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+	contract := common.Address{0x42}
+
+	// Seed the local chain with a funded account and a contract returning
+	// its first storage slot
+	dump := state.Dump{
+		Accounts: map[common.Address]state.DumpAccount{
+			sender: {Balance: "1000000000000000000"},
+			contract: {
+				Balance: "0",
+				Code:    common.FromHex("0x60005460005260206000f3"),
+				Storage: map[common.Hash]string{{}: "2a"},
+			},
+		},
+	}
+	dumpPath := filepath.Join(t.TempDir(), "dump.json")
+	data, err := json.Marshal(&dump)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(dumpPath, data, 0600))
+
+	genesis, err := LoadLocalEthGenesis("", dumpPath)
+	require.NoError(t, err)
+
+	backend, err := NewLocalEthBackend(genesis, nil)
+	require.NoError(t, err)
+	defer backend.Stop()
+
+	result, err := backend.Call(context.Background(), contract, nil)
+	require.NoError(t, err)
+	require.Equal(t, common.BigToHash(big.NewInt(42)).Bytes(), result)
+
+	signer := types.LatestSigner(genesis.Config)
+	tx, err := types.SignTx(types.NewTx(&types.DynamicFeeTx{
+		ChainID:   genesis.Config.ChainID,
+		Nonce:     0,
+		GasTipCap: big.NewInt(10 * params.GWei),
+		GasFeeCap: big.NewInt(20 * params.GWei),
+		Gas:       21000,
+		To:        &common.Address{0x43},
+		Value:     big.NewInt(1),
+	}), signer, key)
+	require.NoError(t, err)
+
+	// The parent of the block is not known locally, it is built on the head
+	feeRecipient := common.Address{0x44}
+	envelope, err := backend.BuildEthBlockFromBundles(context.Background(), &types.BuildBlockArgsV2{
+		Parent:       common.Hash{0x01},
+		FeeRecipient: feeRecipient,
+		GasLimit:     30000000,
+	}, []types.SBundle{{Txs: types.Transactions{tx}}})
+	require.NoError(t, err)
+
+	payload := envelope.ExecutionPayload
+	require.Equal(t, uint64(1), payload.Number)
+	require.Len(t, payload.Transactions, 2)
+	// The bundle transaction and the payment to the fee recipient
+	require.Equal(t, uint64(42000), payload.GasUsed)
+	require.Equal(t, 1, envelope.BlockValue.Sign())
+
+	var payment types.Transaction
+	require.NoError(t, payment.UnmarshalBinary(payload.Transactions[1]))
+	require.Equal(t, feeRecipient, *payment.To())
+	require.Equal(t, envelope.BlockValue, payment.Value())
+
+	// A bundle that cannot be executed fails the block
+	_, err = backend.BuildEthBlockFromBundles(context.Background(), nil, []types.SBundle{{Txs: types.Transactions{tx, tx}}})
+	require.Error(t, err)
+}
+
+func TestLoadLocalEthGenesis_Default(t *testing.T) {
+	genesis, err := LoadLocalEthGenesis("", "")
+	require.NoError(t, err)
+	require.Equal(t, DefaultLocalEthGenesis(), genesis)
+
+	_, err = LoadLocalEthGenesis(filepath.Join(t.TempDir(), "missing.json"), "")
+	require.Error(t, err)
+}
@@ -0,0 +1,368 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// contractArtifact is a contract read from a plain ABI file or from a forge
+// artifact, which also holds the creation bytecode.
+type contractArtifact struct {
+	Abi      *abi.ABI
+	Bytecode []byte
+}
+
+func readContractArtifact(path string) (*contractArtifact, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		// plain abi file
+		parsed, err := abi.JSON(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		return &contractArtifact{Abi: &parsed}, nil
+	}
+
+	var artifactObj struct {
+		Abi      json.RawMessage `json:"abi"`
+		Bytecode struct {
+			Object string
+		} `json:"bytecode"`
+	}
+	if err := json.Unmarshal(data, &artifactObj); err != nil {
+		return nil, err
+	}
+	parsed, err := abi.JSON(bytes.NewReader(artifactObj.Abi))
+	if err != nil {
+		return nil, err
+	}
+
+	artifact := &contractArtifact{Abi: &parsed}
+	if artifactObj.Bytecode.Object != "" && artifactObj.Bytecode.Object != "0x" {
+		if artifact.Bytecode, err = hexutil.Decode(artifactObj.Bytecode.Object); err != nil {
+			return nil, fmt.Errorf("invalid bytecode: %w", err)
+		}
+	}
+	return artifact, nil
+}
+
+// parseAbiArgs converts the JSON arguments to the Go values abi.Pack expects.
+// The arguments are either a JSON array in the order of the ABI or a JSON
+// object keyed by argument name. Integers are JSON numbers or decimal or hex
+// strings, bytes are hex strings and tuples are arrays or objects.
+func parseAbiArgs(args abi.Arguments, data string) ([]interface{}, error) {
+	if strings.TrimSpace(data) == "" {
+		data = "[]"
+	}
+
+	raw, err := jsonElements(json.RawMessage(data), abiArgNames(args))
+	if err != nil {
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	values := make([]interface{}, len(args))
+	for i, arg := range args {
+		v, err := abiValueFromJSON(arg.Type, raw[i])
+		if err != nil {
+			return nil, fmt.Errorf("argument %s: %w", abiArgName(arg.Name, i), err)
+		}
+		values[i] = v.Interface()
+	}
+	return values, nil
+}
+
+func abiArgNames(args abi.Arguments) []string {
+	names := make([]string, len(args))
+	for i, arg := range args {
+		names[i] = arg.Name
+	}
+	return names
+}
+
+func abiArgName(name string, i int) string {
+	if name == "" {
+		return fmt.Sprintf("#%d", i)
+	}
+	return name
+}
+
+// jsonElements returns the elements of a JSON array, or the values of a JSON
+// object in the order of the names. The number of elements must match.
+func jsonElements(data json.RawMessage, names []string) ([]json.RawMessage, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '{' {
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(data, &obj); err != nil {
+			return nil, err
+		}
+		elems := make([]json.RawMessage, len(names))
+		for i, name := range names {
+			elem, ok := obj[name]
+			if !ok {
+				return nil, fmt.Errorf("missing field %s", abiArgName(name, i))
+			}
+			elems[i] = elem
+		}
+		if len(obj) != len(names) {
+			return nil, fmt.Errorf("expected fields %s", strings.Join(names, ", "))
+		}
+		return elems, nil
+	}
+
+	var elems []json.RawMessage
+	if err := json.Unmarshal(data, &elems); err != nil {
+		return nil, err
+	}
+	if len(elems) != len(names) {
+		return nil, fmt.Errorf("expected %d values, got %d", len(names), len(elems))
+	}
+	return elems, nil
+}
+
+func abiValueFromJSON(typ abi.Type, raw json.RawMessage) (reflect.Value, error) {
+	switch typ.T {
+	case abi.IntTy, abi.UintTy:
+		n, err := parseJSONInteger(raw)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if typ.T == abi.UintTy && n.Sign() < 0 {
+			return reflect.Value{}, fmt.Errorf("negative value %v for %s", n, typ)
+		}
+		bits, maxBits := n.BitLen(), typ.Size
+		if typ.T == abi.IntTy {
+			// -2^(size-1) is the smallest value, with the sign taking a bit
+			maxBits--
+			if n.Sign() < 0 {
+				bits = new(big.Int).Add(n, common.Big1).BitLen()
+			}
+		}
+		if bits > maxBits {
+			return reflect.Value{}, fmt.Errorf("value %v overflows %s", n, typ)
+		}
+		if typ.Size > 64 {
+			return reflect.ValueOf(n), nil
+		}
+		v := reflect.New(typ.GetType()).Elem()
+		if typ.T == abi.UintTy {
+			v.SetUint(n.Uint64())
+		} else {
+			v.SetInt(n.Int64())
+		}
+		return v, nil
+
+	case abi.BoolTy:
+		var b bool
+		if err := json.Unmarshal(raw, &b); err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(b), nil
+
+	case abi.StringTy:
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(s), nil
+
+	case abi.AddressTy:
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return reflect.Value{}, err
+		}
+		if !common.IsHexAddress(s) {
+			return reflect.Value{}, fmt.Errorf("invalid address %q", s)
+		}
+		return reflect.ValueOf(common.HexToAddress(s)), nil
+
+	case abi.BytesTy:
+		var b hexutil.Bytes
+		if err := json.Unmarshal(raw, &b); err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf([]byte(b)), nil
+
+	case abi.FixedBytesTy, abi.HashTy:
+		var b hexutil.Bytes
+		if err := json.Unmarshal(raw, &b); err != nil {
+			return reflect.Value{}, err
+		}
+		v := reflect.New(typ.GetType()).Elem()
+		if len(b) != v.Len() {
+			return reflect.Value{}, fmt.Errorf("expected %d bytes, got %d", v.Len(), len(b))
+		}
+		reflect.Copy(v, reflect.ValueOf([]byte(b)))
+		return v, nil
+
+	case abi.SliceTy, abi.ArrayTy:
+		var elems []json.RawMessage
+		if err := json.Unmarshal(raw, &elems); err != nil {
+			return reflect.Value{}, err
+		}
+		var v reflect.Value
+		if typ.T == abi.SliceTy {
+			v = reflect.MakeSlice(typ.GetType(), len(elems), len(elems))
+		} else {
+			if len(elems) != typ.Size {
+				return reflect.Value{}, fmt.Errorf("expected %d elements, got %d", typ.Size, len(elems))
+			}
+			v = reflect.New(typ.GetType()).Elem()
+		}
+		for i, elem := range elems {
+			ev, err := abiValueFromJSON(*typ.Elem, elem)
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("element %d: %w", i, err)
+			}
+			v.Index(i).Set(ev)
+		}
+		return v, nil
+
+	case abi.TupleTy:
+		elems, err := jsonElements(raw, typ.TupleRawNames)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		v := reflect.New(typ.GetType()).Elem()
+		for i, elemTyp := range typ.TupleElems {
+			ev, err := abiValueFromJSON(*elemTyp, elems[i])
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("field %s: %w", abiArgName(typ.TupleRawNames[i], i), err)
+			}
+			v.Field(i).Set(ev)
+		}
+		return v, nil
+
+	default:
+		return reflect.Value{}, fmt.Errorf("unsupported type %s", typ)
+	}
+}
+
+func parseJSONInteger(raw json.RawMessage) (*big.Int, error) {
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		// not a string, must be a number
+		var num json.Number
+		if err := json.Unmarshal(raw, &num); err != nil {
+			return nil, err
+		}
+		s = num.String()
+	}
+
+	n, ok := new(big.Int).SetString(s, 0)
+	if !ok {
+		return nil, fmt.Errorf("invalid integer %q", s)
+	}
+	return n, nil
+}
+
+// formatAbiValues returns the unpacked values keyed by argument name, ready
+// to be printed as JSON.
+func formatAbiValues(args abi.Arguments, values []interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(values))
+	for i, value := range values {
+		out[abiArgName(args[i].Name, i)] = formatAbiValue(args[i].Type, reflect.ValueOf(value))
+	}
+	return out
+}
+
+func formatAbiValue(typ abi.Type, v reflect.Value) interface{} {
+	switch typ.T {
+	case abi.IntTy, abi.UintTy:
+		// numbers are printed without losing precision
+		return json.Number(fmt.Sprint(v.Interface()))
+	case abi.BytesTy:
+		return hexutil.Bytes(v.Bytes())
+	case abi.FixedBytesTy, abi.HashTy:
+		b := make([]byte, v.Len())
+		reflect.Copy(reflect.ValueOf(b), v)
+		return hexutil.Bytes(b)
+	case abi.SliceTy, abi.ArrayTy:
+		elems := make([]interface{}, v.Len())
+		for i := range elems {
+			elems[i] = formatAbiValue(*typ.Elem, v.Index(i))
+		}
+		return elems
+	case abi.TupleTy:
+		fields := make(map[string]interface{}, len(typ.TupleElems))
+		for i, elemTyp := range typ.TupleElems {
+			fields[abiArgName(typ.TupleRawNames[i], i)] = formatAbiValue(*elemTyp, v.Field(i))
+		}
+		return fields
+	default:
+		return v.Interface()
+	}
+}
+
+// decodedEvent is a log decoded with the ABI of the contract.
+type decodedEvent struct {
+	Address common.Address         `json:"address"`
+	Event   string                 `json:"event,omitempty"`
+	Args    map[string]interface{} `json:"args,omitempty"`
+	Topics  []common.Hash          `json:"topics,omitempty"`
+	Data    hexutil.Bytes          `json:"data,omitempty"`
+}
+
+// decodeLogs decodes the logs of events in the ABI, other logs are kept raw.
+func decodeLogs(contractAbi *abi.ABI, logs []*types.Log) []*decodedEvent {
+	events := make([]*decodedEvent, 0, len(logs))
+	for _, log := range logs {
+		events = append(events, decodeLog(contractAbi, log))
+	}
+	return events
+}
+
+func decodeLog(contractAbi *abi.ABI, log *types.Log) *decodedEvent {
+	raw := &decodedEvent{Address: log.Address, Topics: log.Topics, Data: log.Data}
+	if len(log.Topics) == 0 {
+		return raw
+	}
+	event, err := contractAbi.EventByID(log.Topics[0])
+	if err != nil {
+		return raw
+	}
+
+	var indexed, nonIndexed abi.Arguments
+	for _, arg := range event.Inputs {
+		if arg.Indexed {
+			indexed = append(indexed, arg)
+		} else {
+			nonIndexed = append(nonIndexed, arg)
+		}
+	}
+
+	values, err := nonIndexed.Unpack(log.Data)
+	if err != nil {
+		return raw
+	}
+	args := formatAbiValues(nonIndexed, values)
+
+	topics := make(map[string]interface{})
+	if err := abi.ParseTopicsIntoMap(topics, indexed, log.Topics[1:]); err != nil {
+		return raw
+	}
+	for i, arg := range indexed {
+		name := abiArgName(arg.Name, i)
+		if arg.Type.T == abi.TupleTy || arg.Type.T == abi.SliceTy || arg.Type.T == abi.ArrayTy || arg.Type.T == abi.StringTy || arg.Type.T == abi.BytesTy {
+			// dynamic indexed values are only available as their hash
+			args[name] = topics[arg.Name]
+			continue
+		}
+		args[name] = formatAbiValue(arg.Type, reflect.ValueOf(topics[arg.Name]))
+	}
+	return &decodedEvent{Address: log.Address, Event: event.Name, Args: args}
+}
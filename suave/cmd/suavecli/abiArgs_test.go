@@ -0,0 +1,163 @@
+package main
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+const testAbiArgsAbi = `[{
+	"type": "function",
+	"name": "f",
+	"inputs": [
+		{"name": "u8", "type": "uint8"},
+		{"name": "i64", "type": "int64"},
+		{"name": "u256", "type": "uint256"},
+		{"name": "i256", "type": "int256"},
+		{"name": "ok", "type": "bool"},
+		{"name": "name", "type": "string"},
+		{"name": "addr", "type": "address"},
+		{"name": "data", "type": "bytes"},
+		{"name": "b4", "type": "bytes4"},
+		{"name": "b32", "type": "bytes32"},
+		{"name": "list", "type": "uint64[]"},
+		{"name": "pair", "type": "address[2]"},
+		{"name": "order", "type": "tuple", "components": [
+			{"name": "id", "type": "bytes16"},
+			{"name": "amounts", "type": "uint256[]"},
+			{"name": "inner", "type": "tuple", "components": [{"name": "flag", "type": "bool"}]}
+		]},
+		{"name": "orders", "type": "tuple[]", "components": [{"name": "amount", "type": "uint128"}]}
+	],
+	"outputs": []
+}]`
+
+func TestParseAbiArgs(t *testing.T) {
+	parsed, err := abi.JSON(strings.NewReader(testAbiArgsAbi))
+	require.NoError(t, err)
+	args := parsed.Methods["f"].Inputs
+
+	maxUint256 := new(big.Int).Sub(new(big.Int).Lsh(common.Big1, 256), common.Big1)
+	minInt256 := new(big.Int).Neg(new(big.Int).Lsh(common.Big1, 255))
+
+	want := map[string]interface{}{
+		"u8":   json.Number("255"),
+		"i64":  json.Number("-9223372036854775808"),
+		"u256": json.Number(maxUint256.String()),
+		"i256": json.Number(minInt256.String()),
+		"ok":   true,
+		"name": "suave",
+		"addr": common.HexToAddress("0x00000000000000000000000000000000000000aa"),
+		"data": "0x0102",
+		"b4":   "0xdeadbeef",
+		"b32":  common.Hash{0x01}.Hex(),
+		"list": []interface{}{json.Number("1"), json.Number("2")},
+		"pair": []interface{}{common.HexToAddress("0x01"), common.HexToAddress("0x02")},
+		"order": map[string]interface{}{
+			"id":      "0x000102030405060708090a0b0c0d0e0f",
+			"amounts": []interface{}{json.Number("3")},
+			"inner":   map[string]interface{}{"flag": true},
+		},
+		"orders": []interface{}{
+			map[string]interface{}{"amount": json.Number("4")},
+			map[string]interface{}{"amount": json.Number("5")},
+		},
+	}
+
+	cases := []struct {
+		name string
+		data string
+	}{
+		{
+			name: "array",
+			data: `[255, "-9223372036854775808", "` + hexBig(maxUint256) + `", "` + minInt256.String() + `", true, "suave",
+				"0x00000000000000000000000000000000000000aa", "0x0102", "0xdeadbeef", "` + common.Hash{0x01}.Hex() + `",
+				[1, "0x2"], ["0x0000000000000000000000000000000000000001", "0x0000000000000000000000000000000000000002"],
+				["0x000102030405060708090a0b0c0d0e0f", [3], [true]], [[4], {"amount": 5}]]`,
+		},
+		{
+			name: "named object",
+			data: `{"u8": "0xff", "i64": -9223372036854775808, "u256": "` + maxUint256.String() + `", "i256": "` + minInt256.String() + `",
+				"ok": true, "name": "suave", "addr": "0x00000000000000000000000000000000000000aa", "data": "0x0102",
+				"b4": "0xdeadbeef", "b32": "` + common.Hash{0x01}.Hex() + `", "list": ["1", 2],
+				"pair": ["0x0000000000000000000000000000000000000001", "0x0000000000000000000000000000000000000002"],
+				"order": {"inner": {"flag": true}, "amounts": ["3"], "id": "0x000102030405060708090a0b0c0d0e0f"},
+				"orders": [{"amount": "4"}, [5]]}`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			values, err := parseAbiArgs(args, c.data)
+			require.NoError(t, err)
+
+			packed, err := args.Pack(values...)
+			require.NoError(t, err)
+			unpacked, err := args.Unpack(packed)
+			require.NoError(t, err)
+
+			// The values come back the way they were given, through JSON
+			formatted, err := json.Marshal(formatAbiValues(args, unpacked))
+			require.NoError(t, err)
+			expected, err := json.Marshal(want)
+			require.NoError(t, err)
+			require.JSONEq(t, string(expected), string(formatted))
+		})
+	}
+}
+
+func TestParseAbiArgs_Errors(t *testing.T) {
+	parsed, err := abi.JSON(strings.NewReader(`[{
+		"type": "function",
+		"name": "f",
+		"inputs": [
+			{"name": "u8", "type": "uint8"},
+			{"name": "i8", "type": "int8"},
+			{"name": "b2", "type": "bytes2"},
+			{"name": "pair", "type": "uint8[2]"},
+			{"name": "t", "type": "tuple", "components": [{"name": "a", "type": "address"}]}
+		],
+		"outputs": []
+	}]`))
+	require.NoError(t, err)
+	args := parsed.Methods["f"].Inputs
+
+	cases := []struct {
+		data string
+		err  string
+	}{
+		{`[256, 0, "0x0102", [1, 2], ["0x0000000000000000000000000000000000000001"]]`, "argument u8: value 256 overflows uint8"},
+		{`[-1, 0, "0x0102", [1, 2], ["0x0000000000000000000000000000000000000001"]]`, "argument u8: negative value -1 for uint8"},
+		{`[0, 128, "0x0102", [1, 2], ["0x0000000000000000000000000000000000000001"]]`, "argument i8: value 128 overflows int8"},
+		{`[0, -129, "0x0102", [1, 2], ["0x0000000000000000000000000000000000000001"]]`, "argument i8: value -129 overflows int8"},
+		{`[0, "1.5", "0x0102", [1, 2], ["0x0000000000000000000000000000000000000001"]]`, `argument i8: invalid integer "1.5"`},
+		{`[0, 0, "0x010203", [1, 2], ["0x0000000000000000000000000000000000000001"]]`, "argument b2: expected 2 bytes, got 3"},
+		{`[0, 0, "0x0102", [1], ["0x0000000000000000000000000000000000000001"]]`, "argument pair: expected 2 elements, got 1"},
+		{`[0, 0, "0x0102", [1, 256], ["0x0000000000000000000000000000000000000001"]]`, "argument pair: element 1: value 256 overflows uint8"},
+		{`[0, 0, "0x0102", [1, 2], ["0x01"]]`, `argument t: field a: invalid address "0x01"`},
+		{`[0, 0, "0x0102", [1, 2], {"b": "0x0000000000000000000000000000000000000001"}]`, "argument t: missing field a"},
+		{`[0, 0, "0x0102", [1, 2]]`, "invalid arguments: expected 5 values, got 4"},
+		{`{"u8": 0, "i8": 0, "b2": "0x0102", "pair": [1, 2]}`, "invalid arguments: missing field t"},
+		{`{"u8": 0, "i8": 0, "b2": "0x0102", "pair": [1, 2], "t": ["0x0000000000000000000000000000000000000001"], "x": 1}`, "invalid arguments: expected fields u8, i8, b2, pair, t"},
+	}
+
+	for _, c := range cases {
+		_, err := parseAbiArgs(args, c.data)
+		require.EqualError(t, err, c.err, c.data)
+	}
+}
+
+func TestParseAbiArgs_Empty(t *testing.T) {
+	values, err := parseAbiArgs(abi.Arguments{}, "")
+	require.NoError(t, err)
+	require.Empty(t, values)
+}
+
+func hexBig(n *big.Int) string {
+	return "0x" + n.Text(16)
+}
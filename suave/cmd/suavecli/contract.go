@@ -0,0 +1,234 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/suave/sdk"
+)
+
+// contractFlags are the flags shared by the generic contract commands.
+type contractFlags struct {
+	flagset *flag.FlagSet
+
+	suaveRpc         *string
+	kettleAddressHex *string
+	privKeyHex       *string
+	artifactPath     *string
+	argsJSON         *string
+	verbosity        *int
+}
+
+func newContractFlags(name string) *contractFlags {
+	flagset := flag.NewFlagSet(name, flag.ExitOnError)
+	return &contractFlags{
+		flagset:          flagset,
+		suaveRpc:         flagset.String("suave_rpc", "http://127.0.0.1:8545", "address of suave rpc"),
+		kettleAddressHex: flagset.String("kettleAddress", "0x4E2B0c0e428AE1CDE26d5BcF17Ba83f447068E5B", "wallet address of execution node"),
+		privKeyHex:       flagset.String("privkey", "", "private key as hex (for testing)"),
+		artifactPath:     flagset.String("artifact", "", "path to the contract abi or forge artifact"),
+		argsJSON:         flagset.String("args", "", "arguments as a json array, or a json object keyed by argument name"),
+		verbosity:        flagset.Int("verbosity", int(log.LvlInfo), "log verbosity (0-5)"),
+	}
+}
+
+// setup parses the flags and returns the contract artifact and a client
+// connected to the suave node. A random key is used if none is provided.
+func (f *contractFlags) setup() (*contractArtifact, *sdk.Client) {
+	f.flagset.Parse(os.Args[2:])
+
+	glogger := log.NewGlogHandler(log.StreamHandler(os.Stderr, log.TerminalFormat(false)))
+	log.Root().SetHandler(glogger)
+	glogger.Verbosity(log.Lvl(*f.verbosity))
+
+	if *f.artifactPath == "" {
+		utils.Fatalf("please provide the contract -artifact")
+	}
+	artifact, err := readContractArtifact(*f.artifactPath)
+	RequireNoErrorf(err, "could not read artifact %s: %v", *f.artifactPath, err)
+
+	var privKey *ecdsa.PrivateKey
+	if *f.privKeyHex == "" {
+		privKey, err = crypto.GenerateKey()
+		RequireNoError(err)
+	} else {
+		privKey, err = crypto.HexToECDSA(*f.privKeyHex)
+		RequireNoErrorf(err, "-privkey: %v", err)
+	}
+
+	if *f.kettleAddressHex == "" {
+		utils.Fatalf("please provide kettleAddress")
+	}
+	kettleAddress := common.HexToAddress(*f.kettleAddressHex)
+
+	suaveClient, err := rpc.DialContext(context.TODO(), *f.suaveRpc)
+	RequireNoErrorf(err, "could not connect to suave rpc: %v", err)
+
+	return artifact, sdk.NewClient(suaveClient, privKey, kettleAddress)
+}
+
+func (f *contractFlags) contract(artifact *contractArtifact, client *sdk.Client, addrHex string) *sdk.Contract {
+	if !common.IsHexAddress(addrHex) {
+		utils.Fatalf("please provide a valid contract address, got %q", addrHex)
+	}
+	return sdk.GetContract(common.HexToAddress(addrHex), artifact.Abi, client)
+}
+
+func (f *contractFlags) methodArgs(artifact *contractArtifact, methodName string) []interface{} {
+	method, ok := artifact.Abi.Methods[methodName]
+	if !ok {
+		utils.Fatalf("method %s not found in the abi", methodName)
+	}
+	args, err := parseAbiArgs(method.Inputs, *f.argsJSON)
+	RequireNoErrorf(err, "could not parse the arguments of %s: %v", methodName, err)
+	return args
+}
+
+func cmdCall() {
+	flags := newContractFlags("call")
+	var (
+		contractAddr = flags.flagset.String("contract", "", "address of the contract")
+		methodName   = flags.flagset.String("method", "", "name of the method to call")
+	)
+	artifact, client := flags.setup()
+	contract := flags.contract(artifact, client, *contractAddr)
+	args := flags.methodArgs(artifact, *methodName)
+
+	outputs, err := contract.Call(*methodName, args)
+	RequireNoErrorf(err, "could not call %s: %v", *methodName, unwrapPeekerError(err))
+
+	printJSON(formatAbiValues(artifact.Abi.Methods[*methodName].Outputs, outputs))
+}
+
+func cmdSend() {
+	flags := newContractFlags("send")
+	var (
+		contractAddr          = flags.flagset.String("contract", "", "address of the contract")
+		methodName            = flags.flagset.String("method", "", "name of the method to send the confidential compute request to")
+		confidentialInputPath = flags.flagset.String("confidential_input", "", "file with the confidential inputs, - to read them from stdin")
+	)
+	artifact, client := flags.setup()
+	contract := flags.contract(artifact, client, *contractAddr)
+	args := flags.methodArgs(artifact, *methodName)
+
+	var confidentialInput []byte
+	if *confidentialInputPath != "" {
+		var err error
+		confidentialInput, err = readConfidentialInput(*confidentialInputPath)
+		RequireNoErrorf(err, "could not read the confidential inputs: %v", err)
+	}
+
+	txnResult, err := contract.SendTransaction(*methodName, args, confidentialInput)
+	RequireNoErrorf(err, "could not send the confidential compute request: %v", unwrapPeekerError(err))
+	log.Info("Sent confidential compute request", "hash", txnResult.Hash())
+
+	receipt, err := txnResult.Wait()
+	RequireNoErrorf(err, "could not get the receipt of %s: %v", txnResult.Hash(), err)
+
+	output := struct {
+		Hash             common.Hash     `json:"hash"`
+		BlockNumber      *hexutil.Big    `json:"blockNumber"`
+		Status           hexutil.Uint64  `json:"status"`
+		ComputeResult    interface{}     `json:"computeResult,omitempty"`
+		Events           []*decodedEvent `json:"events"`
+		ConfidentialLogs []*decodedEvent `json:"confidentialLogs,omitempty"`
+	}{
+		Hash:        txnResult.Hash(),
+		BlockNumber: (*hexutil.Big)(receipt.BlockNumber),
+		Status:      hexutil.Uint64(receipt.Status),
+		Events:      decodeLogs(artifact.Abi, receipt.Logs),
+	}
+
+	if result, err := txnResult.ConfidentialComputeResult(); err != nil {
+		log.Warn("Could not get the confidential compute result", "err", err)
+	} else {
+		output.ComputeResult = decodeComputeResult(artifact, result)
+	}
+
+	if logs, err := txnResult.ConfidentialLogs(); err != nil {
+		log.Debug("Could not get the confidential logs", "err", err)
+	} else {
+		output.ConfidentialLogs = decodeLogs(artifact.Abi, logs)
+	}
+
+	printJSON(output)
+}
+
+func cmdDeploy() {
+	flags := newContractFlags("deploy")
+	artifact, client := flags.setup()
+	if len(artifact.Bytecode) == 0 {
+		utils.Fatalf("the artifact %s has no bytecode, a forge artifact is required", *flags.artifactPath)
+	}
+
+	args, err := parseAbiArgs(artifact.Abi.Constructor.Inputs, *flags.argsJSON)
+	RequireNoErrorf(err, "could not parse the constructor arguments: %v", err)
+	packedArgs, err := artifact.Abi.Pack("", args...)
+	RequireNoErrorf(err, "could not pack the constructor arguments: %v", err)
+
+	bytecode := append(append([]byte{}, artifact.Bytecode...), packedArgs...)
+	txnResult, err := sdk.DeployContract(bytecode, client)
+	RequireNoErrorf(err, "could not deploy the contract: %v", err)
+	log.Info("Sent contract deployment", "hash", txnResult.Hash())
+
+	receipt, err := txnResult.Wait()
+	RequireNoErrorf(err, "could not get the receipt of %s: %v", txnResult.Hash(), err)
+	if receipt.Status != 1 {
+		utils.Fatalf("contract deployment %s failed", txnResult.Hash())
+	}
+
+	printJSON(struct {
+		Hash    common.Hash    `json:"hash"`
+		Address common.Address `json:"address"`
+	}{
+		Hash:    txnResult.Hash(),
+		Address: receipt.ContractAddress,
+	})
+}
+
+// decodeComputeResult decodes the callback the confidential computation
+// returned, keeping it raw if it is not a method of the contract.
+func decodeComputeResult(artifact *contractArtifact, result []byte) interface{} {
+	if len(result) < 4 {
+		return hexutil.Bytes(result)
+	}
+	method, err := artifact.Abi.MethodById(result)
+	if err != nil {
+		return hexutil.Bytes(result)
+	}
+	values, err := method.Inputs.Unpack(result[4:])
+	if err != nil {
+		return hexutil.Bytes(result)
+	}
+	return struct {
+		Method string                 `json:"method"`
+		Args   map[string]interface{} `json:"args"`
+	}{
+		Method: method.Name,
+		Args:   formatAbiValues(method.Inputs, values),
+	}
+}
+
+func readConfidentialInput(path string) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(path)
+}
+
+func printJSON(v interface{}) {
+	out, err := json.MarshalIndent(v, "", "  ")
+	RequireNoError(err)
+	fmt.Println(string(out))
+}
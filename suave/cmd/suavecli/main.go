@@ -20,6 +20,10 @@ import (
 )
 
 var commands = map[string]func(){
+	// generic contract interaction
+	"call":   cmdCall,
+	"send":   cmdSend,
+	"deploy": cmdDeploy,
 	// deploy
 	"deployBlockSenderContract": cmdDeployBlockSenderContract,
 	"deployMevShareContract":    cmdDeployMevShareContract,
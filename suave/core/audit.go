@@ -0,0 +1,363 @@
+package suave
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// AuditRecord is the evidence of the confidential execution of a request by
+// the kettle: what it was asked, what the precompiles answered, including the
+// responses of the relay and of the execution backend, and what it read from
+// and wrote to the confidential store.
+type AuditRecord struct {
+	// Hash of the confidential compute request
+	RequestHash common.Hash `json:"requestHash"`
+	// Kettle address the request was sent to, whose key signs the record
+	Kettle common.Address `json:"kettle"`
+	// Hash of the SuaveTransaction submitted for the request, zero if the
+	// execution failed
+	TxHash common.Hash `json:"txHash"`
+	// Unix time of the execution
+	Time uint64 `json:"time"`
+	// Block whose state the request was executed on
+	BlockNumber uint64      `json:"blockNumber"`
+	BlockHash   common.Hash `json:"blockHash"`
+	// The request as received, with its confidential inputs in plaintext
+	Request            hexutil.Bytes `json:"request"`
+	ConfidentialInputs hexutil.Bytes `json:"confidentialInputs"`
+	SuaveTransaction   hexutil.Bytes `json:"suaveTransaction,omitempty"`
+	Error              string        `json:"error,omitempty"`
+
+	PrecompileCalls []*PrecompileCall `json:"precompileCalls"`
+	StoreReads      []*StoreAccess    `json:"storeReads"`
+	StoreWrites     []*StoreAccess    `json:"storeWrites"`
+}
+
+// PrecompileCall is a call of the MEVM to a SUAVE precompile.
+type PrecompileCall struct {
+	Address common.Address `json:"address"`
+	Input   hexutil.Bytes  `json:"input"`
+	Output  hexutil.Bytes  `json:"output"`
+	Error   string         `json:"error,omitempty"`
+	// Whether the precompile reverted, which leaves the caller its gas
+	Reverted bool `json:"reverted,omitempty"`
+}
+
+// StoreAccess is a value read from or written to the confidential store.
+type StoreAccess struct {
+	BidId  BidId          `json:"bidId"`
+	Caller common.Address `json:"caller"`
+	Key    string         `json:"key"`
+	Value  hexutil.Bytes  `json:"value"`
+}
+
+// AddPrecompileCall records a call to a precompile, nothing if the record is
+// nil.
+func (r *AuditRecord) AddPrecompileCall(addr common.Address, input []byte, output []byte, err error, reverted bool) {
+	if r == nil {
+		return
+	}
+	call := &PrecompileCall{
+		Address:  addr,
+		Input:    common.CopyBytes(input),
+		Output:   common.CopyBytes(output),
+		Reverted: reverted,
+	}
+	if err != nil {
+		call.Error = err.Error()
+	}
+	r.PrecompileCalls = append(r.PrecompileCalls, call)
+}
+
+// AddStoreRead records a value retrieved from the confidential store, nothing
+// if the record is nil.
+func (r *AuditRecord) AddStoreRead(bidId BidId, caller common.Address, key string, value []byte) {
+	if r == nil {
+		return
+	}
+	r.StoreReads = append(r.StoreReads, &StoreAccess{BidId: bidId, Caller: caller, Key: key, Value: common.CopyBytes(value)})
+}
+
+// AddStoreWrite records a value stored in the confidential store, nothing if
+// the record is nil.
+func (r *AuditRecord) AddStoreWrite(bidId BidId, caller common.Address, key string, value []byte) {
+	if r == nil {
+		return
+	}
+	r.StoreWrites = append(r.StoreWrites, &StoreAccess{BidId: bidId, Caller: caller, Key: key, Value: common.CopyBytes(value)})
+}
+
+// AuditReplay serves the precompile calls of a recorded execution in the
+// order they were made, so that the execution can be repeated without
+// reaching the store nor any external service.
+type AuditReplay struct {
+	calls []*PrecompileCall
+	next  int
+	err   error
+}
+
+// NewAuditReplay returns a replay of the precompile calls of the record.
+func NewAuditReplay(record *AuditRecord) *AuditReplay {
+	return &AuditReplay{calls: record.PrecompileCalls}
+}
+
+// Next returns the recorded call answering a call to the precompile, an error
+// if the execution diverged from the recorded one.
+func (r *AuditReplay) Next(addr common.Address, input []byte) (*PrecompileCall, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	if r.next >= len(r.calls) {
+		r.err = fmt.Errorf("replay diverged: unrecorded call %d to precompile %s", r.next, addr)
+		return nil, r.err
+	}
+
+	call := r.calls[r.next]
+	if call.Address != addr || !bytes.Equal(call.Input, input) {
+		r.err = fmt.Errorf("replay diverged: call %d to precompile %s does not match the recorded call to %s", r.next, addr, call.Address)
+		return nil, r.err
+	}
+	r.next++
+	return call, nil
+}
+
+// Done returns an error if the execution diverged from the recorded one or
+// did not make all the recorded calls.
+func (r *AuditReplay) Done() error {
+	if r.err != nil {
+		return r.err
+	}
+	if r.next != len(r.calls) {
+		return fmt.Errorf("replay diverged: %d of %d recorded precompile calls made", r.next, len(r.calls))
+	}
+	return nil
+}
+
+// AuditLogSigner signs the audit records with the key of the kettle that
+// executed them.
+type AuditLogSigner interface {
+	Sign(account common.Address, data []byte) ([]byte, error)
+}
+
+// auditLogEntry is a line of the audit log. Every record is signed by its
+// kettle together with the hash of the previous entry, so that records cannot
+// be altered, dropped or reordered without breaking the chain.
+type auditLogEntry struct {
+	Record    json.RawMessage `json:"record"`
+	PrevHash  common.Hash     `json:"prevHash"`
+	Signature hexutil.Bytes   `json:"signature"`
+}
+
+// signingPayload is what the kettle signs, its hash is the one the next entry
+// is chained to.
+func (e *auditLogEntry) signingPayload() []byte {
+	return append(e.PrevHash.Bytes(), e.Record...)
+}
+
+// AuditLog appends the audit records of a kettle to a file, one JSON entry
+// per line. With an encryption key, every line is sealed with AES-GCM and
+// base64 encoded. Records are never rewritten.
+type AuditLog struct {
+	lock     sync.Mutex
+	file     *os.File
+	aead     cipher.AEAD
+	signer   AuditLogSigner
+	lastHash common.Hash
+}
+
+// OpenAuditLog opens the audit log at path for appending, creating it if it
+// does not exist. The records are encrypted if key, a 16, 24 or 32 bytes AES
+// key, is set, and signed with signer. The entries already in the log are
+// verified, new ones are chained to the last of them.
+func OpenAuditLog(path string, key []byte, signer AuditLogSigner) (*AuditLog, error) {
+	aead, err := auditLogCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("could not open the audit log: %w", err)
+	}
+	lastHash, err := readAuditLog(file, aead, func(*AuditRecord) error { return nil })
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("could not verify the audit log: %w", err)
+	}
+	return &AuditLog{file: file, aead: aead, signer: signer, lastHash: lastHash}, nil
+}
+
+func auditLogCipher(key []byte) (cipher.AEAD, error) {
+	if len(key) == 0 {
+		return nil, nil
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid audit log encryption key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// Append signs the record with the key of its kettle, writes it at the end of
+// the log and syncs it to disk.
+func (l *AuditLog) Append(record *AuditRecord) error {
+	recordBytes, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	entry := &auditLogEntry{Record: recordBytes, PrevHash: l.lastHash}
+	payload := entry.signingPayload()
+	if entry.Signature, err = l.signer.Sign(record.Kettle, payload); err != nil {
+		return fmt.Errorf("could not sign the audit record with %s: %w", record.Kettle, err)
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if l.aead != nil {
+		nonce := make([]byte, l.aead.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return err
+		}
+		sealed := l.aead.Seal(nonce, nonce, line, nil)
+		line = []byte(base64.StdEncoding.EncodeToString(sealed))
+	}
+
+	if _, err := l.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	l.lastHash = crypto.Keccak256Hash(payload)
+	return l.file.Sync()
+}
+
+// Start implements node.Lifecycle, the log is opened when created.
+func (l *AuditLog) Start() error {
+	return nil
+}
+
+// Stop implements node.Lifecycle, closing the log.
+func (l *AuditLog) Stop() error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	return l.file.Close()
+}
+
+// ReadAuditLog calls fn with every record of the log, in the order they were
+// appended, until it returns an error. Every record is checked to be signed by
+// its kettle and chained to the previous one. If key is set all the records
+// must be encrypted with it, otherwise none can be.
+func ReadAuditLog(r io.Reader, key []byte, fn func(*AuditRecord) error) error {
+	aead, err := auditLogCipher(key)
+	if err != nil {
+		return err
+	}
+	_, err = readAuditLog(r, aead, fn)
+	return err
+}
+
+// readAuditLog verifies and reads the log, returning the hash the next entry
+// is chained to.
+func readAuditLog(r io.Reader, aead cipher.AEAD, fn func(*AuditRecord) error) (common.Hash, error) {
+	var prevHash common.Hash
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 256*1024*1024)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var err error
+		if aead != nil {
+			if line[0] == '{' {
+				return common.Hash{}, fmt.Errorf("audit log line %d: plaintext record in an encrypted audit log", lineNum)
+			}
+			if line, err = openAuditLogLine(aead, line); err != nil {
+				return common.Hash{}, fmt.Errorf("audit log line %d: %w", lineNum, err)
+			}
+		} else if line[0] != '{' {
+			return common.Hash{}, errors.New("audit log is encrypted, the encryption key is required")
+		}
+
+		entry := new(auditLogEntry)
+		if err := json.Unmarshal(line, entry); err != nil {
+			return common.Hash{}, fmt.Errorf("audit log line %d: %w", lineNum, err)
+		}
+		record := new(AuditRecord)
+		if err := json.Unmarshal(entry.Record, record); err != nil {
+			return common.Hash{}, fmt.Errorf("audit log line %d: %w", lineNum, err)
+		}
+
+		if entry.PrevHash != prevHash {
+			return common.Hash{}, fmt.Errorf("audit log line %d: not chained to the previous record", lineNum)
+		}
+		payload := entry.signingPayload()
+		pubkey, err := crypto.SigToPub(crypto.Keccak256(payload), entry.Signature)
+		if err != nil {
+			return common.Hash{}, fmt.Errorf("audit log line %d: invalid signature: %w", lineNum, err)
+		}
+		if signer := crypto.PubkeyToAddress(*pubkey); signer != record.Kettle {
+			return common.Hash{}, fmt.Errorf("audit log line %d: record of kettle %s signed by %s", lineNum, record.Kettle, signer)
+		}
+		prevHash = crypto.Keccak256Hash(payload)
+
+		if err := fn(record); err != nil {
+			return common.Hash{}, err
+		}
+	}
+	return prevHash, scanner.Err()
+}
+
+func openAuditLogLine(aead cipher.AEAD, line []byte) ([]byte, error) {
+	sealed, err := base64.StdEncoding.DecodeString(string(line))
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("truncated record")
+	}
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not decrypt the record: %w", err)
+	}
+	return plaintext, nil
+}
+
+// FindAuditRecord returns the latest record of the log for the request or
+// SuaveTransaction hash.
+func FindAuditRecord(r io.Reader, key []byte, hash common.Hash) (*AuditRecord, error) {
+	var found *AuditRecord
+	err := ReadAuditLog(r, key, func(record *AuditRecord) error {
+		if record.RequestHash == hash || record.TxHash == hash {
+			found = record
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if found == nil {
+		return nil, fmt.Errorf("no audit record for %s", hash)
+	}
+	return found, nil
+}
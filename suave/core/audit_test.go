@@ -0,0 +1,129 @@
+package suave
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+type testAuditLogSigner struct {
+	key *ecdsa.PrivateKey
+}
+
+func (s *testAuditLogSigner) Sign(account common.Address, data []byte) ([]byte, error) {
+	return crypto.Sign(crypto.Keccak256(data), s.key)
+}
+
+func newTestAuditLogSigner(t *testing.T) (*testAuditLogSigner, common.Address) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	return &testAuditLogSigner{key}, crypto.PubkeyToAddress(key.PublicKey)
+}
+
+func TestAuditLog(t *testing.T) {
+	key := bytes.Repeat([]byte{0x01}, 32)
+	path := filepath.Join(t.TempDir(), "audit.log")
+	signer, kettle := newTestAuditLogSigner(t)
+
+	// The chain is continued across restarts
+	auditLog, err := OpenAuditLog(path, key, signer)
+	require.NoError(t, err)
+	failed := &AuditRecord{RequestHash: common.Hash{0x01}, Kettle: kettle, Request: []byte{0x01}, ConfidentialInputs: []byte{}, Error: "reverted"}
+	require.NoError(t, auditLog.Append(failed))
+	require.NoError(t, auditLog.Stop())
+
+	auditLog, err = OpenAuditLog(path, key, signer)
+	require.NoError(t, err)
+	record := &AuditRecord{
+		RequestHash:        common.Hash{0x01},
+		Kettle:             kettle,
+		TxHash:             common.Hash{0x02},
+		Request:            []byte{0x01},
+		ConfidentialInputs: []byte{0x02},
+		PrecompileCalls: []*PrecompileCall{
+			{Address: common.Address{0x42}, Input: []byte{0x01}, Output: []byte{0x02}},
+		},
+		StoreWrites: []*StoreAccess{{Key: "key", Value: []byte("secret bundle")}},
+	}
+	require.NoError(t, auditLog.Append(record))
+	require.NoError(t, auditLog.Stop())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "secret bundle")
+
+	var records []*AuditRecord
+	require.NoError(t, ReadAuditLog(bytes.NewReader(data), key, func(r *AuditRecord) error {
+		records = append(records, r)
+		return nil
+	}))
+	require.Equal(t, []*AuditRecord{failed, record}, records)
+
+	// The latest record of the request is found by either hash
+	for _, hash := range []common.Hash{{0x01}, {0x02}} {
+		found, err := FindAuditRecord(bytes.NewReader(data), key, hash)
+		require.NoError(t, err)
+		require.Equal(t, record, found)
+	}
+
+	_, err = FindAuditRecord(bytes.NewReader(data), key, common.Hash{0x03})
+	require.ErrorContains(t, err, "no audit record")
+
+	_, err = FindAuditRecord(bytes.NewReader(data), nil, common.Hash{0x02})
+	require.ErrorContains(t, err, "encryption key is required")
+
+	_, err = FindAuditRecord(bytes.NewReader(data), bytes.Repeat([]byte{0x02}, 32), common.Hash{0x02})
+	require.ErrorContains(t, err, "could not decrypt")
+
+	// Records cannot be dropped nor reordered
+	lines := strings.SplitAfter(string(data), "\n")
+	_, err = FindAuditRecord(strings.NewReader(lines[1]), key, common.Hash{0x02})
+	require.ErrorContains(t, err, "not chained")
+	_, err = FindAuditRecord(strings.NewReader(lines[1]+lines[0]), key, common.Hash{0x02})
+	require.ErrorContains(t, err, "not chained")
+}
+
+func TestAuditLog_Plaintext(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	signer, kettle := newTestAuditLogSigner(t)
+
+	auditLog, err := OpenAuditLog(path, nil, signer)
+	require.NoError(t, err)
+	require.NoError(t, auditLog.Append(&AuditRecord{RequestHash: common.Hash{0x01}, Kettle: kettle}))
+	require.NoError(t, auditLog.Stop())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	_, err = FindAuditRecord(bytes.NewReader(data), nil, common.Hash{0x01})
+	require.NoError(t, err)
+
+	// Plaintext records are not accepted in an encrypted log
+	_, err = FindAuditRecord(bytes.NewReader(data), bytes.Repeat([]byte{0x01}, 32), common.Hash{0x01})
+	require.ErrorContains(t, err, "plaintext record")
+	_, err = OpenAuditLog(path, bytes.Repeat([]byte{0x01}, 32), signer)
+	require.ErrorContains(t, err, "plaintext record")
+
+	// Altered records do not match their signature
+	tampered := bytes.Replace(data, []byte(`"requestHash":"0x01`), []byte(`"requestHash":"0x02`), 1)
+	require.NotEqual(t, data, tampered)
+	_, err = FindAuditRecord(bytes.NewReader(tampered), nil, common.Hash{0x02})
+	require.ErrorContains(t, err, "signed by")
+
+	// Records must be signed by their kettle
+	otherSigner, _ := newTestAuditLogSigner(t)
+	auditLog, err = OpenAuditLog(path, nil, otherSigner)
+	require.NoError(t, err)
+	require.NoError(t, auditLog.Append(&AuditRecord{RequestHash: common.Hash{0x02}, Kettle: kettle}))
+	require.NoError(t, auditLog.Stop())
+
+	_, err = OpenAuditLog(path, nil, signer)
+	require.ErrorContains(t, err, "signed by")
+}
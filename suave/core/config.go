@@ -1,12 +1,144 @@
 package suave
 
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+)
+
 type Config struct {
 	SuaveEthRemoteBackendEndpoint string
+	EthLocalGenesisPath           string // genesis of the local execution chain, used without a remote endpoint
+	EthLocalStateDumpPath         string // state dump the local execution chain is seeded with
+	BeaconRemoteEndpoint          string
+	RelayRemoteEndpoint           string
 	RedisStorePubsubUri           string
+	RedisStoreStreamsUri          string
+	RedisStoreStreamsGroup        string
+	RedisStoreStreamsMaxAge       time.Duration
 	RedisStoreUri                 string
 	PebbleDbPath                  string
 	EthBundleSigningKeyHex        string
 	EthBlockSigningKeyHex         string
+	EncryptionKeyHex              string
+	AuditLogPath                  string // file the confidential executions are recorded in, disabled if empty
+	AuditLogEncryptionKeyHex      string // AES key the audit records are encrypted with
+	AuditLogPlaintext             bool   // allow recording the confidential executions without an encryption key
+	StoreQuotas                   StoreQuotas
+	KettleKeyMaxAge               time.Duration // how long a kettle key can be used before it has to be rotated
+	KettleKeyGracePeriod          time.Duration // how long a rotated kettle key is still accepted
+}
+
+// QuotaLimits bounds the use of the confidential store by a single principal.
+// Zero values are not limited.
+type QuotaLimits struct {
+	// Bids created for the same block (decryption condition)
+	BidsPerBlock uint64
+	// Bytes stored in a single bid
+	BytesPerBid uint64
+	// Bytes stored overall. The usage expires with the data on the redis
+	// backend, while the local and pebble backends never delete bids: there
+	// it is a cap on the bytes stored over the lifetime of the store.
+	TotalBytes uint64
+}
+
+// StoreQuotas are the limits applied to the signer of a confidential compute
+// request and to the contract it calls.
+type StoreQuotas struct {
+	Sender   QuotaLimits
+	Contract QuotaLimits
+}
+
+var DefaultConfig = Config{
+	RedisStoreStreamsMaxAge: 24 * time.Hour,
+	KettleKeyMaxAge:         90 * 24 * time.Hour,
+	KettleKeyGracePeriod:    7 * 24 * time.Hour,
+}
+
+// StoreBackendType returns the kind of confidential store backend configured.
+func (c *Config) StoreBackendType() string {
+	switch {
+	case c.RedisStoreUri != "":
+		return "redis"
+	case c.PebbleDbPath != "":
+		return "pebble"
+	default:
+		return "local"
+	}
 }
 
-var DefaultConfig = Config{}
+// StoreTransportType returns the kind of transport confidential store writes
+// are shared with other kettles over.
+func (c *Config) StoreTransportType() string {
+	switch {
+	case c.RedisStorePubsubUri != "":
+		return "redis"
+	case c.RedisStoreStreamsUri != "":
+		return "redis-streams"
+	default:
+		return "none"
+	}
+}
+
+// EthBackendType returns the kind of backend used for eth precompiles.
+func (c *Config) EthBackendType() string {
+	if c.SuaveEthRemoteBackendEndpoint != "" {
+		return "remote"
+	}
+	return "local"
+}
+
+// AuditLogKey returns the key the audit records are encrypted with, nil if
+// they are written in plaintext.
+func (c *Config) AuditLogKey() ([]byte, error) {
+	if c.AuditLogEncryptionKeyHex == "" {
+		return nil, nil
+	}
+	key, err := hex.DecodeString(strings.TrimPrefix(c.AuditLogEncryptionKeyHex, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid audit log encryption key: %w", err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("invalid audit log encryption key: %d bytes instead of 32", len(key))
+	}
+	return key, nil
+}
+
+// EncryptionKey returns the key confidential inputs are decrypted with.
+// Without a configured key the one saved at path is used, generated on first
+// use so that inputs encrypted to the kettle can still be decrypted after a
+// restart. Nodes without a datadir pass an empty path and get a random key.
+func (c *Config) EncryptionKey(path string) (*ecdsa.PrivateKey, error) {
+	if c.EncryptionKeyHex != "" {
+		return crypto.HexToECDSA(strings.TrimPrefix(c.EncryptionKeyHex, "0x"))
+	}
+	if path == "" {
+		return crypto.GenerateKey()
+	}
+
+	if key, err := crypto.LoadECDSA(path); err == nil {
+		return key, nil
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("could not load encryption key %s: %w", path, err)
+	}
+
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("could not save encryption key: %w", err)
+	}
+	if err := crypto.SaveECDSA(path, key); err != nil {
+		return nil, fmt.Errorf("could not save encryption key: %w", err)
+	}
+	log.Info("Generated confidential inputs encryption key", "path", path)
+	return key, nil
+}
@@ -0,0 +1,31 @@
+package suave
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigEncryptionKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "geth", "suave-encryptionkey")
+
+	// The generated key is kept across restarts
+	key, err := (&Config{}).EncryptionKey(path)
+	require.NoError(t, err)
+	require.FileExists(t, path)
+
+	again, err := (&Config{}).EncryptionKey(path)
+	require.NoError(t, err)
+	require.Equal(t, crypto.FromECDSA(key), crypto.FromECDSA(again))
+
+	// A configured key takes precedence
+	configured, err := (&Config{EncryptionKeyHex: "b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291"}).EncryptionKey(path)
+	require.NoError(t, err)
+	require.NotEqual(t, crypto.FromECDSA(key), crypto.FromECDSA(configured))
+
+	// Without a datadir the key is not saved
+	_, err = (&Config{}).EncryptionKey("")
+	require.NoError(t, err)
+}
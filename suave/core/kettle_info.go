@@ -0,0 +1,107 @@
+package suave
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// KettleInfo describes the capabilities of a kettle, so that clients can
+// check it is configured the way they expect before sending requests to it.
+type KettleInfo struct {
+	KettleAddresses []common.Address `json:"kettleAddresses"`
+	// Key confidential inputs are encrypted to, empty if not supported
+	EncryptionKey hexutil.Bytes `json:"encryptionKey"`
+	// Public key bundles sent to the execution layer are signed with
+	// (uncompressed secp256k1)
+	BundleSigningKey hexutil.Bytes `json:"bundleSigningKey"`
+	// Public key blocks submitted to relays are signed with (BLS)
+	BlockSigningKey hexutil.Bytes `json:"blockSigningKey"`
+
+	// Hash of the spec the precompiles were generated from
+	PrecompileSpecHash string                    `json:"precompileSpecHash"`
+	Precompiles        map[string]common.Address `json:"precompiles"`
+
+	ChainID       *hexutil.Big `json:"chainId"`
+	EthBackend    string       `json:"ethBackend"`
+	BeaconBackend bool         `json:"beaconBackend"`
+	RelayBackend  bool         `json:"relayBackend"`
+
+	StoreBackend   string `json:"storeBackend"`
+	StoreTransport string `json:"storeTransport"`
+
+	BuildVersion string `json:"buildVersion"`
+}
+
+// KettleInfoSignature is the signature of a kettle address over the
+// keccak256 hash of the capability document.
+type KettleInfoSignature struct {
+	KettleAddress common.Address `json:"kettleAddress"`
+	Signature     hexutil.Bytes  `json:"signature"`
+}
+
+// SignedKettleInfo is the capability document as returned by suave_kettleInfo.
+// The document is kept in its serialized form so that the signatures can be
+// checked against the exact bytes the kettle signed.
+type SignedKettleInfo struct {
+	Info       json.RawMessage        `json:"info"`
+	Signatures []*KettleInfoSignature `json:"signatures"`
+}
+
+// Verify checks that every kettle address of the document signed it, one of
+// them being trusted, and returns the decoded document. The signatures alone
+// only prove the document was signed by the keys it lists, anyone can make
+// up such a document.
+func (s *SignedKettleInfo) Verify(trusted []common.Address) (*KettleInfo, error) {
+	if len(trusted) == 0 {
+		return nil, errors.New("no trusted kettle addresses")
+	}
+
+	var info KettleInfo
+	if err := json.Unmarshal(s.Info, &info); err != nil {
+		return nil, fmt.Errorf("could not decode kettle info: %w", err)
+	}
+	if len(info.KettleAddresses) == 0 {
+		return nil, errors.New("kettle info has no kettle addresses")
+	}
+	if info.TrustedKettle(trusted) == (common.Address{}) {
+		return nil, fmt.Errorf("kettle info not signed by a trusted kettle, signers %v", info.KettleAddresses)
+	}
+
+	hash := crypto.Keccak256(s.Info)
+	for _, addr := range info.KettleAddresses {
+		if !s.signedBy(hash, addr) {
+			return nil, fmt.Errorf("kettle info not signed by kettle %s", addr)
+		}
+	}
+
+	return &info, nil
+}
+
+// TrustedKettle returns the first kettle address of the document that is
+// trusted, the zero address if none is.
+func (info *KettleInfo) TrustedKettle(trusted []common.Address) common.Address {
+	for _, addr := range info.KettleAddresses {
+		for _, trustedAddr := range trusted {
+			if addr == trustedAddr {
+				return addr
+			}
+		}
+	}
+	return common.Address{}
+}
+
+func (s *SignedKettleInfo) signedBy(hash []byte, addr common.Address) bool {
+	for _, sig := range s.Signatures {
+		if sig.KettleAddress != addr {
+			continue
+		}
+		signer, err := crypto.SigToPub(hash, sig.Signature)
+		return err == nil && crypto.PubkeyToAddress(*signer) == addr
+	}
+	return false
+}
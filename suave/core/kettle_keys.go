@@ -0,0 +1,54 @@
+package suave
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// KettleKeyHandover hands the identity of a kettle over from a signing key to
+// its replacement. It is signed by both keys: the old key vouches for the new
+// one, and the new key proves it is held by the same kettle. Other kettles
+// accept the new key on the bids the old one could store to, and keep
+// accepting the old key until the end of the grace window.
+type KettleKeyHandover struct {
+	OldAddress common.Address `json:"oldAddress"`
+	NewAddress common.Address `json:"newAddress"`
+	// Unix time of the rotation
+	RotatedAt uint64 `json:"rotatedAt"`
+	// Unix time until which the old key is still accepted
+	GraceUntil uint64 `json:"graceUntil"`
+
+	OldSignature hexutil.Bytes `json:"oldSignature"`
+	NewSignature hexutil.Bytes `json:"newSignature"`
+}
+
+type KettleKeyStatus string
+
+const (
+	// KettleKeyActive keys sign the requests and bids of the kettle
+	KettleKeyActive KettleKeyStatus = "active"
+	// KettleKeyRetiring keys were rotated but are accepted until the end of
+	// the grace window
+	KettleKeyRetiring KettleKeyStatus = "retiring"
+	// KettleKeyRetired keys are no longer accepted, the bids they signed
+	// remain valid
+	KettleKeyRetired KettleKeyStatus = "retired"
+)
+
+// KettleKey describes a signing key of the kettle, as returned by
+// eth_kettleKeys.
+type KettleKey struct {
+	Address common.Address  `json:"address"`
+	Status  KettleKeyStatus `json:"status"`
+	// Unix time the key became active
+	ActiveSince uint64 `json:"activeSince"`
+	// Unix time the key has to be rotated by, zero without a rotation policy
+	RotateBy uint64 `json:"rotateBy,omitempty"`
+	// Handover to the key that replaced it, for retiring and retired keys
+	Handover *KettleKeyHandover `json:"handover,omitempty"`
+}
+
+// Usable reports whether requests can still be sent to the key.
+func (k *KettleKey) Usable() bool {
+	return k.Status != KettleKeyRetired
+}
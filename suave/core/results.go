@@ -0,0 +1,168 @@
+package suave
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/event"
+	"golang.org/x/exp/slices"
+)
+
+// ConfidentialResult is what the confidential execution of a request
+// produced that is only delivered to the signer of the request: the logs
+// emitted by the contracts. It is never included on chain.
+type ConfidentialResult struct {
+	// Hash of the confidential compute request
+	RequestHash common.Hash `json:"requestHash"`
+	// Hash of the SuaveTransaction submitted for the request
+	TxHash common.Hash    `json:"txHash"`
+	Sender common.Address `json:"sender"`
+	Logs   []*types.Log   `json:"logs"`
+}
+
+// ConfidentialResults keeps the latest confidential results for their signers
+// to fetch, and notifies the subscribers of new ones.
+type ConfidentialResults struct {
+	lock    sync.Mutex
+	results lru.BasicLRU[common.Hash, *ConfidentialResult]
+	feed    event.Feed
+}
+
+// NewConfidentialResults returns a store keeping the latest limit results.
+func NewConfidentialResults(limit int) *ConfidentialResults {
+	return &ConfidentialResults{
+		// Results are indexed by both the request and the transaction hash
+		results: lru.NewBasicLRU[common.Hash, *ConfidentialResult](2 * limit),
+	}
+}
+
+// Add stores the result and sends it to the subscribers.
+func (r *ConfidentialResults) Add(result *ConfidentialResult) {
+	r.lock.Lock()
+	r.results.Add(result.RequestHash, result)
+	r.results.Add(result.TxHash, result)
+	r.lock.Unlock()
+
+	r.feed.Send(result)
+}
+
+// Get returns the result of a request by the hash of the request or of its
+// SuaveTransaction, nil if it is unknown or was evicted.
+func (r *ConfidentialResults) Get(hash common.Hash) *ConfidentialResult {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	result, _ := r.results.Get(hash)
+	return result
+}
+
+// Subscribe sends the new results to ch, whoever signed them.
+func (r *ConfidentialResults) Subscribe(ch chan<- *ConfidentialResult) event.Subscription {
+	return r.feed.Subscribe(ch)
+}
+
+// ConfidentialResultMessage is the message the signer of a request signs to
+// fetch its confidential result from a kettle, until expiry.
+func ConfidentialResultMessage(chainID *big.Int, kettle common.Address, hash common.Hash, expiry uint64) []byte {
+	return []byte(fmt.Sprintf("suave confidential result %s chain %d kettle %s expiry %d", hash.Hex(), chainID, kettle.Hex(), expiry))
+}
+
+// ConfidentialLogsMessage is the message an account signs to subscribe to the
+// confidential results of its requests on a kettle, until expiry.
+func ConfidentialLogsMessage(chainID *big.Int, kettle common.Address, addr common.Address, expiry uint64) []byte {
+	return []byte(fmt.Sprintf("suave confidential logs %s chain %d kettle %s expiry %d", addr.Hex(), chainID, kettle.Hex(), expiry))
+}
+
+// ConfidentialResultAuth authenticates the fetching of a confidential result
+// by the signer of the request.
+type ConfidentialResultAuth struct {
+	// Kettle address the result is fetched from
+	Kettle common.Address `json:"kettle"`
+	// Unix time until which the authentication is accepted
+	Expiry uint64 `json:"expiry"`
+	// EIP-191 signature of ConfidentialResultMessage
+	Signature hexutil.Bytes `json:"signature"`
+}
+
+// Verify checks that the authentication is for the result of hash on one of
+// the kettles of the chain and has not expired. It returns the account that
+// signed it.
+func (a *ConfidentialResultAuth) Verify(chainID *big.Int, kettles []common.Address, hash common.Hash, now time.Time) (common.Address, error) {
+	if uint64(now.Unix()) > a.Expiry {
+		return common.Address{}, errors.New("confidential result authentication expired")
+	}
+	if !slices.Contains(kettles, a.Kettle) {
+		return common.Address{}, fmt.Errorf("confidential result authentication is for kettle %s", a.Kettle)
+	}
+	return RecoverMessageSigner(ConfidentialResultMessage(chainID, a.Kettle, hash, a.Expiry), a.Signature)
+}
+
+// ConfidentialLogsAuth authenticates a subscription to the confidential
+// results of the requests signed by Address. It can be used to subscribe
+// until Expiry, the subscription then stays open.
+type ConfidentialLogsAuth struct {
+	Address common.Address `json:"address"`
+	// Kettle address the subscription is made to
+	Kettle common.Address `json:"kettle"`
+	// Unix time until which the authentication is accepted
+	Expiry uint64 `json:"expiry"`
+	// EIP-191 signature of ConfidentialLogsMessage
+	Signature hexutil.Bytes `json:"signature"`
+}
+
+// Verify checks that the authentication was signed by its address for one of
+// the kettles of the chain and has not expired.
+func (a *ConfidentialLogsAuth) Verify(chainID *big.Int, kettles []common.Address, now time.Time) error {
+	if uint64(now.Unix()) > a.Expiry {
+		return errors.New("confidential logs authentication expired")
+	}
+	if !slices.Contains(kettles, a.Kettle) {
+		return fmt.Errorf("confidential logs authentication is for kettle %s", a.Kettle)
+	}
+	signer, err := RecoverMessageSigner(ConfidentialLogsMessage(chainID, a.Kettle, a.Address, a.Expiry), a.Signature)
+	if err != nil {
+		return err
+	}
+	if signer != a.Address {
+		return fmt.Errorf("confidential logs authentication not signed by %s", a.Address)
+	}
+	return nil
+}
+
+// SignMessage signs the EIP-191 hash of the message, the way accounts sign
+// text with personal_sign.
+func SignMessage(message []byte, key *ecdsa.PrivateKey) ([]byte, error) {
+	signature, err := crypto.Sign(accounts.TextHash(message), key)
+	if err != nil {
+		return nil, err
+	}
+	signature[crypto.RecoveryIDOffset] += 27
+	return signature, nil
+}
+
+// RecoverMessageSigner returns the account that signed the EIP-191 hash of
+// the message.
+func RecoverMessageSigner(message []byte, signature []byte) (common.Address, error) {
+	if len(signature) != crypto.SignatureLength {
+		return common.Address{}, errors.New("invalid signature length")
+	}
+	sig := common.CopyBytes(signature)
+	if sig[crypto.RecoveryIDOffset] == 27 || sig[crypto.RecoveryIDOffset] == 28 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+	pubkey, err := crypto.SigToPub(accounts.TextHash(message), sig)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("invalid signature: %w", err)
+	}
+	return crypto.PubkeyToAddress(*pubkey), nil
+}
@@ -0,0 +1,67 @@
+package suave
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfidentialResultAuth(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	chainID := big.NewInt(16813125)
+	kettle := common.Address{0x1}
+	hash := common.Hash{0x2}
+	now := time.Now()
+
+	auth := &ConfidentialResultAuth{Kettle: kettle, Expiry: uint64(now.Add(time.Minute).Unix())}
+	auth.Signature, err = SignMessage(ConfidentialResultMessage(chainID, kettle, hash, auth.Expiry), key)
+	require.NoError(t, err)
+
+	signer, err := auth.Verify(chainID, []common.Address{kettle}, hash, now)
+	require.NoError(t, err)
+	require.Equal(t, crypto.PubkeyToAddress(key.PublicKey), signer)
+
+	// The authentication does not carry over to other kettles, chains or results
+	_, err = auth.Verify(chainID, []common.Address{{0x3}}, hash, now)
+	require.Error(t, err)
+
+	signer, err = auth.Verify(big.NewInt(1), []common.Address{kettle}, hash, now)
+	require.NoError(t, err)
+	require.NotEqual(t, crypto.PubkeyToAddress(key.PublicKey), signer)
+
+	signer, err = auth.Verify(chainID, []common.Address{kettle}, common.Hash{0x3}, now)
+	require.NoError(t, err)
+	require.NotEqual(t, crypto.PubkeyToAddress(key.PublicKey), signer)
+
+	// nor past its expiry
+	_, err = auth.Verify(chainID, []common.Address{kettle}, hash, now.Add(2*time.Minute))
+	require.ErrorContains(t, err, "expired")
+}
+
+func TestConfidentialLogsAuth(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	chainID := big.NewInt(16813125)
+	kettle := common.Address{0x1}
+	now := time.Now()
+
+	auth := &ConfidentialLogsAuth{
+		Address: crypto.PubkeyToAddress(key.PublicKey),
+		Kettle:  kettle,
+		Expiry:  uint64(now.Add(time.Minute).Unix()),
+	}
+	auth.Signature, err = SignMessage(ConfidentialLogsMessage(chainID, kettle, auth.Address, auth.Expiry), key)
+	require.NoError(t, err)
+
+	require.NoError(t, auth.Verify(chainID, []common.Address{kettle}, now))
+	require.Error(t, auth.Verify(chainID, []common.Address{{0x3}}, now))
+	require.Error(t, auth.Verify(big.NewInt(1), []common.Address{kettle}, now))
+	require.ErrorContains(t, auth.Verify(chainID, []common.Address{kettle}, now.Add(2*time.Minute)), "expired")
+}
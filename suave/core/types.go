@@ -3,18 +3,24 @@ package suave
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/ethereum/go-ethereum/beacon/engine"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/node"
+	"golang.org/x/exp/slices"
 )
 
 var AllowedPeekerAny = common.HexToAddress("0xC8df3686b4Afb2BB53e60EAe97EF043FE03Fb829") // "*"
 
 type Bytes = hexutil.Bytes
 type BidId = types.BidId
+type AccessWindow = types.AccessWindow
+type BidPolicy = types.BidPolicy
+type BidKeyRule = types.BidKeyRule
 
 type Bid struct {
 	Id                  types.BidId
@@ -23,10 +29,24 @@ type Bid struct {
 	AllowedPeekers      []common.Address
 	AllowedStores       []common.Address
 	Version             string
+	AccessWindow        AccessWindow
+	Policy              *BidPolicy
 	CreationTx          *types.Transaction
 	Signature           []byte
 }
 
+// CheckAccessWindow returns an error if the bid's data cannot be accessed at
+// the given block number. A zero bound leaves that side of the window open.
+func (b *Bid) CheckAccessWindow(blockNumber uint64) error {
+	if b.AccessWindow.NotBefore != 0 && blockNumber < b.AccessWindow.NotBefore {
+		return fmt.Errorf("%w: bid %x is accessible from block %d, head is %d", ErrBidNotAccessibleYet, b.Id, b.AccessWindow.NotBefore, blockNumber)
+	}
+	if b.AccessWindow.NotAfter != 0 && blockNumber > b.AccessWindow.NotAfter {
+		return fmt.Errorf("%w: bid %x was accessible until block %d, head is %d", ErrBidAccessExpired, b.Id, b.AccessWindow.NotAfter, blockNumber)
+	}
+	return nil
+}
+
 func (b *Bid) ToInnerBid() types.Bid {
 	return types.Bid{
 		Id:                  b.Id,
@@ -38,18 +58,100 @@ func (b *Bid) ToInnerBid() types.Bid {
 	}
 }
 
+// AllowedReaders returns the addresses allowed to read key on the bid. Bids
+// without a policy can be read and written by all of their allowed peekers.
+func (b *Bid) AllowedReaders(key string) []common.Address {
+	if b.Policy == nil {
+		return b.AllowedPeekers
+	}
+	if rule := b.keyRule(key); rule != nil {
+		return rule.Readers
+	}
+	return b.Policy.Readers
+}
+
+// AllowedWriters returns the addresses allowed to write key on the bid.
+func (b *Bid) AllowedWriters(key string) []common.Address {
+	if b.Policy == nil {
+		return b.AllowedPeekers
+	}
+	if rule := b.keyRule(key); rule != nil {
+		return rule.Writers
+	}
+	return b.Policy.Writers
+}
+
+// IsReader reports whether addr is allowed to read at least one key of the bid.
+func (b *Bid) IsReader(addr common.Address) bool {
+	if IsAllowed(b.AllowedReaders(""), addr) {
+		return true
+	}
+	if b.Policy != nil {
+		for _, rule := range b.Policy.KeyRules {
+			if rule != nil && IsAllowed(rule.Readers, addr) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// keyRule returns the rule with the longest prefix of key, if any.
+func (b *Bid) keyRule(key string) *BidKeyRule {
+	var match *BidKeyRule
+	for _, rule := range b.Policy.KeyRules {
+		if rule == nil || !strings.HasPrefix(key, rule.KeyPrefix) {
+			continue
+		}
+		if match == nil || len(rule.KeyPrefix) > len(match.KeyPrefix) {
+			match = rule
+		}
+	}
+	return match
+}
+
+// IsAllowed reports whether addr is part of the set, either explicitly or
+// because the set allows any address.
+func IsAllowed(set []common.Address, addr common.Address) bool {
+	return slices.Contains(set, addr) || slices.Contains(set, AllowedPeekerAny)
+}
+
 type MEVMBid = types.Bid
 
 type BuildBlockArgs = types.BuildBlockArgs
 
+type BuildBlockArgsV2 = types.BuildBlockArgsV2
+
 var ConfStoreAllowedAny common.Address = common.HexToAddress("0x42")
 
 var (
 	ErrBidAlreadyPresent = errors.New("bid already present")
 	ErrBidNotFound       = errors.New("bid not found")
 	ErrUnsignedFinalize  = errors.New("finalize called with unsigned transaction, refusing to propagate")
+
+	ErrBidNotAccessibleYet = errors.New("bid not accessible yet")
+	ErrBidAccessExpired    = errors.New("bid access expired")
+	ErrInvalidAccessWindow = errors.New("invalid access window")
+
+	ErrQuotaExceeded = errors.New("confidential store quota exceeded")
+
+	ErrKettleKeyRetired = errors.New("kettle key retired")
 )
 
+// BundleReplacement is the latest bundle a searcher sent with a replacement
+// UUID, earlier bundles with the same UUID are replaced by it.
+type BundleReplacement struct {
+	BidId     BidId  `json:"bidId"`
+	Nonce     uint64 `json:"nonce"`     // Nonce of the request that created the bid
+	Cancelled bool   `json:"cancelled"` // The bundle cancels the earlier ones
+}
+
+// Replaces reports whether the replacement replaces or cancels the bundle
+// created by a request with the nonce.
+func (r *BundleReplacement) Replaces(nonce uint64) bool {
+	return r.Nonce > nonce || (r.Cancelled && r.Nonce == nonce)
+}
+
 type ConfidentialStoreBackend interface {
 	node.Lifecycle
 
@@ -61,7 +163,21 @@ type ConfidentialStoreBackend interface {
 }
 
 type ConfidentialEthBackend interface {
-	BuildEthBlock(ctx context.Context, args *BuildBlockArgs, txs types.Transactions) (*engine.ExecutionPayloadEnvelope, error)
-	BuildEthBlockFromBundles(ctx context.Context, args *BuildBlockArgs, bundles []types.SBundle) (*engine.ExecutionPayloadEnvelope, error)
+	BuildEthBlock(ctx context.Context, args *BuildBlockArgsV2, txs types.Transactions) (*engine.ExecutionPayloadEnvelope, error)
+	BuildEthBlockFromBundles(ctx context.Context, args *BuildBlockArgsV2, bundles []types.SBundle) (*engine.ExecutionPayloadEnvelope, error)
+	SimulateBundles(ctx context.Context, args *BuildBlockArgsV2, bundles []types.SBundle) ([]*SimulatedBundle, error)
 	Call(ctx context.Context, contractAddr common.Address, input []byte) ([]byte, error)
 }
+
+// SimulatedBundle is the outcome of a bundle executed on its own on top of the
+// parent of the block it is meant for.
+type SimulatedBundle struct {
+	BundleHash common.Hash    `json:"bundleHash"`
+	GasUsed    hexutil.Uint64 `json:"gasUsed"`
+	Profit     *hexutil.Big   `json:"profit,omitempty"`
+	Error      string         `json:"error,omitempty"`
+}
+
+type ConfidentialBeaconBackend interface {
+	UpcomingBuildBlockArgs(ctx context.Context) (*BuildBlockArgs, error)
+}
@@ -30,7 +30,48 @@ func testBackendStore(t *testing.T, store ConfidentialStorageBackend) {
 	require.NoError(t, err)
 	require.Equal(t, []byte{0x43, 0x14}, retrievedData)
 
+	size, err := store.StoredSize(bid, "xx")
+	require.NoError(t, err)
+	require.Equal(t, 2, size)
+
+	size, err = store.StoredSize(bid, "missing")
+	require.NoError(t, err)
+	require.Zero(t, size)
+
 	bids := store.FetchBidsByProtocolAndBlock(10, "default:v0:ethBundles")
 	require.Len(t, bids, 1)
 	require.Equal(t, bid, bids[0])
+
+	usage, err := store.Usage("quota-test")
+	require.NoError(t, err)
+	require.Zero(t, usage)
+
+	usage, err = store.AddUsage("quota-test", 5)
+	require.NoError(t, err)
+	require.Equal(t, int64(5), usage)
+
+	usage, err = store.AddUsage("quota-test", -2)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), usage)
+
+	usage, err = store.Usage("quota-test")
+	require.NoError(t, err)
+	require.Equal(t, int64(3), usage)
+
+	_, found, err := store.Replacement("replacement-test")
+	require.NoError(t, err)
+	require.False(t, found)
+
+	require.NoError(t, store.RecordReplacement("replacement-test", suave.BundleReplacement{BidId: bid.Id, Nonce: 2}))
+	// Replacements of lower nonces are ignored
+	require.NoError(t, store.RecordReplacement("replacement-test", suave.BundleReplacement{Nonce: 1}))
+	replacement, found, err := store.Replacement("replacement-test")
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, suave.BundleReplacement{BidId: bid.Id, Nonce: 2}, replacement)
+
+	require.NoError(t, store.RecordReplacement("replacement-test", suave.BundleReplacement{Nonce: 3, Cancelled: true}))
+	replacement, _, err = store.Replacement("replacement-test")
+	require.NoError(t, err)
+	require.Equal(t, suave.BundleReplacement{Nonce: 3, Cancelled: true}, replacement)
 }
@@ -0,0 +1,57 @@
+package cstore
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	suave "github.com/ethereum/go-ethereum/suave/core"
+	"github.com/google/uuid"
+	"golang.org/x/exp/slices"
+)
+
+// bundleStoreKeys are the keys holding JSON encoded bundles, the ones blocks
+// are built from.
+var bundleStoreKeys = []string{"default:v0:ethBundles", "mevshare:v0:ethBundles"}
+
+func formatReplacementKey(sender common.Address, replacementUuid uuid.UUID) string {
+	return fmt.Sprintf("%x-%s", sender, replacementUuid)
+}
+
+// BundleReplacement returns the latest bundle the sender stored with the
+// replacement UUID, false if the sender stored none.
+func (e *ConfidentialStoreEngine) BundleReplacement(sender common.Address, replacementUuid uuid.UUID) (suave.BundleReplacement, bool, error) {
+	return e.storage.Replacement(formatReplacementKey(sender, replacementUuid))
+}
+
+// recordBundleReplacement records the bundle of the write as the latest one
+// of its replacement UUID, the sender being the one of the request that
+// created the bid. Values that are not bundles are not recorded.
+func (e *ConfidentialStoreEngine) recordBundleReplacement(sw StoreWrite) error {
+	return recordBundleReplacement(e.storage, e.chainSigner, sw)
+}
+
+func recordBundleReplacement(storage ConfidentialStorageBackend, chainSigner ChainSigner, sw StoreWrite) error {
+	if !slices.Contains(bundleStoreKeys, sw.Key) || sw.Bid.CreationTx == nil {
+		return nil
+	}
+
+	var bundle types.SBundle
+	if err := json.Unmarshal(sw.Value, &bundle); err != nil || bundle.ReplacementUuid == nil {
+		return nil
+	}
+
+	sender, err := chainSigner.Sender(sw.Bid.CreationTx)
+	if err != nil {
+		return fmt.Errorf("could not recover the sender of the replaceable bundle of bid %x: %w", sw.Bid.Id, err)
+	}
+
+	log.Debug("Recording bundle replacement", "sender", sender, "replacementUuid", bundle.ReplacementUuid, "bid", sw.Bid.Id)
+	return storage.RecordReplacement(formatReplacementKey(sender, *bundle.ReplacementUuid), suave.BundleReplacement{
+		BidId:     sw.Bid.Id,
+		Nonce:     sw.Bid.CreationTx.Nonce(),
+		Cancelled: bundle.IsCancellation(),
+	})
+}
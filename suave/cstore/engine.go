@@ -5,10 +5,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/node"
 	suave "github.com/ethereum/go-ethereum/suave/core"
 	"github.com/google/uuid"
@@ -21,8 +23,21 @@ type ConfidentialStorageBackend interface {
 	InitializeBid(bid suave.Bid) error
 	Store(bid suave.Bid, caller common.Address, key string, value []byte) (suave.Bid, error)
 	Retrieve(bid suave.Bid, caller common.Address, key string) ([]byte, error)
+	// StoredSize returns the length of the value stored under key in the bid,
+	// zero if none is.
+	StoredSize(bid suave.Bid, key string) (int, error)
 	FetchBidById(suave.BidId) (suave.Bid, error)
 	FetchBidsByProtocolAndBlock(blockNumber uint64, namespace string) []suave.Bid
+	// Usage returns the value of a quota usage counter, zero if it was never set.
+	Usage(key string) (int64, error)
+	// AddUsage atomically adds delta to a quota usage counter and returns its new value.
+	AddUsage(key string, delta int64) (int64, error)
+	// Replacement returns the bundle replacement recorded under key, false if
+	// none was.
+	Replacement(key string) (suave.BundleReplacement, bool, error)
+	// RecordReplacement records the bundle replacement under key, unless one
+	// created by a request with a higher nonce is recorded already.
+	RecordReplacement(key string, replacement suave.BundleReplacement) error
 	Stop() error
 }
 
@@ -34,11 +49,26 @@ type StoreTransportTopic interface {
 	Publish(DAMessage)
 }
 
+// AckStoreTransportTopic is implemented by transports that deliver a message
+// until it is acknowledged. The engine acknowledges messages once they have
+// been applied to the store.
+type AckStoreTransportTopic interface {
+	StoreTransportTopic
+	Ack(DAMessage) error
+}
+
 type DAMessage struct {
 	SourceTx    *types.Transaction `json:"sourceTx"`
 	StoreWrites []StoreWrite       `json:"storeWrites"`
 	StoreUUID   uuid.UUID          `json:"storeUUID"`
 	Signature   suave.Bytes        `json:"signature"`
+
+	// Handovers leading to the key that signed the message. Messages that
+	// only announce a rotation have no source transaction.
+	KeyHandovers []*suave.KettleKeyHandover `json:"keyHandovers,omitempty"`
+
+	// Identifies the message to acknowledge in the transport, not shared
+	transportId string
 }
 
 type StoreWrite struct {
@@ -70,6 +100,9 @@ type ConfidentialStoreEngine struct {
 
 	storeUUID      uuid.UUID
 	localAddresses map[common.Address]struct{}
+
+	quotas suave.StoreQuotas
+	keys   *KettleKeyRing
 }
 
 func NewConfidentialStoreEngine(backend ConfidentialStorageBackend, transportTopic StoreTransportTopic, daSigner DASigner, chainSigner ChainSigner) *ConfidentialStoreEngine {
@@ -79,20 +112,27 @@ func NewConfidentialStoreEngine(backend ConfidentialStorageBackend, transportTop
 	}
 
 	return &ConfidentialStoreEngine{
-		storage:        backend,
+		storage:        newMeteredStorageBackend(backend),
 		transportTopic: transportTopic,
 		daSigner:       daSigner,
 		chainSigner:    chainSigner,
 		storeUUID:      uuid.New(),
 		localAddresses: localAddresses,
+		keys:           newMemoryKeyRing(),
 	}
 }
 
-func (e *ConfidentialStoreEngine) NewTransactionalStore(sourceTx *types.Transaction) *TransactionalStore {
+// NewTransactionalStore returns a store for the writes of a single request.
+// Access windows of bids are checked against blockNumber, the chain head at
+// the time the request runs.
+func (e *ConfidentialStoreEngine) NewTransactionalStore(sourceTx *types.Transaction, blockNumber uint64) *TransactionalStore {
 	return &TransactionalStore{
-		sourceTx:    sourceTx,
-		engine:      e,
-		pendingBids: make(map[suave.BidId]suave.Bid),
+		sourceTx:     sourceTx,
+		blockNumber:  blockNumber,
+		engine:       e,
+		pendingBids:  make(map[suave.BidId]suave.Bid),
+		pendingSizes: make(map[string]int),
+		pendingUsage: make(map[string]int64),
 	}
 }
 
@@ -109,6 +149,10 @@ func (e *ConfidentialStoreEngine) Start() error {
 	e.cancel = cancel
 	e.ctx = ctx
 	go e.ProcessMessages()
+	go e.watchKeyRotation()
+	if metrics.Enabled {
+		go e.sampleBidGauges()
+	}
 
 	return nil
 }
@@ -144,22 +188,61 @@ func (e *ConfidentialStoreEngine) ProcessMessages() {
 		select {
 		case <-e.ctx.Done(): // Stop() called
 			return
-		case msg := <-ch:
-			err := e.NewMessage(msg)
-			if err != nil {
-				log.Info("could not process new store message", "err", err)
+		case msg, ok := <-ch:
+			if !ok {
+				// Transport stopped
+				return
+			}
+			receivedMessageCounter.Inc(1)
+
+			// Rejected messages are acknowledged, delivering them again would
+			// not change the outcome. Messages the backend failed to store are
+			// left for the transport to deliver again.
+			var rejected *rejectedMessageError
+			if err := e.NewMessage(msg); errors.As(err, &rejected) {
+				log.Warn("rejected store message", "err", err)
+			} else if err != nil {
+				log.Warn("could not process new store message", "err", err)
+				continue
 			} else {
+				appliedMessageCounter.Inc(1)
 				log.Info("Message processed", "msg", msg)
 			}
+
+			if acker, ok := e.transportTopic.(AckStoreTransportTopic); ok {
+				if err := acker.Ack(msg); err != nil {
+					log.Warn("could not acknowledge store message", "err", err)
+				}
+			}
 		}
 	}
 }
 
 func (e *ConfidentialStoreEngine) InitializeBid(bid types.Bid, creationTx *types.Transaction) (suave.Bid, error) {
+	return e.initializeBid(bid, suave.AccessWindow{}, nil, creationTx)
+}
+
+// InitializeBidWithAccessWindow initializes a bid whose data can only be
+// retrieved while the chain head is within the given access window.
+func (e *ConfidentialStoreEngine) InitializeBidWithAccessWindow(bid types.Bid, accessWindow suave.AccessWindow, creationTx *types.Transaction) (suave.Bid, error) {
+	return e.initializeBid(bid, accessWindow, nil, creationTx)
+}
+
+// InitializeBidWithPolicy initializes a bid whose data is guarded by the given
+// policy instead of its allowed peekers.
+func (e *ConfidentialStoreEngine) InitializeBidWithPolicy(bid types.Bid, policy suave.BidPolicy, creationTx *types.Transaction) (suave.Bid, error) {
+	return e.initializeBid(bid, suave.AccessWindow{}, &policy, creationTx)
+}
+
+func (e *ConfidentialStoreEngine) initializeBid(bid types.Bid, accessWindow suave.AccessWindow, policy *suave.BidPolicy, creationTx *types.Transaction) (suave.Bid, error) {
+	if accessWindow.NotAfter != 0 && accessWindow.NotAfter < accessWindow.NotBefore {
+		return suave.Bid{}, fmt.Errorf("confidential engine: %w: not after %d is before not before %d", suave.ErrInvalidAccessWindow, accessWindow.NotAfter, accessWindow.NotBefore)
+	}
+
 	// Share with all stores this node trusts
 	bid.AllowedStores = append(bid.AllowedStores, e.daSigner.LocalAddresses()...)
 
-	expectedId, err := calculateBidId(bid)
+	expectedId, err := calculateBidId(bid, policy)
 	if err != nil {
 		return suave.Bid{}, fmt.Errorf("confidential engine: could not initialize new bid: %w", err)
 	}
@@ -178,6 +261,8 @@ func (e *ConfidentialStoreEngine) InitializeBid(bid types.Bid, creationTx *types
 		AllowedPeekers:      bid.AllowedPeekers,
 		AllowedStores:       bid.AllowedStores,
 		Version:             bid.Version,
+		AccessWindow:        accessWindow,
+		Policy:              policy,
 		CreationTx:          creationTx,
 	}
 
@@ -190,6 +275,9 @@ func (e *ConfidentialStoreEngine) InitializeBid(bid types.Bid, creationTx *types
 	if err != nil {
 		return suave.Bid{}, fmt.Errorf("confidential engine: could not recover execution node from creation transaction: %w", err)
 	}
+	if err := e.keys.checkSigningKey(signingAccount); err != nil {
+		return suave.Bid{}, fmt.Errorf("confidential engine: could not sign initialized bid: %w", err)
+	}
 
 	initializedBid.Signature, err = e.daSigner.Sign(signingAccount, bidBytes)
 	if err != nil {
@@ -207,25 +295,49 @@ func (e *ConfidentialStoreEngine) FetchBidsByProtocolAndBlock(blockNumber uint64
 	return e.storage.FetchBidsByProtocolAndBlock(blockNumber, namespace)
 }
 
-func (e *ConfidentialStoreEngine) Retrieve(bidId suave.BidId, caller common.Address, key string) ([]byte, error) {
+// Retrieve returns the data stored under key for the bid, provided the caller
+// is an allowed peeker and blockNumber is within the bid's access window.
+func (e *ConfidentialStoreEngine) Retrieve(bidId suave.BidId, caller common.Address, key string, blockNumber uint64) ([]byte, error) {
 	bid, err := e.storage.FetchBidById(bidId)
 	if err != nil {
 		return []byte{}, fmt.Errorf("confidential engine: could not fetch bid %x while retrieving: %w", bidId, err)
 	}
 
-	if !slices.Contains(bid.AllowedPeekers, caller) && !slices.Contains(bid.AllowedPeekers, suave.AllowedPeekerAny) {
+	if !suave.IsAllowed(bid.AllowedReaders(key), caller) {
 		return []byte{}, fmt.Errorf("confidential engine: %x not allowed to retrieve %s on %x", caller, key, bidId)
 	}
 
+	if err := bid.CheckAccessWindow(blockNumber); err != nil {
+		return []byte{}, fmt.Errorf("confidential engine: %x not allowed to retrieve %s: %w", caller, key, err)
+	}
+
 	return e.storage.Retrieve(bid, caller, key)
 }
 
 func (e *ConfidentialStoreEngine) Finalize(tx *types.Transaction, newBids map[suave.BidId]suave.Bid, stores []StoreWrite) error {
-	//
+	defer finalizeTimer.UpdateSince(time.Now())
+
+	if signingAccount, err := KettleAddressFromTransaction(tx); err == nil {
+		if err := e.keys.checkSigningKey(signingAccount); err != nil {
+			return fmt.Errorf("confidential engine: refusing to sign message: %w", err)
+		}
+	}
+
+	bids := make([]suave.Bid, 0, len(newBids))
+	for _, bid := range newBids {
+		bids = append(bids, bid)
+	}
+	counters := e.quotaCounters(tx, bids, stores)
+	if err := e.chargeQuotas(counters); err != nil {
+		return err
+	}
+
+	// The request fails if its writes cannot be applied, its usage is refunded
 	for _, bid := range newBids {
 		err := e.storage.InitializeBid(bid)
 		if err != nil {
 			// TODO: deinitialize!
+			e.refundQuotas(counters)
 			return fmt.Errorf("confidential engine: store backend failed to initialize bid: %w", err)
 		}
 	}
@@ -233,15 +345,13 @@ func (e *ConfidentialStoreEngine) Finalize(tx *types.Transaction, newBids map[su
 	for _, sw := range stores {
 		if _, err := e.storage.Store(sw.Bid, sw.Caller, sw.Key, sw.Value); err != nil {
 			// TODO: deinitialize and deStore!
+			e.refundQuotas(counters)
 			return fmt.Errorf("failed to store data: %w", err)
 		}
-	}
-
-	// Sign and propagate the message
-	pwMsg := DAMessage{
-		SourceTx:    tx,
-		StoreWrites: stores,
-		StoreUUID:   e.storeUUID,
+		if err := e.recordBundleReplacement(sw); err != nil {
+			e.refundQuotas(counters)
+			return fmt.Errorf("confidential engine: %w", err)
+		}
 	}
 
 	if _, sigErr := e.chainSigner.Sender(tx); sigErr != nil {
@@ -249,14 +359,22 @@ func (e *ConfidentialStoreEngine) Finalize(tx *types.Transaction, newBids map[su
 		return suave.ErrUnsignedFinalize
 	}
 
-	msgBytes, err := SerializeMessageForSigning(&pwMsg)
+	signingAccount, err := KettleAddressFromTransaction(tx)
 	if err != nil {
-		return fmt.Errorf("confidential engine: could not hash message for signing: %w", err)
+		return fmt.Errorf("confidential engine: could not recover execution node from source transaction: %w", err)
 	}
 
-	signingAccount, err := KettleAddressFromTransaction(tx)
+	// Sign and propagate the message
+	pwMsg := DAMessage{
+		SourceTx:     tx,
+		StoreWrites:  stores,
+		StoreUUID:    e.storeUUID,
+		KeyHandovers: e.keys.handoversTo(signingAccount),
+	}
+
+	msgBytes, err := SerializeMessageForSigning(&pwMsg)
 	if err != nil {
-		return fmt.Errorf("confidential engine: could not recover execution node from source transaction: %w", err)
+		return fmt.Errorf("confidential engine: could not hash message for signing: %w", err)
 	}
 
 	pwMsg.Signature, err = e.daSigner.Sign(signingAccount, msgBytes)
@@ -265,6 +383,7 @@ func (e *ConfidentialStoreEngine) Finalize(tx *types.Transaction, newBids map[su
 	}
 
 	// TODO: avoid marshalling twice
+	publishedMessageCounter.Inc(1)
 	go e.transportTopic.Publish(pwMsg)
 
 	return nil
@@ -272,22 +391,30 @@ func (e *ConfidentialStoreEngine) Finalize(tx *types.Transaction, newBids map[su
 
 func (e *ConfidentialStoreEngine) NewMessage(message DAMessage) error {
 	// Note the validation is a work in progress and not guaranteed to be correct!
+	defer newMessageTimer.UpdateSince(time.Now())
 
 	// Message-level validation
 	msgBytes, err := SerializeMessageForSigning(&message)
 	if err != nil {
-		return fmt.Errorf("confidential engine: could not hash received message: %w", err)
+		return rejectMessage(rejectedMalformedCounter, fmt.Errorf("confidential engine: could not hash received message: %w", err))
 	}
 	recoveredMessageSigner, err := e.daSigner.Sender(msgBytes, message.Signature)
 	if err != nil {
-		return fmt.Errorf("confidential engine: incorrect message signature: %w", err)
+		return rejectMessage(rejectedSignatureCounter, fmt.Errorf("confidential engine: incorrect message signature: %w", err))
+	}
+
+	if message.SourceTx == nil && len(message.KeyHandovers) != 0 {
+		// Announcement of a key rotation. Handovers of remote kettles are only
+		// known once they come with the bids of the old key.
+		return e.addHandovers(recoveredMessageSigner, message.KeyHandovers, nil)
 	}
+
 	expectedMessageSigner, err := KettleAddressFromTransaction(message.SourceTx)
 	if err != nil {
-		return fmt.Errorf("confidential engine: could not recover signer from message: %w", err)
+		return rejectMessage(rejectedMalformedCounter, fmt.Errorf("confidential engine: could not recover signer from message: %w", err))
 	}
 	if recoveredMessageSigner != expectedMessageSigner {
-		return fmt.Errorf("confidential engine: message signer %x, expected %x", recoveredMessageSigner, expectedMessageSigner)
+		return rejectMessage(rejectedSignatureCounter, fmt.Errorf("confidential engine: message signer %x, expected %x", recoveredMessageSigner, expectedMessageSigner))
 	}
 
 	if message.StoreUUID == e.storeUUID {
@@ -300,75 +427,145 @@ func (e *ConfidentialStoreEngine) NewMessage(message DAMessage) error {
 
 	_, err = e.chainSigner.Sender(message.SourceTx)
 	if err != nil {
-		return fmt.Errorf("confidential engine: source tx for message is not signed properly: %w", err)
+		return rejectMessage(rejectedSourceTxCounter, fmt.Errorf("confidential engine: source tx for message is not signed properly: %w", err))
 	}
 
 	// TODO: check if message.SourceTx is valid and insert it into the mempool!
 
 	// Bid level validation
 
+	var stores []common.Address
 	for _, sw := range message.StoreWrites {
-		expectedId, err := calculateBidId(types.Bid{
-			Id:                  sw.Bid.Id,
-			Salt:                sw.Bid.Salt,
-			DecryptionCondition: sw.Bid.DecryptionCondition,
-			AllowedPeekers:      sw.Bid.AllowedPeekers,
-			AllowedStores:       sw.Bid.AllowedStores,
-			Version:             sw.Bid.Version,
-		})
-		if err != nil {
-			return fmt.Errorf("confidential engine: could not calculate received bids id: %w", err)
+		if err := verifyBid(&sw.Bid, e.daSigner, e.chainSigner); err != nil {
+			return rejectMessage(rejectedBidCounter, fmt.Errorf("confidential engine: %w", err))
 		}
+		stores = append(stores, sw.Bid.AllowedStores...)
+	}
 
-		if expectedId != sw.Bid.Id {
-			return fmt.Errorf("confidential engine: received bids id (%x) does not match the expected (%x)", sw.Bid.Id, expectedId)
-		}
+	if err := e.addHandovers(recoveredMessageSigner, message.KeyHandovers, stores); err != nil {
+		return err
+	}
+	if err := e.keys.checkSigningKey(recoveredMessageSigner); err != nil {
+		return rejectMessage(rejectedSigningKeyCounter, fmt.Errorf("confidential engine: message signer: %w", err))
+	}
 
-		bidBytes, err := SerializeBidForSigning(&sw.Bid)
-		if err != nil {
-			return fmt.Errorf("confidential engine: could not hash received bid: %w", err)
-		}
-		recoveredBidSigner, err := e.daSigner.Sender(bidBytes, sw.Bid.Signature)
-		if err != nil {
-			return fmt.Errorf("confidential engine: incorrect bid signature: %w", err)
-		}
-		expectedBidSigner, err := KettleAddressFromTransaction(sw.Bid.CreationTx)
-		if err != nil {
-			return fmt.Errorf("confidential engine: could not recover signer from bid: %w", err)
-		}
-		if recoveredBidSigner != expectedBidSigner {
-			return fmt.Errorf("confidential engine: bid signer %x, expected %x", recoveredBidSigner, expectedBidSigner)
+	for _, sw := range message.StoreWrites {
+		// Keys that replaced an allowed store through a handover can store too
+		if !e.keys.isAllowedStore(sw.Bid.AllowedStores, recoveredMessageSigner) {
+			return rejectMessage(rejectedStoreCounter, fmt.Errorf("confidential engine: sw signer %x not allowed to store on bid %x", recoveredMessageSigner, sw.Bid.Id))
 		}
 
-		if !slices.Contains(sw.Bid.AllowedStores, recoveredMessageSigner) {
-			return fmt.Errorf("confidential engine: sw signer %x not allowed to store on bid %x", recoveredMessageSigner, sw.Bid.Id)
+		if !suave.IsAllowed(sw.Bid.AllowedWriters(sw.Key), sw.Caller) {
+			return rejectMessage(rejectedWriterCounter, fmt.Errorf("confidential engine: caller %x not allowed to store %s on bid %x", sw.Caller, sw.Key, sw.Bid.Id))
 		}
+	}
 
-		if !slices.Contains(sw.Bid.AllowedPeekers, sw.Caller) && !slices.Contains(sw.Bid.AllowedPeekers, suave.AllowedPeekerAny) {
-			return fmt.Errorf("confidential engine: caller %x not allowed on bid %x", sw.Caller, sw.Bid.Id)
+	// Bids created by the source transaction count against its quotas, unless
+	// they were already stored by an earlier delivery of the message
+	var newBids []suave.Bid
+	for _, sw := range message.StoreWrites {
+		if sw.Bid.CreationTx == nil || sw.Bid.CreationTx.Hash() != message.SourceTx.Hash() || slices.ContainsFunc(newBids, func(bid suave.Bid) bool { return bid.Id == sw.Bid.Id }) {
+			continue
 		}
-
-		// TODO: move to types.Sender()
-		_, err = e.chainSigner.Sender(sw.Bid.CreationTx)
-		if err != nil {
-			return fmt.Errorf("confidential engine: creation tx for bid id %x is not signed properly: %w", sw.Bid.Id, err)
+		if _, err := e.storage.FetchBidById(sw.Bid.Id); err != nil {
+			newBids = append(newBids, sw.Bid)
 		}
 	}
+	if err := e.checkQuotas(e.quotaCounters(message.SourceTx, newBids, message.StoreWrites)); errors.Is(err, suave.ErrQuotaExceeded) {
+		return rejectMessage(rejectedQuotaCounter, err)
+	} else if err != nil {
+		return err
+	}
 
+	// Writes are applied again when a failed message is delivered again. Each
+	// write is charged as it is applied and refunded if it fails, so that the
+	// writes applied by an earlier delivery are not charged twice.
+	var failed int
 	for _, sw := range message.StoreWrites {
+		var writeBids []suave.Bid
+		newBidIdx := slices.IndexFunc(newBids, func(bid suave.Bid) bool { return bid.Id == sw.Bid.Id })
+		if newBidIdx != -1 {
+			writeBids = newBids[newBidIdx : newBidIdx+1]
+		}
+		counters := e.quotaCounters(message.SourceTx, writeBids, []StoreWrite{sw})
+		if err := e.chargeQuotas(counters); err != nil {
+			log.Error("confidential engine: could not charge store write", "err", err)
+			failed++
+			continue // Don't abandon!
+		}
+
 		err = e.storage.InitializeBid(sw.Bid)
 		if err != nil {
 			if !errors.Is(err, suave.ErrBidAlreadyPresent) {
-				log.Error("confidential engine: unexpected error while initializing bid from transport: %w", err)
+				log.Error("confidential engine: unexpected error while initializing bid from transport", "err", err)
+				e.refundQuotas(counters)
+				failed++
 				continue // Don't abandon!
 			}
 		}
 
 		_, err = e.storage.Store(sw.Bid, sw.Caller, sw.Key, sw.Value)
 		if err != nil {
-			log.Error("confidential engine: unexpected error while storing: %w", err)
+			log.Error("confidential engine: unexpected error while storing", "err", err)
+			e.refundQuotas(counters)
+			failed++
 			continue // Don't abandon!
 		}
+		if newBidIdx != -1 {
+			newBids = slices.Delete(newBids, newBidIdx, newBidIdx+1)
+		}
+
+		if err := e.recordBundleReplacement(sw); err != nil {
+			log.Error("confidential engine: unexpected error while recording bundle replacement", "err", err)
+			failed++
+		}
+	}
+
+	if failed != 0 {
+		failedMessageCounter.Inc(1)
+		return fmt.Errorf("confidential engine: could not apply %d of %d store writes", failed, len(message.StoreWrites))
+	}
+	return nil
+}
+
+// verifyBid checks that the id of a bid matches its contents and that it is
+// signed by the kettle its creation transaction was sent to.
+func verifyBid(bid *suave.Bid, daSigner DASigner, chainSigner ChainSigner) error {
+	expectedId, err := calculateBidId(types.Bid{
+		Id:                  bid.Id,
+		Salt:                bid.Salt,
+		DecryptionCondition: bid.DecryptionCondition,
+		AllowedPeekers:      bid.AllowedPeekers,
+		AllowedStores:       bid.AllowedStores,
+		Version:             bid.Version,
+	}, bid.Policy)
+	if err != nil {
+		return fmt.Errorf("could not calculate received bids id: %w", err)
+	}
+
+	if expectedId != bid.Id {
+		return fmt.Errorf("received bids id (%x) does not match the expected (%x)", bid.Id, expectedId)
+	}
+
+	bidBytes, err := SerializeBidForSigning(bid)
+	if err != nil {
+		return fmt.Errorf("could not hash received bid: %w", err)
+	}
+	recoveredBidSigner, err := daSigner.Sender(bidBytes, bid.Signature)
+	if err != nil {
+		return fmt.Errorf("incorrect bid signature: %w", err)
+	}
+	expectedBidSigner, err := KettleAddressFromTransaction(bid.CreationTx)
+	if err != nil {
+		return fmt.Errorf("could not recover signer from bid: %w", err)
+	}
+	if recoveredBidSigner != expectedBidSigner {
+		return fmt.Errorf("bid signer %x, expected %x", recoveredBidSigner, expectedBidSigner)
+	}
+
+	// TODO: move to types.Sender()
+	if _, err := chainSigner.Sender(bid.CreationTx); err != nil {
+		return fmt.Errorf("creation tx for bid id %x is not signed properly: %w", bid.Id, err)
 	}
 
 	return nil
@@ -382,6 +579,8 @@ func SerializeBidForSigning(bid *suave.Bid) ([]byte, error) {
 		AllowedPeekers:      bid.AllowedPeekers,
 		AllowedStores:       bid.AllowedStores,
 		Version:             bid.Version,
+		AccessWindow:        bid.AccessWindow,
+		Policy:              bid.Policy,
 		CreationTx:          bid.CreationTx,
 	})
 	if err != nil {
@@ -393,10 +592,11 @@ func SerializeBidForSigning(bid *suave.Bid) ([]byte, error) {
 
 func SerializeMessageForSigning(message *DAMessage) ([]byte, error) {
 	msgBytes, err := json.Marshal(DAMessage{
-		SourceTx:    message.SourceTx,
-		StoreWrites: message.StoreWrites,
-		StoreUUID:   message.StoreUUID,
-		Signature:   nil,
+		SourceTx:     message.SourceTx,
+		StoreWrites:  message.StoreWrites,
+		StoreUUID:    message.StoreUUID,
+		Signature:    nil,
+		KeyHandovers: message.KeyHandovers,
 	})
 	if err != nil {
 		return []byte{}, err
@@ -450,6 +650,11 @@ func KettleAddressFromTransaction(tx *types.Transaction) (common.Address, error)
 		return innerRequestTx.KettleAddress, nil
 	}
 
+	innerEncryptedRequestTx, ok := types.CastTxInner[*types.EncryptedConfidentialComputeRequest](tx)
+	if ok {
+		return innerEncryptedRequestTx.KettleAddress, nil
+	}
+
 	return common.Address{}, fmt.Errorf("transaction is not of confidential type")
 }
 
@@ -457,10 +662,15 @@ var emptyId [16]byte
 
 var bidUuidSpace = uuid.UUID{0x42}
 
-func calculateBidId(bid types.Bid) (types.BidId, error) {
+// calculateBidId derives the id of the bid from its fields and policy. Bids
+// without a policy keep the ids they had before policies were introduced.
+func calculateBidId(bid types.Bid, policy *suave.BidPolicy) (types.BidId, error) {
 	copy(bid.Id[:], emptyId[:])
 
-	body, err := json.Marshal(bid)
+	body, err := json.Marshal(struct {
+		types.Bid
+		Policy *suave.BidPolicy `json:",omitempty"`
+	}{bid, policy})
 	if err != nil {
 		return types.BidId{}, fmt.Errorf("could not marshal bid to calculate its id: %w", err)
 	}
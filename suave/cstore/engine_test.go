@@ -1,9 +1,14 @@
 package cstore
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"math/big"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -44,6 +49,10 @@ func (*FakeStoreBackend) Retrieve(bid suave.Bid, caller common.Address, key stri
 	return nil, errors.New("not implemented")
 }
 
+func (*FakeStoreBackend) StoredSize(bid suave.Bid, key string) (int, error) {
+	return 0, nil
+}
+
 func (*FakeStoreBackend) FetchBidById(suave.BidId) (suave.Bid, error) {
 	return suave.Bid{}, nil
 }
@@ -56,6 +65,22 @@ func (*FakeStoreBackend) SubmitBid(types.Bid) error {
 	return nil
 }
 
+func (*FakeStoreBackend) Usage(key string) (int64, error) {
+	return 0, nil
+}
+
+func (*FakeStoreBackend) AddUsage(key string, delta int64) (int64, error) {
+	return delta, nil
+}
+
+func (*FakeStoreBackend) Replacement(key string) (suave.BundleReplacement, bool, error) {
+	return suave.BundleReplacement{}, false, nil
+}
+
+func (*FakeStoreBackend) RecordReplacement(key string, replacement suave.BundleReplacement) error {
+	return nil
+}
+
 func TestOwnMessageDropping(t *testing.T) {
 	var wasCalled *bool = new(bool)
 	fakeStore := FakeStoreBackend{OnStore: func(bid suave.Bid, caller common.Address, key string, value []byte) (suave.Bid, error) {
@@ -78,7 +103,7 @@ func TestOwnMessageDropping(t *testing.T) {
 	bidId, err := calculateBidId(types.Bid{
 		AllowedStores:  []common.Address{{0x42}},
 		AllowedPeekers: []common.Address{{}},
-	})
+	}, nil)
 	require.NoError(t, err)
 	testBid := suave.Bid{
 		Id:             bidId,
@@ -124,3 +149,483 @@ func TestOwnMessageDropping(t *testing.T) {
 	require.NoError(t, err)
 	require.True(t, *wasCalled)
 }
+
+func TestCalculateBidId_Policy(t *testing.T) {
+	bid := types.Bid{
+		Salt:           RandomBidId(),
+		AllowedStores:  []common.Address{{0x42}},
+		AllowedPeekers: []common.Address{{0x43}},
+	}
+
+	legacyBody, err := json.Marshal(bid)
+	require.NoError(t, err)
+
+	noPolicyId, err := calculateBidId(bid, nil)
+	require.NoError(t, err)
+	require.Equal(t, types.BidId(uuid.NewSHA1(bidUuidSpace, legacyBody)), noPolicyId)
+
+	policyId, err := calculateBidId(bid, &suave.BidPolicy{Readers: []common.Address{{0x43}}})
+	require.NoError(t, err)
+	require.NotEqual(t, noPolicyId, policyId)
+
+	otherPolicyId, err := calculateBidId(bid, &suave.BidPolicy{Readers: []common.Address{{0x43}}, DirectCallerOnly: true})
+	require.NoError(t, err)
+	require.NotEqual(t, policyId, otherPolicyId)
+}
+
+func TestNewMessage_Quotas(t *testing.T) {
+	engine := NewConfidentialStoreEngine(NewLocalConfidentialStore(), MockTransport{}, MockSigner{}, MockChainSigner{})
+	engine.SetQuotas(suave.StoreQuotas{
+		Sender: suave.QuotaLimits{TotalBytes: 4},
+	})
+
+	testKey, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	sourceTx, err := types.SignTx(types.NewTx(&types.ConfidentialComputeRequest{
+		ConfidentialComputeRecord: types.ConfidentialComputeRecord{
+			KettleAddress: common.Address{0x42},
+		},
+	}), types.NewSuaveSigner(new(big.Int)), testKey)
+	require.NoError(t, err)
+
+	// The message of another kettle, signed with the kettle address
+	newMessage := func(value []byte) DAMessage {
+		bid := types.Bid{
+			Salt:           RandomBidId(),
+			AllowedStores:  []common.Address{{0x42}},
+			AllowedPeekers: []common.Address{{0x43}},
+		}
+		bid.Id, err = calculateBidId(bid, nil)
+		require.NoError(t, err)
+
+		storeBid := suave.Bid{
+			Id:             bid.Id,
+			Salt:           bid.Salt,
+			AllowedStores:  bid.AllowedStores,
+			AllowedPeekers: bid.AllowedPeekers,
+			CreationTx:     sourceTx,
+		}
+		bidBytes, err := SerializeBidForSigning(&storeBid)
+		require.NoError(t, err)
+		storeBid.Signature, err = MockSigner{}.Sign(common.Address{0x42}, bidBytes)
+		require.NoError(t, err)
+
+		msg := DAMessage{
+			SourceTx:    sourceTx,
+			StoreUUID:   uuid.New(),
+			StoreWrites: []StoreWrite{{Bid: storeBid, Caller: common.Address{0x43}, Key: "xx", Value: value}},
+		}
+		msgBytes, err := SerializeMessageForSigning(&msg)
+		require.NoError(t, err)
+		msg.Signature, err = MockSigner{}.Sign(common.Address{0x42}, msgBytes)
+		require.NoError(t, err)
+		return msg
+	}
+
+	msg := newMessage([]byte{0x1, 0x2, 0x3})
+	require.NoError(t, engine.NewMessage(msg))
+
+	// Delivering the same message again does not count twice
+	require.NoError(t, engine.NewMessage(msg))
+
+	err = engine.NewMessage(newMessage([]byte{0x1, 0x2}))
+	require.ErrorIs(t, err, suave.ErrQuotaExceeded)
+
+	require.NoError(t, engine.NewMessage(newMessage([]byte{0x1})))
+}
+
+// failingStoreBackend fails the writes of the key until it is cleared.
+type failingStoreBackend struct {
+	*LocalConfidentialStore
+	failKey string
+}
+
+func (b *failingStoreBackend) Store(bid suave.Bid, caller common.Address, key string, value []byte) (suave.Bid, error) {
+	if key == b.failKey {
+		return suave.Bid{}, errors.New("store unavailable")
+	}
+	return b.LocalConfidentialStore.Store(bid, caller, key, value)
+}
+
+func TestNewMessage_QuotasRefunded(t *testing.T) {
+	backend := &failingStoreBackend{LocalConfidentialStore: NewLocalConfidentialStore(), failKey: "yy"}
+	engine := NewConfidentialStoreEngine(backend, MockTransport{}, MockSigner{}, MockChainSigner{})
+	engine.SetQuotas(suave.StoreQuotas{
+		Sender: suave.QuotaLimits{TotalBytes: 4},
+	})
+
+	testKey, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	sourceTx, err := types.SignTx(types.NewTx(&types.ConfidentialComputeRequest{
+		ConfidentialComputeRecord: types.ConfidentialComputeRecord{
+			KettleAddress: common.Address{0x42},
+		},
+	}), types.NewSuaveSigner(new(big.Int)), testKey)
+	require.NoError(t, err)
+
+	bid := types.Bid{
+		Salt:           RandomBidId(),
+		AllowedStores:  []common.Address{{0x42}},
+		AllowedPeekers: []common.Address{{0x43}},
+	}
+	bid.Id, err = calculateBidId(bid, nil)
+	require.NoError(t, err)
+
+	storeBid := suave.Bid{
+		Id:             bid.Id,
+		Salt:           bid.Salt,
+		AllowedStores:  bid.AllowedStores,
+		AllowedPeekers: bid.AllowedPeekers,
+		CreationTx:     sourceTx,
+	}
+	bidBytes, err := SerializeBidForSigning(&storeBid)
+	require.NoError(t, err)
+	storeBid.Signature, err = MockSigner{}.Sign(common.Address{0x42}, bidBytes)
+	require.NoError(t, err)
+
+	msg := DAMessage{
+		SourceTx:  sourceTx,
+		StoreUUID: uuid.New(),
+		StoreWrites: []StoreWrite{
+			{Bid: storeBid, Caller: common.Address{0x43}, Key: "xx", Value: []byte{0x1, 0x2}},
+			{Bid: storeBid, Caller: common.Address{0x43}, Key: "yy", Value: []byte{0x3, 0x4}},
+		},
+	}
+	msgBytes, err := SerializeMessageForSigning(&msg)
+	require.NoError(t, err)
+	msg.Signature, err = MockSigner{}.Sign(common.Address{0x42}, msgBytes)
+	require.NoError(t, err)
+
+	sender := crypto.PubkeyToAddress(testKey.PublicKey)
+	usageKey := fmt.Sprintf("quota-sender-%x-bytes", sender)
+
+	// Only the applied write is charged
+	require.ErrorContains(t, engine.NewMessage(msg), "could not apply 1 of 2 store writes")
+	usage, err := backend.Usage(usageKey)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), usage)
+
+	// The redelivery charges the write that failed, and only it
+	backend.failKey = ""
+	require.NoError(t, engine.NewMessage(msg))
+	usage, err = backend.Usage(usageKey)
+	require.NoError(t, err)
+	require.Equal(t, int64(4), usage)
+}
+
+// ackTransport delivers the messages sent to it and records the acknowledged ones.
+type ackTransport struct {
+	MockTransport
+	ch   chan DAMessage
+	acks chan DAMessage
+}
+
+func (a *ackTransport) Subscribe() (<-chan DAMessage, context.CancelFunc) {
+	return a.ch, func() {}
+}
+
+func (a *ackTransport) Ack(msg DAMessage) error {
+	a.acks <- msg
+	return nil
+}
+
+func TestProcessMessages_Ack(t *testing.T) {
+	backend := &failingStoreBackend{LocalConfidentialStore: NewLocalConfidentialStore(), failKey: "yy"}
+	transport := &ackTransport{ch: make(chan DAMessage), acks: make(chan DAMessage, 16)}
+	engine := NewConfidentialStoreEngine(backend, transport, MockSigner{}, MockChainSigner{})
+	require.NoError(t, engine.Start())
+	t.Cleanup(func() { engine.Stop() })
+
+	testKey, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	sourceTx, err := types.SignTx(types.NewTx(&types.ConfidentialComputeRequest{
+		ConfidentialComputeRecord: types.ConfidentialComputeRecord{
+			KettleAddress: common.Address{0x42},
+		},
+	}), types.NewSuaveSigner(new(big.Int)), testKey)
+	require.NoError(t, err)
+
+	bid := types.Bid{
+		Salt:           RandomBidId(),
+		AllowedStores:  []common.Address{{0x42}},
+		AllowedPeekers: []common.Address{{0x43}},
+	}
+	bid.Id, err = calculateBidId(bid, nil)
+	require.NoError(t, err)
+
+	storeBid := suave.Bid{
+		Id:             bid.Id,
+		Salt:           bid.Salt,
+		AllowedStores:  bid.AllowedStores,
+		AllowedPeekers: bid.AllowedPeekers,
+		CreationTx:     sourceTx,
+	}
+	bidBytes, err := SerializeBidForSigning(&storeBid)
+	require.NoError(t, err)
+	storeBid.Signature, err = MockSigner{}.Sign(common.Address{0x42}, bidBytes)
+	require.NoError(t, err)
+
+	newMessage := func(id string, signer common.Address, key string) DAMessage {
+		msg := DAMessage{
+			SourceTx:    sourceTx,
+			StoreUUID:   uuid.New(),
+			StoreWrites: []StoreWrite{{Bid: storeBid, Caller: common.Address{0x43}, Key: key, Value: []byte{0x1}}},
+			transportId: id,
+		}
+		msgBytes, err := SerializeMessageForSigning(&msg)
+		require.NoError(t, err)
+		msg.Signature, err = MockSigner{}.Sign(signer, msgBytes)
+		require.NoError(t, err)
+		return msg
+	}
+	nextAck := func() string {
+		select {
+		case msg := <-transport.acks:
+			return msg.transportId
+		case <-time.After(time.Second):
+			t.Fatal("message not acknowledged")
+			return ""
+		}
+	}
+
+	// Messages that can never be applied are acknowledged once
+	transport.ch <- newMessage("invalid", common.Address{0x66}, "xx")
+	require.Equal(t, "invalid", nextAck())
+
+	// Messages the backend failed to store are left to be delivered again
+	transport.ch <- newMessage("failed", common.Address{0x42}, "yy")
+	transport.ch <- newMessage("applied", common.Address{0x42}, "xx")
+	require.Equal(t, "applied", nextAck())
+
+	select {
+	case msg := <-transport.acks:
+		t.Fatalf("unexpected acknowledgement of %s", msg.transportId)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestNewMessage_BundleReplacements(t *testing.T) {
+	engine := NewConfidentialStoreEngine(NewLocalConfidentialStore(), MockTransport{}, MockSigner{}, MockChainSigner{})
+
+	testKey, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	searcher := crypto.PubkeyToAddress(testKey.PublicKey)
+	replacementUuid := uuid.New()
+
+	// The message of another kettle storing a bundle in a bid created by the request
+	newMessage := func(nonce uint64, bundle *types.SBundle) (DAMessage, suave.BidId) {
+		sourceTx, err := types.SignTx(types.NewTx(&types.ConfidentialComputeRequest{
+			ConfidentialComputeRecord: types.ConfidentialComputeRecord{
+				KettleAddress: common.Address{0x42},
+				Nonce:         nonce,
+			},
+		}), types.NewSuaveSigner(new(big.Int)), testKey)
+		require.NoError(t, err)
+
+		bid := types.Bid{
+			Salt:           RandomBidId(),
+			AllowedStores:  []common.Address{{0x42}},
+			AllowedPeekers: []common.Address{{0x43}},
+		}
+		bid.Id, err = calculateBidId(bid, nil)
+		require.NoError(t, err)
+
+		storeBid := suave.Bid{
+			Id:             bid.Id,
+			Salt:           bid.Salt,
+			AllowedStores:  bid.AllowedStores,
+			AllowedPeekers: bid.AllowedPeekers,
+			CreationTx:     sourceTx,
+		}
+		bidBytes, err := SerializeBidForSigning(&storeBid)
+		require.NoError(t, err)
+		storeBid.Signature, err = MockSigner{}.Sign(common.Address{0x42}, bidBytes)
+		require.NoError(t, err)
+
+		bundleBytes, err := json.Marshal(bundle)
+		require.NoError(t, err)
+		msg := DAMessage{
+			SourceTx:    sourceTx,
+			StoreUUID:   uuid.New(),
+			StoreWrites: []StoreWrite{{Bid: storeBid, Caller: common.Address{0x43}, Key: "default:v0:ethBundles", Value: bundleBytes}},
+		}
+		msgBytes, err := SerializeMessageForSigning(&msg)
+		require.NoError(t, err)
+		msg.Signature, err = MockSigner{}.Sign(common.Address{0x42}, msgBytes)
+		require.NoError(t, err)
+		return msg, bid.Id
+	}
+	tx := types.NewTx(&types.LegacyTx{Nonce: 1})
+
+	_, found, err := engine.BundleReplacement(searcher, replacementUuid)
+	require.NoError(t, err)
+	require.False(t, found)
+
+	msg, bidId := newMessage(2, &types.SBundle{Txs: types.Transactions{tx}, ReplacementUuid: &replacementUuid})
+	require.NoError(t, engine.NewMessage(msg))
+	replacement, found, err := engine.BundleReplacement(searcher, replacementUuid)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, suave.BundleReplacement{BidId: bidId, Nonce: 2}, replacement)
+
+	// Bundles of earlier requests delivered late do not replace it
+	msg, _ = newMessage(1, &types.SBundle{Txs: types.Transactions{tx}, ReplacementUuid: &replacementUuid})
+	require.NoError(t, engine.NewMessage(msg))
+	replacement, _, err = engine.BundleReplacement(searcher, replacementUuid)
+	require.NoError(t, err)
+	require.Equal(t, bidId, replacement.BidId)
+
+	msg, bidId = newMessage(3, &types.SBundle{ReplacementUuid: &replacementUuid})
+	require.NoError(t, engine.NewMessage(msg))
+	replacement, _, err = engine.BundleReplacement(searcher, replacementUuid)
+	require.NoError(t, err)
+	require.Equal(t, suave.BundleReplacement{BidId: bidId, Nonce: 3, Cancelled: true}, replacement)
+	require.True(t, replacement.Replaces(2))
+
+	// Bundles without a replacement UUID are not recorded
+	msg, _ = newMessage(4, &types.SBundle{Txs: types.Transactions{tx}})
+	require.NoError(t, engine.NewMessage(msg))
+	replacement, _, err = engine.BundleReplacement(searcher, replacementUuid)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), replacement.Nonce)
+}
+
+func TestKettleKeyRotation(t *testing.T) {
+	oldKey, newKey := common.Address{0x42}, common.Address{0x44}
+	daSigner := FakeDASigner{localAddresses: []common.Address{oldKey, newKey}}
+
+	keysPath := filepath.Join(t.TempDir(), "kettlekeys.json")
+	keys, err := NewKettleKeyRing(keysPath, 24*time.Hour)
+	require.NoError(t, err)
+
+	engine := NewConfidentialStoreEngine(NewLocalConfidentialStore(), MockTransport{}, daSigner, MockChainSigner{})
+	engine.SetKeyRing(keys)
+	remote := NewConfidentialStoreEngine(NewLocalConfidentialStore(), MockTransport{}, FakeDASigner{}, MockChainSigner{})
+
+	testKey, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	newRequest := func(kettle common.Address) *types.Transaction {
+		tx, err := types.SignTx(types.NewTx(&types.ConfidentialComputeRequest{
+			ConfidentialComputeRecord: types.ConfidentialComputeRecord{
+				KettleAddress: kettle,
+			},
+		}), types.NewSuaveSigner(new(big.Int)), testKey)
+		require.NoError(t, err)
+		return tx
+	}
+
+	// A bid created before the rotation, only the old key can store to it
+	bid, err := engine.InitializeBid(types.Bid{
+		Salt:           RandomBidId(),
+		AllowedPeekers: []common.Address{{0x43}},
+	}, newRequest(oldKey))
+	require.NoError(t, err)
+	bid.AllowedStores = []common.Address{oldKey}
+	bid.Id, err = calculateBidId(bid.ToInnerBid(), nil)
+	require.NoError(t, err)
+	bidBytes, err := SerializeBidForSigning(&bid)
+	require.NoError(t, err)
+	bid.Signature, err = daSigner.Sign(oldKey, bidBytes)
+	require.NoError(t, err)
+
+	newMessage := func(kettle common.Address, handovers []*suave.KettleKeyHandover) DAMessage {
+		msg := DAMessage{
+			SourceTx:     newRequest(kettle),
+			StoreUUID:    uuid.New(),
+			StoreWrites:  []StoreWrite{{Bid: bid, Caller: common.Address{0x43}, Key: "xx", Value: []byte{0x1}}},
+			KeyHandovers: handovers,
+		}
+		msgBytes, err := SerializeMessageForSigning(&msg)
+		require.NoError(t, err)
+		msg.Signature, err = daSigner.Sign(kettle, msgBytes)
+		require.NoError(t, err)
+		return msg
+	}
+
+	require.NoError(t, remote.NewMessage(newMessage(oldKey, nil)))
+	require.Error(t, remote.NewMessage(newMessage(newKey, nil)))
+
+	_, err = engine.RotateKettleKey(oldKey, oldKey, time.Hour)
+	require.Error(t, err)
+	_, err = engine.RotateKettleKey(oldKey, common.Address{0x45}, time.Hour)
+	require.Error(t, err)
+
+	handover, err := engine.RotateKettleKey(oldKey, newKey, time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, []*suave.KettleKeyHandover{handover}, engine.keys.handoversTo(newKey))
+
+	kettleKeys := engine.KettleKeys()
+	require.Len(t, kettleKeys, 2)
+	require.Equal(t, newKey, kettleKeys[0].Address)
+	require.Equal(t, suave.KettleKeyActive, kettleKeys[0].Status)
+	require.Equal(t, kettleKeys[0].ActiveSince+uint64(24*time.Hour/time.Second), kettleKeys[0].RotateBy)
+	require.Equal(t, oldKey, kettleKeys[1].Address)
+	require.Equal(t, suave.KettleKeyRetiring, kettleKeys[1].Status)
+	require.Equal(t, handover, kettleKeys[1].Handover)
+
+	// Remote stores accept the new key once they verified the handover, and
+	// the old key until the end of the grace window
+	require.NoError(t, remote.NewMessage(newMessage(newKey, []*suave.KettleKeyHandover{handover})))
+	require.NoError(t, remote.NewMessage(newMessage(newKey, nil)))
+	require.NoError(t, remote.NewMessage(newMessage(oldKey, nil)))
+
+	forged := *handover
+	forged.NewAddress = common.Address{0x45}
+	require.Error(t, remote.NewMessage(newMessage(common.Address{0x45}, []*suave.KettleKeyHandover{&forged})))
+
+	// A key can only be handed over once
+	_, err = engine.RotateKettleKey(oldKey, newKey, time.Hour)
+	require.NoError(t, err)
+	otherHandover := &suave.KettleKeyHandover{OldAddress: oldKey, NewAddress: common.Address{0x45}}
+	otherHandover.OldSignature, _ = daSigner.Sign(oldKey, nil)
+	otherHandover.NewSignature, _ = daSigner.Sign(common.Address{0x45}, nil)
+	require.ErrorIs(t, remote.addHandovers(common.Address{0x45}, []*suave.KettleKeyHandover{otherHandover}, []common.Address{oldKey}), errKeyHandedOver)
+
+	// Handovers are only accepted from known kettles and must lead to the
+	// signer of the message
+	strangerHandover := &suave.KettleKeyHandover{OldAddress: common.Address{0x47}, NewAddress: common.Address{0x48}}
+	strangerHandover.OldSignature, _ = daSigner.Sign(strangerHandover.OldAddress, nil)
+	strangerHandover.NewSignature, _ = daSigner.Sign(strangerHandover.NewAddress, nil)
+	require.ErrorContains(t, remote.addHandovers(common.Address{0x48}, []*suave.KettleKeyHandover{strangerHandover}, nil), "unknown kettle")
+	require.ErrorContains(t, remote.addHandovers(common.Address{0x49}, []*suave.KettleKeyHandover{strangerHandover}, []common.Address{{0x47}}), "does not lead to the message signer")
+	require.False(t, remote.keys.hasHandover(strangerHandover))
+
+	// The rotation is persisted
+	reloaded, err := NewKettleKeyRing(keysPath, 24*time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, handover, reloaded.handovers[oldKey])
+	require.Equal(t, kettleKeys[0].ActiveSince, reloaded.activeSince[newKey])
+
+	// Once the grace window ended the old key is rejected, the bids it signed
+	// remain valid
+	expired := &suave.KettleKeyHandover{
+		OldAddress: newKey,
+		NewAddress: common.Address{0x46},
+		RotatedAt:  uint64(time.Now().Add(-2 * time.Hour).Unix()),
+		GraceUntil: uint64(time.Now().Add(-time.Hour).Unix()),
+	}
+	expired.OldSignature, _ = daSigner.Sign(expired.OldAddress, nil)
+	expired.NewSignature, _ = daSigner.Sign(expired.NewAddress, nil)
+	require.NoError(t, remote.addHandovers(expired.NewAddress, []*suave.KettleKeyHandover{expired}, nil))
+	require.NoError(t, engine.addHandovers(expired.NewAddress, []*suave.KettleKeyHandover{expired}, nil))
+
+	require.ErrorIs(t, remote.NewMessage(newMessage(newKey, nil)), suave.ErrKettleKeyRetired)
+	require.NoError(t, remote.NewMessage(newMessage(common.Address{0x46}, nil)))
+
+	_, err = engine.InitializeBid(types.Bid{AllowedPeekers: []common.Address{{0x43}}}, newRequest(newKey))
+	require.ErrorIs(t, err, suave.ErrKettleKeyRetired)
+	require.ErrorIs(t, engine.Finalize(newRequest(newKey), nil, nil), suave.ErrKettleKeyRetired)
+
+	require.Equal(t, suave.KettleKeyRetired, engine.KettleKeys()[1].Status)
+}
+
+func TestKettleKeyRing_MaxHandovers(t *testing.T) {
+	keys, err := NewKettleKeyRing("", 0)
+	require.NoError(t, err)
+
+	for i := 0; i < maxKettleKeyHandovers; i++ {
+		h := &suave.KettleKeyHandover{OldAddress: common.BigToAddress(big.NewInt(int64(2 * i))), NewAddress: common.BigToAddress(big.NewInt(int64(2*i + 1)))}
+		require.NoError(t, keys.addHandover(h))
+		// Recording a handover again does not add it twice
+		require.NoError(t, keys.addHandover(h))
+	}
+	require.Len(t, keys.handovers, maxKettleKeyHandovers)
+
+	h := &suave.KettleKeyHandover{OldAddress: common.Address{0x42}, NewAddress: common.Address{0x44}}
+	require.ErrorIs(t, keys.addHandover(h), errTooManyHandovers)
+}
@@ -0,0 +1,464 @@
+package cstore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	suave "github.com/ethereum/go-ethereum/suave/core"
+	"golang.org/x/exp/slices"
+)
+
+var keyRotationCheckInterval = time.Hour
+
+// maxKettleKeyHandovers bounds the handovers a key ring records, each of them
+// is kept for good as it extends the stores of the bids of the old key.
+const maxKettleKeyHandovers = 1024
+
+var (
+	errKeyHandedOver    = errors.New("kettle key was already handed over")
+	errTooManyHandovers = fmt.Errorf("more than %d kettle key handovers", maxKettleKeyHandovers)
+)
+
+// KettleKeyRing tracks the rotations of kettle signing keys. It records when
+// each local key became active, so that it can be rotated before it gets too
+// old, and the handovers of local and remote keys, so that a new key is
+// accepted on the bids its predecessors could store to.
+type KettleKeyRing struct {
+	path   string // empty keeps the ring in memory
+	maxAge time.Duration
+
+	lock        sync.RWMutex
+	activeSince map[common.Address]uint64
+	handovers   map[common.Address]*suave.KettleKeyHandover // by old address
+}
+
+type kettleKeyRingFile struct {
+	ActiveSince map[common.Address]uint64  `json:"activeSince"`
+	Handovers   []*suave.KettleKeyHandover `json:"handovers"`
+}
+
+// NewKettleKeyRing loads the key ring persisted at path, if any. Active keys
+// older than maxAge are reported as due for rotation, a zero maxAge disables
+// the check.
+func NewKettleKeyRing(path string, maxAge time.Duration) (*KettleKeyRing, error) {
+	r := newMemoryKeyRing()
+	r.path = path
+	r.maxAge = maxAge
+
+	if path == "" {
+		return r, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return r, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("could not read kettle keys: %w", err)
+	}
+
+	var file kettleKeyRingFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("could not parse kettle keys %s: %w", path, err)
+	}
+	for addr, since := range file.ActiveSince {
+		r.activeSince[addr] = since
+	}
+	for _, h := range file.Handovers {
+		r.handovers[h.OldAddress] = h
+	}
+	return r, nil
+}
+
+func newMemoryKeyRing() *KettleKeyRing {
+	return &KettleKeyRing{
+		activeSince: make(map[common.Address]uint64),
+		handovers:   make(map[common.Address]*suave.KettleKeyHandover),
+	}
+}
+
+// Keys returns the status of the given local keys, active keys first.
+func (r *KettleKeyRing) Keys(local []common.Address) []*suave.KettleKey {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	now := uint64(time.Now().Unix())
+
+	changed := false
+	keys := make([]*suave.KettleKey, 0, len(local))
+	for _, addr := range local {
+		since, found := r.activeSince[addr]
+		if !found {
+			// First time the key is seen, its age is counted from now
+			since = now
+			r.activeSince[addr] = since
+			changed = true
+		}
+
+		key := &suave.KettleKey{
+			Address:     addr,
+			Status:      suave.KettleKeyActive,
+			ActiveSince: since,
+		}
+		if h, found := r.handovers[addr]; found {
+			key.Handover = h
+			key.Status = suave.KettleKeyRetiring
+			if now > h.GraceUntil {
+				key.Status = suave.KettleKeyRetired
+			}
+		} else if r.maxAge != 0 {
+			key.RotateBy = since + uint64(r.maxAge/time.Second)
+		}
+		keys = append(keys, key)
+	}
+
+	if changed {
+		if err := r.save(); err != nil {
+			log.Error("could not save kettle keys", "err", err)
+		}
+	}
+
+	order := map[suave.KettleKeyStatus]int{suave.KettleKeyActive: 0, suave.KettleKeyRetiring: 1, suave.KettleKeyRetired: 2}
+	sort.SliceStable(keys, func(i, j int) bool {
+		return order[keys[i].Status] < order[keys[j].Status]
+	})
+	return keys
+}
+
+// addHandover records a verified handover. A key can only be handed over
+// once, and not to a key that was itself handed over.
+func (r *KettleKeyRing) addHandover(h *suave.KettleKeyHandover) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if prev, found := r.handovers[h.OldAddress]; found {
+		if prev.NewAddress != h.NewAddress {
+			return fmt.Errorf("%w: %s to %s", errKeyHandedOver, h.OldAddress, prev.NewAddress)
+		}
+		return nil
+	}
+	if next, found := r.handovers[h.NewAddress]; found {
+		return fmt.Errorf("%w: %s to %s", errKeyHandedOver, h.NewAddress, next.NewAddress)
+	}
+	if len(r.handovers) >= maxKettleKeyHandovers {
+		return errTooManyHandovers
+	}
+
+	r.handovers[h.OldAddress] = h
+	if _, found := r.activeSince[h.NewAddress]; !found {
+		r.activeSince[h.NewAddress] = h.RotatedAt
+	}
+	return r.save()
+}
+
+// hasHandover reports whether the handover is already recorded.
+func (r *KettleKeyRing) hasHandover(h *suave.KettleKeyHandover) bool {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	prev, found := r.handovers[h.OldAddress]
+	return found && prev.NewAddress == h.NewAddress && prev.RotatedAt == h.RotatedAt && prev.GraceUntil == h.GraceUntil
+}
+
+// isSuccessor reports whether addr was handed over to.
+func (r *KettleKeyRing) isSuccessor(addr common.Address) bool {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	for _, h := range r.handovers {
+		if h.NewAddress == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// checkSigningKey returns an error if the key was handed over and its grace
+// window ended.
+func (r *KettleKeyRing) checkSigningKey(addr common.Address) error {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	h, found := r.handovers[addr]
+	if !found || uint64(time.Now().Unix()) <= h.GraceUntil {
+		return nil
+	}
+	return fmt.Errorf("%w: %s was handed over to %s, grace window ended at %s", suave.ErrKettleKeyRetired, addr, h.NewAddress, time.Unix(int64(h.GraceUntil), 0).UTC())
+}
+
+// isAllowedStore reports whether addr is one of the allowed stores or the
+// successor of one of them.
+func (r *KettleKeyRing) isAllowedStore(allowed []common.Address, addr common.Address) bool {
+	if slices.Contains(allowed, addr) {
+		return true
+	}
+
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	for _, store := range allowed {
+		// Handovers form chains, addresses cannot be handed over twice
+		for h := r.handovers[store]; h != nil; h = r.handovers[h.NewAddress] {
+			if h.NewAddress == addr {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// handoversTo returns the handovers that lead to addr, oldest first.
+func (r *KettleKeyRing) handoversTo(addr common.Address) []*suave.KettleKeyHandover {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	var res []*suave.KettleKeyHandover
+	successors := map[common.Address]struct{}{addr: {}}
+	for changed := true; changed; {
+		changed = false
+		for old, h := range r.handovers {
+			if _, found := successors[h.NewAddress]; !found {
+				continue
+			}
+			if _, found := successors[old]; found {
+				continue
+			}
+			successors[old] = struct{}{}
+			res = append(res, h)
+			changed = true
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].RotatedAt < res[j].RotatedAt
+	})
+	return res
+}
+
+// save writes the ring to its file, the lock must be held.
+func (r *KettleKeyRing) save() error {
+	if r.path == "" {
+		return nil
+	}
+
+	file := kettleKeyRingFile{
+		ActiveSince: r.activeSince,
+		Handovers:   make([]*suave.KettleKeyHandover, 0, len(r.handovers)),
+	}
+	for _, h := range r.handovers {
+		file.Handovers = append(file.Handovers, h)
+	}
+	sort.Slice(file.Handovers, func(i, j int) bool {
+		return file.Handovers[i].RotatedAt < file.Handovers[j].RotatedAt
+	})
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(r.path), filepath.Base(r.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), r.path)
+}
+
+// SetKeyRing replaces the in-memory key ring the engine starts with.
+func (e *ConfidentialStoreEngine) SetKeyRing(ring *KettleKeyRing) {
+	e.keys = ring
+}
+
+// KettleKeys returns the status of the local signing keys, active keys first.
+func (e *ConfidentialStoreEngine) KettleKeys() []*suave.KettleKey {
+	return e.keys.Keys(e.daSigner.LocalAddresses())
+}
+
+// RotateKettleKey hands the identity of the kettle over from oldAddr to
+// newAddr, which must both be local accounts. The old key is still accepted
+// for the grace period, after which the kettle stops signing with it. The
+// handover is published to the other kettles, and attached to every message
+// signed by the new key so that kettles which missed it can verify it.
+func (e *ConfidentialStoreEngine) RotateKettleKey(oldAddr, newAddr common.Address, grace time.Duration) (*suave.KettleKeyHandover, error) {
+	if oldAddr == newAddr {
+		return nil, errors.New("confidential engine: cannot rotate a kettle key to itself")
+	}
+	local := e.daSigner.LocalAddresses()
+	for _, addr := range []common.Address{oldAddr, newAddr} {
+		if !slices.Contains(local, addr) {
+			return nil, fmt.Errorf("confidential engine: %s is not a local account", addr)
+		}
+	}
+	if err := e.keys.checkSigningKey(oldAddr); err != nil {
+		return nil, fmt.Errorf("confidential engine: %w", err)
+	}
+
+	rotatedAt := time.Now()
+	handover := &suave.KettleKeyHandover{
+		OldAddress: oldAddr,
+		NewAddress: newAddr,
+		RotatedAt:  uint64(rotatedAt.Unix()),
+		GraceUntil: uint64(rotatedAt.Add(grace).Unix()),
+	}
+
+	handoverBytes, err := SerializeHandoverForSigning(handover)
+	if err != nil {
+		return nil, fmt.Errorf("confidential engine: could not hash handover for signing: %w", err)
+	}
+	if handover.OldSignature, err = e.daSigner.Sign(oldAddr, handoverBytes); err != nil {
+		return nil, fmt.Errorf("confidential engine: could not sign handover with %s: %w", oldAddr, err)
+	}
+	if handover.NewSignature, err = e.daSigner.Sign(newAddr, handoverBytes); err != nil {
+		return nil, fmt.Errorf("confidential engine: could not sign handover with %s: %w", newAddr, err)
+	}
+
+	if err := e.keys.addHandover(handover); err != nil {
+		return nil, fmt.Errorf("confidential engine: %w", err)
+	}
+	log.Info("Rotated kettle key", "old", oldAddr, "new", newAddr, "graceUntil", rotatedAt.Add(grace))
+
+	// Announce the rotation, the message is signed by the new key
+	msg := DAMessage{
+		KeyHandovers: []*suave.KettleKeyHandover{handover},
+		StoreUUID:    e.storeUUID,
+	}
+	msgBytes, err := SerializeMessageForSigning(&msg)
+	if err != nil {
+		return nil, fmt.Errorf("confidential engine: could not hash message for signing: %w", err)
+	}
+	if msg.Signature, err = e.daSigner.Sign(newAddr, msgBytes); err != nil {
+		return nil, fmt.Errorf("confidential engine: could not sign message: %w", err)
+	}
+	go e.transportTopic.Publish(msg)
+
+	return handover, nil
+}
+
+// addHandovers verifies and records the handovers of a message signed by
+// signer, oldest first. Anyone can sign a handover between two keys of their
+// own, so the old key of a handover must be a known kettle: a local key, a key
+// handed over to before, or one of the given stores of the verified bids of
+// the message. The handovers must lead to the signer of the message.
+func (e *ConfidentialStoreEngine) addHandovers(signer common.Address, handovers []*suave.KettleKeyHandover, stores []common.Address) error {
+	for i, h := range handovers {
+		if e.keys.hasHandover(h) {
+			continue
+		}
+
+		err := e.verifyHandover(h)
+		if err == nil && !e.isKnownKettle(h.OldAddress, stores) {
+			err = errors.New("unknown kettle")
+		}
+		if err == nil && !handoversLeadTo(handovers[i:], signer) {
+			err = fmt.Errorf("does not lead to the message signer %s", signer)
+		}
+		if err != nil {
+			return rejectMessage(rejectedSigningKeyCounter, fmt.Errorf("confidential engine: invalid key handover from %s to %s: %w", h.OldAddress, h.NewAddress, err))
+		}
+
+		if err := e.keys.addHandover(h); errors.Is(err, errKeyHandedOver) || errors.Is(err, errTooManyHandovers) {
+			return rejectMessage(rejectedSigningKeyCounter, fmt.Errorf("confidential engine: %w", err))
+		} else if err != nil {
+			return fmt.Errorf("confidential engine: could not record key handover: %w", err)
+		}
+	}
+	return nil
+}
+
+func (e *ConfidentialStoreEngine) isKnownKettle(addr common.Address, stores []common.Address) bool {
+	if slices.Contains(stores, addr) || slices.Contains(e.daSigner.LocalAddresses(), addr) {
+		return true
+	}
+	return e.keys.isSuccessor(addr)
+}
+
+// handoversLeadTo reports whether the first handover leads to addr through
+// the next ones.
+func handoversLeadTo(handovers []*suave.KettleKeyHandover, addr common.Address) bool {
+	next := handovers[0].NewAddress
+	for _, h := range handovers[1:] {
+		if h.OldAddress == next {
+			next = h.NewAddress
+		}
+	}
+	return next == addr
+}
+
+func (e *ConfidentialStoreEngine) verifyHandover(h *suave.KettleKeyHandover) error {
+	if h.OldAddress == h.NewAddress {
+		return errors.New("key handed over to itself")
+	}
+	if h.GraceUntil < h.RotatedAt {
+		return fmt.Errorf("grace window ends at %d, before the rotation at %d", h.GraceUntil, h.RotatedAt)
+	}
+
+	handoverBytes, err := SerializeHandoverForSigning(h)
+	if err != nil {
+		return err
+	}
+	for _, signer := range []struct {
+		addr      common.Address
+		signature []byte
+	}{{h.OldAddress, h.OldSignature}, {h.NewAddress, h.NewSignature}} {
+		recovered, err := e.daSigner.Sender(handoverBytes, signer.signature)
+		if err != nil {
+			return fmt.Errorf("incorrect signature of %s: %w", signer.addr, err)
+		}
+		if recovered != signer.addr {
+			return fmt.Errorf("signed by %s, expected %s", recovered, signer.addr)
+		}
+	}
+	return nil
+}
+
+// watchKeyRotation warns about the local keys that are due for rotation
+// until the engine is stopped.
+func (e *ConfidentialStoreEngine) watchKeyRotation() {
+	ticker := time.NewTicker(keyRotationCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		now := uint64(time.Now().Unix())
+		for _, key := range e.KettleKeys() {
+			if key.RotateBy != 0 && now > key.RotateBy {
+				log.Warn("Kettle key is due for rotation", "address", key.Address, "activeSince", time.Unix(int64(key.ActiveSince), 0).UTC(), "rotateBy", time.Unix(int64(key.RotateBy), 0).UTC())
+			}
+		}
+
+		select {
+		case <-e.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func SerializeHandoverForSigning(h *suave.KettleKeyHandover) ([]byte, error) {
+	handoverBytes, err := json.Marshal(suave.KettleKeyHandover{
+		OldAddress: h.OldAddress,
+		NewAddress: h.NewAddress,
+		RotatedAt:  h.RotatedAt,
+		GraceUntil: h.GraceUntil,
+	})
+	if err != nil {
+		return []byte{}, err
+	}
+
+	return []byte(fmt.Sprintf("\x19Suave Signed Message:\n%d%s", len(handoverBytes), string(handoverBytes))), nil
+}
@@ -1,8 +1,10 @@
 package cstore
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"sort"
 	"sync"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -10,13 +12,16 @@ import (
 	suave "github.com/ethereum/go-ethereum/suave/core"
 )
 
-var _ ConfidentialStorageBackend = &LocalConfidentialStore{}
+var _ ExportableStorageBackend = &LocalConfidentialStore{}
 
 type LocalConfidentialStore struct {
 	lock    sync.Mutex
 	bids    map[suave.BidId]suave.Bid
 	dataMap map[string][]byte
 	index   map[string][]suave.BidId
+	usage   map[string]int64
+
+	replacements map[string]suave.BundleReplacement
 }
 
 func NewLocalConfidentialStore() *LocalConfidentialStore {
@@ -24,6 +29,9 @@ func NewLocalConfidentialStore() *LocalConfidentialStore {
 		bids:    make(map[suave.BidId]suave.Bid),
 		dataMap: make(map[string][]byte),
 		index:   make(map[string][]suave.BidId),
+		usage:   make(map[string]int64),
+
+		replacements: make(map[string]suave.BundleReplacement),
 	}
 }
 
@@ -74,6 +82,13 @@ func (l *LocalConfidentialStore) Retrieve(bid suave.Bid, caller common.Address,
 	return append(make([]byte, 0, len(data)), data...), nil
 }
 
+func (l *LocalConfidentialStore) StoredSize(bid suave.Bid, key string) (int, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	return len(l.dataMap[fmt.Sprintf("%x-%s", bid.Id, key)]), nil
+}
+
 func (l *LocalConfidentialStore) FetchBidById(bidId suave.BidId) (suave.Bid, error) {
 	l.lock.Lock()
 	defer l.lock.Unlock()
@@ -106,3 +121,80 @@ func (l *LocalConfidentialStore) FetchBidsByProtocolAndBlock(blockNumber uint64,
 
 	return res
 }
+
+func (l *LocalConfidentialStore) Usage(key string) (int64, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	return l.usage[key], nil
+}
+
+func (l *LocalConfidentialStore) AddUsage(key string, delta int64) (int64, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.usage[key] += delta
+	return l.usage[key], nil
+}
+
+func (l *LocalConfidentialStore) Replacement(key string) (suave.BundleReplacement, bool, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	replacement, ok := l.replacements[key]
+	return replacement, ok, nil
+}
+
+func (l *LocalConfidentialStore) RecordReplacement(key string, replacement suave.BundleReplacement) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if recorded, ok := l.replacements[key]; ok && recorded.Nonce > replacement.Nonce {
+		return nil
+	}
+	l.replacements[key] = replacement
+	return nil
+}
+
+// CountBids returns the number of bids stored by namespace.
+func (l *LocalConfidentialStore) CountBids() (map[string]int64, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	counts := make(map[string]int64)
+	for _, bid := range l.bids {
+		counts[bid.Version]++
+	}
+	return counts, nil
+}
+
+func (l *LocalConfidentialStore) ForEachBid(fn func(bid suave.Bid, values map[string][]byte) error) error {
+	// Work on a snapshot, fn may use the store
+	l.lock.Lock()
+	bids := make([]suave.Bid, 0, len(l.bids))
+	for _, bid := range l.bids {
+		bids = append(bids, bid)
+	}
+	// Data is stored under the hex id of the bid followed by the key
+	values := make(map[string]map[string][]byte, len(bids))
+	for storeKey, value := range l.dataMap {
+		idLen := 2 * len(suave.BidId{})
+		if len(storeKey) <= idLen || storeKey[idLen] != '-' {
+			continue
+		}
+		id, key := storeKey[:idLen], storeKey[idLen+1:]
+		if values[id] == nil {
+			values[id] = make(map[string][]byte)
+		}
+		values[id][key] = append(make([]byte, 0, len(value)), value...)
+	}
+	l.lock.Unlock()
+
+	sort.Slice(bids, func(i, j int) bool { return bytes.Compare(bids[i].Id[:], bids[j].Id[:]) < 0 })
+	for _, bid := range bids {
+		if err := fn(bid, values[fmt.Sprintf("%x", bid.Id)]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
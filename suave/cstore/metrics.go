@@ -0,0 +1,265 @@
+package cstore
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	suave "github.com/ethereum/go-ethereum/suave/core"
+)
+
+var (
+	finalizeTimer   = metrics.NewRegisteredTimer("suave/cstore/finalize", nil)
+	newMessageTimer = metrics.NewRegisteredTimer("suave/cstore/message/process", nil)
+
+	receivedMessageCounter = metrics.NewRegisteredCounter("suave/cstore/message/received", nil)
+	appliedMessageCounter  = metrics.NewRegisteredCounter("suave/cstore/message/applied", nil)
+	failedMessageCounter   = metrics.NewRegisteredCounter("suave/cstore/message/failed", nil) // Valid messages the backend could not store
+
+	// Messages rejected by the engine, by reason
+	rejectedMalformedCounter  = metrics.NewRegisteredCounter("suave/cstore/message/rejected/malformed", nil)
+	rejectedSignatureCounter  = metrics.NewRegisteredCounter("suave/cstore/message/rejected/signature", nil)
+	rejectedSigningKeyCounter = metrics.NewRegisteredCounter("suave/cstore/message/rejected/signingkey", nil) // Unknown handovers or retired keys
+	rejectedSourceTxCounter   = metrics.NewRegisteredCounter("suave/cstore/message/rejected/sourcetx", nil)
+	rejectedBidCounter        = metrics.NewRegisteredCounter("suave/cstore/message/rejected/bid", nil) // Bad bid id or bid signature
+	rejectedStoreCounter      = metrics.NewRegisteredCounter("suave/cstore/message/rejected/store", nil)
+	rejectedWriterCounter     = metrics.NewRegisteredCounter("suave/cstore/message/rejected/writer", nil)
+	rejectedQuotaCounter      = metrics.NewRegisteredCounter("suave/cstore/message/rejected/quota", nil)
+
+	publishedMessageCounter = metrics.NewRegisteredCounter("suave/cstore/transport/published", nil)
+	publishFailedCounter    = metrics.NewRegisteredCounter("suave/cstore/transport/publish/failed", nil)
+	droppedMessageCounter   = metrics.NewRegisteredCounter("suave/cstore/transport/dropped", nil)
+	messageLagTimer         = metrics.NewRegisteredTimer("suave/cstore/transport/lag", nil) // Time from publishing to delivery
+
+	backendInitializeTimer  = metrics.NewRegisteredTimer("suave/cstore/backend/initialize", nil)
+	backendStoreTimer       = metrics.NewRegisteredTimer("suave/cstore/backend/store", nil)
+	backendRetrieveTimer    = metrics.NewRegisteredTimer("suave/cstore/backend/retrieve", nil)
+	backendFetchTimer       = metrics.NewRegisteredTimer("suave/cstore/backend/fetch", nil)
+	backendFetchBlockTimer  = metrics.NewRegisteredTimer("suave/cstore/backend/fetchblock", nil)
+	backendUsageTimer       = metrics.NewRegisteredTimer("suave/cstore/backend/usage", nil)
+	backendReplacementTimer = metrics.NewRegisteredTimer("suave/cstore/backend/replacement", nil)
+	backendErrorCounter     = metrics.NewRegisteredCounter("suave/cstore/backend/errors", nil)
+)
+
+// maxBidGaugeNamespaces bounds the number of bid gauges, namespaces are
+// chosen by contracts. Bids of further namespaces are counted as "other".
+const maxBidGaugeNamespaces = 256
+
+// bidGaugeSampleInterval is the interval the bid gauges are sampled from the
+// storage backend at.
+var bidGaugeSampleInterval = time.Minute
+
+// bidCountingBackend is implemented by the storage backends that can count
+// their bids, to sample the bid gauges.
+type bidCountingBackend interface {
+	// CountBids returns the number of bids stored by namespace.
+	CountBids() (map[string]int64, error)
+}
+
+// rejectMessage counts a message rejected for the reason of the counter.
+func rejectMessage(reason metrics.Counter, err error) error {
+	reason.Inc(1)
+	return &rejectedMessageError{err}
+}
+
+// rejectedMessageError is returned for messages that can never be applied,
+// as opposed to valid messages the backend failed to store.
+type rejectedMessageError struct {
+	err error
+}
+
+func (e *rejectedMessageError) Error() string { return e.err.Error() }
+func (e *rejectedMessageError) Unwrap() error { return e.err }
+
+// meteredStorageBackend measures the latency and errors of the operations of
+// a storage backend, and the number of bids stored in it by namespace.
+type meteredStorageBackend struct {
+	ConfidentialStorageBackend
+
+	lock      sync.Mutex
+	bidGauges map[string]metrics.Gauge
+}
+
+func newMeteredStorageBackend(backend ConfidentialStorageBackend) *meteredStorageBackend {
+	return &meteredStorageBackend{
+		ConfidentialStorageBackend: backend,
+		bidGauges:                  make(map[string]metrics.Gauge),
+	}
+}
+
+// bidGauge returns the gauge of the number of bids of the namespace, the
+// lock must be held.
+func (m *meteredStorageBackend) bidGauge(namespace string) metrics.Gauge {
+	if gauge, ok := m.bidGauges[namespace]; ok {
+		return gauge
+	}
+	name := "other"
+	if len(m.bidGauges) < maxBidGaugeNamespaces {
+		name = metricsNamespace(namespace)
+	}
+	gauge := metrics.GetOrRegisterGauge("suave/cstore/bids/"+name, nil)
+	if name != "other" {
+		m.bidGauges[namespace] = gauge
+	}
+	return gauge
+}
+
+// sampleBidGauges sets the bid gauges to the number of bids of each
+// namespace in the backend, so that expired bids are no longer counted.
+func (m *meteredStorageBackend) sampleBidGauges() error {
+	backend, ok := m.ConfidentialStorageBackend.(bidCountingBackend)
+	if !ok {
+		return nil
+	}
+	counts, err := backend.CountBids()
+	if err != nil {
+		m.countError(err)
+		return err
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	samples := make(map[metrics.Gauge]int64)
+	for _, gauge := range m.bidGauges {
+		samples[gauge] = 0
+	}
+	samples[metrics.GetOrRegisterGauge("suave/cstore/bids/other", nil)] = 0
+	for namespace, count := range counts {
+		samples[m.bidGauge(namespace)] += count
+	}
+	for gauge, count := range samples {
+		gauge.Update(count)
+	}
+	return nil
+}
+
+// sampleBidGauges samples the bid gauges from the storage backend until the
+// engine is stopped.
+func (e *ConfidentialStoreEngine) sampleBidGauges() {
+	storage, ok := e.storage.(*meteredStorageBackend)
+	if !ok {
+		return
+	}
+
+	ticker := time.NewTicker(bidGaugeSampleInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := storage.sampleBidGauges(); err != nil {
+			log.Warn("could not sample bid gauges", "err", err)
+		}
+
+		select {
+		case <-e.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// metricsNamespace returns the namespace with the characters that are not
+// allowed in metric names replaced.
+func metricsNamespace(namespace string) string {
+	if namespace == "" {
+		return "none"
+	}
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == ':' {
+			return r
+		}
+		return '_'
+	}, namespace)
+}
+
+func (m *meteredStorageBackend) countError(err error) {
+	if err != nil {
+		backendErrorCounter.Inc(1)
+	}
+}
+
+func (m *meteredStorageBackend) InitializeBid(bid suave.Bid) error {
+	defer backendInitializeTimer.UpdateSince(time.Now())
+
+	err := m.ConfidentialStorageBackend.InitializeBid(bid)
+	if err == nil {
+		// Counted until the next sample
+		m.lock.Lock()
+		m.bidGauge(bid.Version).Inc(1)
+		m.lock.Unlock()
+	} else if !errors.Is(err, suave.ErrBidAlreadyPresent) {
+		m.countError(err)
+	}
+	return err
+}
+
+func (m *meteredStorageBackend) Store(bid suave.Bid, caller common.Address, key string, value []byte) (suave.Bid, error) {
+	defer backendStoreTimer.UpdateSince(time.Now())
+
+	bid, err := m.ConfidentialStorageBackend.Store(bid, caller, key, value)
+	m.countError(err)
+	return bid, err
+}
+
+func (m *meteredStorageBackend) Retrieve(bid suave.Bid, caller common.Address, key string) ([]byte, error) {
+	defer backendRetrieveTimer.UpdateSince(time.Now())
+
+	// Missing data is the caller's problem, not an error of the backend
+	return m.ConfidentialStorageBackend.Retrieve(bid, caller, key)
+}
+
+func (m *meteredStorageBackend) StoredSize(bid suave.Bid, key string) (int, error) {
+	defer backendRetrieveTimer.UpdateSince(time.Now())
+
+	size, err := m.ConfidentialStorageBackend.StoredSize(bid, key)
+	m.countError(err)
+	return size, err
+}
+
+func (m *meteredStorageBackend) FetchBidById(bidId suave.BidId) (suave.Bid, error) {
+	defer backendFetchTimer.UpdateSince(time.Now())
+
+	return m.ConfidentialStorageBackend.FetchBidById(bidId)
+}
+
+func (m *meteredStorageBackend) FetchBidsByProtocolAndBlock(blockNumber uint64, namespace string) []suave.Bid {
+	defer backendFetchBlockTimer.UpdateSince(time.Now())
+
+	return m.ConfidentialStorageBackend.FetchBidsByProtocolAndBlock(blockNumber, namespace)
+}
+
+func (m *meteredStorageBackend) Usage(key string) (int64, error) {
+	defer backendUsageTimer.UpdateSince(time.Now())
+
+	usage, err := m.ConfidentialStorageBackend.Usage(key)
+	m.countError(err)
+	return usage, err
+}
+
+func (m *meteredStorageBackend) AddUsage(key string, delta int64) (int64, error) {
+	defer backendUsageTimer.UpdateSince(time.Now())
+
+	usage, err := m.ConfidentialStorageBackend.AddUsage(key, delta)
+	m.countError(err)
+	return usage, err
+}
+
+func (m *meteredStorageBackend) Replacement(key string) (suave.BundleReplacement, bool, error) {
+	defer backendReplacementTimer.UpdateSince(time.Now())
+
+	replacement, ok, err := m.ConfidentialStorageBackend.Replacement(key)
+	m.countError(err)
+	return replacement, ok, err
+}
+
+func (m *meteredStorageBackend) RecordReplacement(key string, replacement suave.BundleReplacement) error {
+	defer backendReplacementTimer.UpdateSince(time.Now())
+
+	err := m.ConfidentialStorageBackend.RecordReplacement(key, replacement)
+	m.countError(err)
+	return err
+}
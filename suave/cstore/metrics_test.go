@@ -0,0 +1,91 @@
+package cstore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/metrics"
+	suave "github.com/ethereum/go-ethereum/suave/core"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetered_StoreSuite(t *testing.T) {
+	store := newMeteredStorageBackend(NewLocalConfidentialStore())
+	testBackendStore(t, store)
+}
+
+func TestMetered_SampleBidGauges(t *testing.T) {
+	enabled := metrics.Enabled
+	metrics.Enabled = true
+	t.Cleanup(func() { metrics.Enabled = enabled })
+
+	redisStore, err := NewRedisStoreBackend("")
+	require.NoError(t, err)
+	t.Cleanup(func() { redisStore.Stop() })
+
+	store := newMeteredStorageBackend(redisStore)
+	for _, namespace := range []string{"gaugetest:a", "gaugetest:a", "gaugetest:b"} {
+		require.NoError(t, store.InitializeBid(suave.Bid{Id: suave.RandomBidId(), Version: namespace}))
+	}
+
+	gaugeA := metrics.GetOrRegisterGauge("suave/cstore/bids/gaugetest:a", nil)
+	gaugeB := metrics.GetOrRegisterGauge("suave/cstore/bids/gaugetest:b", nil)
+	require.NoError(t, store.sampleBidGauges())
+	require.Equal(t, int64(2), gaugeA.Value())
+	require.Equal(t, int64(1), gaugeB.Value())
+
+	// Expired bids are no longer counted
+	redisStore.local.FastForward(ffStoreTTL)
+	require.NoError(t, store.sampleBidGauges())
+	require.Zero(t, gaugeA.Value())
+	require.Zero(t, gaugeB.Value())
+}
+
+func TestCountBids(t *testing.T) {
+	pebbleStore, err := NewPebbleStoreBackend(t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { pebbleStore.Stop() })
+
+	redisStore, err := NewRedisStoreBackend("")
+	require.NoError(t, err)
+	t.Cleanup(func() { redisStore.Stop() })
+
+	backends := map[string]interface {
+		ConfidentialStorageBackend
+		bidCountingBackend
+	}{
+		"local":  NewLocalConfidentialStore(),
+		"pebble": pebbleStore,
+		"redis":  redisStore,
+	}
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			for _, namespace := range []string{"a", "a", "b"} {
+				bid := suave.Bid{Id: suave.RandomBidId(), Version: namespace}
+				require.NoError(t, backend.InitializeBid(bid))
+				_, err := backend.Store(bid, common.Address{}, "key", []byte{0x42})
+				require.NoError(t, err)
+			}
+
+			counts, err := backend.CountBids()
+			require.NoError(t, err)
+			require.Equal(t, map[string]int64{"a": 2, "b": 1}, counts)
+		})
+	}
+}
+
+func TestMetricsNamespace(t *testing.T) {
+	require.Equal(t, "none", metricsNamespace(""))
+	require.Equal(t, "mevshare:v0:unmatchedBundles", metricsNamespace("mevshare:v0:unmatchedBundles"))
+	require.Equal(t, "default_v0_ethBundles__", metricsNamespace("default-v0.ethBundles /"))
+}
+
+func TestStreamEntryTime(t *testing.T) {
+	published, ok := streamEntryTime("1700000000123-4")
+	require.True(t, ok)
+	require.Equal(t, time.UnixMilli(1700000000123), published)
+
+	_, ok = streamEntryTime("invalid")
+	require.False(t, ok)
+}
@@ -1,10 +1,13 @@
 package cstore
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
+	"sync"
 
 	"github.com/cockroachdb/pebble"
 	"github.com/ethereum/go-ethereum/common"
@@ -15,13 +18,21 @@ import (
 var (
 	formatPebbleBidKey      = formatRedisBidKey
 	formatPebbleBidValueKey = formatRedisBidValueKey
+	formatPebbleUsageKey    = formatRedisUsageKey
+
+	formatPebbleReplacementKey = formatRedisReplacementKey
 )
 
+var _ ExportableStorageBackend = &PebbleStoreBackend{}
+
 type PebbleStoreBackend struct {
 	ctx    context.Context
 	cancel context.CancelFunc
 	dbPath string
 	db     *pebble.DB
+
+	usageLock       sync.Mutex
+	replacementLock sync.Mutex
 }
 
 var bidByBlockAndProtocolIndexDbKey = func(blockNumber uint64, namespace string) []byte {
@@ -150,6 +161,17 @@ func (b *PebbleStoreBackend) Retrieve(bid suave.Bid, caller common.Address, key
 	return ret, nil
 }
 
+func (b *PebbleStoreBackend) StoredSize(bid suave.Bid, key string) (int, error) {
+	data, closer, err := b.db.Get([]byte(formatPebbleBidValueKey(bid.Id, key)))
+	if errors.Is(err, pebble.ErrNotFound) {
+		return 0, nil
+	} else if err != nil {
+		return 0, fmt.Errorf("could not fetch data for bid %x and key %s: %w", bid.Id, key, err)
+	}
+	defer closer.Close()
+	return len(data), nil
+}
+
 func (b *PebbleStoreBackend) FetchBidsByProtocolAndBlock(blockNumber uint64, namespace string) []suave.Bid {
 	dbBlockProtoIndexKey := bidByBlockAndProtocolIndexDbKey(blockNumber, namespace)
 	rawCurrentValues, closer, err := b.db.Get(dbBlockProtoIndexKey)
@@ -174,3 +196,150 @@ func (b *PebbleStoreBackend) FetchBidsByProtocolAndBlock(blockNumber uint64, nam
 
 	return bids
 }
+
+// CountBids returns the number of bids stored by namespace.
+func (b *PebbleStoreBackend) CountBids() (map[string]int64, error) {
+	bidKeyLen := len(formatPebbleBidKey(suave.BidId{}))
+
+	iter := b.db.NewIter(prefixIterOptions([]byte("bid-")))
+	defer iter.Close()
+
+	counts := make(map[string]int64)
+	for iter.First(); iter.Valid(); iter.Next() {
+		if len(iter.Key()) != bidKeyLen || bytes.HasPrefix(iter.Key(), []byte("bid-data-")) {
+			continue
+		}
+
+		var bid struct{ Version string }
+		if err := json.Unmarshal(iter.Value(), &bid); err != nil {
+			return nil, fmt.Errorf("could not unmarshal stored bid %s: %w", iter.Key(), err)
+		}
+		counts[bid.Version]++
+	}
+	return counts, iter.Error()
+}
+
+func (b *PebbleStoreBackend) ForEachBid(fn func(bid suave.Bid, values map[string][]byte) error) error {
+	// Bid keys are "bid-" followed by the hex id, data keys share the prefix
+	bidKeyLen := len(formatPebbleBidKey(suave.BidId{}))
+
+	iter := b.db.NewIter(prefixIterOptions([]byte("bid-")))
+	defer iter.Close()
+
+	for iter.First(); iter.Valid(); iter.Next() {
+		if len(iter.Key()) != bidKeyLen || bytes.HasPrefix(iter.Key(), []byte("bid-data-")) {
+			continue
+		}
+
+		var bid suave.Bid
+		if err := json.Unmarshal(iter.Value(), &bid); err != nil {
+			return fmt.Errorf("could not unmarshal stored bid %s: %w", iter.Key(), err)
+		}
+
+		values, err := b.bidValues(bid.Id)
+		if err != nil {
+			return err
+		}
+		if err := fn(bid, values); err != nil {
+			return err
+		}
+	}
+	return iter.Error()
+}
+
+func (b *PebbleStoreBackend) bidValues(bidId suave.BidId) (map[string][]byte, error) {
+	prefix := formatPebbleBidValueKey(bidId, "")
+
+	iter := b.db.NewIter(prefixIterOptions([]byte(prefix)))
+	defer iter.Close()
+
+	values := make(map[string][]byte)
+	for iter.First(); iter.Valid(); iter.Next() {
+		values[string(iter.Key()[len(prefix):])] = append([]byte{}, iter.Value()...)
+	}
+	return values, iter.Error()
+}
+
+// prefixIterOptions bounds an iterator to the keys starting with prefix.
+func prefixIterOptions(prefix []byte) *pebble.IterOptions {
+	upper := append([]byte{}, prefix...)
+	upper[len(upper)-1]++
+	return &pebble.IterOptions{LowerBound: prefix, UpperBound: upper}
+}
+
+func (b *PebbleStoreBackend) Usage(key string) (int64, error) {
+	b.usageLock.Lock()
+	defer b.usageLock.Unlock()
+
+	return b.usage(key)
+}
+
+func (b *PebbleStoreBackend) AddUsage(key string, delta int64) (int64, error) {
+	b.usageLock.Lock()
+	defer b.usageLock.Unlock()
+
+	usage, err := b.usage(key)
+	if err != nil {
+		return 0, err
+	}
+
+	usage += delta
+	if err := b.db.Set([]byte(formatPebbleUsageKey(key)), []byte(strconv.FormatInt(usage, 10)), nil); err != nil {
+		return 0, err
+	}
+	return usage, nil
+}
+
+func (b *PebbleStoreBackend) usage(key string) (int64, error) {
+	data, closer, err := b.db.Get([]byte(formatPebbleUsageKey(key)))
+	if errors.Is(err, pebble.ErrNotFound) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	defer closer.Close()
+
+	return strconv.ParseInt(string(data), 10, 64)
+}
+
+func (b *PebbleStoreBackend) Replacement(key string) (suave.BundleReplacement, bool, error) {
+	b.replacementLock.Lock()
+	defer b.replacementLock.Unlock()
+
+	return b.replacement(key)
+}
+
+func (b *PebbleStoreBackend) RecordReplacement(key string, replacement suave.BundleReplacement) error {
+	b.replacementLock.Lock()
+	defer b.replacementLock.Unlock()
+
+	recorded, ok, err := b.replacement(key)
+	if err != nil {
+		return err
+	}
+	if ok && recorded.Nonce > replacement.Nonce {
+		return nil
+	}
+
+	data, err := json.Marshal(replacement)
+	if err != nil {
+		return err
+	}
+	return b.db.Set([]byte(formatPebbleReplacementKey(key)), data, nil)
+}
+
+func (b *PebbleStoreBackend) replacement(key string) (suave.BundleReplacement, bool, error) {
+	data, closer, err := b.db.Get([]byte(formatPebbleReplacementKey(key)))
+	if errors.Is(err, pebble.ErrNotFound) {
+		return suave.BundleReplacement{}, false, nil
+	} else if err != nil {
+		return suave.BundleReplacement{}, false, err
+	}
+	defer closer.Close()
+
+	var replacement suave.BundleReplacement
+	if err := json.Unmarshal(data, &replacement); err != nil {
+		return suave.BundleReplacement{}, false, err
+	}
+	return replacement, true, nil
+}
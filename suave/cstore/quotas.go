@@ -0,0 +1,183 @@
+package cstore
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	suave "github.com/ethereum/go-ethereum/suave/core"
+)
+
+// quotaCounter is a usage counter changed by the writes of a request.
+type quotaCounter struct {
+	key   string
+	delta int64
+	limit uint64
+	desc  string
+}
+
+func (c *quotaCounter) exceeded(usage int64) error {
+	return fmt.Errorf("confidential engine: %w: %s would be %d, limit is %d", suave.ErrQuotaExceeded, c.desc, usage, c.limit)
+}
+
+type quotaPrincipal struct {
+	kind   string
+	addr   common.Address
+	limits suave.QuotaLimits
+}
+
+// SetQuotas limits the bids and bytes each request signer and contract can
+// add to the store. The limits apply to local requests and to the messages
+// received from other kettles alike.
+func (e *ConfidentialStoreEngine) SetQuotas(quotas suave.StoreQuotas) {
+	e.quotas = quotas
+}
+
+// quotaCounters returns the usage counters the new bids and writes of a
+// request change. Bytes are accounted as the difference with the data
+// already stored, so overwriting a value only counts the growth.
+func (e *ConfidentialStoreEngine) quotaCounters(sourceTx *types.Transaction, newBids []suave.Bid, writes []StoreWrite) []*quotaCounter {
+	return e.quotaCountersWithSizes(sourceTx, newBids, writes, map[string]int{})
+}
+
+// quotaCountersWithSizes is like quotaCounters, with the sizes of the values
+// already written by the request. The sizes of the given writes are added.
+func (e *ConfidentialStoreEngine) quotaCountersWithSizes(sourceTx *types.Transaction, newBids []suave.Bid, writes []StoreWrite, sizes map[string]int) []*quotaCounter {
+	if e.quotas == (suave.StoreQuotas{}) || sourceTx == nil {
+		return nil
+	}
+
+	var principals []quotaPrincipal
+	if sender, err := e.chainSigner.Sender(sourceTx); err == nil {
+		principals = append(principals, quotaPrincipal{"sender", sender, e.quotas.Sender})
+	}
+	if contract := sourceTx.To(); contract != nil {
+		principals = append(principals, quotaPrincipal{"contract", *contract, e.quotas.Contract})
+	}
+
+	var (
+		bidBytes   = map[suave.BidId]int64{}
+		bidOrder   []suave.BidId
+		totalBytes int64
+	)
+	for _, sw := range writes {
+		storeKey := quotaSizeKey(sw)
+		prev, found := sizes[storeKey]
+		if !found {
+			size, err := e.storage.StoredSize(sw.Bid, sw.Key)
+			if err != nil {
+				log.Warn("confidential engine: could not look up stored size", "bid", sw.Bid.Id, "key", sw.Key, "err", err)
+			}
+			prev = size
+		}
+		sizes[storeKey] = len(sw.Value)
+
+		if _, found := bidBytes[sw.Bid.Id]; !found {
+			bidOrder = append(bidOrder, sw.Bid.Id)
+		}
+		delta := int64(len(sw.Value) - prev)
+		bidBytes[sw.Bid.Id] += delta
+		totalBytes += delta
+	}
+
+	var counters []*quotaCounter
+
+	// The bytes of a bid are bound by the lowest limit of the principals writing to it
+	var bytesPerBid uint64
+	for _, p := range principals {
+		if p.limits.BytesPerBid != 0 && (bytesPerBid == 0 || p.limits.BytesPerBid < bytesPerBid) {
+			bytesPerBid = p.limits.BytesPerBid
+		}
+	}
+	if bytesPerBid != 0 {
+		for _, bidId := range bidOrder {
+			counters = append(counters, &quotaCounter{
+				key:   fmt.Sprintf("quota-bid-%x-bytes", bidId),
+				delta: bidBytes[bidId],
+				limit: bytesPerBid,
+				desc:  fmt.Sprintf("bytes of bid %x", bidId),
+			})
+		}
+	}
+
+	for _, p := range principals {
+		if p.limits.BidsPerBlock != 0 {
+			bidsPerBlock := map[uint64]int64{}
+			var blocks []uint64
+			for _, bid := range newBids {
+				if _, found := bidsPerBlock[bid.DecryptionCondition]; !found {
+					blocks = append(blocks, bid.DecryptionCondition)
+				}
+				bidsPerBlock[bid.DecryptionCondition]++
+			}
+			for _, block := range blocks {
+				counters = append(counters, &quotaCounter{
+					key:   fmt.Sprintf("quota-%s-%x-bids-bn-%d", p.kind, p.addr, block),
+					delta: bidsPerBlock[block],
+					limit: p.limits.BidsPerBlock,
+					desc:  fmt.Sprintf("bids of %s %s for block %d", p.kind, p.addr, block),
+				})
+			}
+		}
+
+		if p.limits.TotalBytes != 0 && totalBytes != 0 {
+			counters = append(counters, &quotaCounter{
+				key:   fmt.Sprintf("quota-%s-%x-bytes", p.kind, p.addr),
+				delta: totalBytes,
+				limit: p.limits.TotalBytes,
+				desc:  fmt.Sprintf("bytes of %s %s", p.kind, p.addr),
+			})
+		}
+	}
+
+	return counters
+}
+
+func quotaSizeKey(sw StoreWrite) string {
+	return fmt.Sprintf("%x-%s", sw.Bid.Id, sw.Key)
+}
+
+// checkQuotas returns an error if the counters would exceed their limits,
+// without changing them.
+func (e *ConfidentialStoreEngine) checkQuotas(counters []*quotaCounter) error {
+	for _, c := range counters {
+		if c.delta <= 0 {
+			continue
+		}
+
+		usage, err := e.storage.Usage(c.key)
+		if err != nil {
+			return fmt.Errorf("confidential engine: could not read quota usage: %w", err)
+		}
+		if usage+c.delta > int64(c.limit) {
+			return c.exceeded(usage + c.delta)
+		}
+	}
+	return nil
+}
+
+// chargeQuotas updates the counters, or leaves them untouched and returns an
+// error if any of them would exceed its limit.
+func (e *ConfidentialStoreEngine) chargeQuotas(counters []*quotaCounter) error {
+	for i, c := range counters {
+		usage, err := e.storage.AddUsage(c.key, c.delta)
+		if err != nil {
+			e.refundQuotas(counters[:i])
+			return fmt.Errorf("confidential engine: could not update quota usage: %w", err)
+		}
+		if c.delta > 0 && usage > int64(c.limit) {
+			e.refundQuotas(counters[:i+1])
+			return c.exceeded(usage)
+		}
+	}
+	return nil
+}
+
+func (e *ConfidentialStoreEngine) refundQuotas(counters []*quotaCounter) {
+	for _, c := range counters {
+		if _, err := e.storage.AddUsage(c.key, -c.delta); err != nil {
+			log.Error("confidential engine: could not refund quota usage", "key", c.key, "err", err)
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package cstore
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"math/big"
 	"testing"
@@ -149,7 +151,7 @@ func TestEngineOnRedis(t *testing.T) {
 		t.Error("did not receive expected message")
 	}
 
-	retrievedData, err := engine2.Retrieve(bid.Id, bid.AllowedPeekers[0], "xx")
+	retrievedData, err := engine2.Retrieve(bid.Id, bid.AllowedPeekers[0], "xx", 0)
 	require.NoError(t, err)
 	require.Equal(t, []byte{0x43, 0x14}, retrievedData)
 
@@ -161,3 +163,176 @@ func TestEngineOnRedis(t *testing.T) {
 
 	require.Equal(t, submittedBidJson, fetchedBidJson)
 }
+
+func setRedisStreamsBlock(t *testing.T, block time.Duration) {
+	prev := redisStreamsBlock
+	redisStreamsBlock = block
+	t.Cleanup(func() { redisStreamsBlock = prev })
+}
+
+func newTestStreamsTransport(t *testing.T, addr string, group string, maxAge time.Duration) (*RedisStreamsTransport, <-chan DAMessage) {
+	transport := NewRedisStreamsTransport(addr, group, maxAge)
+	require.NoError(t, transport.Start())
+	t.Cleanup(func() { transport.Stop() })
+
+	ch, cancel := transport.Subscribe()
+	t.Cleanup(cancel)
+	return transport, ch
+}
+
+func receiveDAMessage(t *testing.T, ch <-chan DAMessage) DAMessage {
+	select {
+	case msg := <-ch:
+		return msg
+	case <-time.After(time.Second):
+		t.Fatal("did not receive expected message")
+		return DAMessage{}
+	}
+}
+
+func requireNoDAMessage(t *testing.T, ch <-chan DAMessage) {
+	select {
+	case <-ch:
+		t.Error("received an unexpected message")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestRedisStreamsTransport(t *testing.T) {
+	setRedisStreamsBlock(t, 10*time.Millisecond)
+	mr := miniredis.RunT(t)
+
+	daMsg := DAMessage{
+		StoreWrites: []StoreWrite{{
+			Bid: suave.Bid{
+				Id:                  suave.BidId{0x42},
+				DecryptionCondition: uint64(13),
+				AllowedPeekers:      []common.Address{{0x41, 0x39}},
+				Version:             string("vv"),
+			},
+			Value: suave.Bytes{},
+		}},
+		Signature: []byte{},
+	}
+
+	kettle1, kettle1Ch := newTestStreamsTransport(t, mr.Addr(), "kettle1", 0)
+	kettle1.Publish(daMsg)
+
+	msg := receiveDAMessage(t, kettle1Ch)
+	require.Equal(t, daMsg.StoreWrites, msg.StoreWrites)
+	require.NotEmpty(t, msg.transportId)
+
+	// Messages published before a kettle joined are delivered to it as well
+	_, kettle2Ch := newTestStreamsTransport(t, mr.Addr(), "kettle2", 0)
+	require.Equal(t, daMsg.StoreWrites, receiveDAMessage(t, kettle2Ch).StoreWrites)
+
+	// Unacknowledged messages are delivered again after a restart
+	require.NoError(t, kettle1.Stop())
+	kettle1, kettle1Ch = newTestStreamsTransport(t, mr.Addr(), "kettle1", 0)
+
+	msg = receiveDAMessage(t, kettle1Ch)
+	require.Equal(t, daMsg.StoreWrites, msg.StoreWrites)
+	require.NoError(t, kettle1.Ack(msg))
+
+	require.NoError(t, kettle1.Stop())
+	_, kettle1Ch = newTestStreamsTransport(t, mr.Addr(), "kettle1", 0)
+	requireNoDAMessage(t, kettle1Ch)
+
+	require.Error(t, kettle1.Ack(daMsg))
+}
+
+func TestRedisStreamsTransport_Redelivery(t *testing.T) {
+	setRedisStreamsBlock(t, 10*time.Millisecond)
+	mr := miniredis.RunT(t)
+
+	transport := NewRedisStreamsTransport(mr.Addr(), "kettle", 0)
+	transport.claimIdle = 50 * time.Millisecond
+	transport.maxDeliveries = 2
+	require.NoError(t, transport.Start())
+	t.Cleanup(func() { transport.Stop() })
+
+	ch, cancel := transport.Subscribe()
+	t.Cleanup(cancel)
+
+	transport.Publish(DAMessage{Signature: []byte{0x1}})
+	require.Equal(t, suave.Bytes{0x1}, receiveDAMessage(t, ch).Signature)
+
+	// Not acknowledged in time, delivered again until it is dropped
+	require.Equal(t, suave.Bytes{0x1}, receiveDAMessage(t, ch).Signature)
+	requireNoDAMessage(t, ch)
+
+	time.Sleep(100 * time.Millisecond)
+	pending, err := transport.client.XPending(context.Background(), redisUpsertStream, "kettle").Result()
+	require.NoError(t, err)
+	require.Zero(t, pending.Count)
+}
+
+func TestRedisStreamsTransport_TrimByAge(t *testing.T) {
+	setRedisStreamsBlock(t, 10*time.Millisecond)
+	mr := miniredis.RunT(t)
+
+	transport, _ := newTestStreamsTransport(t, mr.Addr(), "kettle", time.Hour)
+
+	mr.SetTime(time.Now().Add(-2 * time.Hour))
+	transport.Publish(DAMessage{})
+	transport.Publish(DAMessage{})
+	mr.SetTime(time.Now())
+	transport.Publish(DAMessage{})
+
+	length, err := transport.client.XLen(context.Background(), redisUpsertStream).Result()
+	require.NoError(t, err)
+	require.Equal(t, int64(1), length)
+}
+
+func TestEngineOnRedisStreams(t *testing.T) {
+	setRedisStreamsBlock(t, 10*time.Millisecond)
+	mrTransport := miniredis.RunT(t)
+
+	engine1 := NewConfidentialStoreEngine(NewLocalConfidentialStore(), NewRedisStreamsTransport(mrTransport.Addr(), "kettle1", 0), MockSigner{}, MockChainSigner{})
+	require.NoError(t, engine1.Start())
+	t.Cleanup(func() { engine1.Stop() })
+
+	engine2 := NewConfidentialStoreEngine(NewLocalConfidentialStore(), NewRedisStreamsTransport(mrTransport.Addr(), "kettle2", 0), MockSigner{}, MockChainSigner{})
+	require.NoError(t, engine2.Start())
+	t.Cleanup(func() { engine2.Stop() })
+
+	testKey, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	dummyCreationTx, err := types.SignTx(types.NewTx(&types.ConfidentialComputeRequest{
+		ConfidentialComputeRecord: types.ConfidentialComputeRecord{
+			KettleAddress: common.Address{},
+		},
+	}), types.NewSuaveSigner(new(big.Int)), testKey)
+	require.NoError(t, err)
+
+	bid, err := engine1.InitializeBid(types.Bid{
+		DecryptionCondition: uint64(13),
+		AllowedPeekers:      []common.Address{{0x41, 0x39}},
+		AllowedStores:       []common.Address{{}},
+		Version:             string("vv"),
+	}, dummyCreationTx)
+	require.NoError(t, err)
+
+	require.NoError(t, engine1.Finalize(dummyCreationTx, nil, []StoreWrite{{
+		Bid:    bid,
+		Caller: bid.AllowedPeekers[0],
+		Key:    "xx",
+		Value:  []byte{0x43, 0x14},
+	}}))
+
+	require.Eventually(t, func() bool {
+		data, err := engine2.Retrieve(bid.Id, bid.AllowedPeekers[0], "xx", 0)
+		return err == nil && bytes.Equal(data, []byte{0x43, 0x14})
+	}, time.Second, 10*time.Millisecond)
+
+	// Both kettles acknowledged the message once applied
+	client, err := connectRedis(mrTransport.Addr())
+	require.NoError(t, err)
+	defer client.Close()
+
+	for _, group := range []string{"kettle1", "kettle2"} {
+		require.Eventually(t, func() bool {
+			pending, err := client.XPending(context.Background(), redisUpsertStream, group).Result()
+			return err == nil && pending.Count == 0
+		}, time.Second, 10*time.Millisecond, group)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/alicebob/miniredis/v2"
@@ -15,7 +17,7 @@ import (
 	"github.com/go-redis/redis/v8"
 )
 
-var _ ConfidentialStorageBackend = &RedisStoreBackend{}
+var _ ExportableStorageBackend = &RedisStoreBackend{}
 
 var (
 	formatRedisBidKey = func(bidId suave.BidId) string {
@@ -26,7 +28,23 @@ var (
 		return fmt.Sprintf("bid-data-%x-%s", bidId, key)
 	}
 
+	// Set of the data keys stored in a bid
+	formatRedisBidDataKeysKey = func(bidId suave.BidId) string {
+		return fmt.Sprintf("bid-keys-%x", bidId)
+	}
+
+	formatRedisUsageKey = func(key string) string {
+		return fmt.Sprintf("usage-%s", key)
+	}
+
+	formatRedisReplacementKey = func(key string) string {
+		return fmt.Sprintf("replacement-%s", key)
+	}
+
 	ffStoreTTL = 24 * time.Hour
+
+	// Number of bids fetched at once to count them
+	redisCountBatchSize = 1000
 )
 
 type RedisStoreBackend struct {
@@ -139,10 +157,157 @@ func (r *RedisStoreBackend) FetchBidById(bidId suave.BidId) (suave.Bid, error) {
 	return bid, nil
 }
 
+// ForEachBid calls fn with every bid stored in redis, ordered by id. The bid
+// holding the mempool is internal to the backend and skipped.
+func (r *RedisStoreBackend) ForEachBid(fn func(bid suave.Bid, values map[string][]byte) error) error {
+	bidKeys, err := r.bidKeys()
+	if err != nil {
+		return err
+	}
+	sort.Strings(bidKeys)
+
+	for _, bidKey := range bidKeys {
+		data, err := r.client.Get(r.ctx, bidKey).Bytes()
+		if errors.Is(err, redis.Nil) {
+			// Expired since the scan
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("unexpected redis error: %w", err)
+		}
+
+		var bid suave.Bid
+		if err := json.Unmarshal(data, &bid); err != nil {
+			return fmt.Errorf("could not unmarshal bid %s: %w", bidKey, err)
+		}
+
+		values, err := r.bidValues(bid.Id)
+		if err != nil {
+			return err
+		}
+		if err := fn(bid, values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CountBids returns the number of bids stored by namespace, without the bid
+// holding the mempool.
+func (r *RedisStoreBackend) CountBids() (map[string]int64, error) {
+	bidKeys, err := r.bidKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64)
+	for start := 0; start < len(bidKeys); start += redisCountBatchSize {
+		end := start + redisCountBatchSize
+		if end > len(bidKeys) {
+			end = len(bidKeys)
+		}
+		data, err := r.client.MGet(r.ctx, bidKeys[start:end]...).Result()
+		if err != nil {
+			return nil, fmt.Errorf("unexpected redis error: %w", err)
+		}
+		for i, value := range data {
+			// Expired since the scan
+			value, ok := value.(string)
+			if !ok {
+				continue
+			}
+			var bid struct{ Version string }
+			if err := json.Unmarshal([]byte(value), &bid); err != nil {
+				return nil, fmt.Errorf("could not unmarshal bid %s: %w", bidKeys[start+i], err)
+			}
+			counts[bid.Version]++
+		}
+	}
+	return counts, nil
+}
+
+// bidKeys returns the keys of the bids stored in redis, without the bid
+// holding the mempool.
+func (r *RedisStoreBackend) bidKeys() ([]string, error) {
+	// Bid keys are "bid-" followed by the hex id, data keys and the sets of
+	// data keys share the prefix
+	bidKeyLen := len(formatRedisBidKey(suave.BidId{}))
+	mempoolKey := formatRedisBidKey(mempoolConfStoreId)
+
+	var bidKeys []string
+	iter := r.client.Scan(r.ctx, 0, "bid-*", 0).Iterator()
+	for iter.Next(r.ctx) {
+		key := iter.Val()
+		if len(key) != bidKeyLen || strings.HasPrefix(key, "bid-data-") || key == mempoolKey {
+			continue
+		}
+		bidKeys = append(bidKeys, key)
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("unexpected redis error: %w", err)
+	}
+	return bidKeys, nil
+}
+
+// bidValues returns the data stored in the bid, looked up through the set of
+// its data keys. Bids stored before the set was introduced have none, their
+// data keys are scanned instead.
+func (r *RedisStoreBackend) bidValues(bidId suave.BidId) (map[string][]byte, error) {
+	keys, err := r.client.SMembers(r.ctx, formatRedisBidDataKeysKey(bidId)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("unexpected redis error: %w", err)
+	}
+	if len(keys) == 0 {
+		if keys, err = r.scanBidDataKeys(bidId); err != nil {
+			return nil, err
+		}
+	}
+
+	values := make(map[string][]byte)
+	if len(keys) == 0 {
+		return values, nil
+	}
+
+	storeKeys := make([]string, len(keys))
+	for i, key := range keys {
+		storeKeys[i] = formatRedisBidValueKey(bidId, key)
+	}
+	data, err := r.client.MGet(r.ctx, storeKeys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("unexpected redis error: %w", err)
+	}
+	for i, value := range data {
+		// Expired since the key was added
+		if value, ok := value.(string); ok {
+			values[keys[i]] = []byte(value)
+		}
+	}
+	return values, nil
+}
+
+func (r *RedisStoreBackend) scanBidDataKeys(bidId suave.BidId) ([]string, error) {
+	prefix := formatRedisBidValueKey(bidId, "")
+
+	var keys []string
+	iter := r.client.Scan(r.ctx, 0, prefix+"*", 0).Iterator()
+	for iter.Next(r.ctx) {
+		keys = append(keys, strings.TrimPrefix(iter.Val(), prefix))
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("unexpected redis error: %w", err)
+	}
+	return keys, nil
+}
+
 func (r *RedisStoreBackend) Store(bid suave.Bid, caller common.Address, key string, value []byte) (suave.Bid, error) {
 	storeKey := formatRedisBidValueKey(bid.Id, key)
-	err := r.client.Set(r.ctx, storeKey, string(value), ffStoreTTL).Err()
-	if err != nil {
+	keysKey := formatRedisBidDataKeysKey(bid.Id)
+
+	pipe := r.client.TxPipeline()
+	pipe.Set(r.ctx, storeKey, string(value), ffStoreTTL)
+	pipe.SAdd(r.ctx, keysKey, key)
+	pipe.Expire(r.ctx, keysKey, ffStoreTTL)
+	if _, err := pipe.Exec(r.ctx); err != nil {
 		return suave.Bid{}, fmt.Errorf("unexpected redis error: %w", err)
 	}
 
@@ -159,6 +324,84 @@ func (r *RedisStoreBackend) Retrieve(bid suave.Bid, caller common.Address, key s
 	return data, nil
 }
 
+func (r *RedisStoreBackend) StoredSize(bid suave.Bid, key string) (int, error) {
+	size, err := r.client.StrLen(r.ctx, formatRedisBidValueKey(bid.Id, key)).Result()
+	if err != nil {
+		return 0, fmt.Errorf("unexpected redis error: %w", err)
+	}
+	return int(size), nil
+}
+
+// Usage counters expire with the data they account for. The expiry is set
+// when a counter is created and not extended by later updates, so the usage
+// of a principal that keeps writing is reset once the window passes.
+func (r *RedisStoreBackend) Usage(key string) (int64, error) {
+	usage, err := r.client.Get(r.ctx, formatRedisUsageKey(key)).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	} else if err != nil {
+		return 0, fmt.Errorf("unexpected redis error: %w", err)
+	}
+	return usage, nil
+}
+
+func (r *RedisStoreBackend) AddUsage(key string, delta int64) (int64, error) {
+	usageKey := formatRedisUsageKey(key)
+
+	pipe := r.client.TxPipeline()
+	pipe.SetNX(r.ctx, usageKey, 0, ffStoreTTL)
+	incr := pipe.IncrBy(r.ctx, usageKey, delta)
+	if _, err := pipe.Exec(r.ctx); err != nil {
+		return 0, fmt.Errorf("unexpected redis error: %w", err)
+	}
+	return incr.Val(), nil
+}
+
+func (r *RedisStoreBackend) Replacement(key string) (suave.BundleReplacement, bool, error) {
+	return r.replacement(r.client, formatRedisReplacementKey(key))
+}
+
+func (r *RedisStoreBackend) RecordReplacement(key string, replacement suave.BundleReplacement) error {
+	data, err := json.Marshal(replacement)
+	if err != nil {
+		return err
+	}
+
+	replacementKey := formatRedisReplacementKey(key)
+	err = r.client.Watch(r.ctx, func(tx *redis.Tx) error {
+		recorded, ok, err := r.replacement(tx, replacementKey)
+		if err != nil {
+			return err
+		}
+		if ok && recorded.Nonce > replacement.Nonce {
+			return nil
+		}
+		_, err = tx.TxPipelined(r.ctx, func(pipe redis.Pipeliner) error {
+			return pipe.Set(r.ctx, replacementKey, data, ffStoreTTL).Err()
+		})
+		return err
+	}, replacementKey)
+	if err != nil {
+		return fmt.Errorf("unexpected redis error: %w", err)
+	}
+	return nil
+}
+
+func (r *RedisStoreBackend) replacement(client redis.Cmdable, replacementKey string) (suave.BundleReplacement, bool, error) {
+	data, err := client.Get(r.ctx, replacementKey).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return suave.BundleReplacement{}, false, nil
+	} else if err != nil {
+		return suave.BundleReplacement{}, false, fmt.Errorf("unexpected redis error: %w", err)
+	}
+
+	var replacement suave.BundleReplacement
+	if err := json.Unmarshal(data, &replacement); err != nil {
+		return suave.BundleReplacement{}, false, err
+	}
+	return replacement, true, nil
+}
+
 var (
 	mempoolConfStoreId          = types.BidId{0x39}
 	mempoolConfStoreAddr        = common.HexToAddress("0x39")
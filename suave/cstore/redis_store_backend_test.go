@@ -2,9 +2,32 @@ package cstore
 
 import (
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
 )
 
 func TestRedis_StoreSuite(t *testing.T) {
 	store, _ := NewRedisStoreBackend("")
 	testBackendStore(t, store)
 }
+
+func TestRedis_UsageExpiry(t *testing.T) {
+	store, err := NewRedisStoreBackend("")
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Stop() })
+
+	_, err = store.AddUsage("quota-test", 5)
+	require.NoError(t, err)
+
+	// Later updates do not extend the lifetime of the counter
+	store.local.FastForward(ffStoreTTL - time.Hour)
+	usage, err := store.AddUsage("quota-test", 3)
+	require.NoError(t, err)
+	require.Equal(t, int64(8), usage)
+
+	store.local.FastForward(time.Hour)
+	usage, err = store.Usage("quota-test")
+	require.NoError(t, err)
+	require.Zero(t, usage)
+}
@@ -0,0 +1,324 @@
+package cstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+var (
+	redisUpsertStream = "store:upsert:stream"
+
+	redisStreamsBlock          = time.Second      // how long a read waits for new messages
+	redisStreamsClaimIdle      = 30 * time.Second // messages not acknowledged for this long are delivered again
+	redisStreamsMaxDeliveries  = int64(10)        // messages delivered this many times are dropped
+	redisStreamsPublishRetries = 3
+)
+
+// RedisStreamsTransport shares store writes over a Redis stream. Each kettle
+// reads the stream through its own consumer group, so the messages published
+// while it is disconnected are delivered once it is back. Messages are only
+// acknowledged once the engine applied them, until then they are delivered
+// again, including after a restart.
+type RedisStreamsTransport struct {
+	ctx      context.Context
+	cancel   context.CancelFunc
+	redisUri string
+	group    string
+	maxAge   time.Duration
+	client   *redis.Client
+
+	claimIdle     time.Duration
+	maxDeliveries int64
+}
+
+// NewRedisStreamsTransport creates a transport reading the stream as the given
+// consumer group. The group must be stable across restarts of the kettle and
+// unique to it. Messages older than maxAge are trimmed from the stream when
+// new ones are published, a zero maxAge keeps them forever.
+func NewRedisStreamsTransport(redisUri string, group string, maxAge time.Duration) *RedisStreamsTransport {
+	return &RedisStreamsTransport{
+		redisUri:      redisUri,
+		group:         group,
+		maxAge:        maxAge,
+		claimIdle:     redisStreamsClaimIdle,
+		maxDeliveries: redisStreamsMaxDeliveries,
+	}
+}
+
+func (r *RedisStreamsTransport) Start() error {
+	if r.group == "" {
+		return errors.New("Redis streams: consumer group not set")
+	}
+
+	if r.cancel != nil {
+		r.cancel()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.ctx = ctx
+	r.cancel = cancel
+
+	client, err := connectRedis(r.redisUri)
+	if err != nil {
+		return err
+	}
+	r.client = client
+
+	return r.createGroup(ctx)
+}
+
+func (r *RedisStreamsTransport) Stop() error {
+	if r.cancel == nil || r.client == nil {
+		return errors.New("Redis streams: Stop() called before Start()")
+	}
+
+	r.cancel()
+	r.client.Close()
+
+	return nil
+}
+
+// createGroup creates the consumer group of the kettle if it does not exist.
+// A new group reads the stream from the start, which holds the last maxAge
+// worth of messages.
+func (r *RedisStreamsTransport) createGroup(ctx context.Context) error {
+	err := r.client.XGroupCreateMkStream(ctx, redisUpsertStream, r.group, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("Redis streams: could not create consumer group %s: %w", r.group, err)
+	}
+	return nil
+}
+
+func (r *RedisStreamsTransport) Subscribe() (<-chan DAMessage, context.CancelFunc) {
+	ch := make(chan DAMessage, 16)
+	ctx, cancel := context.WithCancel(r.ctx)
+
+	go func() {
+		defer close(ch)
+
+		// Start with the messages delivered but not acknowledged before the
+		// last restart, then read new ones.
+		lastId := "0"
+		lastClaim := time.Now()
+
+		for ctx.Err() == nil /* run until Stop() or cancel() called */ {
+			if time.Since(lastClaim) >= r.claimIdle/2 {
+				if !r.claimPending(ctx, ch) {
+					return
+				}
+				lastClaim = time.Now()
+			}
+
+			streams, err := r.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+				Group:    r.group,
+				Consumer: r.group,
+				Streams:  []string{redisUpsertStream, lastId},
+				Count:    16,
+				Block:    redisStreamsBlock,
+			}).Result()
+			if errors.Is(err, redis.Nil) {
+				// No new messages
+				continue
+			}
+			if err != nil {
+				if ctx.Err() != nil {
+					log.Info("Redis streams: closing subscription")
+					return
+				}
+
+				log.Error("Redis streams: error while receiving messages", "err", err)
+				if strings.HasPrefix(err.Error(), "NOGROUP") {
+					// Redis lost the stream, recreate it
+					if err := r.createGroup(ctx); err != nil {
+						log.Error("Redis streams: could not recreate consumer group", "err", err)
+					}
+				}
+
+				select {
+				case <-ctx.Done():
+				case <-time.After(redisStreamsBlock):
+				}
+				continue
+			}
+
+			entries := streams[0].Messages
+			if lastId != ">" {
+				if len(entries) == 0 {
+					// All the pending messages have been delivered again
+					lastId = ">"
+					continue
+				}
+				lastId = entries[len(entries)-1].ID
+			}
+
+			for _, entry := range entries {
+				if !r.deliver(ctx, ch, entry) {
+					return
+				}
+			}
+		}
+	}()
+
+	return ch, cancel
+}
+
+// claimPending delivers again the messages that were not acknowledged within
+// claimIdle, and drops the ones that failed too many times.
+func (r *RedisStreamsTransport) claimPending(ctx context.Context, ch chan<- DAMessage) bool {
+	pending, err := r.client.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream: redisUpsertStream,
+		Group:  r.group,
+		Start:  "-",
+		End:    "+",
+		Count:  100,
+	}).Result()
+	if err != nil {
+		log.Error("Redis streams: could not list pending messages", "err", err)
+		return ctx.Err() == nil
+	}
+
+	var ids []string
+	for _, p := range pending {
+		if p.Idle < r.claimIdle {
+			continue
+		}
+		if p.RetryCount >= r.maxDeliveries {
+			log.Error("Redis streams: dropping message after too many deliveries", "id", p.ID, "deliveries", p.RetryCount)
+			droppedMessageCounter.Inc(1)
+			r.ack(ctx, p.ID)
+			continue
+		}
+		ids = append(ids, p.ID)
+	}
+	if len(ids) == 0 {
+		return true
+	}
+
+	entries, err := r.client.XClaim(ctx, &redis.XClaimArgs{
+		Stream:   redisUpsertStream,
+		Group:    r.group,
+		Consumer: r.group,
+		MinIdle:  r.claimIdle,
+		Messages: ids,
+	}).Result()
+	if err != nil {
+		log.Error("Redis streams: could not claim pending messages", "err", err)
+		return ctx.Err() == nil
+	}
+
+	for _, entry := range entries {
+		if !r.deliver(ctx, ch, entry) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *RedisStreamsTransport) deliver(ctx context.Context, ch chan<- DAMessage, entry redis.XMessage) bool {
+	msg, err := parseStreamEntry(entry)
+	if err != nil {
+		// The message can never be applied, do not deliver it again
+		log.Error("Redis streams: dropping message", "id", entry.ID, "err", err)
+		droppedMessageCounter.Inc(1)
+		r.ack(ctx, entry.ID)
+		return true
+	}
+	if published, ok := streamEntryTime(entry.ID); ok {
+		messageLagTimer.UpdateSince(published)
+	}
+
+	log.Debug("Redis streams: new message", "id", entry.ID, "msg", msg)
+	select {
+	case <-ctx.Done():
+		log.Info("Redis streams: closing subscription")
+		return false
+	case ch <- msg:
+		return true
+	}
+}
+
+func parseStreamEntry(entry redis.XMessage) (DAMessage, error) {
+	data, ok := entry.Values["data"].(string)
+	if !ok {
+		// Entries trimmed before they were acknowledged have no values
+		return DAMessage{}, errors.New("message has no data")
+	}
+
+	var msg DAMessage
+	if err := json.Unmarshal([]byte(data), &msg); err != nil {
+		return DAMessage{}, fmt.Errorf("could not parse message: %w", err)
+	}
+	msg.transportId = entry.ID
+	return msg, nil
+}
+
+// streamEntryTime returns the time an entry was added to the stream, which
+// prefixes its id in milliseconds.
+func streamEntryTime(id string) (time.Time, bool) {
+	ms, _, found := strings.Cut(id, "-")
+	if !found {
+		return time.Time{}, false
+	}
+	millis, err := strconv.ParseInt(ms, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.UnixMilli(millis), true
+}
+
+// Ack acknowledges a message delivered by the subscription, it is not
+// delivered again.
+func (r *RedisStreamsTransport) Ack(message DAMessage) error {
+	if message.transportId == "" {
+		return errors.New("Redis streams: message was not received from the stream")
+	}
+	return r.ack(r.ctx, message.transportId)
+}
+
+func (r *RedisStreamsTransport) ack(ctx context.Context, id string) error {
+	if err := r.client.XAck(ctx, redisUpsertStream, r.group, id).Err(); err != nil {
+		log.Error("Redis streams: could not acknowledge message", "id", id, "err", err)
+		return err
+	}
+	return nil
+}
+
+func (r *RedisStreamsTransport) Publish(message DAMessage) {
+	log.Trace("Redis streams: publishing", "message", message)
+	data, err := json.Marshal(message)
+	if err != nil {
+		log.Error("Redis streams: could not marshal message", "err", err)
+		return
+	}
+
+	args := &redis.XAddArgs{
+		Stream: redisUpsertStream,
+		Values: map[string]interface{}{"data": data},
+	}
+	if r.maxAge > 0 {
+		args.MinID = fmt.Sprintf("%d-0", time.Now().Add(-r.maxAge).UnixMilli())
+		args.Approx = true
+	}
+
+	for attempt := 1; ; attempt++ {
+		err = r.client.XAdd(r.ctx, args).Err()
+		if err == nil || attempt == redisStreamsPublishRetries || r.ctx.Err() != nil {
+			break
+		}
+		log.Warn("Redis streams: could not publish message, retrying", "attempt", attempt, "err", err)
+		time.Sleep(time.Duration(attempt) * 100 * time.Millisecond)
+	}
+	if err != nil {
+		log.Error("Redis streams: could not publish message", "err", err)
+		publishFailedCounter.Inc(1)
+	}
+}
@@ -128,6 +128,7 @@ func (r *RedisPubSubTransport) Subscribe() (<-chan DAMessage, context.CancelFunc
 				continue
 			default:
 				log.Error("dropping transport message due to channel being blocked")
+				droppedMessageCounter.Inc(1)
 				continue
 			}
 		}
@@ -144,7 +145,10 @@ func (r *RedisPubSubTransport) Publish(message DAMessage) {
 		return
 	}
 
-	r.client.Publish(r.ctx, redisUpsertTopic, common.Bytes2Hex(data))
+	if err := r.client.Publish(r.ctx, redisUpsertTopic, common.Bytes2Hex(data)).Err(); err != nil {
+		log.Error("Redis pubsub: could not publish message", "err", err)
+		publishFailedCounter.Inc(1)
+	}
 }
 
 func connectRedis(redisURI string) (*redis.Client, error) {
@@ -0,0 +1,193 @@
+package cstore
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	suave "github.com/ethereum/go-ethereum/suave/core"
+)
+
+const (
+	// StoreExportFormat identifies confidential store exports.
+	StoreExportFormat = "suave-confidential-store"
+
+	// StoreExportVersion is the version of the export format written by
+	// ExportStore. Imports accept any version up to this one.
+	StoreExportVersion = 1
+)
+
+// ExportableStorageBackend is implemented by the storage backends that can
+// list their contents, to export them or migrate them to another backend.
+type ExportableStorageBackend interface {
+	ConfidentialStorageBackend
+
+	// ForEachBid calls fn with every bid stored, along with the values stored
+	// for it by key, and stops at the first error fn returns.
+	ForEachBid(fn func(bid suave.Bid, values map[string][]byte) error) error
+}
+
+// StoreExportHeader is the first line of an export.
+type StoreExportHeader struct {
+	Format  string `json:"format"`
+	Version uint64 `json:"version"`
+	// Unix time of the export
+	ExportedAt uint64 `json:"exportedAt"`
+}
+
+// StoreExportEntry is a bid of an export with the values stored for it. An
+// export holds one entry per line following the header.
+type StoreExportEntry struct {
+	Bid    suave.Bid              `json:"bid"`
+	Values map[string]suave.Bytes `json:"values"`
+}
+
+// StoreImportConfig sets how the bids of an import are verified.
+type StoreImportConfig struct {
+	// DASigner recovers the kettle that signed a bid
+	DASigner DASigner
+	// ChainSigner recovers the sender of the creation transaction of a bid
+	ChainSigner ChainSigner
+	// SkipInvalid skips the bids failing verification instead of aborting
+	SkipInvalid bool
+	// Overwrite replaces the values of the bids already in the backend, which
+	// are left untouched otherwise
+	Overwrite bool
+}
+
+// StoreImportResult counts the bids of an import or a migration.
+type StoreImportResult struct {
+	Imported int `json:"imported"`
+	Existing int `json:"existing"`
+	Invalid  int `json:"invalid"`
+}
+
+// ExportStore writes every bid of the backend, with its signature, creation
+// transaction and stored values, to w. Quota usage counters are not exported
+// and bundle replacements are recorded again from the bundles on import.
+func ExportStore(backend ExportableStorageBackend, w io.Writer) (int, error) {
+	enc := json.NewEncoder(w)
+
+	header := &StoreExportHeader{
+		Format:     StoreExportFormat,
+		Version:    StoreExportVersion,
+		ExportedAt: uint64(time.Now().Unix()),
+	}
+	if err := enc.Encode(header); err != nil {
+		return 0, fmt.Errorf("could not write export header: %w", err)
+	}
+
+	exported := 0
+	err := backend.ForEachBid(func(bid suave.Bid, values map[string][]byte) error {
+		entry := &StoreExportEntry{
+			Bid:    bid,
+			Values: make(map[string]suave.Bytes, len(values)),
+		}
+		for key, value := range values {
+			entry.Values[key] = value
+		}
+		if err := enc.Encode(entry); err != nil {
+			return fmt.Errorf("could not write bid %x: %w", bid.Id, err)
+		}
+		exported++
+		return nil
+	})
+	return exported, err
+}
+
+// ImportStore adds the bids of an export to the backend. Bids are verified
+// with the rules applied to the bids received from other kettles. Bids already
+// in the backend are skipped, unless config.Overwrite is set.
+func ImportStore(backend ConfidentialStorageBackend, r io.Reader, config StoreImportConfig) (*StoreImportResult, error) {
+	dec := json.NewDecoder(bufio.NewReader(r))
+
+	var header StoreExportHeader
+	if err := dec.Decode(&header); err != nil {
+		return nil, fmt.Errorf("could not read export header: %w", err)
+	}
+	if header.Format != StoreExportFormat {
+		return nil, fmt.Errorf("not a confidential store export: format %q", header.Format)
+	}
+	if header.Version == 0 || header.Version > StoreExportVersion {
+		return nil, fmt.Errorf("unsupported export version %d, expected at most %d", header.Version, StoreExportVersion)
+	}
+
+	result := &StoreImportResult{}
+	for {
+		var entry StoreExportEntry
+		err := dec.Decode(&entry)
+		if errors.Is(err, io.EOF) {
+			return result, nil
+		}
+		if err != nil {
+			return result, fmt.Errorf("could not read bid %d of export: %w", result.Imported+result.Existing+result.Invalid+1, err)
+		}
+
+		values := make(map[string][]byte, len(entry.Values))
+		for key, value := range entry.Values {
+			values[key] = value
+		}
+		if err := importBid(backend, entry.Bid, values, config, result); err != nil {
+			return result, err
+		}
+	}
+}
+
+// MigrateStore copies every bid of the source backend to the destination,
+// verifying them like ImportStore does.
+func MigrateStore(src ExportableStorageBackend, dst ConfidentialStorageBackend, config StoreImportConfig) (*StoreImportResult, error) {
+	result := &StoreImportResult{}
+	err := src.ForEachBid(func(bid suave.Bid, values map[string][]byte) error {
+		return importBid(dst, bid, values, config, result)
+	})
+	return result, err
+}
+
+func importBid(backend ConfidentialStorageBackend, bid suave.Bid, values map[string][]byte, config StoreImportConfig, result *StoreImportResult) error {
+	err := verifyBid(&bid, config.DASigner, config.ChainSigner)
+	if err == nil {
+		// Like the store writes received from other kettles, the values must
+		// come from a kettle allowed to store on the bid
+		kettle, _ := KettleAddressFromTransaction(bid.CreationTx)
+		if !suave.IsAllowed(bid.AllowedStores, kettle) {
+			err = fmt.Errorf("kettle %x not allowed to store on bid", kettle)
+		}
+	}
+	if err != nil {
+		if !config.SkipInvalid {
+			return fmt.Errorf("invalid bid %x: %w", bid.Id, err)
+		}
+		log.Warn("Skipping invalid bid", "id", fmt.Sprintf("%x", bid.Id), "err", err)
+		result.Invalid++
+		return nil
+	}
+
+	err = backend.InitializeBid(bid)
+	switch {
+	case errors.Is(err, suave.ErrBidAlreadyPresent):
+		result.Existing++
+		if !config.Overwrite {
+			return nil
+		}
+	case err != nil:
+		return fmt.Errorf("could not initialize bid %x: %w", bid.Id, err)
+	default:
+		result.Imported++
+	}
+
+	for key, value := range values {
+		sw := StoreWrite{Bid: bid, Key: key, Value: value}
+		if _, err := backend.Store(bid, sw.Caller, key, value); err != nil {
+			return fmt.Errorf("could not store %s of bid %x: %w", key, bid.Id, err)
+		}
+		// Replacements are not exported, they are recorded again from the bundles
+		if err := recordBundleReplacement(backend, config.ChainSigner, sw); err != nil {
+			return fmt.Errorf("could not record bundle replacement of bid %x: %w", bid.Id, err)
+		}
+	}
+	return nil
+}
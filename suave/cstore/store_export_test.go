@@ -0,0 +1,288 @@
+package cstore
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	suave "github.com/ethereum/go-ethereum/suave/core"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+var testImportConfig = StoreImportConfig{
+	DASigner:    MockSigner{},
+	ChainSigner: MockChainSigner{},
+}
+
+// newExportTestStore returns a local store holding two bids initialized and
+// written through the engine, as a kettle would.
+func newExportTestStore(t *testing.T) (*LocalConfidentialStore, []suave.Bid) {
+	store := NewLocalConfidentialStore()
+	engine := NewConfidentialStoreEngine(store, MockTransport{}, MockSigner{}, MockChainSigner{})
+
+	testKey, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	sourceTx, err := types.SignTx(types.NewTx(&types.ConfidentialComputeRequest{
+		ConfidentialComputeRecord: types.ConfidentialComputeRecord{
+			KettleAddress: common.Address{0x42},
+		},
+	}), types.NewSuaveSigner(new(big.Int)), testKey)
+	require.NoError(t, err)
+
+	var bids []suave.Bid
+	for i := byte(0); i < 2; i++ {
+		bid, err := engine.InitializeBid(types.Bid{
+			DecryptionCondition: 10 + uint64(i),
+			AllowedPeekers:      []common.Address{{0x43}},
+			AllowedStores:       []common.Address{{0x42}},
+			Version:             "default:v0:ethBundles",
+		}, sourceTx)
+		require.NoError(t, err)
+
+		require.NoError(t, engine.Finalize(sourceTx, map[suave.BidId]suave.Bid{bid.Id: bid}, []StoreWrite{
+			{Bid: bid, Caller: common.Address{0x43}, Key: "a", Value: []byte{i, 0x01}},
+			{Bid: bid, Caller: common.Address{0x43}, Key: "b-c", Value: []byte{i, 0x02}},
+		}))
+
+		storedBid, err := store.FetchBidById(bid.Id)
+		require.NoError(t, err)
+		bids = append(bids, storedBid)
+	}
+	return store, bids
+}
+
+func requireSameStore(t *testing.T, expected []suave.Bid, store ConfidentialStorageBackend) {
+	for i, bid := range expected {
+		stored, err := store.FetchBidById(bid.Id)
+		require.NoError(t, err)
+		require.Equal(t, bid.Signature, stored.Signature)
+		require.Equal(t, bid.CreationTx.Hash(), stored.CreationTx.Hash())
+
+		value, err := store.Retrieve(stored, common.Address{0x43}, "a")
+		require.NoError(t, err)
+		require.Equal(t, []byte{byte(i), 0x01}, value)
+
+		value, err = store.Retrieve(stored, common.Address{0x43}, "b-c")
+		require.NoError(t, err)
+		require.Equal(t, []byte{byte(i), 0x02}, value)
+	}
+}
+
+func TestStoreExportImport(t *testing.T) {
+	src, bids := newExportTestStore(t)
+
+	var buf bytes.Buffer
+	exported, err := ExportStore(src, &buf)
+	require.NoError(t, err)
+	require.Equal(t, 2, exported)
+
+	dst, err := NewPebbleStoreBackend(t.TempDir())
+	require.NoError(t, err)
+	defer dst.Stop()
+
+	result, err := ImportStore(dst, bytes.NewReader(buf.Bytes()), testImportConfig)
+	require.NoError(t, err)
+	require.Equal(t, &StoreImportResult{Imported: 2}, result)
+	requireSameStore(t, bids, dst)
+
+	// Importing again skips the bids already present, unless overwriting
+	_, err = dst.Store(bids[0], common.Address{0x43}, "a", []byte{0x66})
+	require.NoError(t, err)
+
+	result, err = ImportStore(dst, bytes.NewReader(buf.Bytes()), testImportConfig)
+	require.NoError(t, err)
+	require.Equal(t, &StoreImportResult{Existing: 2}, result)
+	value, err := dst.Retrieve(bids[0], common.Address{0x43}, "a")
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x66}, value)
+
+	config := testImportConfig
+	config.Overwrite = true
+	result, err = ImportStore(dst, bytes.NewReader(buf.Bytes()), config)
+	require.NoError(t, err)
+	require.Equal(t, &StoreImportResult{Existing: 2}, result)
+	requireSameStore(t, bids, dst)
+
+	// The pebble store exports the same bids
+	var reexport bytes.Buffer
+	exported, err = ExportStore(dst, &reexport)
+	require.NoError(t, err)
+	require.Equal(t, 2, exported)
+	require.Equal(t, strings.Split(buf.String(), "\n")[1:], strings.Split(reexport.String(), "\n")[1:])
+}
+
+func TestStoreImport_Verification(t *testing.T) {
+	src, _ := newExportTestStore(t)
+
+	var buf bytes.Buffer
+	_, err := ExportStore(src, &buf)
+	require.NoError(t, err)
+
+	// Change the peekers of the second bid without signing it again
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	var entry StoreExportEntry
+	require.NoError(t, json.Unmarshal([]byte(lines[2]), &entry))
+	entry.Bid.AllowedPeekers = []common.Address{{0x66}}
+	tampered, err := json.Marshal(&entry)
+	require.NoError(t, err)
+	lines[2] = string(tampered)
+	export := strings.Join(lines, "\n")
+
+	_, err = ImportStore(NewLocalConfidentialStore(), strings.NewReader(export), testImportConfig)
+	require.ErrorContains(t, err, "invalid bid")
+
+	config := testImportConfig
+	config.SkipInvalid = true
+	dst := NewLocalConfidentialStore()
+	result, err := ImportStore(dst, strings.NewReader(export), config)
+	require.NoError(t, err)
+	require.Equal(t, &StoreImportResult{Imported: 1, Invalid: 1}, result)
+
+	_, err = dst.FetchBidById(entry.Bid.Id)
+	require.Error(t, err)
+}
+
+func TestStoreImport_AllowedStores(t *testing.T) {
+	src := NewLocalConfidentialStore()
+	engine := NewConfidentialStoreEngine(src, MockTransport{}, MockSigner{}, MockChainSigner{})
+
+	testKey, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	sourceTx, err := types.SignTx(types.NewTx(&types.ConfidentialComputeRequest{
+		ConfidentialComputeRecord: types.ConfidentialComputeRecord{
+			KettleAddress: common.Address{0x42},
+		},
+	}), types.NewSuaveSigner(new(big.Int)), testKey)
+	require.NoError(t, err)
+
+	// The kettle that created the bid is not one of its stores
+	bid, err := engine.InitializeBid(types.Bid{
+		DecryptionCondition: 10,
+		AllowedPeekers:      []common.Address{{0x43}},
+		AllowedStores:       []common.Address{{0x44}},
+		Version:             "default:v0:ethBundles",
+	}, sourceTx)
+	require.NoError(t, err)
+	require.NoError(t, engine.Finalize(sourceTx, map[suave.BidId]suave.Bid{bid.Id: bid}, nil))
+
+	_, err = MigrateStore(src, NewLocalConfidentialStore(), testImportConfig)
+	require.ErrorContains(t, err, "not allowed to store on bid")
+
+	config := testImportConfig
+	config.SkipInvalid = true
+	result, err := MigrateStore(src, NewLocalConfidentialStore(), config)
+	require.NoError(t, err)
+	require.Equal(t, &StoreImportResult{Invalid: 1}, result)
+}
+
+func TestStoreImport_Header(t *testing.T) {
+	_, err := ImportStore(NewLocalConfidentialStore(), strings.NewReader(`{"format":"other","version":1}`), testImportConfig)
+	require.ErrorContains(t, err, "not a confidential store export")
+
+	_, err = ImportStore(NewLocalConfidentialStore(), strings.NewReader(`{"format":"suave-confidential-store","version":2}`), testImportConfig)
+	require.ErrorContains(t, err, "unsupported export version")
+}
+
+func TestStoreMigrate(t *testing.T) {
+	src, bids := newExportTestStore(t)
+
+	dst, err := NewRedisStoreBackend("")
+	require.NoError(t, err)
+	defer dst.Stop()
+
+	result, err := MigrateStore(src, dst, testImportConfig)
+	require.NoError(t, err)
+	require.Equal(t, &StoreImportResult{Imported: 2}, result)
+	requireSameStore(t, bids, dst)
+
+	// The redis store lists the migrated bids, not its mempool
+	var listed []suave.BidId
+	require.NoError(t, dst.ForEachBid(func(bid suave.Bid, values map[string][]byte) error {
+		listed = append(listed, bid.Id)
+		require.Len(t, values, 2)
+		return nil
+	}))
+	require.ElementsMatch(t, []suave.BidId{bids[0].Id, bids[1].Id}, listed)
+}
+
+func TestStoreExport_RedisWithoutDataKeys(t *testing.T) {
+	src, bids := newExportTestStore(t)
+
+	store, err := NewRedisStoreBackend("")
+	require.NoError(t, err)
+	defer store.Stop()
+
+	// Bids stored before the sets of data keys were introduced have none
+	for i, bid := range bids {
+		require.NoError(t, store.InitializeBid(bid))
+		for key, value := range map[string][]byte{"a": {byte(i), 0x01}, "b-c": {byte(i), 0x02}} {
+			require.NoError(t, store.client.Set(store.ctx, formatRedisBidValueKey(bid.Id, key), string(value), ffStoreTTL).Err())
+		}
+	}
+
+	var expected, exported bytes.Buffer
+	_, err = ExportStore(src, &expected)
+	require.NoError(t, err)
+	n, err := ExportStore(store, &exported)
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	require.ElementsMatch(t, strings.Split(expected.String(), "\n")[1:], strings.Split(exported.String(), "\n")[1:])
+}
+
+func TestStoreMigrate_BundleReplacements(t *testing.T) {
+	src := NewLocalConfidentialStore()
+	engine := NewConfidentialStoreEngine(src, MockTransport{}, MockSigner{}, MockChainSigner{})
+
+	testKey, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	searcher := crypto.PubkeyToAddress(testKey.PublicKey)
+	replacementUuid := uuid.New()
+
+	// A bundle and the request of the searcher cancelling it
+	var bidIds []suave.BidId
+	for nonce, bundle := range []*types.SBundle{
+		{Txs: types.Transactions{types.NewTx(&types.LegacyTx{Nonce: 1})}, ReplacementUuid: &replacementUuid},
+		{ReplacementUuid: &replacementUuid},
+	} {
+		sourceTx, err := types.SignTx(types.NewTx(&types.ConfidentialComputeRequest{
+			ConfidentialComputeRecord: types.ConfidentialComputeRecord{
+				KettleAddress: common.Address{0x42},
+				Nonce:         uint64(nonce),
+			},
+		}), types.NewSuaveSigner(new(big.Int)), testKey)
+		require.NoError(t, err)
+
+		bid, err := engine.InitializeBid(types.Bid{
+			Salt:                RandomBidId(),
+			DecryptionCondition: 10,
+			AllowedPeekers:      []common.Address{{0x43}},
+			AllowedStores:       []common.Address{{0x42}},
+			Version:             "default:v0:ethBundles",
+		}, sourceTx)
+		require.NoError(t, err)
+
+		bundleBytes, err := json.Marshal(bundle)
+		require.NoError(t, err)
+		require.NoError(t, engine.Finalize(sourceTx, map[suave.BidId]suave.Bid{bid.Id: bid}, []StoreWrite{
+			{Bid: bid, Caller: common.Address{0x43}, Key: "default:v0:ethBundles", Value: bundleBytes},
+		}))
+		bidIds = append(bidIds, bid.Id)
+	}
+
+	dst, err := NewRedisStoreBackend("")
+	require.NoError(t, err)
+	defer dst.Stop()
+
+	result, err := MigrateStore(src, dst, testImportConfig)
+	require.NoError(t, err)
+	require.Equal(t, &StoreImportResult{Imported: 2}, result)
+
+	// The cancellation is still the latest bundle of the replacement UUID
+	replacement, found, err := dst.Replacement(formatReplacementKey(searcher, replacementUuid))
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, suave.BundleReplacement{BidId: bidIds[1], Nonce: 1, Cancelled: true}, replacement)
+}
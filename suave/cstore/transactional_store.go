@@ -8,16 +8,19 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	suave "github.com/ethereum/go-ethereum/suave/core"
-	"golang.org/x/exp/slices"
+	"github.com/google/uuid"
 )
 
 type TransactionalStore struct {
-	sourceTx *types.Transaction
-	engine   *ConfidentialStoreEngine
+	sourceTx    *types.Transaction
+	blockNumber uint64
+	engine      *ConfidentialStoreEngine
 
 	pendingLock   sync.Mutex
 	pendingBids   map[suave.BidId]suave.Bid
 	pendingWrites []StoreWrite
+	pendingSizes  map[string]int   // sizes of the values written, by bid and key
+	pendingUsage  map[string]int64 // quota usage of the pending bids and writes
 }
 
 func (s *TransactionalStore) FetchBidById(bidId suave.BidId) (suave.Bid, error) {
@@ -46,24 +49,36 @@ func (s *TransactionalStore) FetchBidsByProtocolAndBlock(blockNumber uint64, nam
 	return bids
 }
 
+// BundleReplacement returns the latest bundle the sender stored with the
+// replacement UUID. Bundles stored by the request itself are not recorded
+// until it is finalized.
+func (s *TransactionalStore) BundleReplacement(sender common.Address, replacementUuid uuid.UUID) (suave.BundleReplacement, bool, error) {
+	return s.engine.BundleReplacement(sender, replacementUuid)
+}
+
 func (s *TransactionalStore) Store(bidId suave.BidId, caller common.Address, key string, value []byte) (suave.Bid, error) {
 	bid, err := s.FetchBidById(bidId)
 	if err != nil {
 		return suave.Bid{}, err
 	}
 
-	if !slices.Contains(bid.AllowedPeekers, caller) && !slices.Contains(bid.AllowedPeekers, suave.AllowedPeekerAny) {
+	if !suave.IsAllowed(bid.AllowedWriters(key), caller) {
 		return suave.Bid{}, fmt.Errorf("confidential store transaction: %x not allowed to store %s on %x", caller, key, bidId)
 	}
 
-	s.pendingLock.Lock()
-	defer s.pendingLock.Unlock()
-	s.pendingWrites = append(s.pendingWrites, StoreWrite{
+	sw := StoreWrite{
 		Bid:    bid,
 		Caller: caller,
 		Key:    key,
 		Value:  common.CopyBytes(value),
-	})
+	}
+	s.pendingLock.Lock()
+	defer s.pendingLock.Unlock()
+
+	if err := s.checkQuotas(nil, &sw); err != nil {
+		return suave.Bid{}, err
+	}
+	s.pendingWrites = append(s.pendingWrites, sw)
 
 	return bid, nil
 }
@@ -74,10 +89,14 @@ func (s *TransactionalStore) Retrieve(bidId suave.BidId, caller common.Address,
 		return nil, err
 	}
 
-	if !slices.Contains(bid.AllowedPeekers, caller) && !slices.Contains(bid.AllowedPeekers, suave.AllowedPeekerAny) {
+	if !suave.IsAllowed(bid.AllowedReaders(key), caller) {
 		return nil, fmt.Errorf("confidential store transaction: %x not allowed to retrieve %s on %x", caller, key, bidId)
 	}
 
+	if err := bid.CheckAccessWindow(s.blockNumber); err != nil {
+		return nil, fmt.Errorf("confidential store transaction: %x not allowed to retrieve %s: %w", caller, key, err)
+	}
+
 	s.pendingLock.Lock()
 
 	for _, sw := range s.pendingWrites {
@@ -88,27 +107,105 @@ func (s *TransactionalStore) Retrieve(bidId suave.BidId, caller common.Address,
 	}
 
 	s.pendingLock.Unlock()
-	return s.engine.Retrieve(bidId, caller, key)
+	return s.engine.Retrieve(bidId, caller, key, s.blockNumber)
 }
 
 func (s *TransactionalStore) InitializeBid(rawBid types.Bid) (types.Bid, error) {
-	bid, err := s.engine.InitializeBid(rawBid, s.sourceTx)
+	return s.InitializeBidWithAccessWindow(rawBid, suave.AccessWindow{})
+}
+
+func (s *TransactionalStore) InitializeBidWithAccessWindow(rawBid types.Bid, accessWindow suave.AccessWindow) (types.Bid, error) {
+	bid, err := s.engine.InitializeBidWithAccessWindow(rawBid, accessWindow, s.sourceTx)
+	if err != nil {
+		return types.Bid{}, err
+	}
+
+	return s.addPendingBid(bid)
+}
+
+func (s *TransactionalStore) InitializeBidWithPolicy(rawBid types.Bid, policy suave.BidPolicy) (types.Bid, error) {
+	bid, err := s.engine.InitializeBidWithPolicy(rawBid, policy, s.sourceTx)
 	if err != nil {
 		return types.Bid{}, err
 	}
 
+	return s.addPendingBid(bid)
+}
+
+func (s *TransactionalStore) addPendingBid(bid suave.Bid) (types.Bid, error) {
 	s.pendingLock.Lock()
 	_, found := s.pendingBids[bid.Id]
 	if found {
 		s.pendingLock.Unlock()
 		return types.Bid{}, errors.New("bid with this id already exists")
 	}
+	if err := s.checkQuotas(&bid, nil); err != nil {
+		s.pendingLock.Unlock()
+		return types.Bid{}, err
+	}
 	s.pendingBids[bid.Id] = bid
 	s.pendingLock.Unlock()
 
 	return bid.ToInnerBid(), nil
 }
 
+// checkQuotas fails if the given bid or write, together with the ones already
+// pending, would exceed the quotas of the engine once finalized. Only the
+// counters changed by the new bid or write are looked up, the usage of the
+// pending ones is kept in pendingUsage. Must be called with pendingLock held.
+func (s *TransactionalStore) checkQuotas(newBid *suave.Bid, newWrite *StoreWrite) error {
+	var (
+		bids   []suave.Bid
+		writes []StoreWrite
+	)
+	if newBid != nil {
+		bids = append(bids, *newBid)
+	}
+	if newWrite != nil {
+		writes = append(writes, *newWrite)
+	}
+
+	// The size of the value is only recorded once the write is accepted
+	var (
+		sizeKey     string
+		prevSize    int
+		hadPrevSize bool
+	)
+	if newWrite != nil {
+		sizeKey = quotaSizeKey(*newWrite)
+		prevSize, hadPrevSize = s.pendingSizes[sizeKey]
+	}
+
+	counters := s.engine.quotaCountersWithSizes(s.sourceTx, bids, writes, s.pendingSizes)
+	for _, c := range counters {
+		if c.delta <= 0 {
+			continue
+		}
+
+		usage, err := s.engine.storage.Usage(c.key)
+		if err != nil {
+			err = fmt.Errorf("confidential engine: could not read quota usage: %w", err)
+		} else if usage += s.pendingUsage[c.key] + c.delta; usage > int64(c.limit) {
+			err = c.exceeded(usage)
+		}
+		if err != nil {
+			if newWrite != nil {
+				if hadPrevSize {
+					s.pendingSizes[sizeKey] = prevSize
+				} else {
+					delete(s.pendingSizes, sizeKey)
+				}
+			}
+			return err
+		}
+	}
+
+	for _, c := range counters {
+		s.pendingUsage[c.key] += c.delta
+	}
+	return nil
+}
+
 func (s *TransactionalStore) Finalize() error {
 	return s.engine.Finalize(s.sourceTx, s.pendingBids, s.pendingWrites)
 }
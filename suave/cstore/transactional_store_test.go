@@ -22,7 +22,7 @@ func TestTransactionalStore(t *testing.T) {
 	}), types.NewSuaveSigner(new(big.Int)), testKey)
 	require.NoError(t, err)
 
-	tstore := engine.NewTransactionalStore(dummyCreationTx)
+	tstore := engine.NewTransactionalStore(dummyCreationTx, 46)
 
 	testBid, err := tstore.InitializeBid(types.Bid{
 		Salt:                RandomBidId(),
@@ -64,7 +64,7 @@ func TestTransactionalStore(t *testing.T) {
 	_, err = engine.FetchBidById(testBid.Id)
 	require.Error(t, err)
 	require.Empty(t, engine.FetchBidsByProtocolAndBlock(46, "v0-test"))
-	_, err = engine.Retrieve(testBid.Id, testBid.AllowedPeekers[0], "xx")
+	_, err = engine.Retrieve(testBid.Id, testBid.AllowedPeekers[0], "xx", 46)
 	require.Error(t, err)
 
 	require.NoError(t, tstore.Finalize())
@@ -77,7 +77,233 @@ func TestTransactionalStore(t *testing.T) {
 	require.Equal(t, 1, len(efetchedBids))
 	require.Equal(t, testBid, efetchedBids[0].ToInnerBid())
 
-	eretrieved, err := engine.Retrieve(testBid.Id, testBid.AllowedPeekers[0], "xx")
+	eretrieved, err := engine.Retrieve(testBid.Id, testBid.AllowedPeekers[0], "xx", 46)
 	require.NoError(t, err)
 	require.Equal(t, []byte{0x44}, eretrieved)
 }
+
+func TestTransactionalStore_AccessWindow(t *testing.T) {
+	engine := NewConfidentialStoreEngine(NewLocalConfidentialStore(), MockTransport{}, MockSigner{}, MockChainSigner{})
+
+	testKey, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	dummyCreationTx, err := types.SignTx(types.NewTx(&types.ConfidentialComputeRequest{
+		ConfidentialComputeRecord: types.ConfidentialComputeRecord{
+			KettleAddress: common.Address{0x42},
+		},
+	}), types.NewSuaveSigner(new(big.Int)), testKey)
+	require.NoError(t, err)
+
+	_, err = engine.NewTransactionalStore(dummyCreationTx, 10).InitializeBidWithAccessWindow(types.Bid{
+		Salt:           RandomBidId(),
+		AllowedPeekers: []common.Address{{0x43}},
+	}, suave.AccessWindow{NotBefore: 20, NotAfter: 15})
+	require.ErrorIs(t, err, suave.ErrInvalidAccessWindow)
+
+	// data can be stored before the window opens, but not read back
+	tstore := engine.NewTransactionalStore(dummyCreationTx, 10)
+	testBid, err := tstore.InitializeBidWithAccessWindow(types.Bid{
+		Salt:                RandomBidId(),
+		DecryptionCondition: 15,
+		AllowedStores:       []common.Address{{0x42}},
+		AllowedPeekers:      []common.Address{{0x43}},
+		Version:             "v0-test",
+	}, suave.AccessWindow{NotBefore: 15, NotAfter: 20})
+	require.NoError(t, err)
+
+	_, err = tstore.Store(testBid.Id, testBid.AllowedPeekers[0], "xx", []byte{0x44})
+	require.NoError(t, err)
+
+	_, err = tstore.Retrieve(testBid.Id, testBid.AllowedPeekers[0], "xx")
+	require.ErrorIs(t, err, suave.ErrBidNotAccessibleYet)
+
+	require.NoError(t, tstore.Finalize())
+
+	cases := []struct {
+		head uint64
+		err  error
+	}{
+		{14, suave.ErrBidNotAccessibleYet},
+		{15, nil},
+		{20, nil},
+		{21, suave.ErrBidAccessExpired},
+	}
+
+	for _, c := range cases {
+		_, err = engine.Retrieve(testBid.Id, testBid.AllowedPeekers[0], "xx", c.head)
+		if c.err != nil {
+			require.ErrorIs(t, err, c.err)
+		} else {
+			require.NoError(t, err)
+		}
+
+		_, err = engine.NewTransactionalStore(dummyCreationTx, c.head).Retrieve(testBid.Id, testBid.AllowedPeekers[0], "xx")
+		if c.err != nil {
+			require.ErrorIs(t, err, c.err)
+		} else {
+			require.NoError(t, err)
+		}
+	}
+
+	// the window is part of the signed bid
+	fetchedBid, err := engine.FetchBidById(testBid.Id)
+	require.NoError(t, err)
+	require.Equal(t, suave.AccessWindow{NotBefore: 15, NotAfter: 20}, fetchedBid.AccessWindow)
+
+	signedBytes, err := SerializeBidForSigning(&fetchedBid)
+	require.NoError(t, err)
+
+	fetchedBid.AccessWindow.NotBefore = 0
+	tamperedBytes, err := SerializeBidForSigning(&fetchedBid)
+	require.NoError(t, err)
+	require.NotEqual(t, signedBytes, tamperedBytes)
+}
+
+func TestTransactionalStore_Policy(t *testing.T) {
+	engine := NewConfidentialStoreEngine(NewLocalConfidentialStore(), MockTransport{}, MockSigner{}, MockChainSigner{})
+
+	testKey, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	dummyCreationTx, err := types.SignTx(types.NewTx(&types.ConfidentialComputeRequest{
+		ConfidentialComputeRecord: types.ConfidentialComputeRecord{
+			KettleAddress: common.Address{0x42},
+		},
+	}), types.NewSuaveSigner(new(big.Int)), testKey)
+	require.NoError(t, err)
+
+	var (
+		contract = common.Address{0x43}
+		reader   = common.Address{0x44}
+		builder  = common.Address{0x45}
+	)
+
+	tstore := engine.NewTransactionalStore(dummyCreationTx, 0)
+	testBid, err := tstore.InitializeBidWithPolicy(types.Bid{
+		Salt:          RandomBidId(),
+		AllowedStores: []common.Address{{0x42}},
+		Version:       "v0-test",
+	}, suave.BidPolicy{
+		Readers: []common.Address{contract, reader},
+		Writers: []common.Address{contract},
+		KeyRules: []*suave.BidKeyRule{{
+			KeyPrefix: "v0:bundles",
+			Readers:   []common.Address{builder},
+			Writers:   []common.Address{contract},
+		}},
+	})
+	require.NoError(t, err)
+
+	// readers cannot write
+	_, err = tstore.Store(testBid.Id, reader, "v0:hint", []byte{0x1})
+	require.Error(t, err)
+
+	_, err = tstore.Store(testBid.Id, contract, "v0:hint", []byte{0x1})
+	require.NoError(t, err)
+	_, err = tstore.Store(testBid.Id, contract, "v0:bundles:0", []byte{0x2})
+	require.NoError(t, err)
+
+	data, err := tstore.Retrieve(testBid.Id, reader, "v0:hint")
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x1}, data)
+
+	// bundles are only readable by the builder
+	_, err = tstore.Retrieve(testBid.Id, contract, "v0:bundles:0")
+	require.Error(t, err)
+	_, err = tstore.Retrieve(testBid.Id, builder, "v0:hint")
+	require.Error(t, err)
+
+	data, err = tstore.Retrieve(testBid.Id, builder, "v0:bundles:0")
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x2}, data)
+
+	require.NoError(t, tstore.Finalize())
+
+	_, err = engine.Retrieve(testBid.Id, contract, "v0:bundles:0", 0)
+	require.Error(t, err)
+	data, err = engine.Retrieve(testBid.Id, builder, "v0:bundles:0", 0)
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x2}, data)
+
+	// the policy is bound to the bid id and signature
+	fetchedBid, err := engine.FetchBidById(testBid.Id)
+	require.NoError(t, err)
+	require.NotNil(t, fetchedBid.Policy)
+
+	expectedId, err := calculateBidId(fetchedBid.ToInnerBid(), fetchedBid.Policy)
+	require.NoError(t, err)
+	require.Equal(t, testBid.Id, expectedId)
+
+	signedBytes, err := SerializeBidForSigning(&fetchedBid)
+	require.NoError(t, err)
+
+	fetchedBid.Policy = &suave.BidPolicy{Readers: []common.Address{suave.AllowedPeekerAny}}
+	tamperedBytes, err := SerializeBidForSigning(&fetchedBid)
+	require.NoError(t, err)
+	require.NotEqual(t, signedBytes, tamperedBytes)
+}
+
+func TestTransactionalStore_Quotas(t *testing.T) {
+	engine := NewConfidentialStoreEngine(NewLocalConfidentialStore(), MockTransport{}, MockSigner{}, MockChainSigner{})
+	engine.SetQuotas(suave.StoreQuotas{
+		Sender:   suave.QuotaLimits{BidsPerBlock: 2, BytesPerBid: 4, TotalBytes: 6},
+		Contract: suave.QuotaLimits{TotalBytes: 8},
+	})
+
+	contract := common.Address{0x99}
+	newRequest := func() *types.Transaction {
+		key, err := crypto.GenerateKey()
+		require.NoError(t, err)
+		tx, err := types.SignTx(types.NewTx(&types.ConfidentialComputeRequest{
+			ConfidentialComputeRecord: types.ConfidentialComputeRecord{
+				KettleAddress: common.Address{0x42},
+				To:            &contract,
+			},
+		}), types.NewSuaveSigner(new(big.Int)), key)
+		require.NoError(t, err)
+		return tx
+	}
+	newBid := func(tstore *TransactionalStore) (types.Bid, error) {
+		return tstore.InitializeBid(types.Bid{
+			Salt:                RandomBidId(),
+			DecryptionCondition: 46,
+			AllowedStores:       []common.Address{{0x42}},
+			AllowedPeekers:      []common.Address{{0x43}},
+		})
+	}
+
+	request := newRequest()
+	tstore := engine.NewTransactionalStore(request, 45)
+
+	bid1, err := newBid(tstore)
+	require.NoError(t, err)
+	bid2, err := newBid(tstore)
+	require.NoError(t, err)
+	_, err = newBid(tstore)
+	require.ErrorIs(t, err, suave.ErrQuotaExceeded)
+
+	_, err = tstore.Store(bid1.Id, bid1.AllowedPeekers[0], "xx", []byte{0x1, 0x2, 0x3, 0x4, 0x5})
+	require.ErrorIs(t, err, suave.ErrQuotaExceeded)
+	_, err = tstore.Store(bid1.Id, bid1.AllowedPeekers[0], "xx", []byte{0x1, 0x2, 0x3, 0x4})
+	require.NoError(t, err)
+
+	// Overwriting a value only counts its growth
+	_, err = tstore.Store(bid1.Id, bid1.AllowedPeekers[0], "xx", []byte{0x1, 0x2})
+	require.NoError(t, err)
+	_, err = tstore.Store(bid2.Id, bid2.AllowedPeekers[0], "xx", []byte{0x1, 0x2, 0x3, 0x4})
+	require.NoError(t, err)
+	require.NoError(t, tstore.Finalize())
+
+	// The usage of the finalized request counts for the next ones of the signer
+	tstore = engine.NewTransactionalStore(request, 46)
+	_, err = newBid(tstore)
+	require.ErrorIs(t, err, suave.ErrQuotaExceeded)
+	_, err = tstore.Store(bid1.Id, bid1.AllowedPeekers[0], "xy", []byte{0x1})
+	require.ErrorIs(t, err, suave.ErrQuotaExceeded)
+
+	// Other signers are only bound by the quota of the contract
+	tstore = engine.NewTransactionalStore(newRequest(), 46)
+	_, err = tstore.Store(bid1.Id, bid1.AllowedPeekers[0], "xy", []byte{0x1, 0x2})
+	require.NoError(t, err)
+	bid3, err := newBid(tstore)
+	require.NoError(t, err)
+	_, err = tstore.Store(bid3.Id, bid3.AllowedPeekers[0], "xx", []byte{0x1})
+	require.ErrorContains(t, err, "bytes of contract 0x9900000000000000000000000000000000000000 would be 9, limit is 8")
+}
@@ -4,29 +4,23 @@ import (
 	"encoding/json"
 	"math/big"
 	"testing"
-	"time"
 
-	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/suave/sdk"
+	"github.com/ethereum/go-ethereum/suave/suavetest"
 	"github.com/stretchr/testify/require"
 )
 
 func TestRedisBackends(t *testing.T) {
-	withMiniredisTransportOpt := WithRedisTransportOpt(t)
+	fr := newFramework(t, suavetest.WithKettles(2), suavetest.WithL1(), suavetest.WithRedisStore())
+	defer fr.Close()
 
-	fr1 := newFramework(t, WithKettleAddress(), WithRedisStoreBackend(), withMiniredisTransportOpt)
-	t.Cleanup(fr1.Close)
+	kettle1, kettle2 := fr.Kettles[0], fr.Kettles[1]
+	kettle1.ImportKey(testKey)
 
-	var keystoreBackend *keystore.KeyStore = fr1.suethSrv.service.APIBackend.AccountManager().Backends(keystore.KeyStoreType)[0].(*keystore.KeyStore)
-	keystoreBackend.ImportECDSA(testKey, "")
-
-	fr2 := newFramework(t, WithKettleAddress(), WithRedisStoreBackend(), withMiniredisTransportOpt)
-	t.Cleanup(fr2.Close)
-
-	clt1 := fr1.NewSDKClient()
-	clt2 := fr2.NewSDKClient()
+	clt1 := kettle1.NewSDKClient(testKey)
+	clt2 := kettle2.NewSDKClient(testKey)
 
 	ethTx, err := clt1.SignTxn(&types.LegacyTx{
 		Nonce:    0,
@@ -55,19 +49,21 @@ func TestRedisBackends(t *testing.T) {
 
 		bundleBidContractI := sdk.GetContract(newBundleBidAddress, BundleBidContract.Abi, clt1)
 
-		_, err = bundleBidContractI.SendTransaction("newBid", []interface{}{targetBlock + 1, allowedPeekers, []common.Address{fr1.KettleAddress(), fr2.KettleAddress()}}, confidentialDataBytes)
-		requireNoRpcError(t, err)
+		_, err = bundleBidContractI.SendTransaction("newBid", []interface{}{targetBlock + 1, allowedPeekers, fr.KettleAddresses()}, confidentialDataBytes)
+		suavetest.RequireNoRpcError(t, err)
 	}
 
-	block := fr1.suethSrv.ProgressChain()
+	block := kettle1.ProgressChain()
 	require.Equal(t, 1, len(block.Transactions()))
 
-	time.Sleep(1000 * time.Millisecond)
-
-	// TODO: explicitly check if fr2 received the bid
+	{ // The bid reaches the second kettle
+		unpacked, err := BundleBidContract.Abi.Events["BidEvent"].Inputs.Unpack(block.Receipts[0].Logs[0].Data)
+		require.NoError(t, err)
+		fr.WaitForBid(unpacked[0].([16]byte))
+	}
 
 	{
-		ethHead := fr2.ethSrv.CurrentBlock()
+		ethHead := fr.L1.CurrentBlock()
 
 		payloadArgsTuple := types.BuildBlockArgs{
 			ProposerPubkey: []byte{0x42},
@@ -78,9 +74,9 @@ func TestRedisBackends(t *testing.T) {
 		buildEthBlockContractI := sdk.GetContract(newBlockBidAddress, buildEthBlockContract.Abi, clt2)
 
 		_, err = buildEthBlockContractI.SendTransaction("buildFromPool", []interface{}{payloadArgsTuple, targetBlock + 1}, nil)
-		requireNoRpcError(t, err)
+		suavetest.RequireNoRpcError(t, err)
 
-		block = fr2.suethSrv.ProgressChain()
+		block = kettle2.ProgressChain()
 		require.Equal(t, 1, len(block.Transactions()))
 	}
 }
@@ -1,46 +1,39 @@
 package e2e
 
 import (
+	"bytes"
 	"context"
 	"crypto/ecdsa"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"io"
 	"math/big"
-	"net/http"
-	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
-	"github.com/alicebob/miniredis/v2"
 	builderCapella "github.com/attestantio/go-builder-client/api/capella"
 	bellatrixSpec "github.com/attestantio/go-eth2-client/spec/bellatrix"
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/accounts/abi"
-	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/beacon/engine"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/ethereum/go-ethereum/eth"
-	"github.com/ethereum/go-ethereum/eth/downloader"
-	"github.com/ethereum/go-ethereum/eth/ethconfig"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
-	"github.com/ethereum/go-ethereum/miner"
-	"github.com/ethereum/go-ethereum/node"
-	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/ethereum/go-ethereum/suave/artifacts"
 	suave "github.com/ethereum/go-ethereum/suave/core"
 	"github.com/ethereum/go-ethereum/suave/cstore"
 	"github.com/ethereum/go-ethereum/suave/sdk"
+	"github.com/ethereum/go-ethereum/suave/suavetest"
 	"github.com/flashbots/go-boost-utils/bls"
 	"github.com/flashbots/go-boost-utils/ssz"
 	"github.com/mitchellh/mapstructure"
@@ -60,7 +53,7 @@ func TestIsConfidential(t *testing.T) {
 	{
 		// Verify eth_call of isConfidentialAddress returns 1/0 depending on confidential compute setting
 		var result string
-		requireNoRpcError(t, rpc.Call(&result, "eth_call", setTxArgsDefaults(ethapi.TransactionArgs{
+		suavetest.RequireNoRpcError(t, rpc.Call(&result, "eth_call", setTxArgsDefaults(ethapi.TransactionArgs{
 			To:             &isConfidentialAddress,
 			IsConfidential: true,
 			ChainID:        &chainId,
@@ -87,7 +80,7 @@ func TestIsConfidential(t *testing.T) {
 		require.NoError(t, err)
 
 		var confidentialRequestTxHash common.Hash
-		requireNoRpcError(t, rpc.Call(&confidentialRequestTxHash, "eth_sendRawTransaction", hexutil.Encode(confidentialRequestTxBytes)))
+		suavetest.RequireNoRpcError(t, rpc.Call(&confidentialRequestTxHash, "eth_sendRawTransaction", hexutil.Encode(confidentialRequestTxBytes)))
 
 		onchainTx, err := types.SignTx(types.NewTx(&types.LegacyTx{
 			Nonce:    1,
@@ -103,7 +96,7 @@ func TestIsConfidential(t *testing.T) {
 		require.NoError(t, err)
 
 		var onchainTxHash common.Hash
-		requireNoRpcError(t, rpc.Call(&onchainTxHash, "eth_sendRawTransaction", hexutil.Encode(onchainTxBytes)))
+		suavetest.RequireNoRpcError(t, rpc.Call(&onchainTxHash, "eth_sendRawTransaction", hexutil.Encode(onchainTxBytes)))
 		require.Equal(t, common.HexToHash("0x031415a9010d25f2a882758cf7b8dbb3750678828e9973f32f0c73ef49a038b4"), onchainTxHash)
 
 		block := fr.suethSrv.ProgressChain()
@@ -122,6 +115,31 @@ func TestIsConfidential(t *testing.T) {
 	}
 }
 
+func TestKettleInfo(t *testing.T) {
+	fr := newFramework(t, suavetest.WithL1())
+	defer fr.Close()
+
+	info, err := sdk.FetchKettleInfo(context.Background(), fr.suethSrv.RPCNode(), []common.Address{fr.KettleAddress()})
+	require.NoError(t, err)
+
+	require.Equal(t, []common.Address{fr.KettleAddress()}, info.KettleAddresses)
+	require.NotEmpty(t, info.EncryptionKey)
+	require.Len(t, info.BundleSigningKey, 65)
+	require.Len(t, info.BlockSigningKey, 48)
+	require.Equal(t, artifacts.SpecHash, info.PrecompileSpecHash)
+	require.Equal(t, artifacts.SuaveMethods, info.Precompiles)
+	require.Equal(t, testSuaveGenesis.Config.ChainID, info.ChainID.ToInt())
+	require.Equal(t, "local", info.StoreBackend)
+
+	clt, err := sdk.SelectKettle(context.Background(), []*rpc.Client{fr.suethSrv.RPCNode()}, testKey, sdk.KettleRequirements{
+		KettleAddresses:    []common.Address{fr.KettleAddress()},
+		PrecompileSpecHash: artifacts.SpecHash,
+		EncryptedInputs:    true,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, clt)
+}
+
 func TestMempool(t *testing.T) {
 	// t.Fatal("not implemented")
 	fr := newFramework(t)
@@ -166,7 +184,7 @@ func TestMempool(t *testing.T) {
 		require.NoError(t, err)
 
 		var simResult hexutil.Bytes
-		requireNoRpcError(t, rpc.Call(&simResult, "eth_call", setTxArgsDefaults(ethapi.TransactionArgs{
+		suavetest.RequireNoRpcError(t, rpc.Call(&simResult, "eth_call", setTxArgsDefaults(ethapi.TransactionArgs{
 			To:             &fetchBidsAddress,
 			Gas:            &gas,
 			IsConfidential: true,
@@ -210,7 +228,7 @@ func TestMempool(t *testing.T) {
 		require.NoError(t, err)
 
 		var confidentialRequestTxHash common.Hash
-		requireNoRpcError(t, rpc.Call(&confidentialRequestTxHash, "eth_sendRawTransaction", hexutil.Encode(confidentialRequestTxBytes)))
+		suavetest.RequireNoRpcError(t, rpc.Call(&confidentialRequestTxHash, "eth_sendRawTransaction", hexutil.Encode(confidentialRequestTxBytes)))
 
 		block := fr.suethSrv.ProgressChain()
 		require.Equal(t, 1, len(block.Transactions()))
@@ -255,7 +273,7 @@ func TestTxSigningPrecompile(t *testing.T) {
 		ChainID:        &chainId,
 		Data:           (*hexutil.Bytes)(&args),
 	}), "latest")
-	requireNoRpcError(t, err)
+	suavetest.RequireNoRpcError(t, err)
 
 	unpackedCallResult, err := artifacts.SuaveAbi.Methods["signEthTransaction"].Outputs.Unpack(callResult)
 	require.NoError(t, err)
@@ -301,7 +319,7 @@ func TestBundleBid(t *testing.T) {
 
 		bundleBidContractI := sdk.GetContract(newBundleBidAddress, BundleBidContract.Abi, clt)
 		_, err = bundleBidContractI.SendTransaction("newBid", []interface{}{targetBlock, allowedPeekers, []common.Address{}}, confidentialDataBytes)
-		requireNoRpcError(t, err)
+		suavetest.RequireNoRpcError(t, err)
 
 		block := fr.suethSrv.ProgressChain()
 		require.Equal(t, 1, len(block.Transactions()))
@@ -335,7 +353,7 @@ func TestBundleBid(t *testing.T) {
 		require.Equal(t, bid.DecryptionCondition, unpacked[1].(uint64))
 		require.Equal(t, bid.AllowedPeekers, unpacked[2].([]common.Address))
 
-		_, err = fr.ConfidentialEngine().Retrieve(bid.Id, common.Address{0x41, 0x42, 0x43}, "default:v0:ethBundleSimResults")
+		_, err = fr.ConfidentialEngine().Retrieve(bid.Id, common.Address{0x41, 0x42, 0x43}, "default:v0:ethBundleSimResults", fr.HeadNumber())
 		require.NoError(t, err)
 	}
 }
@@ -347,38 +365,6 @@ func TestBundleSenderContract(t *testing.T) {
 
 	clt := fr.NewSDKClient()
 
-	bundleSentToBuilder := &struct {
-		Id     json.RawMessage
-		Params []types.RpcSBundle
-	}{}
-	serveHttp := func(t *testing.T, w http.ResponseWriter, r *http.Request) {
-		bodyBytes, err := io.ReadAll(r.Body)
-		require.NoError(t, err)
-
-		err = json.Unmarshal(bodyBytes, bundleSentToBuilder)
-		if err != nil {
-			require.NoError(t, err, string(bodyBytes))
-			w.WriteHeader(http.StatusBadRequest)
-			return
-		}
-
-		splitSig := strings.Split(r.Header.Get("x-flashbots-signature"), ":")
-		require.Equal(t, 2, len(splitSig))
-		require.Equal(t, splitSig[0], crypto.PubkeyToAddress(*bundleSigningKeyPub).Hex())
-
-		signature := hexutil.MustDecode(splitSig[1])
-		hashedBody := accounts.TextHash([]byte(crypto.Keccak256Hash(bodyBytes).Hex()))
-		pk, err := crypto.SigToPub(hashedBody, signature)
-		require.NoError(t, err)
-		require.True(t, pk.Equal(bundleSigningKeyPub))
-		require.True(t, crypto.VerifySignature(crypto.CompressPubkey(bundleSigningKeyPub), hashedBody, signature[:64]))
-
-		w.WriteHeader(http.StatusOK)
-	}
-
-	fakeRelayServer := httptest.NewServer(&fakeRelayHandler{t, serveHttp})
-	defer fakeRelayServer.Close()
-
 	{
 		targetBlock := uint64(16103213)
 
@@ -399,7 +385,7 @@ func TestBundleSenderContract(t *testing.T) {
 		confidentialDataBytes, err := BundleBidContract.Abi.Methods["fetchBidConfidentialBundleData"].Outputs.Pack(bundleBytes)
 		require.NoError(t, err)
 
-		constructorArgs, err := EthBundleSenderContract.Abi.Constructor.Inputs.Pack([]string{fakeRelayServer.URL})
+		constructorArgs, err := EthBundleSenderContract.Abi.Constructor.Inputs.Pack([]string{fr.Relay.URL()})
 		require.NoError(t, err)
 
 		deployCode := EthBundleSenderContract.Code
@@ -416,7 +402,7 @@ func TestBundleSenderContract(t *testing.T) {
 		allowedPeekers := []common.Address{bundleSenderContract.Address()}
 
 		_, err = bundleSenderContract.SendTransaction("newBid", []interface{}{targetBlock, allowedPeekers, []common.Address{}}, confidentialDataBytes)
-		requireNoRpcError(t, err)
+		suavetest.RequireNoRpcError(t, err)
 
 		block := fr.suethSrv.ProgressChain()
 		require.Equal(t, 1, len(block.Transactions()))
@@ -426,6 +412,26 @@ func TestBundleSenderContract(t *testing.T) {
 		require.Equal(t, uint8(types.SuaveTxType), receipts[0].Type)
 		require.Equal(t, uint64(1), receipts[0].Status)
 
+		submission := fr.Relay.LastSubmission()
+		require.NotNil(t, submission)
+
+		bundleSentToBuilder := &struct {
+			Id     json.RawMessage
+			Params []types.RpcSBundle
+		}{}
+		require.NoError(t, submission.Decode(bundleSentToBuilder), string(submission.Body))
+
+		splitSig := strings.Split(submission.Header.Get("x-flashbots-signature"), ":")
+		require.Equal(t, 2, len(splitSig))
+		require.Equal(t, splitSig[0], crypto.PubkeyToAddress(*bundleSigningKeyPub).Hex())
+
+		signature := hexutil.MustDecode(splitSig[1])
+		hashedBody := accounts.TextHash([]byte(crypto.Keccak256Hash(submission.Body).Hex()))
+		pk, err := crypto.SigToPub(hashedBody, signature)
+		require.NoError(t, err)
+		require.True(t, pk.Equal(bundleSigningKeyPub))
+		require.True(t, crypto.VerifySignature(crypto.CompressPubkey(bundleSigningKeyPub), hashedBody, signature[:64]))
+
 		require.Equal(t, 1, len(bundleSentToBuilder.Params))
 		require.Equal(t, 1, len(bundleSentToBuilder.Params[0].Txs))
 
@@ -499,7 +505,7 @@ func TestMevShare(t *testing.T) {
 	// 3. build share block
 	//   3a. confirm share bundle
 
-	fr := newFramework(t, WithKettleAddress())
+	fr := newFramework(t, suavetest.WithL1())
 	defer fr.Close()
 
 	rpc := fr.suethSrv.RPCNode()
@@ -515,7 +521,7 @@ func TestMevShare(t *testing.T) {
 
 	bundleBidContractI := sdk.GetContract(mevShareAddress, BundleBidContract.Abi, clt)
 	_, err := bundleBidContractI.SendTransaction("newBid", []interface{}{targetBlock + 1, allowedPeekers, []common.Address{fr.KettleAddress()}}, confidentialDataBytes)
-	requireNoRpcError(t, err)
+	suavetest.RequireNoRpcError(t, err)
 
 	//   1a. confirm submission
 	block := fr.suethSrv.ProgressChain()
@@ -543,7 +549,7 @@ func TestMevShare(t *testing.T) {
 
 	cc := sdk.GetContract(mevShareAddress, MevShareBidContract.Abi, clt)
 	_, err = cc.SendTransaction("newMatch", []interface{}{targetBlock + 1, allowedPeekers, []common.Address{fr.KettleAddress()}, shareBidId}, confidentialDataMatchBytes)
-	requireNoRpcError(t, err)
+	suavetest.RequireNoRpcError(t, err)
 
 	block = fr.suethSrv.ProgressChain()
 	require.Equal(t, 1, len(block.Transactions()))
@@ -568,13 +574,13 @@ func TestMevShare(t *testing.T) {
 
 	cc = sdk.GetContract(newBlockBidAddress, buildEthBlockContract.Abi, clt)
 	_, err = cc.SendTransaction("buildMevShare", []interface{}{payloadArgsTuple, targetBlock + 1}, nil)
-	requireNoRpcError(t, err)
+	suavetest.RequireNoRpcError(t, err)
 
 	block = fr.suethSrv.ProgressChain() // block = progressChain(t, ethservice, block.Header())
 	require.Equal(t, 1, len(block.Transactions()))
 
 	var r3 *types.Receipt
-	requireNoRpcError(t, rpc.Call(&r3, "eth_getTransactionReceipt", block.Transactions()[0].Hash()))
+	suavetest.RequireNoRpcError(t, rpc.Call(&r3, "eth_getTransactionReceipt", block.Transactions()[0].Hash()))
 	require.NotEmpty(t, r3.Logs)
 
 	{ // Fetch the built block id and check that the payload contains mev share trasnactions!
@@ -589,7 +595,7 @@ func TestMevShare(t *testing.T) {
 		require.NoError(t, err)
 
 		bidId := unpacked[0].([16]byte)
-		payloadData, err := fr.ConfidentialEngine().Retrieve(bidId, newBlockBidAddress, "default:v0:builderPayload")
+		payloadData, err := fr.ConfidentialEngine().Retrieve(bidId, newBlockBidAddress, "default:v0:builderPayload", fr.HeadNumber())
 		require.NoError(t, err)
 
 		var payloadEnvelope engine.ExecutionPayloadEnvelope
@@ -615,26 +621,7 @@ func TestMevShareBundleSenderContract(t *testing.T) {
 
 	clt := fr.NewSDKClient()
 
-	bundleSentToBuilder := &struct {
-		Params []types.RPCMevShareBundle
-	}{}
-	serveHttp := func(t *testing.T, w http.ResponseWriter, r *http.Request) {
-		bodyBytes, err := io.ReadAll(r.Body)
-		require.NoError(t, err)
-
-		err = json.Unmarshal(bodyBytes, bundleSentToBuilder)
-		if err != nil {
-			w.WriteHeader(http.StatusBadRequest)
-			return
-		}
-
-		w.WriteHeader(http.StatusOK)
-	}
-
-	fakeRelayServer := httptest.NewServer(&fakeRelayHandler{t, serveHttp})
-	defer fakeRelayServer.Close()
-
-	constructorArgs, err := MevShareBundleSenderContract.Abi.Constructor.Inputs.Pack([]string{fakeRelayServer.URL})
+	constructorArgs, err := MevShareBundleSenderContract.Abi.Constructor.Inputs.Pack([]string{fr.Relay.URL()})
 	require.NoError(t, err)
 
 	deployCode := MevShareBundleSenderContract.Code
@@ -658,7 +645,7 @@ func TestMevShareBundleSenderContract(t *testing.T) {
 		allowedPeekers := []common.Address{fillMevShareBundleAddress, bundleSenderContract.Address()}
 
 		txRes, err := bundleSenderContract.SendTransaction("newBid", []interface{}{targetBlock, allowedPeekers, []common.Address{}}, confidentialDataBytes)
-		requireNoRpcError(t, err)
+		suavetest.RequireNoRpcError(t, err)
 
 		fr.suethSrv.ProgressChain()
 
@@ -678,7 +665,7 @@ func TestMevShareBundleSenderContract(t *testing.T) {
 		matchTx, _, confidentialDataMatchBytes := prepareMevShareBackrun(t, shareBidId)
 
 		txRes, err = bundleSenderContract.SendTransaction("newMatch", []interface{}{targetBlock, allowedPeekers, []common.Address{fr.KettleAddress()}, shareBidId}, confidentialDataMatchBytes)
-		requireNoRpcError(t, err)
+		suavetest.RequireNoRpcError(t, err)
 
 		fr.suethSrv.ProgressChain()
 
@@ -686,6 +673,14 @@ func TestMevShareBundleSenderContract(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, uint64(1), receipt.Status)
 
+		submission := fr.Relay.LastSubmission()
+		require.NotNil(t, submission)
+
+		bundleSentToBuilder := &struct {
+			Params []types.RPCMevShareBundle
+		}{}
+		require.NoError(t, submission.Decode(bundleSentToBuilder))
+
 		retrievedBlockNumber, err := hexutil.DecodeUint64(bundleSentToBuilder.Params[0].Inclusion.Block)
 		require.NoError(t, err)
 		require.Equal(t, targetBlock, retrievedBlockNumber)
@@ -707,7 +702,7 @@ func TestMevShareBundleSenderContract(t *testing.T) {
 }
 
 func TestBlockBuildingPrecompiles(t *testing.T) {
-	fr := newFramework(t, WithKettleAddress())
+	fr := newFramework(t, suavetest.WithL1())
 	defer fr.Close()
 
 	rpc := fr.suethSrv.RPCNode()
@@ -739,7 +734,7 @@ func TestBlockBuildingPrecompiles(t *testing.T) {
 		require.NoError(t, err)
 
 		var simResult hexutil.Bytes
-		requireNoRpcError(t, rpc.CallContext(ctx, &simResult, "eth_call", setTxArgsDefaults(ethapi.TransactionArgs{
+		suavetest.RequireNoRpcError(t, rpc.CallContext(ctx, &simResult, "eth_call", setTxArgsDefaults(ethapi.TransactionArgs{
 			To:             &simulateBundleAddress,
 			Gas:            &gas,
 			IsConfidential: true,
@@ -789,7 +784,7 @@ func TestBlockBuildingPrecompiles(t *testing.T) {
 		require.NoError(t, err)
 
 		var simResult hexutil.Bytes
-		requireNoRpcError(t, rpc.CallContext(ctx, &simResult, "eth_call", setTxArgsDefaults(ethapi.TransactionArgs{
+		suavetest.RequireNoRpcError(t, rpc.CallContext(ctx, &simResult, "eth_call", setTxArgsDefaults(ethapi.TransactionArgs{
 			To:             &buildEthBlockAddress,
 			Gas:            &gas,
 			IsConfidential: true,
@@ -816,7 +811,7 @@ func TestBlockBuildingPrecompiles(t *testing.T) {
 }
 
 func TestBlockBuildingContract(t *testing.T) {
-	fr := newFramework(t, WithKettleAddress())
+	fr := newFramework(t, suavetest.WithL1())
 	defer fr.Close()
 
 	clt := fr.NewSDKClient()
@@ -876,44 +871,18 @@ func TestBlockBuildingContract(t *testing.T) {
 
 func TestRelayBlockSubmissionContract(t *testing.T) {
 	skOpt, signingPubkey := WithBlockSigningKeyOpt(t)
-	fr := newFramework(t, WithKettleAddress(), skOpt)
+	fr := newFramework(t, suavetest.WithL1(), skOpt)
 	defer fr.Close()
 
 	rpc := fr.suethSrv.RPCNode()
 	clt := fr.NewSDKClient()
 
-	var block *block
+	var block *suavetest.Block
 
 	var ethBlockBidSenderAddr common.Address
 
-	var blockPayloadSentToRelay *builderCapella.SubmitBlockRequest = &builderCapella.SubmitBlockRequest{}
-	serveHttp := func(t *testing.T, w http.ResponseWriter, r *http.Request) {
-		bodyBytes, err := io.ReadAll(r.Body)
-		require.NoError(t, err)
-
-		err = json.Unmarshal(bodyBytes, blockPayloadSentToRelay)
-		if err != nil {
-			blockPayloadSentToRelay = nil
-			require.NoError(t, err)
-			w.WriteHeader(http.StatusBadRequest)
-			return
-		}
-
-		genesisForkVersion := phase0.Version{0x00, 0x00, 0x10, 0x20}
-		builderSigningDomain := ssz.ComputeDomain(ssz.DomainTypeAppBuilder, genesisForkVersion, phase0.Root{})
-		ok, err := ssz.VerifySignature(blockPayloadSentToRelay.Message, builderSigningDomain, bls.PublicKeyToBytes(signingPubkey), blockPayloadSentToRelay.Signature[:])
-		require.NoError(t, err)
-		require.True(t, ok)
-
-		w.WriteHeader(http.StatusOK)
-	}
-	testHandler := &fakeRelayHandler{t, serveHttp}
-
-	fakeRelayServer := httptest.NewServer(testHandler)
-	defer fakeRelayServer.Close()
-
 	{ // Deploy the contract
-		abiEncodedRelayUrl, err := ethBlockBidSenderContract.Abi.Pack("", fakeRelayServer.URL)
+		abiEncodedRelayUrl, err := ethBlockBidSenderContract.Abi.Pack("", fr.Relay.URL())
 		require.NoError(t, err)
 
 		calldata := append(ethBlockBidSenderContract.Code, abiEncodedRelayUrl...)
@@ -934,7 +903,7 @@ func TestRelayBlockSubmissionContract(t *testing.T) {
 		require.NoError(t, err)
 
 		var txHash common.Hash
-		requireNoRpcError(t, rpc.Call(&txHash, "eth_sendRawTransaction", hexutil.Encode(txBytes)))
+		suavetest.RequireNoRpcError(t, rpc.Call(&txHash, "eth_sendRawTransaction", hexutil.Encode(txBytes)))
 
 		block = fr.suethSrv.ProgressChain()
 		require.Equal(t, 1, len(block.Transactions()))
@@ -968,7 +937,7 @@ func TestRelayBlockSubmissionContract(t *testing.T) {
 
 		bundleBidContractI := sdk.GetContract(newBundleBidAddress, BundleBidContract.Abi, clt)
 		_, err = bundleBidContractI.SendTransaction("newBid", []interface{}{targetBlock + 1, allowedPeekers, []common.Address{}}, confidentialDataBytes)
-		requireNoRpcError(t, err)
+		suavetest.RequireNoRpcError(t, err)
 	}
 
 	block = fr.suethSrv.ProgressChain()
@@ -991,8 +960,18 @@ func TestRelayBlockSubmissionContract(t *testing.T) {
 		require.Equal(t, 1, len(block.Transactions()))
 	}
 
-	require.NotNil(t, blockPayloadSentToRelay)
+	submission := fr.Relay.LastSubmission()
+	require.NotNil(t, submission)
+
+	blockPayloadSentToRelay := &builderCapella.SubmitBlockRequest{}
+	require.NoError(t, submission.Decode(blockPayloadSentToRelay))
 	require.NotNil(t, blockPayloadSentToRelay.ExecutionPayload)
+
+	genesisForkVersion := phase0.Version{0x00, 0x00, 0x10, 0x20}
+	builderSigningDomain := ssz.ComputeDomain(ssz.DomainTypeAppBuilder, genesisForkVersion, phase0.Root{})
+	ok, err := ssz.VerifySignature(blockPayloadSentToRelay.Message, builderSigningDomain, bls.PublicKeyToBytes(signingPubkey), blockPayloadSentToRelay.Signature[:])
+	require.NoError(t, err)
+	require.True(t, ok)
 	require.Equal(t, 2, len(blockPayloadSentToRelay.ExecutionPayload.Transactions)) // Should be 2, including the proposer payment tx - todo
 	ethTxBytes, _ := ethTx.MarshalBinary()
 	require.Equal(t, bellatrixSpec.Transaction(ethTxBytes), blockPayloadSentToRelay.ExecutionPayload.Transactions[0])
@@ -1002,15 +981,14 @@ func TestRelayBlockSubmissionContract(t *testing.T) {
 
 	builderPubkey := blockPayloadSentToRelay.Message.BuilderPubkey
 	signature := blockPayloadSentToRelay.Signature
-	builderSigningDomain := ssz.ComputeDomain(ssz.DomainTypeAppBuilder, phase0.Version{0x00, 0x00, 0x10, 0x20}, phase0.Root{})
-	ok, err := ssz.VerifySignature(blockPayloadSentToRelay.Message, builderSigningDomain, builderPubkey[:], signature[:])
+	ok, err = ssz.VerifySignature(blockPayloadSentToRelay.Message, builderSigningDomain, builderPubkey[:], signature[:])
 	require.NoError(t, err)
 	require.True(t, ok)
 }
 
 func TestE2E_ForgeIntegration(t *testing.T) {
 	// This end-to-end test ensures that the precompile lifecycle expected in Forge works
-	fr := newFramework(t, WithKettleAddress())
+	fr := newFramework(t, suavetest.WithL1())
 	defer fr.Close()
 
 	rpcClient := fr.suethSrv.RPCNode()
@@ -1071,7 +1049,7 @@ func TestE2E_ForgeIntegration(t *testing.T) {
 
 func TestE2EPrecompile_Call(t *testing.T) {
 	// This end-to-end tests that the callx precompile gets called from a confidential request
-	fr := newFramework(t, WithKettleAddress())
+	fr := newFramework(t, suavetest.WithL1())
 	defer fr.Close()
 
 	clt := fr.NewSDKClient()
@@ -1097,7 +1075,7 @@ func TestE2EPrecompile_Call(t *testing.T) {
 func TestE2EKettleAddressEndpoint(t *testing.T) {
 	// this end-to-end tests ensures that we can call eth_kettleAddress endpoint in a MEVM node
 	// and return the correct execution address list
-	fr := newFramework(t, WithKettleAddress())
+	fr := newFramework(t, suavetest.WithL1())
 	defer fr.Close()
 
 	var addrs []common.Address
@@ -1120,155 +1098,143 @@ func TestE2EOnChainStateTransition(t *testing.T) {
 	require.Error(t, err)
 }
 
-type clientWrapper struct {
-	t *testing.T
-
-	node    *node.Node
-	service *eth.Ethereum
-}
-
-func (c *clientWrapper) Close() {
-	c.node.Close()
-}
-
-func (c *clientWrapper) RPCNode() *rpc.Client {
-	rpc, err := c.node.Attach()
-	if err != nil {
-		c.t.Fatal(err)
-	}
-	return rpc
-}
-
-func (c *clientWrapper) CurrentBlock() *types.Header {
-	return c.service.BlockChain().CurrentBlock()
-}
+func TestConfidentialLogs(t *testing.T) {
+	// Logs emitted during confidential execution are only delivered to the
+	// signer of the request. The contract logs 42 when called without
+	// calldata and returns a callback that does nothing.
+	contractAddr := common.Address{0x44}
+	fr := newFramework(t, suavetest.WithGenesisAlloc(core.GenesisAlloc{
+		contractAddr: {Balance: common.Big0, Code: common.FromHex("0x361560075700005b602a60005260206000a06004601cf3")},
+	}))
+	defer fr.Close()
 
-type block struct {
-	*types.Block
+	clt := fr.NewSDKClient()
 
-	Receipts []*types.Receipt
-}
+	results := make(chan *suave.ConfidentialResult, 1)
+	sub, err := clt.SubscribeConfidentialLogs(context.Background(), results)
+	require.NoError(t, err)
+	defer sub.Unsubscribe()
 
-func (c *clientWrapper) ProgressChain() *block {
-	tBlock := progressChain(c.t, c.service, c.service.BlockChain().CurrentBlock())
-	receipts := c.service.BlockChain().GetReceiptsByHash(tBlock.Hash())
+	contract := sdk.GetContract(contractAddr, &abi.ABI{}, clt)
+	res, err := contract.SendTransaction("", nil, nil)
+	suavetest.RequireNoRpcError(t, err)
 
-	return &block{
-		Block:    tBlock,
-		Receipts: receipts,
+	logs, err := res.ConfidentialLogs()
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	require.Equal(t, contractAddr, logs[0].Address)
+	require.Equal(t, common.BigToHash(big.NewInt(42)).Bytes(), logs[0].Data)
+
+	select {
+	case result := <-results:
+		require.Equal(t, res.Hash(), result.TxHash)
+		require.Equal(t, testAddr, result.Sender)
+		require.Equal(t, logs, result.Logs)
+	case <-time.After(5 * time.Second):
+		t.Fatal("confidential result not delivered")
 	}
-}
 
-type framework struct {
-	t *testing.T
+	// Other accounts cannot fetch the logs
+	otherKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	_, err = fr.suethSrv.NewSDKClient(otherKey).ConfidentialResult(context.Background(), res.Hash())
+	require.Error(t, err)
 
-	ethSrv   *clientWrapper
-	suethSrv *clientWrapper
+	// The logs are not included on chain
+	block := fr.suethSrv.ProgressChain()
+	require.Len(t, block.Receipts, 1)
+	require.Equal(t, types.ReceiptStatusSuccessful, block.Receipts[0].Status)
+	require.Empty(t, block.Receipts[0].Logs)
 }
 
-type frameworkConfig struct {
-	kettleAddress     bool
-	redisStoreBackend bool
-	suaveConfig       suave.Config
-}
+func TestAuditLogReplay(t *testing.T) {
+	// The contract returns its confidential inputs, read from the
+	// confidentialInputs precompile
+	contractAddr := common.Address{0x45}
+	auditLogPath := filepath.Join(t.TempDir(), "audit.log")
+	auditLogKey := bytes.Repeat([]byte{0x01}, 32)
+
+	fr := newFramework(t, suavetest.WithGenesisAlloc(core.GenesisAlloc{
+		contractAddr: {Balance: common.Big0, Code: common.FromHex("0x600060006000600063420100015afa503d600060003e3d6000f3")},
+	}), suavetest.WithSuaveConfig(func(config *suave.Config) {
+		config.AuditLogPath = auditLogPath
+		config.AuditLogEncryptionKeyHex = hex.EncodeToString(auditLogKey)
+	}))
+	defer fr.Close()
 
-var defaultFrameworkConfig = frameworkConfig{
-	kettleAddress:     false,
-	redisStoreBackend: false,
-	suaveConfig:       suave.Config{},
-}
+	contract := sdk.GetContract(contractAddr, &abi.ABI{}, fr.NewSDKClient())
+	res, err := contract.SendTransaction("", nil, []byte{0x12, 0x34})
+	suavetest.RequireNoRpcError(t, err)
 
-type frameworkOpt func(*frameworkConfig)
+	auditLog, err := os.ReadFile(auditLogPath)
+	require.NoError(t, err)
+	record, err := suave.FindAuditRecord(bytes.NewReader(auditLog), auditLogKey, res.Hash())
+	require.NoError(t, err)
+	require.Len(t, record.PrecompileCalls, 1)
+	require.Equal(t, hexutil.Bytes{0x12, 0x34}, record.PrecompileCalls[0].Output)
 
-func WithKettleAddress() frameworkOpt {
-	return func(c *frameworkConfig) {
-		c.kettleAddress = true
-	}
-}
+	chain := fr.suethSrv.Service.BlockChain()
+	result, err := ethapi.ReplayConfidentialRequest(chain, record, nil)
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x12, 0x34}, result)
 
-func WithRedisStoreBackend() frameworkOpt {
-	return func(c *frameworkConfig) {
-		c.redisStoreBackend = true
-	}
+	// A different recorded response does not produce the same result
+	record.PrecompileCalls[0].Output = hexutil.Bytes{0x56, 0x78}
+	_, err = ethapi.ReplayConfidentialRequest(chain, record, nil)
+	require.ErrorContains(t, err, "ConfidentialComputeResult mismatch")
 }
 
-func WithRedisTransportOpt(t *testing.T) frameworkOpt {
-	mr := miniredis.RunT(t)
-	return func(c *frameworkConfig) {
-		c.suaveConfig.RedisStorePubsubUri = mr.Addr()
-	}
+type framework struct {
+	*suavetest.Framework
+
+	ethSrv   *suavetest.Node
+	suethSrv *suavetest.Kettle
 }
 
-func WithBundleSigningKeyOpt(t *testing.T) (frameworkOpt, *ecdsa.PublicKey) {
+func WithBundleSigningKeyOpt(t *testing.T) (suavetest.Option, *ecdsa.PublicKey) {
 	sk, err := crypto.GenerateKey()
 	require.NoError(t, err)
-	return func(c *frameworkConfig) {
-		c.suaveConfig.EthBundleSigningKeyHex = hex.EncodeToString(crypto.FromECDSA(sk))
-	}, &sk.PublicKey
+	return suavetest.WithBundleSigningKey(sk), &sk.PublicKey
 }
 
-func WithBlockSigningKeyOpt(t *testing.T) (frameworkOpt, *bls.PublicKey) {
+func WithBlockSigningKeyOpt(t *testing.T) (suavetest.Option, *bls.PublicKey) {
 	sk, pk, err := bls.GenerateNewKeypair()
 	require.NoError(t, err)
-	return func(c *frameworkConfig) {
-		c.suaveConfig.EthBlockSigningKeyHex = hexutil.Encode(bls.SecretKeyToBytes(sk))
-	}, pk
+	return suavetest.WithBlockSigningKey(sk), pk
 }
 
-func newFramework(t *testing.T, opts ...frameworkOpt) *framework {
-	cfg := defaultFrameworkConfig
-	for _, opt := range opts {
-		opt(&cfg)
-	}
-
-	var ethSrv *clientWrapper
-
-	if cfg.kettleAddress {
-		ethNode, ethEthService := startEthService(t, testEthGenesis, nil)
-		ethEthService.APIs()
-
-		ethSrv = &clientWrapper{t, ethNode, ethEthService}
-
-		cfg.suaveConfig.SuaveEthRemoteBackendEndpoint = ethNode.HTTPEndpoint()
-	}
-
-	if cfg.redisStoreBackend {
-		mr := miniredis.RunT(t)
-		cfg.suaveConfig.RedisStoreUri = mr.Addr()
+func newFramework(t *testing.T, opts ...suavetest.Option) *framework {
+	opts = append([]suavetest.Option{
+		suavetest.WithSuaveGenesis(testSuaveGenesis),
+		suavetest.WithL1Genesis(testEthGenesis),
+	}, opts...)
+
+	f := suavetest.New(t, opts...)
+	return &framework{
+		Framework: f,
+		ethSrv:    f.L1,
+		suethSrv:  f.Kettle(),
 	}
-
-	node, ethservice := startSuethService(t, testSuaveGenesis, nil, cfg.suaveConfig)
-
-	f := &framework{
-		t:        t,
-		ethSrv:   ethSrv,
-		suethSrv: &clientWrapper{t, node, ethservice},
-	}
-
-	return f
 }
 
 func (f *framework) NewSDKClient() *sdk.Client {
-	return sdk.NewClient(f.suethSrv.RPCNode(), testKey, f.KettleAddress())
+	return f.suethSrv.NewSDKClient(testKey)
 }
 
 func (f *framework) ConfidentialStoreBackend() cstore.ConfidentialStorageBackend {
-	return f.suethSrv.service.APIBackend.SuaveEngine().Backend()
+	return f.suethSrv.Store()
 }
 
 func (f *framework) ConfidentialEngine() *cstore.ConfidentialStoreEngine {
-	return f.suethSrv.service.APIBackend.SuaveEngine()
+	return f.suethSrv.Engine()
 }
 
-func (f *framework) KettleAddress() common.Address {
-	return f.suethSrv.service.AccountManager().Accounts()[0]
+func (f *framework) HeadNumber() uint64 {
+	return f.suethSrv.HeadNumber()
 }
 
-func (f *framework) Close() {
-	if f.ethSrv != nil {
-		f.ethSrv.Close()
-	}
-	f.suethSrv.Close()
+func (f *framework) KettleAddress() common.Address {
+	return f.suethSrv.Address()
 }
 
 // Utilities
@@ -1346,124 +1312,6 @@ func init() {
 	testEthGenesis.Config = &ethConfig
 }
 
-// startSuethService creates a full node instance for testing.
-func startSuethService(t *testing.T, genesis *core.Genesis, blocks []*types.Block, suaveConfig suave.Config) (*node.Node, *eth.Ethereum) {
-	t.Helper()
-
-	n, err := node.New(&node.Config{
-		P2P: p2p.Config{
-			ListenAddr:  "0.0.0.0:0",
-			NoDiscovery: true,
-			MaxPeers:    25,
-		}})
-	if err != nil {
-		t.Fatal("can't create node:", err)
-	}
-
-	ethcfg := &ethconfig.Config{Genesis: genesis, SyncMode: downloader.FullSync, TrieTimeout: time.Minute, TrieDirtyCache: 256, TrieCleanCache: 256, Suave: suaveConfig}
-	ethservice, err := eth.New(n, ethcfg)
-	if err != nil {
-		t.Fatal("can't create eth service:", err)
-	}
-	if err := n.Start(); err != nil {
-		t.Fatal("can't start node:", err)
-	}
-	if _, err := ethservice.BlockChain().InsertChain(blocks); err != nil {
-		n.Close()
-		t.Fatal("can't import test blocks:", err)
-	}
-
-	ethservice.SetEtherbase(testAddr)
-	ethservice.SetSynced()
-
-	keydir := t.TempDir()
-	keystore := keystore.NewPlaintextKeyStore(keydir)
-	acc, err := keystore.NewAccount("")
-	require.NoError(t, err)
-	require.NoError(t, keystore.TimedUnlock(acc, "", time.Hour))
-
-	ethservice.AccountManager().AddBackend(keystore)
-	return n, ethservice
-}
-
-func startEthService(t *testing.T, genesis *core.Genesis, blocks []*types.Block) (*node.Node, *eth.Ethereum) {
-	t.Helper()
-
-	n, err := node.New(&node.Config{
-		HTTPHost: "127.0.0.1",
-	})
-	if err != nil {
-		t.Fatal("can't create node:", err)
-	}
-
-	ethcfg := &ethconfig.Config{Genesis: genesis, SyncMode: downloader.FullSync, TrieTimeout: time.Minute, TrieDirtyCache: 256, TrieCleanCache: 256}
-	ethservice, err := eth.New(n, ethcfg)
-	if err != nil {
-		t.Fatal("can't create eth service:", err)
-	}
-	if err := n.Start(); err != nil {
-		t.Fatal("can't start node:", err)
-	}
-	if _, err := ethservice.BlockChain().InsertChain(blocks); err != nil {
-		n.Close()
-		t.Fatal("can't import test blocks:", err)
-	}
-
-	ethservice.SetEtherbase(testAddr)
-	ethservice.SetSynced()
-	return n, ethservice
-}
-
-func progressChain(t *testing.T, ethservice *eth.Ethereum, parent *types.Header) *types.Block {
-	payload, err := ethservice.Miner().BuildPayload(&miner.BuildPayloadArgs{
-		Parent:       parent.Hash(),
-		Timestamp:    parent.Time + 12,
-		FeeRecipient: testAddr,
-	})
-	require.NoError(t, err)
-	envelope := payload.ResolveFull()
-	block, err := engine.ExecutableDataToBlock(*envelope.ExecutionPayload)
-	require.NoError(t, err)
-
-	n, err := ethservice.BlockChain().InsertChain(types.Blocks{block})
-	require.NoError(t, err)
-	require.Equal(t, 1, n)
-
-	return block
-}
-
-func requireNoRpcError(t *testing.T, rpcErr error) {
-	if rpcErr != nil {
-		if len(rpcErr.Error()) < len("execution reverted: 0x") {
-			require.NoError(t, rpcErr)
-		}
-		decodedError, err := hexutil.Decode(rpcErr.Error()[len("execution reverted: "):])
-		if err != nil {
-			require.NoError(t, rpcErr, err.Error())
-		}
-
-		if len(decodedError) < 4 {
-			require.NoError(t, rpcErr, decodedError)
-		}
-
-		unpacked, err := artifacts.SuaveAbi.Errors["PeekerReverted"].Inputs.Unpack(decodedError[4:])
-		if err != nil {
-			require.NoError(t, err, rpcErr.Error())
-		} else {
-			require.NoError(t, rpcErr, fmt.Sprintf("peeker 0x%x reverted: %s", unpacked[0].(common.Address), unpacked[1].([]byte)))
-		}
-	}
-}
-
-type fakeRelayHandler struct {
-	t         *testing.T
-	serveHttp func(t *testing.T, w http.ResponseWriter, r *http.Request)
-}
-
-func (h *fakeRelayHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	h.serveHttp(h.t, w, r)
-}
-
 func mustParseMethodAbi(data string, method string) abi.Method {
 	inoutAbi, err := abi.JSON(strings.NewReader(data))
 	if err != nil {
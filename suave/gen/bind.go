@@ -0,0 +1,454 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// bindArtifact is a contract artifact, either a forge build artifact or a
+// plain ABI json file.
+type bindArtifact struct {
+	Abi      abi.ABI
+	AbiJSON  []byte
+	Bytecode []byte
+}
+
+func readBindArtifact(path string) (*bindArtifact, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		// plain abi file
+		parsed, err := abi.JSON(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		return &bindArtifact{Abi: parsed, AbiJSON: data}, nil
+	}
+
+	var artifactObj struct {
+		Abi      json.RawMessage `json:"abi"`
+		Bytecode struct {
+			Object string
+		} `json:"bytecode"`
+	}
+	if err := json.Unmarshal(data, &artifactObj); err != nil {
+		return nil, err
+	}
+	parsed, err := abi.JSON(bytes.NewReader(artifactObj.Abi))
+	if err != nil {
+		return nil, err
+	}
+
+	artifact := &bindArtifact{Abi: parsed, AbiJSON: artifactObj.Abi}
+	if artifactObj.Bytecode.Object != "" && artifactObj.Bytecode.Object != "0x" {
+		if artifact.Bytecode, err = hexutil.Decode(artifactObj.Bytecode.Object); err != nil {
+			return nil, err
+		}
+	}
+	return artifact, nil
+}
+
+type bindArg struct {
+	Name  string
+	Field string
+	Typ   string
+}
+
+type bindMethod struct {
+	Name     string
+	GoName   string
+	Inputs   []bindArg
+	Outputs  []bindArg
+	Constant bool
+}
+
+type bindEvent struct {
+	Name   string
+	GoName string
+	Fields []bindArg
+}
+
+type bindStruct struct {
+	Name   string
+	Fields []bindArg
+}
+
+type bindDesc struct {
+	Package     string
+	Type        string
+	ABI         string
+	Bytecode    string
+	Constructor []bindArg
+	Methods     []*bindMethod
+	Events      []*bindEvent
+	Structs     []*bindStruct
+}
+
+// binder converts the types of a contract abi into Go types. Structs defined
+// in the Suave library are bound to the types in core/types, any other struct
+// is generated alongside the bindings.
+type binder struct {
+	suaveStructs map[string]string
+	structs      map[string]*bindStruct
+}
+
+func newBinder(ff desc) *binder {
+	b := &binder{
+		suaveStructs: map[string]string{},
+		structs:      map[string]*bindStruct{},
+	}
+	for _, s := range ff.Structs {
+		b.suaveStructs["Suave"+s.Name] = "types." + s.Name
+	}
+	return b
+}
+
+func (b *binder) bindType(typ abi.Type) string {
+	switch typ.T {
+	case abi.IntTy, abi.UintTy:
+		prefix := "int"
+		if typ.T == abi.UintTy {
+			prefix = "uint"
+		}
+		switch typ.Size {
+		case 8, 16, 32, 64:
+			return fmt.Sprintf("%s%d", prefix, typ.Size)
+		}
+		return "*big.Int"
+	case abi.BoolTy:
+		return "bool"
+	case abi.StringTy:
+		return "string"
+	case abi.AddressTy:
+		return "common.Address"
+	case abi.BytesTy:
+		return "[]byte"
+	case abi.HashTy:
+		return "common.Hash"
+	case abi.FixedBytesTy:
+		return fmt.Sprintf("[%d]byte", typ.Size)
+	case abi.SliceTy:
+		return "[]" + b.bindType(*typ.Elem)
+	case abi.ArrayTy:
+		return fmt.Sprintf("[%d]%s", typ.Size, b.bindType(*typ.Elem))
+	case abi.TupleTy:
+		return b.bindTuple(typ)
+	case abi.FunctionTy:
+		return "[24]byte"
+	}
+	panic(fmt.Sprintf("unsupported abi type: %s", typ.String()))
+}
+
+func (b *binder) bindTuple(typ abi.Type) string {
+	if name, ok := b.suaveStructs[typ.TupleRawName]; ok {
+		return name
+	}
+
+	name := typ.TupleRawName
+	if name == "" {
+		// anonymous tuples are named in order of appearance
+		name = fmt.Sprintf("Struct%d", len(b.structs))
+	}
+	name = abi.ToCamelCase(name)
+
+	if _, ok := b.structs[name]; ok {
+		return name
+	}
+
+	s := &bindStruct{Name: name}
+	b.structs[name] = s
+	for i, elem := range typ.TupleElems {
+		s.Fields = append(s.Fields, bindArg{
+			Field: abi.ToCamelCase(typ.TupleRawNames[i]),
+			Typ:   b.bindType(*elem),
+		})
+	}
+	return name
+}
+
+func (b *binder) bindArgs(args abi.Arguments, isEvent bool) []bindArg {
+	res := make([]bindArg, 0, len(args))
+	for i, arg := range args {
+		name := arg.Name
+		if name == "" {
+			name = fmt.Sprintf("arg%d", i)
+		}
+
+		typ := b.bindType(arg.Type)
+		if isEvent && arg.Indexed {
+			// indexed dynamic values are only available as their hash
+			switch arg.Type.T {
+			case abi.StringTy, abi.BytesTy, abi.SliceTy, abi.ArrayTy, abi.TupleTy:
+				typ = "common.Hash"
+			}
+		}
+
+		res = append(res, bindArg{
+			Name:  bindVarName(name),
+			Field: abi.ToCamelCase(name),
+			Typ:   typ,
+		})
+	}
+	return res
+}
+
+// bindVarName returns a valid Go identifier for a parameter name.
+func bindVarName(name string) string {
+	name = strings.ToLower(name[:1]) + name[1:]
+	if token.IsKeyword(name) {
+		return name + "_"
+	}
+	return name
+}
+
+// generateBindings renders the Go client bindings of a confidential contract.
+// Every state changing method is sent to the kettle as a confidential compute
+// request, read-only methods are executed with eth_call.
+func generateBindings(ff desc, artifact *bindArtifact, pkg string, typeName string) (string, error) {
+	b := newBinder(ff)
+
+	var abiJSON bytes.Buffer
+	if err := json.Compact(&abiJSON, artifact.AbiJSON); err != nil {
+		return "", err
+	}
+
+	input := &bindDesc{
+		Package: pkg,
+		Type:    typeName,
+		ABI:     abiJSON.String(),
+	}
+	if len(artifact.Bytecode) != 0 {
+		input.Bytecode = hexutil.Encode(artifact.Bytecode)
+		input.Constructor = b.bindArgs(artifact.Abi.Constructor.Inputs, false)
+	}
+
+	for name, method := range artifact.Abi.Methods {
+		input.Methods = append(input.Methods, &bindMethod{
+			Name:     name,
+			GoName:   abi.ToCamelCase(name),
+			Inputs:   b.bindArgs(method.Inputs, false),
+			Outputs:  b.bindArgs(method.Outputs, false),
+			Constant: method.IsConstant(),
+		})
+	}
+	sort.Slice(input.Methods, func(i, j int) bool {
+		return input.Methods[i].Name < input.Methods[j].Name
+	})
+
+	for name, event := range artifact.Abi.Events {
+		input.Events = append(input.Events, &bindEvent{
+			Name:   name,
+			GoName: abi.ToCamelCase(name),
+			Fields: b.bindArgs(event.Inputs, true),
+		})
+	}
+	sort.Slice(input.Events, func(i, j int) bool {
+		return input.Events[i].Name < input.Events[j].Name
+	})
+
+	for _, s := range b.structs {
+		input.Structs = append(input.Structs, s)
+	}
+	sort.Slice(input.Structs, func(i, j int) bool {
+		return input.Structs[i].Name < input.Structs[j].Name
+	})
+
+	t, err := template.New("bindings").Parse(bindTemplate)
+	if err != nil {
+		return "", err
+	}
+
+	var outputRaw bytes.Buffer
+	if err := t.Execute(&outputRaw, input); err != nil {
+		return "", err
+	}
+	return formatGo(outputRaw.String())
+}
+
+var bindTemplate = `// Code generated by suave/gen. DO NOT EDIT.
+package {{.Package}}
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/suave/sdk"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = fmt.Errorf
+	_ = big.NewInt
+	_ = common.Big1
+	_ = types.BloomLookup
+)
+
+// {{.Type}}ABI is the input ABI used to generate the binding from.
+const {{.Type}}ABI = {{printf "%q" .ABI}}
+{{if .Bytecode}}
+// {{.Type}}Bin is the compiled bytecode used for deploying new contracts.
+const {{.Type}}Bin = "{{.Bytecode}}"
+{{end}}
+{{range .Structs}}
+type {{.Name}} struct {
+	{{range .Fields}}{{.Field}} {{.Typ}}
+	{{end}}
+}
+{{end}}
+
+// {{.Type}} is a client binding of the contract that sends confidential
+// compute requests to a kettle.
+type {{.Type}} struct {
+	contract *sdk.Contract
+}
+
+func New{{.Type}}(addr common.Address, client *sdk.Client) (*{{.Type}}, error) {
+	parsed, err := abi.JSON(strings.NewReader({{.Type}}ABI))
+	if err != nil {
+		return nil, err
+	}
+	return &{{.Type}}{contract: sdk.GetContract(addr, &parsed, client)}, nil
+}
+{{if .Bytecode}}
+// Deploy{{.Type}} sends a transaction that deploys a new instance of the contract.
+func Deploy{{.Type}}(client *sdk.Client{{range .Constructor}}, {{.Name}} {{.Typ}}{{end}}) (*sdk.TransactionResult, error) {
+	parsed, err := abi.JSON(strings.NewReader({{.Type}}ABI))
+	if err != nil {
+		return nil, err
+	}
+	input, err := parsed.Pack(""{{range .Constructor}}, {{.Name}}{{end}})
+	if err != nil {
+		return nil, err
+	}
+	return sdk.DeployContract(append(common.FromHex({{.Type}}Bin), input...), client)
+}
+{{end}}
+func (c *{{.Type}}) Address() common.Address {
+	return c.contract.Address()
+}
+
+func (c *{{.Type}}) Contract() *sdk.Contract {
+	return c.contract
+}
+{{$type := .Type}}
+{{range .Methods}}{{if .Constant}}
+{{if gt (len .Outputs) 1}}
+// {{$type}}{{.GoName}}Output is the output of the {{.Name}} method.
+type {{$type}}{{.GoName}}Output struct {
+	{{range .Outputs}}{{.Field}} {{.Typ}}
+	{{end}}
+}
+{{end}}
+// {{.GoName}} calls the {{.Name}} method with eth_call.
+func (c *{{$type}}) {{.GoName}}({{range $i, $arg := .Inputs}}{{if $i}}, {{end}}{{.Name}} {{.Typ}}{{end}}) ({{if eq (len .Outputs) 1}}{{(index .Outputs 0).Typ}}, {{else if gt (len .Outputs) 1}}*{{$type}}{{.GoName}}Output, {{end}}error) {
+	out, err := c.contract.Call("{{.Name}}", []interface{}{ {{range $i, $arg := .Inputs}}{{if $i}}, {{end}}{{.Name}}{{end}} })
+	{{if eq (len .Outputs) 0}}_ = out
+	return err
+	{{- else if eq (len .Outputs) 1}}if err != nil {
+		return *new({{(index .Outputs 0).Typ}}), err
+	}
+	return *abi.ConvertType(out[0], new({{(index .Outputs 0).Typ}})).(*{{(index .Outputs 0).Typ}}), nil
+	{{- else}}if err != nil {
+		return nil, err
+	}
+	res := new({{$type}}{{.GoName}}Output)
+	if err := c.contract.ABI().Methods["{{.Name}}"].Outputs.Copy(res, out); err != nil {
+		return nil, err
+	}
+	return res, nil
+	{{- end}}
+}
+{{else}}
+// {{$type}}{{.GoName}}Call holds the arguments of a call to the {{.Name}} method.
+type {{$type}}{{.GoName}}Call struct {
+	{{range .Inputs}}{{.Field}} {{.Typ}}
+	{{end}}
+}
+
+// {{.GoName}} sends a confidential compute request that calls the {{.Name}} method.
+func (c *{{$type}}) {{.GoName}}({{range .Inputs}}{{.Name}} {{.Typ}}, {{end}}confidentialInputs []byte) (*sdk.TransactionResult, error) {
+	return c.contract.SendTransaction("{{.Name}}", []interface{}{ {{range $i, $arg := .Inputs}}{{if $i}}, {{end}}{{.Name}}{{end}} }, confidentialInputs)
+}
+{{end}}{{end}}
+// DecodeComputeResult decodes the result of a confidential computation into
+// the arguments of the callback it executes on-chain.
+func (c *{{.Type}}) DecodeComputeResult(result []byte) (interface{}, error) {
+	method, err := c.contract.ABI().MethodById(result)
+	if err != nil {
+		return nil, err
+	}
+
+	var out interface{}
+	switch method.Name { {{range .Methods}}{{if not .Constant}}
+	case "{{.Name}}":
+		out = new({{$type}}{{.GoName}}Call){{end}}{{end}}
+	default:
+		return nil, fmt.Errorf("compute result calls unsupported method %s", method.Name)
+	}
+
+	if _, err := c.contract.UnpackComputeResult(out, result); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ComputeResult fetches the SuaveTransaction of a confidential compute request
+// and decodes its result.
+func (c *{{.Type}}) ComputeResult(res *sdk.TransactionResult) (interface{}, error) {
+	result, err := res.ConfidentialComputeResult()
+	if err != nil {
+		return nil, err
+	}
+	return c.DecodeComputeResult(result)
+}
+{{range .Events}}
+// {{$type}}{{.GoName}} represents a {{.Name}} event raised by the contract.
+type {{$type}}{{.GoName}} struct {
+	{{range .Fields}}{{.Field}} {{.Typ}}
+	{{end}}Raw types.Log
+}
+
+// Parse{{.GoName}} decodes a {{.Name}} event from a log.
+func (c *{{$type}}) Parse{{.GoName}}(log types.Log) (*{{$type}}{{.GoName}}, error) {
+	event := new({{$type}}{{.GoName}})
+	if err := c.contract.UnpackLog(event, "{{.Name}}", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// Parse{{.GoName}}Events decodes all the {{.Name}} events emitted by the contract in a receipt.
+func (c *{{$type}}) Parse{{.GoName}}Events(receipt *types.Receipt) ([]*{{$type}}{{.GoName}}, error) {
+	var events []*{{$type}}{{.GoName}}
+	for _, log := range receipt.Logs {
+		if log.Address != c.contract.Address() || len(log.Topics) == 0 || log.Topics[0] != c.contract.ABI().Events["{{.Name}}"].ID {
+			continue
+		}
+		event, err := c.Parse{{.GoName}}(*log)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+{{end}}
+`
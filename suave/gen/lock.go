@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// specLockPath records the ABI of every precompile that has been released.
+// Contracts are deployed against those ABIs, so a precompile can be
+// deprecated or get a new version at a new address but its ABI must not change.
+var specLockPath = "./suave/gen/suave_spec.lock.json"
+
+type lockedPrecompile struct {
+	Address   string `json:"address"`
+	Name      string `json:"name"`
+	Signature string `json:"signature"`
+}
+
+// versionedName returns the name a version of a precompile is generated with.
+func versionedName(name string, version uint64) string {
+	if version <= 1 {
+		return name
+	}
+	return fmt.Sprintf("%sV%d", name, version)
+}
+
+// resolveVersions renames the versioned precompiles to the names they are
+// generated with and checks that names and addresses are unique.
+func resolveVersions(ff *desc) error {
+	names := map[string]string{}
+	addrs := map[common.Address]string{}
+
+	for i, f := range ff.Functions {
+		if f.Version > 1 {
+			found := false
+			for _, other := range ff.Functions {
+				if other.Name == f.Name && other.Version <= 1 {
+					found = true
+				}
+			}
+			if !found {
+				return fmt.Errorf("version %d of precompile %s has no first version", f.Version, f.Name)
+			}
+		}
+
+		name := versionedName(f.Name, f.Version)
+		if prev, ok := names[name]; ok {
+			return fmt.Errorf("precompile %s at %s is already defined at %s", name, f.Address, prev)
+		}
+		names[name] = f.Address
+
+		addr := common.HexToAddress(f.Address)
+		if prev, ok := addrs[addr]; ok {
+			return fmt.Errorf("address %s of precompile %s is already used by %s", f.Address, name, prev)
+		}
+		addrs[addr] = name
+
+		ff.Functions[i].Name = name
+	}
+	return nil
+}
+
+// buildSpecLock returns the ABI signature of each precompile in the spec,
+// sorted by address.
+func buildSpecLock(ff desc) ([]*lockedPrecompile, error) {
+	raw, err := encodeABI(ff)
+	if err != nil {
+		return nil, err
+	}
+	suaveAbi, err := abi.JSON(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+
+	lock := []*lockedPrecompile{}
+	for _, f := range ff.Functions {
+		method := suaveAbi.Methods[f.Name]
+
+		outputs := make([]string, len(method.Outputs))
+		for i, output := range method.Outputs {
+			outputs[i] = output.Type.String()
+		}
+
+		lock = append(lock, &lockedPrecompile{
+			Address:   common.HexToAddress(f.Address).Hex(),
+			Name:      f.Name,
+			Signature: fmt.Sprintf("%s returns (%s)", strings.TrimPrefix(method.Sig, method.Name), strings.Join(outputs, ",")),
+		})
+	}
+
+	sort.Slice(lock, func(i, j int) bool {
+		return lock[i].Address < lock[j].Address
+	})
+	return lock, nil
+}
+
+// checkSpecLock fails if a precompile recorded in the lock file was removed
+// or its ABI changed. Renaming a precompile keeps deployed contracts working
+// and is allowed.
+func checkSpecLock(path string, lock []*lockedPrecompile) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	var locked []*lockedPrecompile
+	if err := json.Unmarshal(data, &locked); err != nil {
+		return fmt.Errorf("invalid spec lock %s: %w", path, err)
+	}
+
+	current := map[string]*lockedPrecompile{}
+	for _, p := range lock {
+		current[p.Address] = p
+	}
+
+	var errs []string
+	for _, p := range locked {
+		c, ok := current[p.Address]
+		if !ok {
+			errs = append(errs, fmt.Sprintf("precompile %s at %s was removed", p.Name, p.Address))
+		} else if c.Signature != p.Signature {
+			errs = append(errs, fmt.Sprintf("ABI of precompile %s at %s changed from %s to %s", c.Name, p.Address, p.Signature, c.Signature))
+		}
+	}
+	if len(errs) != 0 {
+		return fmt.Errorf("incompatible precompile spec, deployed contracts depend on the locked ABIs (add a new version instead): %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func writeSpecLock(path string, lock []*lockedPrecompile) error {
+	data, err := json.MarshalIndent(lock, "", "  ")
+	if err != nil {
+		return err
+	}
+	return outputFile(path, string(data)+"\n")
+}
@@ -21,8 +21,14 @@ import (
 )
 
 var (
-	formatFlag bool
-	writeFlag  bool
+	formatFlag        bool
+	writeFlag         bool
+	allowBreakingFlag bool
+
+	bindFlag     string
+	bindTypeFlag string
+	bindPkgFlag  string
+	bindOutFlag  string
 )
 
 func applyTemplate(templateText string, input desc, out string) error {
@@ -77,6 +83,8 @@ func applyTemplate(templateText string, input desc, out string) error {
 	str = strings.Replace(str, "&amp;", "&", -1)
 	str = strings.Replace(str, ", )", ")", -1)
 	str = strings.Replace(str, "&lt;", "<", -1)
+	str = strings.Replace(str, "&gt;", ">", -1)
+	str = strings.Replace(str, "&#39;", "'", -1)
 
 	if formatFlag || writeFlag {
 		// The output is always formatted if it is going to be written
@@ -101,6 +109,11 @@ func applyTemplate(templateText string, input desc, out string) error {
 func main() {
 	flag.BoolVar(&formatFlag, "format", false, "format the output")
 	flag.BoolVar(&writeFlag, "write", false, "write the output to the file")
+	flag.BoolVar(&allowBreakingFlag, "allow-breaking", false, "allow changing or removing the ABI of a precompile recorded in the spec lock")
+	flag.StringVar(&bindFlag, "bind", "", "generate Go client bindings for the contract artifact or abi file")
+	flag.StringVar(&bindTypeFlag, "type", "", "name of the Go type of the bindings")
+	flag.StringVar(&bindPkgFlag, "pkg", "bindings", "package name of the bindings")
+	flag.StringVar(&bindOutFlag, "out", "", "output file of the bindings")
 	flag.Parse()
 
 	data, err := os.ReadFile("./suave/gen/suave_spec.yaml")
@@ -112,6 +125,17 @@ func main() {
 		panic(err)
 	}
 
+	if err := resolveVersions(&ff); err != nil {
+		panic(err)
+	}
+
+	if bindFlag != "" {
+		if err := bindContract(ff); err != nil {
+			panic(err)
+		}
+		return
+	}
+
 	// sort the structs by name
 	sort.Slice(ff.Structs, func(i, j int) bool {
 		return ff.Structs[i].Name < ff.Structs[j].Name
@@ -122,6 +146,16 @@ func main() {
 		return ff.Functions[i].Name < ff.Functions[j].Name
 	})
 
+	lock, err := buildSpecLock(ff)
+	if err != nil {
+		panic(err)
+	}
+	if !allowBreakingFlag {
+		if err := checkSpecLock(specLockPath, lock); err != nil {
+			panic(err)
+		}
+	}
+
 	if err := applyTemplate(structsTemplate, ff, "./core/types/suave_structs.go"); err != nil {
 		panic(err)
 	}
@@ -145,6 +179,34 @@ func main() {
 	if err := generateABI("./suave/artifacts/SuaveLib.json", ff); err != nil {
 		panic(err)
 	}
+
+	if err := writeSpecLock(specLockPath, lock); err != nil {
+		panic(err)
+	}
+}
+
+func bindContract(ff desc) error {
+	artifact, err := readBindArtifact(bindFlag)
+	if err != nil {
+		return err
+	}
+
+	typeName := bindTypeFlag
+	if typeName == "" {
+		// default to the name of the artifact (i.e. MevShareBidContract.json)
+		typeName = abi.ToCamelCase(strings.TrimSuffix(filepath.Base(bindFlag), filepath.Ext(bindFlag)))
+	}
+
+	str, err := generateBindings(ff, artifact, bindPkgFlag, typeName)
+	if err != nil {
+		return err
+	}
+
+	out := bindOutFlag
+	if out == "" {
+		out = strings.ToLower(typeName) + ".go"
+	}
+	return outputFile(out, str)
 }
 
 func encodeTypeToGolang(str string, insideTypes bool, slicePointers bool) string {
@@ -325,6 +387,9 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 )
 
+// SpecHash identifies the version of the spec the precompiles were generated from
+const SpecHash = "{{hash}}"
+
 // List of suave precompile addresses
 var ( {{range .Functions}}{{.Name}}Addr = common.HexToAddress("{{.Address}}")
 {{end}}
@@ -341,6 +406,11 @@ func PrecompileAddressToName(addr common.Address) string {
 	}
 	return ""
 }
+
+// DeprecatedPrecompiles maps the address of each deprecated precompile to its deprecation notice
+var DeprecatedPrecompiles = map[common.Address]string{
+{{range .Functions}}{{if .Deprecated}}{{.Name}}Addr: {{printf "%q" .Deprecated}},
+{{end}}{{end}}}
 `
 
 var suaveLibTemplate = `// SPDX-License-Identifier: UNLICENSED
@@ -384,7 +454,8 @@ function isConfidential() internal view returns (bool b) {
 }
 
 {{range .Functions}}
-function {{.Name}}({{range .Input}}{{styp .Typ}} {{.Name}}, {{end}}) internal view returns ({{range .Output.Fields}}{{styp .Typ}}, {{end}}) {
+{{if .Deprecated}}/// @custom:deprecated {{.Deprecated}}
+{{end}}function {{.Name}}({{range .Input}}{{styp .Typ}} {{.Name}}, {{end}}) internal view returns ({{range .Output.Fields}}{{styp .Typ}}, {{end}}) {
 	{{if .IsConfidential}}require(isConfidential());{{end}}
 	(bool success, bytes memory data) = {{encodeAddrName .Name}}.staticcall(abi.encode({{range .Input}}{{.Name}}, {{end}}));
 	if (!success) {
@@ -441,7 +512,8 @@ library SuaveForge {
     }
 
 {{range .Functions}}
-function {{.Name}}({{range .Input}}{{styp2 .Typ true}} {{.Name}}, {{end}}) internal view returns ({{range .Output.Fields}}{{styp2 .Typ true}}, {{end}}) {
+{{if .Deprecated}}/// @custom:deprecated {{.Deprecated}}
+{{end}}function {{.Name}}({{range .Input}}{{styp2 .Typ true}} {{.Name}}, {{end}}) internal view returns ({{range .Output.Fields}}{{styp2 .Typ true}}, {{end}}) {
 	bytes memory data = forgeIt("{{.Address}}", abi.encode({{range .Input}}{{.Name}}, {{end}}));
 	{{ if eq (len .Output.Fields) 0 }}
 	{{else if .Output.Packed}}
@@ -461,6 +533,14 @@ type functionDef struct {
 	Input          []field
 	Output         output
 	IsConfidential bool `yaml:"isConfidential"`
+
+	// Version distinguishes the definitions of a precompile that changed
+	// its ABI. Each version lives at its own address and versions after
+	// the first one are generated with a "V<version>" suffix.
+	Version uint64 `yaml:"version,omitempty"`
+
+	// Deprecated is the notice logged when the precompile is called.
+	Deprecated string `yaml:"deprecated,omitempty"`
 }
 
 type output struct {
@@ -558,6 +638,18 @@ type arguments struct {
 }
 
 func generateABI(out string, dd desc) error {
+	raw, err := encodeABI(dd)
+	if err != nil {
+		return err
+	}
+
+	if err := outputFile(out, string(raw)); err != nil {
+		return err
+	}
+	return nil
+}
+
+func encodeABI(dd desc) ([]byte, error) {
 	abiEncode := []*abiField{}
 
 	var encodeType func(name, typ string) arguments
@@ -628,19 +720,15 @@ func generateABI(out string, dd desc) error {
 	// marshal the object
 	raw, err := json.Marshal(abiEncode)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// try to decode the output with abi.ABI to validate
 	// that the result is correct
 	if _, err := abi.JSON(bytes.NewReader(raw)); err != nil {
-		return err
-	}
-
-	if err := outputFile(out, string(raw)); err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return raw, nil
 }
 
 func outputFile(out string, str string) error {
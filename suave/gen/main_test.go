@@ -1,9 +1,18 @@
 package main
 
 import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/ethereum/go-ethereum/accounts/abi"
+
 	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
 )
 
 func TestToAddressName(t *testing.T) {
@@ -43,3 +52,151 @@ func TestEncodeTypeToGolang(t *testing.T) {
 		require.Equal(t, c.expected, actual)
 	}
 }
+
+const testBindABI = `[
+	{"type":"constructor","inputs":[{"name":"owner","type":"address","internalType":"address"}]},
+	{"type":"function","name":"newBid","stateMutability":"payable","inputs":[{"name":"decryptionCondition","type":"uint64","internalType":"uint64"},{"name":"bidAllowedPeekers","type":"address[]","internalType":"address[]"}],"outputs":[{"name":"","type":"bytes","internalType":"bytes"}]},
+	{"type":"function","name":"emitBidAndHint","stateMutability":"nonpayable","inputs":[{"name":"bid","type":"tuple","internalType":"struct Suave.Bid","components":[{"name":"id","type":"bytes16","internalType":"Suave.BidId"},{"name":"salt","type":"bytes16","internalType":"Suave.BidId"},{"name":"decryptionCondition","type":"uint64","internalType":"uint64"},{"name":"allowedPeekers","type":"address[]","internalType":"address[]"},{"name":"allowedStores","type":"address[]","internalType":"address[]"},{"name":"version","type":"string","internalType":"string"}]},{"name":"hint","type":"bytes","internalType":"bytes"}],"outputs":[]},
+	{"type":"function","name":"pairs","stateMutability":"view","inputs":[{"name":"type","type":"uint256","internalType":"uint256"}],"outputs":[{"name":"pair","type":"tuple[]","internalType":"struct EgpBidPair[]","components":[{"name":"egp","type":"uint64","internalType":"uint64"},{"name":"bidId","type":"bytes16","internalType":"Suave.BidId"}]},{"name":"total","type":"uint256","internalType":"uint256"}]},
+	{"type":"event","name":"HintEvent","anonymous":false,"inputs":[{"name":"bidId","type":"bytes16","indexed":true,"internalType":"Suave.BidId"},{"name":"hint","type":"bytes","indexed":false,"internalType":"bytes"}]}
+]`
+
+func TestGenerateBindings(t *testing.T) {
+	parsed, err := abi.JSON(strings.NewReader(testBindABI))
+	require.NoError(t, err)
+
+	ff := desc{Structs: []structsDef{{Name: "Bid"}}}
+	artifact := &bindArtifact{Abi: parsed, AbiJSON: []byte(testBindABI), Bytecode: []byte{0x60, 0x80}}
+
+	code, err := generateBindings(ff, artifact, "bindings", "BidContract")
+	require.NoError(t, err)
+
+	// Suave structs are bound to core/types, other structs are generated
+	require.Contains(t, code, "Bid  types.Bid")
+	require.Contains(t, code, "type EgpBidPair struct")
+	require.Contains(t, code, "func DeployBidContract(client *sdk.Client, owner common.Address)")
+	require.Contains(t, code, "func (c *BidContract) NewBid(decryptionCondition uint64, bidAllowedPeekers []common.Address, confidentialInputs []byte) (*sdk.TransactionResult, error)")
+	require.Contains(t, code, "func (c *BidContract) Pairs(type_ *big.Int) (*BidContractPairsOutput, error)")
+	require.Contains(t, code, "func (c *BidContract) ParseHintEvent(log types.Log) (*BidContractHintEvent, error)")
+	require.Contains(t, code, "out = new(BidContractEmitBidAndHintCall)")
+
+	if testing.Short() {
+		return
+	}
+
+	// the bindings must compile against the sdk, built in a throwaway module
+	// that replaces go-ethereum with this tree
+	root, err := filepath.Abs(filepath.Join("..", ".."))
+	require.NoError(t, err)
+	dir := t.TempDir()
+
+	goMod, err := os.ReadFile(filepath.Join(root, "go.mod"))
+	require.NoError(t, err)
+	goMod = []byte(strings.Replace(string(goMod), "module github.com/ethereum/go-ethereum", "module bindingstest", 1))
+	goMod = append(goMod, fmt.Sprintf("\nrequire github.com/ethereum/go-ethereum v0.0.0\n\nreplace github.com/ethereum/go-ethereum => %s\n", root)...)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), goMod, 0644))
+
+	goSum, err := os.ReadFile(filepath.Join(root, "go.sum"))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.sum"), goSum, 0644))
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "bindings"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bindings", "bindings.go"), []byte(code), 0644))
+
+	cmd := exec.Command("go", "vet", "-mod=mod", "./bindings")
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+}
+
+func testVersionedSpec() desc {
+	return desc{
+		Functions: []functionDef{
+			{
+				Name:    "newBid",
+				Address: "0x0000000000000000000000000000000042030000",
+				Input:   []field{{Name: "decryptionCondition", Typ: "uint64"}},
+				Output:  output{Fields: []field{{Name: "output1", Typ: "bytes"}}},
+			},
+			{
+				Name:       "newBid",
+				Address:    "0x0000000000000000000000000000000042030010",
+				Version:    2,
+				Deprecated: "use newBidV3",
+				Input:      []field{{Name: "decryptionCondition", Typ: "uint64"}, {Name: "bidType", Typ: "string"}},
+				Output:     output{Fields: []field{{Name: "output1", Typ: "bytes"}}},
+			},
+		},
+	}
+}
+
+func TestResolveVersions(t *testing.T) {
+	ff := testVersionedSpec()
+	require.NoError(t, resolveVersions(&ff))
+	require.Equal(t, "newBid", ff.Functions[0].Name)
+	require.Equal(t, "newBidV2", ff.Functions[1].Name)
+
+	// the same version twice
+	ff = testVersionedSpec()
+	ff.Functions[1].Version = 1
+	require.ErrorContains(t, resolveVersions(&ff), "precompile newBid at 0x0000000000000000000000000000000042030010 is already defined")
+
+	// a new version at the address of the previous one
+	ff = testVersionedSpec()
+	ff.Functions[1].Address = ff.Functions[0].Address
+	require.ErrorContains(t, resolveVersions(&ff), "is already used by newBid")
+
+	// a version without its first version
+	ff = testVersionedSpec()
+	ff.Functions = ff.Functions[1:]
+	require.ErrorContains(t, resolveVersions(&ff), "has no first version")
+}
+
+func TestCheckSpecLock(t *testing.T) {
+	ff := testVersionedSpec()
+	require.NoError(t, resolveVersions(&ff))
+
+	lock, err := buildSpecLock(ff)
+	require.NoError(t, err)
+	require.Equal(t, "(uint64,string) returns (bytes)", lock[1].Signature)
+
+	path := filepath.Join(t.TempDir(), "lock.json")
+
+	// a missing lock file is not checked
+	require.NoError(t, checkSpecLock(path, lock))
+
+	data, err := json.Marshal(lock)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0644))
+	require.NoError(t, checkSpecLock(path, lock))
+
+	// renaming keeps the ABI and is allowed
+	ff.Functions[1].Name = "newBidWithType"
+	renamed, err := buildSpecLock(ff)
+	require.NoError(t, err)
+	require.NoError(t, checkSpecLock(path, renamed))
+
+	// changing the ABI of an existing address is not
+	ff = testVersionedSpec()
+	ff.Functions[0].Input = append(ff.Functions[0].Input, field{Name: "allowedPeekers", Typ: "address[]"})
+	require.NoError(t, resolveVersions(&ff))
+	changed, err := buildSpecLock(ff)
+	require.NoError(t, err)
+	require.ErrorContains(t, checkSpecLock(path, changed), "ABI of precompile newBid at 0x0000000000000000000000000000000042030000 changed from (uint64) returns (bytes) to (uint64,address[]) returns (bytes)")
+
+	// nor removing it
+	require.ErrorContains(t, checkSpecLock(path, lock[:1]), "precompile newBidV2 at 0x0000000000000000000000000000000042030010 was removed")
+}
+
+func TestSpecLockUpToDate(t *testing.T) {
+	data, err := os.ReadFile("suave_spec.yaml")
+	require.NoError(t, err)
+
+	var ff desc
+	require.NoError(t, yaml.Unmarshal(data, &ff))
+	require.NoError(t, resolveVersions(&ff))
+
+	lock, err := buildSpecLock(ff)
+	require.NoError(t, err)
+	require.NoError(t, checkSpecLock("suave_spec.lock.json", lock))
+}
@@ -0,0 +1,92 @@
+package sdk
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	suave "github.com/ethereum/go-ethereum/suave/core"
+)
+
+// KettleRequirements lists the capabilities a kettle must advertise to be
+// selected. Zero values are not checked, except for the trusted kettles.
+type KettleRequirements struct {
+	// Kettles the capability document must be signed by one of, required
+	KettleAddresses []common.Address
+
+	ChainID            *big.Int
+	Precompiles        []string
+	PrecompileSpecHash string
+	StoreBackend       string
+	EncryptedInputs    bool
+}
+
+// FetchKettleInfo returns the capability document of the node behind the
+// client, after checking it is signed by all of its kettle addresses, one of
+// which must be trusted.
+func FetchKettleInfo(ctx context.Context, rpc *rpc.Client, trusted []common.Address) (*suave.KettleInfo, error) {
+	var signed suave.SignedKettleInfo
+	if err := rpc.CallContext(ctx, &signed, "suave_kettleInfo"); err != nil {
+		return nil, fmt.Errorf("could not fetch kettle info: %w", err)
+	}
+	return signed.Verify(trusted)
+}
+
+// SelectKettle returns a client for the first node whose kettle is trusted and
+// satisfies the requirements. The encryption key of the kettle is taken from
+// its signed capability document.
+func SelectKettle(ctx context.Context, nodes []*rpc.Client, key *ecdsa.PrivateKey, req KettleRequirements) (*Client, error) {
+	if len(req.KettleAddresses) == 0 {
+		return nil, errors.New("no trusted kettle addresses")
+	}
+
+	var errs []string
+	for i, node := range nodes {
+		info, err := FetchKettleInfo(ctx, node, req.KettleAddresses)
+		if err == nil {
+			err = req.check(info)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("node %d: %v", i, err))
+			continue
+		}
+
+		client := NewClient(node, key, info.TrustedKettle(req.KettleAddresses))
+		if len(info.EncryptionKey) != 0 {
+			client.encryptionKey, err = crypto.UnmarshalPubkey(info.EncryptionKey)
+			if err != nil {
+				return nil, fmt.Errorf("node %d: invalid encryption key: %w", i, err)
+			}
+		}
+		return client, nil
+	}
+
+	return nil, fmt.Errorf("no kettle satisfies the requirements: %s", strings.Join(errs, "; "))
+}
+
+func (r *KettleRequirements) check(info *suave.KettleInfo) error {
+	if r.ChainID != nil && (info.ChainID == nil || info.ChainID.ToInt().Cmp(r.ChainID) != 0) {
+		return fmt.Errorf("chain id %v, expected %v", info.ChainID, r.ChainID)
+	}
+	if r.PrecompileSpecHash != "" && info.PrecompileSpecHash != r.PrecompileSpecHash {
+		return fmt.Errorf("precompile spec %s, expected %s", info.PrecompileSpecHash, r.PrecompileSpecHash)
+	}
+	for _, name := range r.Precompiles {
+		if _, ok := info.Precompiles[name]; !ok {
+			return fmt.Errorf("precompile %s not supported", name)
+		}
+	}
+	if r.StoreBackend != "" && info.StoreBackend != r.StoreBackend {
+		return fmt.Errorf("store backend %s, expected %s", info.StoreBackend, r.StoreBackend)
+	}
+	if r.EncryptedInputs && len(info.EncryptionKey) == 0 {
+		return errors.New("encrypted confidential inputs not supported")
+	}
+	return nil
+}
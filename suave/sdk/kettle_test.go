@@ -0,0 +1,121 @@
+package sdk
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	suave "github.com/ethereum/go-ethereum/suave/core"
+	"github.com/stretchr/testify/require"
+)
+
+// mockSuaveAPI serves a kettle info document signed with signingKey.
+type mockSuaveAPI struct {
+	info       suave.KettleInfo
+	signingKey *ecdsa.PrivateKey
+}
+
+func (m *mockSuaveAPI) KettleInfo() (*suave.SignedKettleInfo, error) {
+	infoBytes, err := json.Marshal(m.info)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := crypto.Sign(crypto.Keccak256(infoBytes), m.signingKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &suave.SignedKettleInfo{
+		Info: infoBytes,
+		Signatures: []*suave.KettleInfoSignature{{
+			KettleAddress: m.info.KettleAddresses[0],
+			Signature:     signature,
+		}},
+	}, nil
+}
+
+func newTestKettle(t *testing.T, storeBackend string) (*rpc.Client, *mockSuaveAPI) {
+	kettleKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	encryptionKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	api := &mockSuaveAPI{
+		info: suave.KettleInfo{
+			KettleAddresses:    []common.Address{crypto.PubkeyToAddress(kettleKey.PublicKey)},
+			EncryptionKey:      crypto.FromECDSAPub(&encryptionKey.PublicKey),
+			PrecompileSpecHash: "01",
+			Precompiles:        map[string]common.Address{"newBid": {0x42}},
+			ChainID:            (*hexutil.Big)(big.NewInt(1)),
+			StoreBackend:       storeBackend,
+		},
+		signingKey: kettleKey,
+	}
+
+	srv := rpc.NewServer()
+	require.NoError(t, srv.RegisterName("suave", api))
+	t.Cleanup(srv.Stop)
+
+	return rpc.DialInProc(srv), api
+}
+
+func TestSelectKettle(t *testing.T) {
+	localKettle, localApi := newTestKettle(t, "local")
+	redisKettle, redisApi := newTestKettle(t, "redis")
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	trusted := []common.Address{localApi.info.KettleAddresses[0], redisApi.info.KettleAddresses[0]}
+
+	clt, err := SelectKettle(context.Background(), []*rpc.Client{localKettle, redisKettle}, key, KettleRequirements{
+		KettleAddresses: trusted,
+		ChainID:         big.NewInt(1),
+		Precompiles:     []string{"newBid"},
+		StoreBackend:    "redis",
+		EncryptedInputs: true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, redisApi.info.KettleAddresses[0], clt.kettleAddress)
+	require.Equal(t, []byte(redisApi.info.EncryptionKey), crypto.FromECDSAPub(clt.encryptionKey))
+
+	_, err = SelectKettle(context.Background(), []*rpc.Client{localKettle, redisKettle}, key, KettleRequirements{
+		KettleAddresses: trusted,
+		Precompiles:     []string{"newBidWithPolicy"},
+	})
+	require.ErrorContains(t, err, "precompile newBidWithPolicy not supported")
+
+	// only trusted kettles are selected
+	_, err = SelectKettle(context.Background(), []*rpc.Client{localKettle, redisKettle}, key, KettleRequirements{})
+	require.ErrorContains(t, err, "no trusted kettle addresses")
+
+	_, err = SelectKettle(context.Background(), []*rpc.Client{localKettle, redisKettle}, key, KettleRequirements{
+		KettleAddresses: localApi.info.KettleAddresses,
+		StoreBackend:    "redis",
+	})
+	require.ErrorContains(t, err, "node 1: kettle info not signed by a trusted kettle")
+
+	// a document listing and signed by another key is not trusted
+	impostorKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	redisApi.info.KettleAddresses = []common.Address{crypto.PubkeyToAddress(impostorKey.PublicKey)}
+	redisApi.signingKey = impostorKey
+
+	_, err = FetchKettleInfo(context.Background(), redisKettle, trusted)
+	require.ErrorContains(t, err, "kettle info not signed by a trusted kettle")
+
+	// documents not signed by the kettle are rejected
+	redisApi.info.KettleAddresses = trusted[1:]
+	redisApi.signingKey, err = crypto.GenerateKey()
+	require.NoError(t, err)
+
+	_, err = FetchKettleInfo(context.Background(), redisKettle, trusted)
+	require.ErrorContains(t, err, "kettle info not signed by kettle")
+}
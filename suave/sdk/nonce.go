@@ -0,0 +1,118 @@
+package sdk
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// nonceManager hands out consecutive nonces for an account so that
+// transactions can be sent concurrently without waiting for the previous
+// ones to reach the pending state of the node.
+type nonceManager struct {
+	rpc  *ethclient.Client
+	addr common.Address
+
+	lock     sync.Mutex
+	synced   bool
+	nonce    uint64
+	reserved int      // nonces handed out and not yet used or released
+	released []uint64 // sorted nonces returned unused while others are reserved
+}
+
+func newNonceManager(rpc *ethclient.Client, addr common.Address) *nonceManager {
+	return &nonceManager{
+		rpc:  rpc,
+		addr: addr,
+	}
+}
+
+// next reserves the next nonce of the account. The local counter is
+// initialized from the pending nonce of the node on first use. Released
+// nonces are handed out again before new ones.
+func (n *nonceManager) next(ctx context.Context) (uint64, error) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if !n.synced {
+		nonce, err := n.rpc.PendingNonceAt(ctx, n.addr)
+		if err != nil {
+			return 0, err
+		}
+		n.nonce = nonce
+		n.synced = true
+	}
+	return n.reserve(), nil
+}
+
+// skip discards a reserved nonce that is already taken on the node and
+// reserves a new one. The counter only moves forward to the pending nonce of
+// the node, so nonces reserved by other senders are never handed out again.
+func (n *nonceManager) skip(ctx context.Context) (uint64, error) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.reserved--
+
+	pending, err := n.rpc.PendingNonceAt(ctx, n.addr)
+	if err != nil {
+		if n.reserved == 0 {
+			n.reset()
+		}
+		return 0, err
+	}
+	if pending > n.nonce {
+		n.nonce = pending
+	}
+	for len(n.released) != 0 && n.released[0] < pending {
+		n.released = n.released[1:]
+	}
+	return n.reserve(), nil
+}
+
+// done marks a reserved nonce as used by a transaction accepted by the node.
+func (n *nonceManager) done() {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.reserved--
+}
+
+// release returns a reserved nonce that is not going to be used. It is handed
+// out again by next. Once no nonces are reserved the counter is synced again
+// with the node, which fills any gap left behind.
+func (n *nonceManager) release(nonce uint64) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.reserved--
+	if n.reserved == 0 {
+		n.reset()
+		return
+	}
+
+	i := sort.Search(len(n.released), func(i int) bool { return n.released[i] >= nonce })
+	n.released = append(n.released, 0)
+	copy(n.released[i+1:], n.released[i:])
+	n.released[i] = nonce
+}
+
+func (n *nonceManager) reserve() uint64 {
+	n.reserved++
+	if len(n.released) != 0 {
+		nonce := n.released[0]
+		n.released = n.released[1:]
+		return nonce
+	}
+	nonce := n.nonce
+	n.nonce++
+	return nonce
+}
+
+func (n *nonceManager) reset() {
+	n.synced = false
+	n.released = nil
+}
@@ -3,7 +3,11 @@ package sdk
 import (
 	"context"
 	"crypto/ecdsa"
+	"errors"
 	"fmt"
+	"math/big"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum"
@@ -14,6 +18,24 @@ import (
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/rpc"
+	suave "github.com/ethereum/go-ethereum/suave/core"
+)
+
+const (
+	defaultWaitTimeout  = 10 * time.Second
+	receiptPollInterval = 100 * time.Millisecond
+
+	// maxSendRetries is the number of times a transaction is resent after
+	// being rejected for its gas price or nonce.
+	maxSendRetries      = 3
+	gasPriceBumpPercent = 12
+)
+
+// Errors of the transaction pool that are handled by resending the transaction.
+var (
+	errUnderpriced        = errors.New("transaction underpriced")
+	errReplaceUnderpriced = errors.New("replacement transaction underpriced")
+	errNonceTooLow        = errors.New("nonce too low")
 )
 
 func DeployContract(bytecode []byte, client *Client) (*TransactionResult, error) {
@@ -42,51 +64,125 @@ func (c *Contract) Address() common.Address {
 	return c.addr
 }
 
-func (c *Contract) SendTransaction(method string, args []interface{}, confidentialDataBytes []byte) (*TransactionResult, error) {
-	signer, err := c.client.getSigner()
+func (c *Contract) ABI() *abi.ABI {
+	return c.abi
+}
+
+// Call executes a read-only call of the contract method with eth_call and
+// returns the unpacked outputs.
+func (c *Contract) Call(method string, args []interface{}) ([]interface{}, error) {
+	calldata, err := c.abi.Pack(method, args...)
 	if err != nil {
 		return nil, err
 	}
 
-	calldata, err := c.abi.Pack(method, args...)
+	msg := ethereum.CallMsg{
+		From: crypto.PubkeyToAddress(c.client.key.PublicKey),
+		To:   &c.addr,
+		Data: calldata,
+	}
+	output, err := c.client.rpc.CallContract(context.Background(), msg, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	senderAddr := crypto.PubkeyToAddress(c.client.key.PublicKey)
-	nonce, err := c.client.rpc.PendingNonceAt(context.Background(), senderAddr)
+	return c.abi.Unpack(method, output)
+}
+
+// UnpackLog unpacks a log emitted by the contract into out. Indexed arguments
+// are decoded from the topics of the log.
+func (c *Contract) UnpackLog(out interface{}, event string, log types.Log) error {
+	ev, ok := c.abi.Events[event]
+	if !ok {
+		return fmt.Errorf("event %s not found in abi", event)
+	}
+	if len(log.Topics) == 0 || log.Topics[0] != ev.ID {
+		return fmt.Errorf("log is not a %s event", event)
+	}
+
+	if len(log.Data) > 0 {
+		if err := c.abi.UnpackIntoInterface(out, event, log.Data); err != nil {
+			return err
+		}
+	}
+
+	var indexed abi.Arguments
+	for _, arg := range ev.Inputs {
+		if arg.Indexed {
+			indexed = append(indexed, arg)
+		}
+	}
+	return abi.ParseTopics(out, indexed, log.Topics[1:])
+}
+
+// UnpackComputeResult decodes the result of a confidential computation, which
+// is the calldata of the callback executed on-chain, into out. It returns the
+// name of the contract method targeted by the callback.
+func (c *Contract) UnpackComputeResult(out interface{}, result []byte) (string, error) {
+	method, err := c.abi.MethodById(result)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
-	gasPrice, err := c.client.rpc.SuggestGasPrice(context.Background())
+	values, err := method.Inputs.Unpack(result[4:])
+	if err != nil {
+		return "", err
+	}
+	if err := method.Inputs.Copy(out, values); err != nil {
+		return "", err
+	}
+	return method.Name, nil
+}
+
+func (c *Contract) SendTransaction(method string, args []interface{}, confidentialDataBytes []byte) (*TransactionResult, error) {
+	signer, err := c.client.getSigner()
 	if err != nil {
 		return nil, err
 	}
 
-	computeRequest, err := types.SignTx(types.NewTx(&types.ConfidentialComputeRequest{
-		ConfidentialComputeRecord: types.ConfidentialComputeRecord{
-			KettleAddress: c.client.kettleAddress,
-			Nonce:         nonce,
-			To:            &c.addr,
-			Value:         nil,
-			GasPrice:      gasPrice,
-			Gas:           1000000,
-			Data:          calldata,
-		},
-		ConfidentialInputs: confidentialDataBytes,
-	}), signer, c.client.key)
+	calldata, err := c.abi.Pack(method, args...)
 	if err != nil {
 		return nil, err
 	}
 
-	computeRequestBytes, err := computeRequest.MarshalBinary()
+	gasPrice, err := c.client.rpc.SuggestGasPrice(context.Background())
 	if err != nil {
 		return nil, err
 	}
 
-	var hash common.Hash
-	if err = c.client.rpc.Client().Call(&hash, "eth_sendRawTransaction", hexutil.Encode(computeRequestBytes)); err != nil {
+	// confidential inputs are sealed to the kettle so that only the kettle
+	// can read them
+	var encryptionKey *ecdsa.PublicKey
+	if len(confidentialDataBytes) != 0 {
+		if encryptionKey, err = c.client.kettleEncryptionKey(context.Background()); err != nil {
+			return nil, err
+		}
+	}
+
+	hash, err := c.client.sendWithRetry(context.Background(), nil, gasPrice, func(nonce uint64, gasPrice *big.Int) (*types.Transaction, error) {
+		request := &types.ConfidentialComputeRequest{
+			ConfidentialComputeRecord: types.ConfidentialComputeRecord{
+				KettleAddress: c.client.kettleAddress,
+				Nonce:         nonce,
+				To:            &c.addr,
+				Value:         nil,
+				GasPrice:      gasPrice,
+				Gas:           1000000,
+				Data:          calldata,
+			},
+			ConfidentialInputs: confidentialDataBytes,
+		}
+		if encryptionKey == nil {
+			return types.SignTx(types.NewTx(request), signer, c.client.key)
+		}
+
+		encryptedRequest, err := types.NewEncryptedConfidentialComputeRequest(request, encryptionKey)
+		if err != nil {
+			return nil, err
+		}
+		return types.SignTx(types.NewTx(encryptedRequest), signer, c.client.key)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -103,33 +199,108 @@ type TransactionResult struct {
 	receipt *types.Receipt
 }
 
+// Wait waits for the receipt of the transaction for up to defaultWaitTimeout.
 func (t *TransactionResult) Wait() (*types.Receipt, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultWaitTimeout)
+	defer cancel()
+
+	return t.WaitContext(ctx)
+}
+
+// WaitContext waits until the transaction is included or the context is done.
+// The receipt is checked on every new head, or periodically if the transport
+// does not support subscriptions.
+func (t *TransactionResult) WaitContext(ctx context.Context) (*types.Receipt, error) {
 	if t.receipt != nil {
 		return t.receipt, nil
 	}
 
-	timer := time.NewTimer(10 * time.Second)
+	var (
+		heads   = make(chan *types.Header, 16)
+		subErr  <-chan error
+		pollC   <-chan time.Time
+		pollTkr *time.Ticker
+	)
+	defer func() {
+		if pollTkr != nil {
+			pollTkr.Stop()
+		}
+	}()
+
+	poll := func() {
+		pollTkr = time.NewTicker(receiptPollInterval)
+		pollC = pollTkr.C
+	}
 
-	var receipt *types.Receipt
-	var err error
+	if sub, err := t.clt.rpc.SubscribeNewHead(ctx, heads); err != nil {
+		poll()
+	} else {
+		defer sub.Unsubscribe()
+		subErr = sub.Err()
+	}
 
 	for {
-		select {
-		case <-timer.C:
-			return nil, fmt.Errorf("timeout")
-		case <-time.After(100 * time.Millisecond):
-			receipt, err = t.clt.rpc.TransactionReceipt(context.Background(), t.hash)
-			if err != nil && err != ethereum.NotFound {
-				return nil, err
-			}
-			if receipt != nil {
-				t.receipt = receipt
-				return t.receipt, nil
+		receipt, err := t.clt.rpc.TransactionReceipt(ctx, t.hash)
+		if err == nil {
+			t.receipt = receipt
+			return t.receipt, nil
+		}
+		if !errors.Is(err, ethereum.NotFound) {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
 			}
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-heads:
+		case <-pollC:
+		case <-subErr:
+			// the subscription was dropped, keep waiting by polling
+			subErr = nil
+			poll()
 		}
 	}
 }
 
+// Result fetches the SuaveTransaction that the kettle executed and submitted
+// for a confidential compute request.
+func (t *TransactionResult) Result() (*types.SuaveTransaction, error) {
+	tx, _, err := t.clt.rpc.TransactionByHash(context.Background(), t.hash)
+	if err != nil {
+		return nil, err
+	}
+
+	suaveTx, ok := types.CastTxInner[*types.SuaveTransaction](tx)
+	if !ok {
+		return nil, fmt.Errorf("transaction %s is not a suave transaction", t.hash)
+	}
+	return suaveTx, nil
+}
+
+// ConfidentialComputeResult returns the result of the confidential computation
+// included in the SuaveTransaction submitted by the kettle for the request.
+func (t *TransactionResult) ConfidentialComputeResult() ([]byte, error) {
+	suaveTx, err := t.Result()
+	if err != nil {
+		return nil, err
+	}
+	return suaveTx.ConfidentialComputeResult, nil
+}
+
+// ConfidentialLogs returns the logs emitted during the confidential execution
+// of the request. The kettle only delivers them to the signer of the request,
+// they are not part of the SuaveTransaction.
+func (t *TransactionResult) ConfidentialLogs() ([]*types.Log, error) {
+	result, err := t.clt.ConfidentialResult(context.Background(), t.hash)
+	if err != nil {
+		return nil, err
+	}
+	return result.Logs, nil
+}
+
 func (t *TransactionResult) Hash() common.Hash {
 	return t.hash
 }
@@ -138,13 +309,19 @@ type Client struct {
 	rpc           *ethclient.Client
 	key           *ecdsa.PrivateKey
 	kettleAddress common.Address
+	nonces        *nonceManager
+
+	encryptionKeyLock sync.Mutex
+	encryptionKey     *ecdsa.PublicKey
 }
 
 func NewClient(rpc *rpc.Client, key *ecdsa.PrivateKey, kettleAddress common.Address) *Client {
+	ethClient := ethclient.NewClient(rpc)
 	c := &Client{
-		rpc:           ethclient.NewClient(rpc),
+		rpc:           ethClient,
 		key:           key,
 		kettleAddress: kettleAddress,
+		nonces:        newNonceManager(ethClient, crypto.PubkeyToAddress(key.PublicKey)),
 	}
 	return c
 }
@@ -153,6 +330,96 @@ func (c *Client) RPC() *ethclient.Client {
 	return c.rpc
 }
 
+// kettleEncryptionKey returns the key that confidential inputs for the kettle
+// are encrypted to. The key is fetched once and must be signed by the kettle
+// address, so that it cannot be replaced by anyone on the RPC path.
+func (c *Client) kettleEncryptionKey(ctx context.Context) (*ecdsa.PublicKey, error) {
+	c.encryptionKeyLock.Lock()
+	defer c.encryptionKeyLock.Unlock()
+
+	if c.encryptionKey != nil {
+		return c.encryptionKey, nil
+	}
+
+	var keys []struct {
+		KettleAddress common.Address `json:"kettleAddress"`
+		PublicKey     hexutil.Bytes  `json:"publicKey"`
+		Signature     hexutil.Bytes  `json:"signature"`
+	}
+	if err := c.rpc.Client().CallContext(ctx, &keys, "eth_kettleEncryptionKeys"); err != nil {
+		return nil, fmt.Errorf("could not fetch kettle encryption keys: %w", err)
+	}
+
+	for _, key := range keys {
+		if key.KettleAddress != c.kettleAddress {
+			continue
+		}
+
+		signer, err := crypto.SigToPub(crypto.Keccak256(key.PublicKey), key.Signature)
+		if err != nil {
+			return nil, fmt.Errorf("invalid encryption key signature: %w", err)
+		}
+		if crypto.PubkeyToAddress(*signer) != c.kettleAddress {
+			return nil, fmt.Errorf("encryption key not signed by kettle %s", c.kettleAddress)
+		}
+
+		encryptionKey, err := crypto.UnmarshalPubkey(key.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		c.encryptionKey = encryptionKey
+		return c.encryptionKey, nil
+	}
+
+	return nil, fmt.Errorf("no encryption key found for kettle %s", c.kettleAddress)
+}
+
+// ConfidentialResult fetches the confidential result of a request signed by
+// the client, by the hash of the request or of its SuaveTransaction.
+func (c *Client) ConfidentialResult(ctx context.Context, hash common.Hash) (*suave.ConfidentialResult, error) {
+	chainID, err := c.rpc.ChainID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	auth := &suave.ConfidentialResultAuth{
+		Kettle: c.kettleAddress,
+		Expiry: uint64(time.Now().Add(time.Minute).Unix()),
+	}
+	auth.Signature, err = suave.SignMessage(suave.ConfidentialResultMessage(chainID, auth.Kettle, hash, auth.Expiry), c.key)
+	if err != nil {
+		return nil, err
+	}
+
+	var result suave.ConfidentialResult
+	if err := c.rpc.Client().CallContext(ctx, &result, "suave_confidentialResult", hash, auth); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SubscribeConfidentialLogs sends the confidential results of the requests
+// signed by the client to ch as the kettle executes them.
+func (c *Client) SubscribeConfidentialLogs(ctx context.Context, ch chan<- *suave.ConfidentialResult) (*rpc.ClientSubscription, error) {
+	chainID, err := c.rpc.ChainID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	auth := &suave.ConfidentialLogsAuth{
+		Address: crypto.PubkeyToAddress(c.key.PublicKey),
+		Kettle:  c.kettleAddress,
+		// The authentication is only needed to subscribe
+		Expiry: uint64(time.Now().Add(time.Minute).Unix()),
+	}
+	auth.Signature, err = suave.SignMessage(suave.ConfidentialLogsMessage(chainID, auth.Kettle, auth.Address, auth.Expiry), c.key)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.rpc.Client().EthSubscribe(ctx, ch, "confidentialLogs", auth)
+}
+
 func (c *Client) getSigner() (types.Signer, error) {
 	chainID, err := c.rpc.ChainID(context.TODO())
 	if err != nil {
@@ -175,15 +442,14 @@ func (c *Client) SignTxn(txn *types.LegacyTx) (*types.Transaction, error) {
 	return ethTx, nil
 }
 
+// SendTransaction signs and sends the transaction. A zero nonce is replaced by
+// the next nonce of the local nonce manager.
 func (c *Client) SendTransaction(wrappedTxData *types.LegacyTx) (*TransactionResult, error) {
 	senderAddr := crypto.PubkeyToAddress(c.key.PublicKey)
 
-	if wrappedTxData.Nonce == 0 {
-		nonce, err := c.rpc.PendingNonceAt(context.Background(), senderAddr)
-		if err != nil {
-			return nil, err
-		}
-		wrappedTxData.Nonce = nonce
+	var nonce *uint64
+	if wrappedTxData.Nonce != 0 {
+		nonce = &wrappedTxData.Nonce
 	}
 
 	if wrappedTxData.GasPrice == nil {
@@ -209,24 +475,104 @@ func (c *Client) SendTransaction(wrappedTxData *types.LegacyTx) (*TransactionRes
 		wrappedTxData.Gas = gasLimit
 	}
 
-	txn, err := c.SignTxn(wrappedTxData)
+	hash, err := c.sendWithRetry(context.Background(), nonce, wrappedTxData.GasPrice, func(nonce uint64, gasPrice *big.Int) (*types.Transaction, error) {
+		wrappedTxData.Nonce = nonce
+		wrappedTxData.GasPrice = gasPrice
+		return c.SignTxn(wrappedTxData)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	txnBytes, err := txn.MarshalBinary()
+	res := &TransactionResult{
+		clt:  c,
+		hash: hash,
+	}
+	return res, nil
+}
+
+// sendWithRetry sends the transaction created by newTx. Underpriced
+// transactions are resent with a bumped gas price. If nonce is nil, the nonce
+// is assigned by the local nonce manager and a nonce that is already taken on
+// the node is replaced by a fresh one instead of bumping the gas price, which
+// would replace a transaction sent concurrently from the same account.
+func (c *Client) sendWithRetry(ctx context.Context, nonce *uint64, gasPrice *big.Int, newTx func(nonce uint64, gasPrice *big.Int) (*types.Transaction, error)) (common.Hash, error) {
+	managed := nonce == nil
+
+	var txNonce uint64
+	if managed {
+		var err error
+		if txNonce, err = c.nonces.next(ctx); err != nil {
+			return common.Hash{}, err
+		}
+	} else {
+		txNonce = *nonce
+	}
+
+	for attempt := 0; ; attempt++ {
+		tx, err := newTx(txNonce, gasPrice)
+		if err != nil {
+			if managed {
+				c.nonces.release(txNonce)
+			}
+			return common.Hash{}, err
+		}
+
+		hash, err := c.sendRawTransaction(ctx, tx)
+		if err == nil {
+			if managed {
+				c.nonces.done()
+			}
+			return hash, nil
+		}
+
+		if attempt < maxSendRetries {
+			switch {
+			case managed && (isSendError(err, errNonceTooLow) || isSendError(err, errReplaceUnderpriced)):
+				if txNonce, err = c.nonces.skip(ctx); err != nil {
+					return common.Hash{}, err
+				}
+				continue
+			case isSendError(err, errUnderpriced), isSendError(err, errReplaceUnderpriced):
+				gasPrice = bumpGasPrice(gasPrice)
+				continue
+			}
+		}
+
+		if managed {
+			c.nonces.release(txNonce)
+		}
+		return common.Hash{}, err
+	}
+}
+
+func (c *Client) sendRawTransaction(ctx context.Context, tx *types.Transaction) (common.Hash, error) {
+	txBytes, err := tx.MarshalBinary()
 	if err != nil {
-		return nil, err
+		return common.Hash{}, err
 	}
 
 	var hash common.Hash
-	if err = c.rpc.Client().Call(&hash, "eth_sendRawTransaction", hexutil.Encode(txnBytes)); err != nil {
-		return nil, err
+	if err = c.rpc.Client().CallContext(ctx, &hash, "eth_sendRawTransaction", hexutil.Encode(txBytes)); err != nil {
+		return common.Hash{}, err
 	}
+	return hash, nil
+}
 
-	res := &TransactionResult{
-		clt:  c,
-		hash: hash,
+// isSendError reports whether the error returned by the node is the given
+// transaction pool error. Errors lose their identity over RPC, so they are
+// matched by message.
+func isSendError(err error, target error) bool {
+	return strings.Contains(err.Error(), target.Error())
+}
+
+// bumpGasPrice increases the gas price by gasPriceBumpPercent, enough for the
+// transaction pool to accept it as a replacement.
+func bumpGasPrice(gasPrice *big.Int) *big.Int {
+	bumped := new(big.Int).Mul(gasPrice, big.NewInt(100+gasPriceBumpPercent))
+	bumped.Div(bumped, big.NewInt(100))
+	if bumped.Cmp(gasPrice) <= 0 {
+		bumped.Add(gasPrice, common.Big1)
 	}
-	return res, nil
+	return bumped
 }
@@ -0,0 +1,361 @@
+package sdk
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+)
+
+// mockEthAPI implements the subset of the eth namespace used by the client.
+type mockEthAPI struct {
+	lock     sync.Mutex
+	nonce    uint64
+	sent     []*types.Transaction
+	rejects  []error
+	receipts map[common.Hash]*types.Receipt
+	txs      map[common.Hash]*types.Transaction
+
+	headsFeed chan *types.Header
+
+	kettleKey     *ecdsa.PrivateKey
+	encryptionKey *ecdsa.PrivateKey
+	forgeryKey    *ecdsa.PrivateKey
+}
+
+func newMockEthAPI() *mockEthAPI {
+	return &mockEthAPI{
+		receipts:  map[common.Hash]*types.Receipt{},
+		txs:       map[common.Hash]*types.Transaction{},
+		headsFeed: make(chan *types.Header, 16),
+	}
+}
+
+func (m *mockEthAPI) ChainId() *hexutil.Big {
+	return (*hexutil.Big)(big.NewInt(1))
+}
+
+func (m *mockEthAPI) GasPrice() *hexutil.Big {
+	return (*hexutil.Big)(big.NewInt(100))
+}
+
+func (m *mockEthAPI) GetTransactionCount(addr common.Address, block string) hexutil.Uint64 {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return hexutil.Uint64(m.nonce)
+}
+
+func (m *mockEthAPI) SendRawTransaction(input hexutil.Bytes) (common.Hash, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if len(m.rejects) != 0 {
+		err := m.rejects[0]
+		m.rejects = m.rejects[1:]
+		return common.Hash{}, err
+	}
+
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(input); err != nil {
+		return common.Hash{}, err
+	}
+	for _, sent := range m.sent {
+		if sent.Nonce() == tx.Nonce() {
+			return common.Hash{}, errReplaceUnderpriced
+		}
+	}
+	m.sent = append(m.sent, tx)
+	return tx.Hash(), nil
+}
+
+func (m *mockEthAPI) GetTransactionReceipt(hash common.Hash) *types.Receipt {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return m.receipts[hash]
+}
+
+func (m *mockEthAPI) GetTransactionByHash(hash common.Hash) *types.Transaction {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return m.txs[hash]
+}
+
+func (m *mockEthAPI) KettleEncryptionKeys() ([]map[string]interface{}, error) {
+	signingKey := m.kettleKey
+	if m.forgeryKey != nil {
+		signingKey = m.forgeryKey
+	}
+
+	publicKey := crypto.FromECDSAPub(&m.encryptionKey.PublicKey)
+	signature, err := crypto.Sign(crypto.Keccak256(publicKey), signingKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return []map[string]interface{}{{
+		"kettleAddress": crypto.PubkeyToAddress(m.kettleKey.PublicKey),
+		"publicKey":     hexutil.Bytes(publicKey),
+		"signature":     hexutil.Bytes(signature),
+	}}, nil
+}
+
+func (m *mockEthAPI) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+	go func() {
+		for {
+			select {
+			case head := <-m.headsFeed:
+				notifier.Notify(rpcSub.ID, head)
+			case <-rpcSub.Err():
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
+
+// mine includes the transaction and announces a new head.
+func (m *mockEthAPI) mine(hash common.Hash) {
+	m.lock.Lock()
+	m.receipts[hash] = &types.Receipt{
+		Type:        types.LegacyTxType,
+		Status:      types.ReceiptStatusSuccessful,
+		TxHash:      hash,
+		BlockNumber: big.NewInt(1),
+		Logs:        []*types.Log{},
+	}
+	m.lock.Unlock()
+
+	m.headsFeed <- &types.Header{Number: big.NewInt(1), Difficulty: common.Big0}
+}
+
+func newTestClient(t *testing.T) (*Client, *mockEthAPI) {
+	api := newMockEthAPI()
+
+	var err error
+	api.kettleKey, err = crypto.GenerateKey()
+	require.NoError(t, err)
+	api.encryptionKey, err = crypto.GenerateKey()
+	require.NoError(t, err)
+
+	srv := rpc.NewServer()
+	require.NoError(t, srv.RegisterName("eth", api))
+	t.Cleanup(srv.Stop)
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	return NewClient(rpc.DialInProc(srv), key, crypto.PubkeyToAddress(api.kettleKey.PublicKey)), api
+}
+
+func TestClient_ConcurrentNonces(t *testing.T) {
+	clt, api := newTestClient(t)
+	api.nonce = 5
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			_, err := clt.SendTransaction(&types.LegacyTx{To: &common.Address{}, Gas: 21000})
+			require.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	nonces := map[uint64]bool{}
+	for _, tx := range api.sent {
+		nonces[tx.Nonce()] = true
+	}
+	require.Len(t, nonces, 10)
+	for i := uint64(5); i < 15; i++ {
+		require.True(t, nonces[i])
+	}
+}
+
+func TestClient_ConcurrentNonces_Rejects(t *testing.T) {
+	clt, api := newTestClient(t)
+	api.nonce = 5
+
+	// a transaction sent by another client takes one of the nonces and some
+	// of the sends fail and release their nonce
+	external := types.NewTx(&types.LegacyTx{Nonce: 8})
+	api.sent = append(api.sent, external)
+	api.rejects = []error{
+		errors.New("insufficient funds"),
+		errors.New("insufficient funds"),
+		errors.New("transaction underpriced"),
+	}
+
+	const senders = 20
+
+	var (
+		wg     sync.WaitGroup
+		failed int32
+	)
+	for i := 0; i < senders; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if _, err := clt.SendTransaction(&types.LegacyTx{To: &common.Address{}, Gas: 21000}); err != nil {
+				require.Contains(t, err.Error(), "insufficient funds")
+				atomic.AddInt32(&failed, 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	require.Equal(t, int32(2), failed)
+	require.Len(t, api.sent, 1+senders-2)
+	require.Equal(t, external, api.sent[0])
+
+	nonces := map[uint64]bool{}
+	for _, tx := range api.sent {
+		require.False(t, nonces[tx.Nonce()], "nonce %d sent twice", tx.Nonce())
+		nonces[tx.Nonce()] = true
+	}
+}
+
+func TestClient_RetryGasBump(t *testing.T) {
+	clt, api := newTestClient(t)
+	api.rejects = []error{
+		errors.New("replacement transaction underpriced"),
+		errors.New("transaction underpriced"),
+	}
+
+	_, err := clt.SendTransaction(&types.LegacyTx{To: &common.Address{}, Gas: 21000})
+	require.NoError(t, err)
+
+	// a taken nonce is skipped instead of replacing the transaction
+	require.Len(t, api.sent, 1)
+	require.Equal(t, uint64(1), api.sent[0].Nonce())
+	require.Equal(t, big.NewInt(112), api.sent[0].GasPrice())
+
+	// a stale nonce is refreshed from the node
+	api.nonce = 3
+	api.rejects = []error{errors.New("nonce too low")}
+
+	_, err = clt.SendTransaction(&types.LegacyTx{To: &common.Address{}, Gas: 21000})
+	require.NoError(t, err)
+
+	require.Len(t, api.sent, 2)
+	require.Equal(t, uint64(3), api.sent[1].Nonce())
+
+	// other errors are not retried and release the nonce
+	api.nonce = 4
+	api.rejects = []error{errors.New("insufficient funds")}
+
+	_, err = clt.SendTransaction(&types.LegacyTx{To: &common.Address{}, Gas: 21000})
+	require.Error(t, err)
+
+	_, err = clt.SendTransaction(&types.LegacyTx{To: &common.Address{}, Gas: 21000})
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), api.sent[2].Nonce())
+}
+
+func TestTransactionResult_WaitContext(t *testing.T) {
+	clt, api := newTestClient(t)
+
+	res, err := clt.SendTransaction(&types.LegacyTx{To: &common.Address{}, Gas: 21000})
+	require.NoError(t, err)
+
+	// the context expires if the transaction is not included
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	_, err = res.WaitContext(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	go api.mine(res.Hash())
+
+	receipt, err := res.Wait()
+	require.NoError(t, err)
+	require.Equal(t, res.Hash(), receipt.TxHash)
+}
+
+func TestTransactionResult_Result(t *testing.T) {
+	clt, api := newTestClient(t)
+
+	hash := common.Hash{0x1}
+	api.txs[hash] = types.NewTx(&types.SuaveTransaction{
+		ConfidentialComputeRequest: types.ConfidentialComputeRecord{
+			To:       &common.Address{},
+			GasPrice: big.NewInt(1),
+			ChainID:  big.NewInt(1),
+			V:        big.NewInt(1),
+			R:        big.NewInt(1),
+			S:        big.NewInt(1),
+		},
+		ConfidentialComputeResult: []byte{0x1, 0x2},
+		ChainID:                   big.NewInt(1),
+		V:                         big.NewInt(1),
+		R:                         big.NewInt(1),
+		S:                         big.NewInt(1),
+	})
+
+	res := &TransactionResult{clt: clt, hash: hash}
+
+	suaveTx, err := res.Result()
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x1, 0x2}, suaveTx.ConfidentialComputeResult)
+
+	result, err := res.ConfidentialComputeResult()
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x1, 0x2}, result)
+}
+
+const testContractABI = `[{"type":"function","name":"newBid","inputs":[{"name":"decryptionCondition","type":"uint64"}],"outputs":[{"name":"","type":"bytes"}]}]`
+
+func TestContract_EncryptedConfidentialInputs(t *testing.T) {
+	clt, api := newTestClient(t)
+
+	contractABI, err := abi.JSON(strings.NewReader(testContractABI))
+	require.NoError(t, err)
+	contract := GetContract(common.Address{0x1}, &contractABI, clt)
+
+	_, err = contract.SendTransaction("newBid", []interface{}{uint64(1)}, []byte{0xca, 0xfe})
+	require.NoError(t, err)
+
+	require.Len(t, api.sent, 1)
+	require.Equal(t, uint8(types.EncryptedConfidentialComputeRequestTxType), api.sent[0].Type())
+
+	encryptedRequest, ok := types.CastTxInner[*types.EncryptedConfidentialComputeRequest](api.sent[0])
+	require.True(t, ok)
+
+	request, err := encryptedRequest.Decrypt(api.encryptionKey)
+	require.NoError(t, err)
+	require.Equal(t, []byte{0xca, 0xfe}, request.ConfidentialInputs)
+
+	// a key that is not signed by the kettle is rejected
+	clt, api = newTestClient(t)
+	api.forgeryKey, err = crypto.GenerateKey()
+	require.NoError(t, err)
+
+	contract = GetContract(common.Address{0x1}, &contractABI, clt)
+	_, err = contract.SendTransaction("newBid", []interface{}{uint64(1)}, []byte{0xca, 0xfe})
+	require.ErrorContains(t, err, "encryption key not signed by kettle")
+	require.Empty(t, api.sent)
+}
@@ -6,6 +6,11 @@ library Suave {
 
     type BidId is bytes16;
 
+    struct AccessWindow {
+        uint64 notBefore;
+        uint64 notAfter;
+    }
+
     struct Bid {
         BidId id;
         BidId salt;
@@ -15,6 +20,19 @@ library Suave {
         string version;
     }
 
+    struct BidKeyRule {
+        string keyPrefix;
+        address[] readers;
+        address[] writers;
+    }
+
+    struct BidPolicy {
+        address[] readers;
+        address[] writers;
+        BidKeyRule[] keyRules;
+        bool directCallerOnly;
+    }
+
     struct BuildBlockArgs {
         uint64 slot;
         bytes proposerPubkey;
@@ -27,6 +45,34 @@ library Suave {
         bytes extra;
     }
 
+    struct BuildBlockArgsV2 {
+        uint64 slot;
+        bytes proposerPubkey;
+        bytes32 parent;
+        uint64 timestamp;
+        address feeRecipient;
+        uint64 gasLimit;
+        bytes32 random;
+        Withdrawal[] withdrawals;
+        bytes extra;
+        uint8 paymentMode;
+    }
+
+    struct RelayResponse {
+        string url;
+        uint64 statusCode;
+        uint64 latencyMs;
+        bytes body;
+        string error;
+    }
+
+    struct RelayTarget {
+        string url;
+        uint64 timeoutMs;
+        bool ssz;
+        bool gzip;
+    }
+
     struct Withdrawal {
         uint64 index;
         uint64 validator;
@@ -40,6 +86,8 @@ library Suave {
 
     address public constant BUILD_ETH_BLOCK = 0x0000000000000000000000000000000042100001;
 
+    address public constant BUILD_ETH_BLOCK_V2 = 0x0000000000000000000000000000000042100006;
+
     address public constant CONFIDENTIAL_INPUTS = 0x0000000000000000000000000000000042010001;
 
     address public constant CONFIDENTIAL_RETRIEVE = 0x0000000000000000000000000000000042020001;
@@ -56,6 +104,10 @@ library Suave {
 
     address public constant NEW_BID = 0x0000000000000000000000000000000042030000;
 
+    address public constant NEW_BID_WITH_ACCESS_WINDOW = 0x0000000000000000000000000000000042030002;
+
+    address public constant NEW_BID_WITH_POLICY = 0x0000000000000000000000000000000042030003;
+
     address public constant SIGN_ETH_TRANSACTION = 0x0000000000000000000000000000000040100001;
 
     address public constant SIMULATE_BUNDLE = 0x0000000000000000000000000000000042100000;
@@ -64,6 +116,12 @@ library Suave {
 
     address public constant SUBMIT_ETH_BLOCK_BID_TO_RELAY = 0x0000000000000000000000000000000042100002;
 
+    address public constant SUBMIT_ETH_BLOCK_BID_TO_RELAYS = 0x0000000000000000000000000000000042100005;
+
+    address public constant UPCOMING_BUILD_BLOCK_ARGS = 0x0000000000000000000000000000000042100004;
+
+    address public constant UPCOMING_BUILD_BLOCK_ARGS_V2 = 0x0000000000000000000000000000000042100007;
+
     // Returns whether execution is off- or on-chain
     function isConfidential() internal view returns (bool b) {
         (bool success, bytes memory isConfidentialBytes) = IS_CONFIDENTIAL_ADDR.staticcall("");
@@ -91,6 +149,19 @@ library Suave {
         return abi.decode(data, (bytes, bytes));
     }
 
+    function buildEthBlockV2(BuildBlockArgsV2 memory blockArgs, BidId bidId, string memory namespace)
+        internal
+        view
+        returns (bytes memory, bytes memory)
+    {
+        (bool success, bytes memory data) = BUILD_ETH_BLOCK_V2.staticcall(abi.encode(blockArgs, bidId, namespace));
+        if (!success) {
+            revert PeekerReverted(BUILD_ETH_BLOCK_V2, data);
+        }
+
+        return abi.decode(data, (bytes, bytes));
+    }
+
     function confidentialInputs() internal view returns (bytes memory) {
         (bool success, bytes memory data) = CONFIDENTIAL_INPUTS.staticcall(abi.encode());
         if (!success) {
@@ -169,6 +240,38 @@ library Suave {
         return abi.decode(data, (Bid));
     }
 
+    function newBidWithAccessWindow(
+        uint64 decryptionCondition,
+        address[] memory allowedPeekers,
+        address[] memory allowedStores,
+        string memory bidType,
+        AccessWindow memory accessWindow
+    ) internal view returns (Bid memory) {
+        (bool success, bytes memory data) = NEW_BID_WITH_ACCESS_WINDOW.staticcall(
+            abi.encode(decryptionCondition, allowedPeekers, allowedStores, bidType, accessWindow)
+        );
+        if (!success) {
+            revert PeekerReverted(NEW_BID_WITH_ACCESS_WINDOW, data);
+        }
+
+        return abi.decode(data, (Bid));
+    }
+
+    function newBidWithPolicy(
+        uint64 decryptionCondition,
+        address[] memory allowedStores,
+        string memory bidType,
+        BidPolicy memory policy
+    ) internal view returns (Bid memory) {
+        (bool success, bytes memory data) =
+            NEW_BID_WITH_POLICY.staticcall(abi.encode(decryptionCondition, allowedStores, bidType, policy));
+        if (!success) {
+            revert PeekerReverted(NEW_BID_WITH_POLICY, data);
+        }
+
+        return abi.decode(data, (Bid));
+    }
+
     function signEthTransaction(bytes memory txn, string memory chainId, string memory signingKey)
         internal
         view
@@ -218,4 +321,38 @@ library Suave {
 
         return data;
     }
+
+    function submitEthBlockBidToRelays(RelayTarget[] memory relays, bytes memory builderBid)
+        internal
+        view
+        returns (RelayResponse[] memory)
+    {
+        require(isConfidential());
+        (bool success, bytes memory data) = SUBMIT_ETH_BLOCK_BID_TO_RELAYS.staticcall(abi.encode(relays, builderBid));
+        if (!success) {
+            revert PeekerReverted(SUBMIT_ETH_BLOCK_BID_TO_RELAYS, data);
+        }
+
+        return abi.decode(data, (RelayResponse[]));
+    }
+
+    function upcomingBuildBlockArgs() internal view returns (BuildBlockArgs memory) {
+        require(isConfidential());
+        (bool success, bytes memory data) = UPCOMING_BUILD_BLOCK_ARGS.staticcall(abi.encode());
+        if (!success) {
+            revert PeekerReverted(UPCOMING_BUILD_BLOCK_ARGS, data);
+        }
+
+        return abi.decode(data, (BuildBlockArgs));
+    }
+
+    function upcomingBuildBlockArgsV2() internal view returns (BuildBlockArgsV2 memory) {
+        require(isConfidential());
+        (bool success, bytes memory data) = UPCOMING_BUILD_BLOCK_ARGS_V2.staticcall(abi.encode());
+        if (!success) {
+            revert PeekerReverted(UPCOMING_BUILD_BLOCK_ARGS_V2, data);
+        }
+
+        return abi.decode(data, (BuildBlockArgsV2));
+    }
 }
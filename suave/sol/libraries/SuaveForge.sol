@@ -47,6 +47,17 @@ library SuaveForge {
         return abi.decode(data, (bytes, bytes));
     }
 
+    function buildEthBlockV2(Suave.BuildBlockArgsV2 memory blockArgs, Suave.BidId bidId, string memory namespace)
+        internal
+        view
+        returns (bytes memory, bytes memory)
+    {
+        bytes memory data =
+            forgeIt("0x0000000000000000000000000000000042100006", abi.encode(blockArgs, bidId, namespace));
+
+        return abi.decode(data, (bytes, bytes));
+    }
+
     function confidentialInputs() internal view returns (bytes memory) {
         bytes memory data = forgeIt("0x0000000000000000000000000000000042010001", abi.encode());
 
@@ -101,6 +112,34 @@ library SuaveForge {
         return abi.decode(data, (Suave.Bid));
     }
 
+    function newBidWithAccessWindow(
+        uint64 decryptionCondition,
+        address[] memory allowedPeekers,
+        address[] memory allowedStores,
+        string memory bidType,
+        Suave.AccessWindow memory accessWindow
+    ) internal view returns (Suave.Bid memory) {
+        bytes memory data = forgeIt(
+            "0x0000000000000000000000000000000042030002",
+            abi.encode(decryptionCondition, allowedPeekers, allowedStores, bidType, accessWindow)
+        );
+
+        return abi.decode(data, (Suave.Bid));
+    }
+
+    function newBidWithPolicy(
+        uint64 decryptionCondition,
+        address[] memory allowedStores,
+        string memory bidType,
+        Suave.BidPolicy memory policy
+    ) internal view returns (Suave.Bid memory) {
+        bytes memory data = forgeIt(
+            "0x0000000000000000000000000000000042030003", abi.encode(decryptionCondition, allowedStores, bidType, policy)
+        );
+
+        return abi.decode(data, (Suave.Bid));
+    }
+
     function signEthTransaction(bytes memory txn, string memory chainId, string memory signingKey)
         internal
         view
@@ -136,4 +175,26 @@ library SuaveForge {
 
         return data;
     }
+
+    function submitEthBlockBidToRelays(Suave.RelayTarget[] memory relays, bytes memory builderBid)
+        internal
+        view
+        returns (Suave.RelayResponse[] memory)
+    {
+        bytes memory data = forgeIt("0x0000000000000000000000000000000042100005", abi.encode(relays, builderBid));
+
+        return abi.decode(data, (Suave.RelayResponse[]));
+    }
+
+    function upcomingBuildBlockArgs() internal view returns (Suave.BuildBlockArgs memory) {
+        bytes memory data = forgeIt("0x0000000000000000000000000000000042100004", abi.encode());
+
+        return abi.decode(data, (Suave.BuildBlockArgs));
+    }
+
+    function upcomingBuildBlockArgsV2() internal view returns (Suave.BuildBlockArgsV2 memory) {
+        bytes memory data = forgeIt("0x0000000000000000000000000000000042100007", abi.encode());
+
+        return abi.decode(data, (Suave.BuildBlockArgsV2));
+    }
 }
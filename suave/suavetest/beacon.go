@@ -0,0 +1,164 @@
+package suavetest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/bellatrix"
+	"github.com/attestantio/go-eth2-client/spec/capella"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/suave/backends"
+	"github.com/stretchr/testify/require"
+)
+
+// FakeBeacon streams the payload_attributes events of the slots announced to
+// it, like the event stream of a beacon node.
+type FakeBeacon struct {
+	srv *httptest.Server
+
+	lock        sync.Mutex
+	last        []byte
+	subscribers map[chan []byte]struct{}
+}
+
+// NewFakeBeacon starts a fake beacon node, it is stopped at the end of the
+// test.
+func NewFakeBeacon(t testing.TB) *FakeBeacon {
+	b := &FakeBeacon{
+		subscribers: make(map[chan []byte]struct{}),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/eth/v1/events", b.serveEvents)
+
+	b.srv = httptest.NewServer(mux)
+	t.Cleanup(func() {
+		// Event streams stay open until the subscriber goes away
+		b.srv.CloseClientConnections()
+		b.srv.Close()
+	})
+
+	return b
+}
+
+// URL returns the endpoint of the beacon node.
+func (b *FakeBeacon) URL() string {
+	return b.srv.URL
+}
+
+// PublishPayloadAttributes sends a payload_attributes event to the current
+// subscribers. New subscribers receive the latest event first.
+func (b *FakeBeacon) PublishPayloadAttributes(event *backends.PayloadAttributesEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.last = data
+	for ch := range b.subscribers {
+		select {
+		case ch <- data:
+		default:
+			// The subscriber is lagging behind, it only needs the latest slot
+		}
+	}
+	return nil
+}
+
+func (b *FakeBeacon) serveEvents(w http.ResponseWriter, r *http.Request) {
+	if topic := r.URL.Query().Get("topics"); topic != "payload_attributes" {
+		http.Error(w, fmt.Sprintf("unsupported topic %s", topic), http.StatusBadRequest)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	ch := make(chan []byte, 16)
+	b.lock.Lock()
+	b.subscribers[ch] = struct{}{}
+	if b.last != nil {
+		ch <- b.last
+	}
+	b.lock.Unlock()
+
+	defer func() {
+		b.lock.Lock()
+		delete(b.subscribers, ch)
+		b.lock.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case data := <-ch:
+			fmt.Fprintf(w, "event: payload_attributes\ndata: %s\n\n", data)
+			flusher.Flush()
+		}
+	}
+}
+
+// AnnounceSlot makes the block building arguments available to the kettles
+// started WithBeacon: the proposer is registered with the relay and the
+// beacon node announces the slot. It waits until every kettle received it.
+// Kettles do not report slots that have started, the timestamp must be in
+// the future.
+func (f *Framework) AnnounceSlot(args *types.BuildBlockArgs) {
+	f.t.Helper()
+
+	f.Relay.RegisterValidator(args.Slot, backends.ValidatorData{
+		Pubkey:       hexutil.Encode(args.ProposerPubkey),
+		FeeRecipient: args.FeeRecipient,
+		GasLimit:     args.GasLimit,
+	})
+
+	event := &backends.PayloadAttributesEvent{
+		Version: "capella",
+		Data: backends.PayloadAttributesEventData{
+			ProposalSlot:    args.Slot,
+			ParentBlockHash: args.Parent,
+			PayloadAttributes: backends.PayloadAttributes{
+				Timestamp:             args.Timestamp,
+				PrevRandao:            args.Random,
+				SuggestedFeeRecipient: args.FeeRecipient,
+				Withdrawals:           []*capella.Withdrawal{},
+			},
+		},
+	}
+	for _, w := range args.Withdrawals {
+		event.Data.PayloadAttributes.Withdrawals = append(event.Data.PayloadAttributes.Withdrawals, &capella.Withdrawal{
+			Index:          capella.WithdrawalIndex(w.Index),
+			ValidatorIndex: phase0.ValidatorIndex(w.Validator),
+			Address:        bellatrix.ExecutionAddress(w.Address),
+			Amount:         phase0.Gwei(w.Amount),
+		})
+	}
+	require.NoError(f.t, f.Beacon.PublishPayloadAttributes(event))
+
+	for _, kettle := range f.Kettles {
+		beacon := kettle.Service.APIBackend.SuaveBeaconBackend()
+		require.NotNil(f.t, beacon, "kettle %s does not follow the beacon node", kettle.Address())
+		require.Eventually(f.t, func() bool {
+			upcoming, err := beacon.UpcomingBuildBlockArgs(context.Background())
+			return err == nil && upcoming.Slot == args.Slot
+		}, 5*time.Second, 10*time.Millisecond, "kettle %s did not receive slot %d", kettle.Address(), args.Slot)
+	}
+}
@@ -0,0 +1,139 @@
+package suavetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/suave/backends"
+)
+
+// RelaySubmission is a request received by the fake relay.
+type RelaySubmission struct {
+	Method string
+	Path   string
+	Header http.Header
+	Body   []byte
+}
+
+// Decode unmarshals the JSON body of the request into v.
+func (s *RelaySubmission) Decode(v interface{}) error {
+	return json.Unmarshal(s.Body, v)
+}
+
+// FakeRelay records the requests sent to it, be it blocks submitted to the
+// relay or bundles sent to a builder, and answers them successfully. It also
+// serves the validator registrations the beacon backend of the kettles reads.
+type FakeRelay struct {
+	srv *httptest.Server
+
+	lock        sync.Mutex
+	submissions []*RelaySubmission
+	validators  map[uint64]backends.ValidatorData
+}
+
+// NewFakeRelay starts a fake relay, it is stopped at the end of the test.
+func NewFakeRelay(t testing.TB) *FakeRelay {
+	r := &FakeRelay{
+		validators: make(map[uint64]backends.ValidatorData),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/relay/v1/builder/validators", r.serveValidators)
+	mux.HandleFunc("/", r.serveSubmission)
+
+	r.srv = httptest.NewServer(mux)
+	t.Cleanup(r.srv.Close)
+
+	return r
+}
+
+// URL returns the endpoint of the relay.
+func (r *FakeRelay) URL() string {
+	return r.srv.URL
+}
+
+// RegisterValidator sets the validator proposing the block of the slot.
+func (r *FakeRelay) RegisterValidator(slot uint64, validator backends.ValidatorData) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.validators[slot] = validator
+}
+
+// Submissions returns the requests received so far, oldest first.
+func (r *FakeRelay) Submissions() []*RelaySubmission {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return append([]*RelaySubmission(nil), r.submissions...)
+}
+
+// LastSubmission returns the latest request received, nil if there is none.
+func (r *FakeRelay) LastSubmission() *RelaySubmission {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if len(r.submissions) == 0 {
+		return nil
+	}
+	return r.submissions[len(r.submissions)-1]
+}
+
+// Reset forgets the requests received so far.
+func (r *FakeRelay) Reset() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.submissions = nil
+}
+
+func (r *FakeRelay) serveSubmission(w http.ResponseWriter, req *http.Request) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	r.lock.Lock()
+	r.submissions = append(r.submissions, &RelaySubmission{
+		Method: req.Method,
+		Path:   req.URL.Path,
+		Header: req.Header.Clone(),
+		Body:   body,
+	})
+	r.lock.Unlock()
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (r *FakeRelay) serveValidators(w http.ResponseWriter, req *http.Request) {
+	r.lock.Lock()
+	slots := make([]uint64, 0, len(r.validators))
+	for slot := range r.validators {
+		slots = append(slots, slot)
+	}
+	sort.Slice(slots, func(i, j int) bool { return slots[i] < slots[j] })
+
+	resp := make(backends.GetValidatorRelayResponse, len(slots))
+	for i, slot := range slots {
+		validator := r.validators[slot]
+
+		resp[i].Slot = slot
+		resp[i].Entry.Message.FeeRecipient = validator.FeeRecipient.Hex()
+		resp[i].Entry.Message.GasLimit = validator.GasLimit
+		resp[i].Entry.Message.Pubkey = validator.Pubkey
+		resp[i].Entry.Signature = "0x"
+	}
+	r.lock.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		http.Error(w, fmt.Sprintf("could not encode validators: %v", err), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,108 @@
+package suavetest
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/suave/artifacts"
+	suave "github.com/ethereum/go-ethereum/suave/core"
+	"github.com/stretchr/testify/require"
+)
+
+// storeTimeout bounds how long a bid takes to reach the other kettles.
+const storeTimeout = 5 * time.Second
+
+// RequireBid fails the test if the kettle does not store the bid.
+func (k *Kettle) RequireBid(bidId suave.BidId) suave.Bid {
+	k.t.Helper()
+
+	bid, err := k.Engine().FetchBidById(bidId)
+	require.NoError(k.t, err, "bid %x not stored by kettle %s", bidId, k.Address())
+	return bid
+}
+
+// RequireStored fails the test if the value stored under key for the bid
+// differs from the expected one.
+func (k *Kettle) RequireStored(bidId suave.BidId, key string, expected []byte) {
+	k.t.Helper()
+
+	bid := k.RequireBid(bidId)
+	value, err := k.Store().Retrieve(bid, common.Address{}, key)
+	require.NoError(k.t, err, "key %s of bid %x not stored by kettle %s", key, bidId, k.Address())
+	require.Equal(k.t, expected, value, "key %s of bid %x stored by kettle %s", key, bidId, k.Address())
+}
+
+// RequireNotStored fails the test if the kettle stores a value under key for
+// the bid.
+func (k *Kettle) RequireNotStored(bidId suave.BidId, key string) {
+	k.t.Helper()
+
+	bid, err := k.Engine().FetchBidById(bidId)
+	if err != nil {
+		return
+	}
+	_, err = k.Store().Retrieve(bid, common.Address{}, key)
+	require.Error(k.t, err, "key %s of bid %x stored by kettle %s", key, bidId, k.Address())
+}
+
+// WaitForStored waits until the value stored under key for the bid by every
+// kettle is the expected one, as writes reach the other kettles through the
+// transport asynchronously.
+func (f *Framework) WaitForStored(bidId suave.BidId, key string, expected []byte) {
+	f.t.Helper()
+
+	for _, kettle := range f.Kettles {
+		kettle := kettle
+		require.Eventually(f.t, func() bool {
+			bid, err := kettle.Engine().FetchBidById(bidId)
+			if err != nil {
+				return false
+			}
+			value, err := kettle.Store().Retrieve(bid, common.Address{}, key)
+			return err == nil && bytes.Equal(value, expected)
+		}, storeTimeout, 10*time.Millisecond, "key %s of bid %x not stored by kettle %s", key, bidId, kettle.Address())
+	}
+}
+
+// WaitForBid waits until every kettle stores the bid.
+func (f *Framework) WaitForBid(bidId suave.BidId) {
+	f.t.Helper()
+
+	for _, kettle := range f.Kettles {
+		kettle := kettle
+		require.Eventually(f.t, func() bool {
+			_, err := kettle.Engine().FetchBidById(bidId)
+			return err == nil
+		}, storeTimeout, 10*time.Millisecond, "bid %x not stored by kettle %s", bidId, kettle.Address())
+	}
+}
+
+// RequireNoRpcError fails the test on the error of a confidential request,
+// decoding the revert reason of the precompile that failed if there is one.
+func RequireNoRpcError(t testing.TB, rpcErr error) {
+	t.Helper()
+
+	if rpcErr == nil {
+		return
+	}
+
+	const prefix = "execution reverted: "
+	if !strings.HasPrefix(rpcErr.Error(), prefix+"0x") {
+		require.NoError(t, rpcErr)
+	}
+	decodedError, err := hexutil.Decode(rpcErr.Error()[len(prefix):])
+	if err != nil || len(decodedError) < 4 {
+		require.NoError(t, rpcErr)
+	}
+
+	unpacked, err := artifacts.SuaveAbi.Errors["PeekerReverted"].Inputs.Unpack(decodedError[4:])
+	if err != nil {
+		require.NoError(t, rpcErr)
+	}
+	require.NoError(t, rpcErr, fmt.Sprintf("peeker 0x%x reverted: %s", unpacked[0].(common.Address), unpacked[1].([]byte)))
+}
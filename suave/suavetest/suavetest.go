@@ -0,0 +1,496 @@
+// Package suavetest runs SUAVE kettles in process for testing contracts and
+// the kettle itself. A Framework starts any number of kettles sharing a
+// confidential store transport, optionally an L1 execution node backing their
+// eth precompiles, along with a fake relay and a fake beacon node.
+package suavetest
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/beacon/engine"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth"
+	"github.com/ethereum/go-ethereum/eth/downloader"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/miner"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+	suave "github.com/ethereum/go-ethereum/suave/core"
+	"github.com/ethereum/go-ethereum/suave/cstore"
+	"github.com/ethereum/go-ethereum/suave/sdk"
+	"github.com/flashbots/go-boost-utils/bls"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	// FundedKey is funded on the SUAVE and L1 chains of the framework, and
+	// receives the fees of the blocks they produce.
+	FundedKey, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+
+	// FundedAddress is the address of FundedKey.
+	FundedAddress = crypto.PubkeyToAddress(FundedKey.PublicKey)
+
+	// FundedBalance is the genesis balance of FundedAddress.
+	FundedBalance = big.NewInt(2e18)
+)
+
+// blockTime is the time between two blocks produced by ProgressChain.
+const blockTime = 12
+
+type config struct {
+	kettles      int
+	l1           bool
+	beacon       bool
+	redisStore   bool
+	streams      bool
+	suaveGenesis *core.Genesis
+	l1Genesis    *core.Genesis
+	suaveConfig  suave.Config
+	modifiers    []func(*suave.Config)
+}
+
+// Option configures the framework started by New.
+type Option func(*config)
+
+// WithKettles starts n kettles instead of one. They share a confidential
+// store transport, bids stored by one of them for the others are delivered
+// to them.
+func WithKettles(n int) Option {
+	return func(c *config) {
+		c.kettles = n
+	}
+}
+
+// WithL1 starts an L1 execution node, the eth precompiles of the kettles run
+// against it.
+func WithL1() Option {
+	return func(c *config) {
+		c.l1 = true
+	}
+}
+
+// WithBeacon connects the kettles to the fake beacon node and relay, new
+// slots are announced with Framework.AnnounceSlot.
+func WithBeacon() Option {
+	return func(c *config) {
+		c.beacon = true
+	}
+}
+
+// WithRedisStore stores the confidential data of each kettle in its own
+// in-process Redis instead of in memory.
+func WithRedisStore() Option {
+	return func(c *config) {
+		c.redisStore = true
+	}
+}
+
+// WithRedisStreamsTransport shares the confidential store writes of the
+// kettles over Redis streams instead of Redis pub/sub.
+func WithRedisStreamsTransport() Option {
+	return func(c *config) {
+		c.streams = true
+	}
+}
+
+// WithSuaveGenesis replaces the genesis of the SUAVE chain. The chain config
+// defaults to the one of the default genesis.
+func WithSuaveGenesis(genesis *core.Genesis) Option {
+	return func(c *config) {
+		c.suaveGenesis = genesis
+	}
+}
+
+// WithL1Genesis replaces the genesis of the L1 chain. The chain config
+// defaults to the one of the default genesis.
+func WithL1Genesis(genesis *core.Genesis) Option {
+	return func(c *config) {
+		c.l1Genesis = genesis
+	}
+}
+
+// WithGenesisAlloc adds accounts to the genesis of the SUAVE chain, for
+// example to predeploy contracts. It applies to the genesis set by a
+// preceding WithSuaveGenesis.
+func WithGenesisAlloc(alloc core.GenesisAlloc) Option {
+	return func(c *config) {
+		c.suaveGenesis = withAlloc(c.suaveGenesis, alloc)
+	}
+}
+
+// WithL1GenesisAlloc adds accounts to the genesis of the L1 chain.
+func WithL1GenesisAlloc(alloc core.GenesisAlloc) Option {
+	return func(c *config) {
+		c.l1Genesis = withAlloc(c.l1Genesis, alloc)
+	}
+}
+
+// withAlloc returns a copy of the genesis with the accounts added, genesis
+// blocks are shared between tests.
+func withAlloc(genesis *core.Genesis, alloc core.GenesisAlloc) *core.Genesis {
+	cpy := *genesis
+	cpy.Alloc = make(core.GenesisAlloc, len(genesis.Alloc)+len(alloc))
+	for addr, account := range genesis.Alloc {
+		cpy.Alloc[addr] = account
+	}
+	for addr, account := range alloc {
+		cpy.Alloc[addr] = account
+	}
+	return &cpy
+}
+
+// WithBundleSigningKey sets the key the kettles sign the bundles they send to
+// builders with.
+func WithBundleSigningKey(sk *ecdsa.PrivateKey) Option {
+	return func(c *config) {
+		c.suaveConfig.EthBundleSigningKeyHex = hex.EncodeToString(crypto.FromECDSA(sk))
+	}
+}
+
+// WithBlockSigningKey sets the key the kettles sign the blocks they submit to
+// relays with.
+func WithBlockSigningKey(sk *bls.SecretKey) Option {
+	return func(c *config) {
+		c.suaveConfig.EthBlockSigningKeyHex = hexutil.Encode(bls.SecretKeyToBytes(sk))
+	}
+}
+
+// WithSuaveConfig changes the configuration of every kettle, after the
+// framework filled in its endpoints.
+func WithSuaveConfig(modify func(*suave.Config)) Option {
+	return func(c *config) {
+		c.modifiers = append(c.modifiers, modify)
+	}
+}
+
+// Framework is a set of in-process kettles with the services they connect to.
+// It is closed at the end of the test.
+type Framework struct {
+	t testing.TB
+
+	// L1 is the L1 execution node, nil without WithL1
+	L1 *Node
+	// Kettles are the kettles of the framework, at least one
+	Kettles []*Kettle
+	// Relay records the requests sent to it by the kettles and their contracts
+	Relay *FakeRelay
+	// Beacon announces slots to the kettles started WithBeacon
+	Beacon *FakeBeacon
+
+	closeOnce sync.Once
+}
+
+// New starts a framework, by default a single kettle with an in-memory store
+// and a mocked L1.
+func New(t testing.TB, opts ...Option) *Framework {
+	t.Helper()
+
+	cfg := &config{
+		kettles:      1,
+		suaveGenesis: DefaultSuaveGenesis(),
+		l1Genesis:    DefaultL1Genesis(),
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	require.Positive(t, cfg.kettles, "at least one kettle is required")
+
+	f := &Framework{
+		t:      t,
+		Relay:  NewFakeRelay(t),
+		Beacon: NewFakeBeacon(t),
+	}
+	t.Cleanup(f.Close)
+
+	if cfg.l1 {
+		f.L1 = startNode(t, withChainConfig(cfg.l1Genesis, DefaultL1Genesis()), suave.Config{}, true)
+		cfg.suaveConfig.SuaveEthRemoteBackendEndpoint = f.L1.Stack.HTTPEndpoint()
+	}
+
+	if cfg.beacon {
+		cfg.suaveConfig.BeaconRemoteEndpoint = f.Beacon.URL()
+		cfg.suaveConfig.RelayRemoteEndpoint = f.Relay.URL()
+	}
+
+	var transport *miniredis.Miniredis
+	if cfg.kettles > 1 || cfg.streams {
+		transport = miniredis.RunT(t)
+		if cfg.streams {
+			cfg.suaveConfig.RedisStoreStreamsUri = transport.Addr()
+		} else {
+			cfg.suaveConfig.RedisStorePubsubUri = transport.Addr()
+		}
+	}
+
+	genesis := withChainConfig(cfg.suaveGenesis, DefaultSuaveGenesis())
+	for i := 0; i < cfg.kettles; i++ {
+		suaveConfig := cfg.suaveConfig
+		if cfg.redisStore {
+			suaveConfig.RedisStoreUri = miniredis.RunT(t).Addr()
+		}
+		if cfg.streams {
+			suaveConfig.RedisStoreStreamsGroup = fmt.Sprintf("kettle-%d", i)
+		}
+		for _, modify := range cfg.modifiers {
+			modify(&suaveConfig)
+		}
+
+		f.Kettles = append(f.Kettles, startKettle(t, genesis, suaveConfig))
+	}
+
+	if transport != nil && !cfg.streams {
+		// Pub/sub drops the messages published before a kettle subscribed
+		require.Eventually(t, func() bool {
+			subscribers := 0
+			for _, n := range transport.PubSubNumSub(transport.PubSubChannels("")...) {
+				subscribers += n
+			}
+			return subscribers >= cfg.kettles
+		}, 5*time.Second, 10*time.Millisecond, "kettles did not subscribe to the transport")
+	}
+
+	return f
+}
+
+// Kettle returns the first kettle of the framework.
+func (f *Framework) Kettle() *Kettle {
+	return f.Kettles[0]
+}
+
+// KettleAddresses returns the addresses of all the kettles, to store bids
+// for all of them.
+func (f *Framework) KettleAddresses() []common.Address {
+	addrs := make([]common.Address, len(f.Kettles))
+	for i, kettle := range f.Kettles {
+		addrs[i] = kettle.Address()
+	}
+	return addrs
+}
+
+// Close stops the nodes of the framework. It is safe to call more than once.
+func (f *Framework) Close() {
+	f.closeOnce.Do(func() {
+		for _, kettle := range f.Kettles {
+			kettle.Close()
+		}
+		if f.L1 != nil {
+			f.L1.Close()
+		}
+	})
+}
+
+// DefaultSuaveGenesis returns the genesis of the SUAVE chain, funding
+// FundedAddress.
+func DefaultSuaveGenesis() *core.Genesis {
+	chainConfig := *params.AllEthashProtocolChanges
+	chainConfig.TerminalTotalDifficulty = new(big.Int)
+
+	return newGenesis(&chainConfig)
+}
+
+// DefaultL1Genesis returns the genesis of the L1 chain, funding FundedAddress.
+func DefaultL1Genesis() *core.Genesis {
+	chainConfig := *params.AllEthashProtocolChanges
+	chainConfig.TerminalTotalDifficulty = new(big.Int)
+	chainConfig.SuaveBlock = nil
+
+	return newGenesis(&chainConfig)
+}
+
+func newGenesis(chainConfig *params.ChainConfig) *core.Genesis {
+	return &core.Genesis{
+		Config:     chainConfig,
+		Timestamp:  1680000000,
+		GasLimit:   30000000,
+		BaseFee:    big.NewInt(0),
+		Difficulty: big.NewInt(0),
+		Alloc: core.GenesisAlloc{
+			FundedAddress: {Balance: FundedBalance},
+		},
+	}
+}
+
+func withChainConfig(genesis *core.Genesis, fallback *core.Genesis) *core.Genesis {
+	if genesis.Config != nil {
+		return genesis
+	}
+	cpy := *genesis
+	cpy.Config = fallback.Config
+	return &cpy
+}
+
+// Node is an in-process execution node, producing blocks on demand.
+type Node struct {
+	t testing.TB
+
+	Stack   *node.Node
+	Service *eth.Ethereum
+}
+
+// Block is a block produced by ProgressChain along with its receipts.
+type Block struct {
+	*types.Block
+
+	Receipts []*types.Receipt
+}
+
+func startNode(t testing.TB, genesis *core.Genesis, suaveConfig suave.Config, http bool) *Node {
+	t.Helper()
+
+	nodeConfig := &node.Config{
+		P2P: p2p.Config{
+			ListenAddr:  "0.0.0.0:0",
+			NoDiscovery: true,
+			MaxPeers:    25,
+		},
+	}
+	if http {
+		nodeConfig.HTTPHost = "127.0.0.1"
+	}
+
+	n, err := node.New(nodeConfig)
+	if err != nil {
+		t.Fatal("can't create node:", err)
+	}
+
+	ethcfg := &ethconfig.Config{Genesis: genesis, SyncMode: downloader.FullSync, TrieTimeout: time.Minute, TrieDirtyCache: 256, TrieCleanCache: 256, Suave: suaveConfig}
+	ethservice, err := eth.New(n, ethcfg)
+	if err != nil {
+		n.Close()
+		t.Fatal("can't create eth service:", err)
+	}
+	if err := n.Start(); err != nil {
+		n.Close()
+		t.Fatal("can't start node:", err)
+	}
+
+	ethservice.SetEtherbase(FundedAddress)
+	ethservice.SetSynced()
+
+	return &Node{t: t, Stack: n, Service: ethservice}
+}
+
+// RPCNode returns an in-process RPC client of the node.
+func (n *Node) RPCNode() *rpc.Client {
+	client, err := n.Stack.Attach()
+	if err != nil {
+		n.t.Fatal(err)
+	}
+	return client
+}
+
+// Client returns an in-process eth client of the node.
+func (n *Node) Client() *ethclient.Client {
+	return ethclient.NewClient(n.RPCNode())
+}
+
+// CurrentBlock returns the head of the chain.
+func (n *Node) CurrentBlock() *types.Header {
+	return n.Service.BlockChain().CurrentBlock()
+}
+
+// HeadNumber returns the number of the head of the chain.
+func (n *Node) HeadNumber() uint64 {
+	return n.CurrentBlock().Number.Uint64()
+}
+
+// ProgressChain builds a block from the transaction pool on top of the head
+// of the chain and imports it.
+func (n *Node) ProgressChain() *Block {
+	n.t.Helper()
+
+	parent := n.CurrentBlock()
+	etherbase, err := n.Service.Etherbase()
+	require.NoError(n.t, err)
+
+	payload, err := n.Service.Miner().BuildPayload(&miner.BuildPayloadArgs{
+		Parent:       parent.Hash(),
+		Timestamp:    parent.Time + blockTime,
+		FeeRecipient: etherbase,
+	})
+	require.NoError(n.t, err)
+	envelope := payload.ResolveFull()
+	block, err := engine.ExecutableDataToBlock(*envelope.ExecutionPayload)
+	require.NoError(n.t, err)
+
+	imported, err := n.Service.BlockChain().InsertChain(types.Blocks{block})
+	require.NoError(n.t, err)
+	require.Equal(n.t, 1, imported)
+
+	return &Block{
+		Block:    block,
+		Receipts: n.Service.BlockChain().GetReceiptsByHash(block.Hash()),
+	}
+}
+
+// Close stops the node.
+func (n *Node) Close() {
+	n.Stack.Close()
+}
+
+// Kettle is a SUAVE node with its own kettle key.
+type Kettle struct {
+	*Node
+
+	address common.Address
+	keys    *keystore.KeyStore
+}
+
+func startKettle(t testing.TB, genesis *core.Genesis, suaveConfig suave.Config) *Kettle {
+	t.Helper()
+
+	n := startNode(t, genesis, suaveConfig, false)
+
+	keys := keystore.NewPlaintextKeyStore(t.TempDir())
+	acc, err := keys.NewAccount("")
+	require.NoError(t, err)
+	require.NoError(t, keys.TimedUnlock(acc, "", 0))
+	n.Service.AccountManager().AddBackend(keys)
+
+	return &Kettle{Node: n, address: acc.Address, keys: keys}
+}
+
+// Address returns the kettle address requests are sent to.
+func (k *Kettle) Address() common.Address {
+	return k.address
+}
+
+// ImportKey adds a key to the accounts of the kettle, for example to let it
+// sign with a funded account.
+func (k *Kettle) ImportKey(key *ecdsa.PrivateKey) common.Address {
+	acc, err := k.keys.ImportECDSA(key, "")
+	require.NoError(k.t, err)
+	require.NoError(k.t, k.keys.TimedUnlock(acc, "", 0))
+	return acc.Address
+}
+
+// NewSDKClient returns a client sending confidential requests signed with key
+// to the kettle.
+func (k *Kettle) NewSDKClient(key *ecdsa.PrivateKey) *sdk.Client {
+	return sdk.NewClient(k.RPCNode(), key, k.address)
+}
+
+// Engine returns the confidential store engine of the kettle.
+func (k *Kettle) Engine() *cstore.ConfidentialStoreEngine {
+	return k.Service.APIBackend.SuaveEngine()
+}
+
+// Store returns the confidential store backend of the kettle.
+func (k *Kettle) Store() cstore.ConfidentialStorageBackend {
+	return k.Engine().Backend()
+}
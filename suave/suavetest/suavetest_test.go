@@ -0,0 +1,87 @@
+package suavetest
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/suave/cstore"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFramework(t *testing.T) {
+	f := New(t, WithKettles(2), WithL1(), WithBeacon())
+	require.Len(t, f.Kettles, 2)
+	require.NotEqual(t, f.Kettles[0].Address(), f.Kettles[1].Address())
+
+	// Both chains produce blocks
+	require.Equal(t, uint64(1), f.Kettle().ProgressChain().NumberU64())
+	require.Equal(t, uint64(1), f.L1.ProgressChain().NumberU64())
+
+	// Bids stored by a kettle reach the other one
+	kettle := f.Kettle()
+	request, err := types.SignTx(types.NewTx(&types.ConfidentialComputeRequest{
+		ConfidentialComputeRecord: types.ConfidentialComputeRecord{
+			KettleAddress: kettle.Address(),
+		},
+	}), types.NewSuaveSigner(params.AllEthashProtocolChanges.ChainID), FundedKey)
+	require.NoError(t, err)
+
+	bid, err := kettle.Engine().InitializeBid(types.Bid{
+		DecryptionCondition: 1,
+		AllowedPeekers:      []common.Address{{0x42}},
+		AllowedStores:       f.KettleAddresses(),
+	}, request)
+	require.NoError(t, err)
+	require.NoError(t, kettle.Engine().Finalize(request, nil, []cstore.StoreWrite{{
+		Bid:    bid,
+		Caller: common.Address{0x42},
+		Key:    "key",
+		Value:  []byte{0x43},
+	}}))
+
+	f.WaitForStored(bid.Id, "key", []byte{0x43})
+	f.Kettles[1].RequireStored(bid.Id, "key", []byte{0x43})
+	f.Kettles[1].RequireNotStored(bid.Id, "other")
+
+	// Slots are announced to every kettle
+	head := f.L1.CurrentBlock()
+	slot := &types.BuildBlockArgs{
+		Slot:           7,
+		ProposerPubkey: []byte{0x42},
+		Parent:         head.Hash(),
+		Timestamp:      uint64(time.Now().Unix()) + 12,
+		FeeRecipient:   common.Address{0x42},
+		GasLimit:       head.GasLimit,
+		Random:         common.Hash{0x43},
+		Withdrawals: []*types.Withdrawal{
+			{Index: 1, Validator: 2, Address: common.Address{0x44}, Amount: 3},
+		},
+	}
+	f.AnnounceSlot(slot)
+	for _, kettle := range f.Kettles {
+		upcoming, err := kettle.Service.APIBackend.SuaveBeaconBackend().UpcomingBuildBlockArgs(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, slot, upcoming)
+	}
+
+	// The relay records submissions
+	resp, err := http.Post(f.Relay.URL()+"/relay/v1/builder/blocks", "application/json", strings.NewReader(`{"block":1}`))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	submission := f.Relay.LastSubmission()
+	require.NotNil(t, submission)
+	require.Equal(t, "/relay/v1/builder/blocks", submission.Path)
+
+	var decoded struct{ Block *big.Int }
+	require.NoError(t, submission.Decode(&decoded))
+	require.Equal(t, big.NewInt(1), decoded.Block)
+}